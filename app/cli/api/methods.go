@@ -8,8 +8,10 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"plandex-cli/types"
 	"strings"
+	"time"
 
 	shared "plandex-shared"
 
@@ -376,6 +378,68 @@ func (a *Api) CreatePlan(projectId string, req shared.CreatePlanRequest) (*share
 	return &respBody, nil
 }
 
+func (a *Api) ExportPlan(planId string, w io.Writer) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/export", GetApiHost(), planId)
+
+	resp, err := authenticatedSlowClient.Get(serverUrl)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ExportPlan(planId, w)
+		}
+		return apiErr
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error reading response: %v", err)}
+	}
+
+	return nil
+}
+
+func (a *Api) ImportPlan(projectId, name string, archive io.ReadSeeker) (*shared.CreatePlanResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/plans/import", GetApiHost(), projectId)
+	if name != "" {
+		serverUrl += "?name=" + url.QueryEscape(name)
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/gzip", archive)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			_, err = archive.Seek(0, io.SeekStart)
+			if err != nil {
+				return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error rewinding archive: %v", err)}
+			}
+			return a.ImportPlan(projectId, name, archive)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.CreatePlanResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
 func (a *Api) GetPlan(planId string) (*shared.Plan, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s", GetApiHost(), planId)
 
@@ -599,6 +663,42 @@ func (a *Api) RespondMissingFile(planId, branch string, req shared.RespondMissin
 
 }
 
+func (a *Api) RespondCodeSearch(planId, branch string, req shared.RespondCodeSearchRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/respond_code_search", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPost, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	// symbol searches include the contents of matching files
+	resp, err := authenticatedSlowClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+
+		if didRefresh {
+			return a.RespondCodeSearch(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
 func (a *Api) ConnectPlan(planId, branch string, onStream types.OnStreamPlan) *shared.ApiError {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/connect", GetApiHost(), planId, branch)
 
@@ -929,6 +1029,73 @@ func (a *Api) RejectFiles(planId, branch string, paths []string) *shared.ApiErro
 	return nil
 }
 
+func (a *Api) RejectReplacements(planId, branch string, replacementIds []string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/reject_replacements", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(shared.RejectReplacementsRequest{ReplacementIds: replacementIds})
+
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.RejectReplacements(planId, branch, replacementIds)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) EditReplacements(planId, branch string, edits []*shared.ReplacementEdit) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/edit_replacements", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(shared.EditReplacementsRequest{Edits: edits})
+
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.EditReplacements(planId, branch, edits)
+		}
+		return apiErr
+	}
+
+	return nil
+}
 func (a *Api) LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context", GetApiHost(), planId, branch)
 	reqBytes, err := json.Marshal(req)
@@ -1318,6 +1485,71 @@ func (a *Api) CreateAccount(req shared.CreateAccountRequest, customHost string)
 	return &sessionResponse, nil
 }
 
+func (a *Api) GetOidcConfig(customHost string) (*shared.OidcConfigResponse, *shared.ApiError) {
+	host := customHost
+	if host == "" {
+		host = CloudApiHost
+	}
+	serverUrl := host + "/accounts/oidc"
+
+	resp, err := unauthenticatedClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	// older servers don't have the endpoint—treat them as not having sso
+	if resp.StatusCode == http.StatusNotFound {
+		return &shared.OidcConfigResponse{}, nil
+	}
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		return nil, apiErr
+	}
+
+	var res shared.OidcConfigResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) SignInWithOidc(req shared.OidcSignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError) {
+	host := customHost
+	if host == "" {
+		host = CloudApiHost
+	}
+	serverUrl := host + "/accounts/oidc/sign_in"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := unauthenticatedClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		return nil, apiErr
+	}
+
+	var sessionResponse shared.SessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&sessionResponse)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &sessionResponse, nil
+}
+
 func (a *Api) CreateOrg(req shared.CreateOrgRequest) (*shared.CreateOrgResponse, *shared.ApiError) {
 	serverUrl := GetApiHost() + "/orgs"
 	reqBytes, err := json.Marshal(req)
@@ -1522,63 +1754,55 @@ func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
 	return roles, nil
 }
 
-func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
-	serverUrl := GetApiHost() + "/invites"
+func (a *Api) CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
+
 	reqBytes, err := json.Marshal(req)
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
 
 	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
 		apiErr := HandleApiError(resp, errorBody)
-
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.InviteUser(req)
+			return a.CreateOrgRole(req)
 		}
-		return apiErr
+		return nil, apiErr
 	}
 
-	return nil
-}
-
-func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/pending"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		errorBody, _ := io.ReadAll(resp.Body)
-		apiErr := HandleApiError(resp, errorBody)
-		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
-		if authRefreshed {
-			return a.ListPendingInvites()
-		}
-		return nil, apiErr
+	return &role, nil
+}
+
+func (a *Api) UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
 
-	return invites, nil
-}
+	request.Header.Set("Content-Type", "application/json")
 
-func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/accepted"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+	resp, err := authenticatedFastClient.Do(request)
 	if err != nil {
 		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
@@ -1589,25 +1813,30 @@ func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListAcceptedInvites()
+			return a.UpdateOrgRole(roleId, req)
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
 	if err != nil {
 		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
 	}
 
-	return invites, nil
+	return &role, nil
 }
 
-func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/all"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+func (a *Api) DeleteOrgRole(roleId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -1616,7 +1845,170 @@ func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListAllInvites()
+			return a.DeleteOrgRole(roleId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/users/%s/role", GetApiHost(), userId)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetOrgUserRole(userId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPermissions() (shared.Permissions, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/permissions"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPermissions()
+		}
+		return nil, apiErr
+	}
+
+	var perms shared.Permissions
+	err = json.NewDecoder(resp.Body).Decode(&perms)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return perms, nil
+}
+
+func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/invites"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.InviteUser(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/pending"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPendingInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/accepted"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAcceptedInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/all"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAllInvites()
 		}
 		return nil, apiErr
 	}
@@ -1823,6 +2215,38 @@ func (a *Api) CreateBranch(planId, branch string, req shared.CreateBranchRequest
 	return nil
 }
 
+func (a *Api) MergeBranch(planId, branch string, req shared.MergeBranchRequest) (*shared.MergeBranchResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/merge", GetApiHost(), planId, branch)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.MergeBranch(planId, branch, req)
+		}
+		return nil, apiErr
+	}
+
+	var mergeBranchResponse shared.MergeBranchResponse
+	err = json.NewDecoder(resp.Body).Decode(&mergeBranchResponse)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &mergeBranchResponse, nil
+}
+
 func (a *Api) DeleteBranch(planId, branch string) *shared.ApiError {
 	serverUrl := fmt.Sprintf("%s/plans/%s/branches/%s", GetApiHost(), planId, branch)
 
@@ -2295,12 +2719,12 @@ func (a *Api) GetCreditsSummary(req shared.CreditsLogRequest) (*shared.CreditsSu
 	return res, nil
 }
 
-func (a *Api) GetBalance() (decimal.Decimal, *shared.ApiError) {
-	serverUrl := fmt.Sprintf("%s/billing/balance", GetApiHost())
+func (a *Api) ListModelPrices() ([]*shared.ModelPrice, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/model_prices", GetApiHost())
 
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return decimal.Zero, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -2309,30 +2733,38 @@ func (a *Api) GetBalance() (decimal.Decimal, *shared.ApiError) {
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.GetBalance()
+			return a.ListModelPrices()
 		}
-		return decimal.Zero, apiErr
+		return nil, apiErr
 	}
 
-	var res *shared.GetBalanceResponse
+	var res shared.ListModelPricesResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return decimal.Zero, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
 	}
 
-	return res.Balance, nil
+	return res.Prices, nil
 }
 
-func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError) {
-	serverUrl := fmt.Sprintf("%s/file_map", GetApiHost())
+func (a *Api) SetModelPrice(req shared.SetModelPriceRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/model_prices", GetApiHost())
+
 	reqBytes, err := json.Marshal(req)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
 
-	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -2341,26 +2773,47 @@ func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapRespon
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.GetFileMap(req)
+			return a.SetModelPrice(req)
 		}
-		return nil, apiErr
+		return apiErr
 	}
 
-	var respBody shared.GetFileMapResponse
-	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	return nil
+}
+
+func (a *Api) DeleteModelPrice(modelId shared.ModelId) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/model_prices/%s", GetApiHost(), modelId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
 
-	return &respBody, nil
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteModelPrice(modelId)
+		}
+		return apiErr
+	}
+
+	return nil
 }
 
-func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError) {
-	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context/%s/body", GetApiHost(), planId, branch, contextId)
+func (a *Api) GetBalance() (decimal.Decimal, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/billing/balance", GetApiHost())
 
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return decimal.Zero, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -2369,23 +2822,83 @@ func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetConte
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.GetContextBody(planId, branch, contextId)
+			return a.GetBalance()
 		}
-		return nil, apiErr
+		return decimal.Zero, apiErr
 	}
 
-	var respBody shared.GetContextBodyResponse
-	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	var res *shared.GetBalanceResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return decimal.Zero, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
 	}
 
-	return &respBody, nil
+	return res.Balance, nil
 }
 
-func (a *Api) AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError) {
-	serverUrl := fmt.Sprintf("%s/plans/%s/%s/auto_load_context", GetApiHost(), planId, branch)
-	reqBytes, err := json.Marshal(req)
+func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_map", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileMap(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileMapResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
+func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context/%s/body", GetApiHost(), planId, branch, contextId)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetContextBody(planId, branch, contextId)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetContextBodyResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
+func (a *Api) AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/auto_load_context", GetApiHost(), planId, branch)
+	reqBytes, err := json.Marshal(req)
 	if err != nil {
 		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
 	}
@@ -2452,3 +2965,941 @@ func (a *Api) GetBuildStatus(planId, branch string) (*shared.GetBuildStatusRespo
 
 	return &respBody, nil
 }
+
+func (a *Api) ListMcpServers(projectId string) ([]*shared.McpServer, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/mcp_servers", GetApiHost(), projectId)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListMcpServers(projectId)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListMcpServersResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Servers, nil
+}
+
+func (a *Api) UpsertMcpServer(projectId string, req shared.UpsertMcpServerRequest) (*shared.McpServer, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/mcp_servers", GetApiHost(), projectId)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpsertMcpServer(projectId, req)
+		}
+		return nil, apiErr
+	}
+
+	var server shared.McpServer
+	err = json.NewDecoder(resp.Body).Decode(&server)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &server, nil
+}
+
+func (a *Api) SetMcpServerEnabled(projectId, name string, enabled bool) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/projects/%s/mcp_servers/%s/enabled", GetApiHost(), projectId, url.PathEscape(name))
+
+	reqBytes, err := json.Marshal(map[string]bool{"enabled": enabled})
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetMcpServerEnabled(projectId, name, enabled)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeleteMcpServer(projectId, name string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/projects/%s/mcp_servers/%s", GetApiHost(), projectId, url.PathEscape(name))
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteMcpServer(projectId, name)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListMcpServerTools(projectId, name string) ([]*shared.McpToolInfo, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/mcp_servers/%s/tools", GetApiHost(), projectId, url.PathEscape(name))
+
+	resp, err := authenticatedSlowClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListMcpServerTools(projectId, name)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.McpServerToolsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Tools, nil
+}
+
+func (a *Api) ListWebhooks() ([]*shared.Webhook, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/webhooks", GetApiHost())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListWebhooks()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListWebhooksResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Webhooks, nil
+}
+
+func (a *Api) CreateWebhook(req shared.CreateWebhookRequest) (*shared.Webhook, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/webhooks", GetApiHost())
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateWebhook(req)
+		}
+		return nil, apiErr
+	}
+
+	var webhook shared.Webhook
+	err = json.NewDecoder(resp.Body).Decode(&webhook)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &webhook, nil
+}
+
+func (a *Api) SetWebhookEnabled(webhookId string, enabled bool) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s/enabled", GetApiHost(), webhookId)
+
+	reqBytes, err := json.Marshal(map[string]bool{"enabled": enabled})
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetWebhookEnabled(webhookId, enabled)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeleteWebhook(webhookId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s", GetApiHost(), webhookId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteWebhook(webhookId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) TestWebhook(webhookId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/webhooks/%s/test", GetApiHost(), webhookId)
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.TestWebhook(webhookId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListWebhookDeliveries(webhookId string, limit int) ([]*shared.WebhookDelivery, *shared.ApiError) {
+	params := url.Values{}
+	if webhookId != "" {
+		params.Set("webhookId", webhookId)
+	}
+	if limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
+
+	serverUrl := fmt.Sprintf("%s/webhooks/deliveries?%s", GetApiHost(), params.Encode())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListWebhookDeliveries(webhookId, limit)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListWebhookDeliveriesResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Deliveries, nil
+}
+
+func (a *Api) ListAuditEvents(req shared.ListAuditEventsRequest) (*shared.ListAuditEventsResponse, *shared.ApiError) {
+	params := url.Values{}
+	if req.Action != "" {
+		params.Set("action", req.Action)
+	}
+	if req.User != "" {
+		params.Set("user", req.User)
+	}
+	if req.PlanId != "" {
+		params.Set("planId", req.PlanId)
+	}
+	if req.Since != nil {
+		params.Set("since", req.Since.Format(time.RFC3339))
+	}
+	if req.Until != nil {
+		params.Set("until", req.Until.Format(time.RFC3339))
+	}
+	if req.Before != "" {
+		params.Set("before", req.Before)
+	}
+	if req.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", req.Limit))
+	}
+
+	serverUrl := fmt.Sprintf("%s/audit?%s", GetApiHost(), params.Encode())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAuditEvents(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListAuditEventsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ReportExecutedCommands(planId, branch string, req shared.ReportExecutedCommandsRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/executed_commands", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ReportExecutedCommands(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ReportPlanEvent(planId, branch string, req shared.ReportPlanEventRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/events", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ReportPlanEvent(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plan_templates", GetApiHost())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanTemplates()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListPlanTemplatesResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Templates, nil
+}
+
+func (a *Api) GetPlanTemplate(name string) (*shared.PlanTemplate, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plan_templates/%s", GetApiHost(), url.PathEscape(name))
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetPlanTemplate(name)
+		}
+		return nil, apiErr
+	}
+
+	var template shared.PlanTemplate
+	err = json.NewDecoder(resp.Body).Decode(&template)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &template, nil
+}
+
+func (a *Api) CreatePlanTemplate(req shared.CreatePlanTemplateRequest) (*shared.PlanTemplate, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plan_templates", GetApiHost())
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreatePlanTemplate(req)
+		}
+		return nil, apiErr
+	}
+
+	var template shared.PlanTemplate
+	err = json.NewDecoder(resp.Body).Decode(&template)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &template, nil
+}
+
+func (a *Api) DeletePlanTemplate(name string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plan_templates/%s", GetApiHost(), url.PathEscape(name))
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeletePlanTemplate(name)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) CreatePlanJob(planId, branch string, req shared.CreatePlanJobRequest) (*shared.PlanJob, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/jobs", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreatePlanJob(planId, branch, req)
+		}
+		return nil, apiErr
+	}
+
+	var job shared.PlanJob
+	err = json.NewDecoder(resp.Body).Decode(&job)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &job, nil
+}
+
+func (a *Api) ListPlanJobs(planId string) ([]*shared.PlanJob, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/jobs", GetApiHost(), planId)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanJobs(planId)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListPlanJobsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Jobs, nil
+}
+
+func (a *Api) DeletePlanJob(jobId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plan_jobs/%s", GetApiHost(), jobId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeletePlanJob(jobId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanShares(planId)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListPlanSharesResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Shares, nil
+}
+
+func (a *Api) SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SharePlan(planId, req)
+		}
+		return nil, apiErr
+	}
+
+	var share shared.PlanShare
+	err = json.NewDecoder(resp.Body).Decode(&share)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &share, nil
+}
+
+func (a *Api) UnsharePlan(planId, userId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares/%s", GetApiHost(), planId, userId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UnsharePlan(planId, userId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetApiTokenSession() (*shared.ApiTokenSessionResponse, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens/session"
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetApiTokenSession()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ApiTokenSessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ListApiTokens() ([]*shared.ApiToken, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens"
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListApiTokens()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListApiTokensResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.Tokens, nil
+}
+
+func (a *Api) CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens"
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateApiToken(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.CreateApiTokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) RevokeApiToken(tokenId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/api_tokens/%s", GetApiHost(), tokenId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.RevokeApiToken(tokenId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListServiceAccounts() ([]*shared.ServiceAccount, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/service_accounts"
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListServiceAccounts()
+		}
+		return nil, apiErr
+	}
+
+	var res shared.ListServiceAccountsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return res.ServiceAccounts, nil
+}
+
+func (a *Api) CreateServiceAccount(req shared.CreateServiceAccountRequest) (*shared.ServiceAccount, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/service_accounts"
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateServiceAccount(req)
+		}
+		return nil, apiErr
+	}
+
+	var serviceAccount shared.ServiceAccount
+	err = json.NewDecoder(resp.Body).Decode(&serviceAccount)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &serviceAccount, nil
+}
+
+func (a *Api) DeleteServiceAccount(userId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/service_accounts/%s", GetApiHost(), userId)
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteServiceAccount(userId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
@@ -163,6 +163,26 @@ func promptSignInNewAccount() error {
 		return fmt.Errorf("error prompting host: %v", err)
 	}
 
+	if selected == SignInOtherOption {
+		useSso, oidcConfig, err := promptSso(host)
+		if err != nil {
+			return err
+		}
+
+		if useSso {
+			err = signInWithOidc(host, oidcConfig)
+			if err != nil {
+				return fmt.Errorf("error signing in with sso: %v", err)
+			}
+
+			if !term.IsRepl {
+				term.PrintCmds("", "")
+			}
+
+			return nil
+		}
+	}
+
 	if selected == SignInLocalOption {
 		email = "local-admin@plandex.ai"
 	} else {
@@ -198,6 +218,34 @@ func promptSignInNewAccount() error {
 	return nil
 }
 
+const (
+	SignInSsoOption   = "Single sign-on (SSO)"
+	SignInEmailOption = "Email pin"
+)
+
+// promptSso checks whether the host supports single sign-on, and if so whether it's required or the user prefers it to an email pin
+func promptSso(host string) (bool, *shared.OidcConfigResponse, error) {
+	oidcConfig, err := getOidcConfig(host)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !oidcConfig.Enabled {
+		return false, nil, nil
+	}
+
+	if oidcConfig.Required {
+		return true, oidcConfig, nil
+	}
+
+	selected, err := term.SelectFromList("Sign in with?", []string{SignInSsoOption, SignInEmailOption})
+	if err != nil {
+		return false, nil, fmt.Errorf("error selecting sign in method: %v", err)
+	}
+
+	return selected == SignInSsoOption, oidcConfig, nil
+}
+
 type verifyEmailRes struct {
 	hasAccount  bool
 	isLocalMode bool
@@ -264,6 +312,7 @@ func handleSignInResponse(res *shared.SessionResponse, host string) error {
 			IsCloud:     host == "",
 			Host:        host,
 			IsLocalMode: isLocalMode,
+			IsSso:       res.IsSso,
 		},
 	})
 
@@ -21,6 +21,13 @@ func SetAuthHeader(req *http.Request) error {
 	if Current == nil {
 		return fmt.Errorf("error setting auth header: auth not loaded")
 	}
+
+	// api tokens are sent as-is—the server gets the org from the token
+	if IsApiTokenAuth() {
+		req.Header.Set("Authorization", "Bearer "+Current.Token)
+		return nil
+	}
+
 	hash := Current.ToHash()
 
 	authHeader := shared.AuthHeader{
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	shared "plandex-shared"
+)
+
+// PLANDEX_TOKEN authenticates with an api token instead of the signed in account, for CI and scripts. The auth it resolves is only kept in memory.
+func getEnvApiToken() string {
+	return os.Getenv("PLANDEX_TOKEN")
+}
+
+func IsApiTokenAuth() bool {
+	return Current != nil && shared.IsApiToken(Current.Token)
+}
+
+func resolveApiTokenAuth(token string) error {
+	if !shared.IsApiToken(token) {
+		return fmt.Errorf("PLANDEX_TOKEN must be an api token starting with '%s'", shared.ApiTokenPrefix)
+	}
+
+	// PLANDEX_HOST selects a self-hosted server—Plandex Cloud is used if it's not set
+	host := os.Getenv("PLANDEX_HOST")
+
+	Current = &shared.ClientAuth{
+		ClientAccount: shared.ClientAccount{
+			IsCloud: host == "",
+			Host:    host,
+			Token:   token,
+		},
+	}
+
+	res, apiErr := apiClient.GetApiTokenSession()
+
+	if apiErr != nil {
+		return fmt.Errorf("error getting api token session: %v", apiErr.Msg)
+	}
+
+	Current.Email = res.ApiToken.UserEmail
+	Current.UserName = res.ApiToken.UserName
+	Current.UserId = res.ApiToken.UserId
+	Current.OrgId = res.Org.Id
+	Current.OrgName = res.Org.Name
+	Current.OrgIsTrial = res.Org.IsTrial
+	Current.IntegratedModelsMode = res.Org.IntegratedModelsMode
+
+	return nil
+}
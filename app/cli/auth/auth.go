@@ -12,6 +12,7 @@ import (
 
 var openUnauthenticatedCloudURL func(msg, path string)
 var openAuthenticatedURL func(msg, path string)
+var openURL func(msg, url string)
 
 func SetOpenUnauthenticatedCloudURLFn(fn func(msg, path string)) {
 	openUnauthenticatedCloudURL = fn
@@ -21,6 +22,10 @@ func SetOpenAuthenticatedURLFn(fn func(msg, path string)) {
 	openAuthenticatedURL = fn
 }
 
+func SetOpenURLFn(fn func(msg, url string)) {
+	openURL = fn
+}
+
 func MustResolveAuthWithOrg() {
 	MustResolveAuth(true)
 }
@@ -30,6 +35,16 @@ func MustResolveAuth(requireOrg bool) {
 		term.OutputErrorAndExit("error resolving auth: api client not set")
 	}
 
+	if token := getEnvApiToken(); token != "" {
+		err := resolveApiTokenAuth(token)
+
+		if err != nil {
+			term.OutputErrorAndExit("Error resolving auth: %v", err)
+		}
+
+		return
+	}
+
 	// load HomeAuthPath file into ClientAuth struct
 	bytes, err := os.ReadFile(fs.HomeAuthPath)
 
@@ -91,6 +106,23 @@ func RefreshInvalidToken() error {
 	if Current == nil {
 		return fmt.Errorf("error refreshing token: auth not loaded")
 	}
+
+	if IsApiTokenAuth() {
+		term.OutputErrorAndExit("PLANDEX_TOKEN is invalid, expired, or has been revoked")
+	}
+
+	if Current.IsSso {
+		oidcConfig, err := getOidcConfig(Current.Host)
+		if err != nil {
+			return err
+		}
+
+		if oidcConfig.Enabled {
+			fmt.Println("🔑 Your session has expired. Sign in again with your identity provider.")
+			return signInWithOidc(Current.Host, oidcConfig)
+		}
+	}
+
 	res, err := verifyEmail(Current.Email, Current.Host)
 
 	if err != nil {
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"plandex-cli/term"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const oidcCallbackPath = "/callback"
+const oidcCallbackTimeout = 5 * time.Minute
+
+const oidcCallbackHtml = `<!DOCTYPE html>
+<html><head><title>Plandex</title></head>
+<body style="font-family: sans-serif; text-align: center; padding-top: 4em;">
+<h2>%s</h2>
+<p>You can close this tab and return to your terminal.</p>
+</body></html>`
+
+type oidcCallbackResult struct {
+	code string
+	err  error
+}
+
+func getOidcConfig(host string) (*shared.OidcConfigResponse, error) {
+	term.StartSpinner("")
+	res, apiErr := apiClient.GetOidcConfig(host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return nil, fmt.Errorf("error getting sso config: %v", apiErr.Msg)
+	}
+
+	return res, nil
+}
+
+// signInWithOidc runs the authorization code flow with PKCE in the user's browser, receiving the code on a loopback port. The server exchanges the code with the identity provider.
+func signInWithOidc(host string, config *shared.OidcConfigResponse) error {
+	verifier, err := shared.GenPkceVerifier()
+	if err != nil {
+		return fmt.Errorf("error generating code verifier: %v", err)
+	}
+
+	state, err := shared.GenPkceVerifier()
+	if err != nil {
+		return fmt.Errorf("error generating state: %v", err)
+	}
+
+	nonce, err := shared.GenPkceVerifier()
+	if err != nil {
+		return fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.RedirectPort))
+	if err != nil {
+		return fmt.Errorf("error listening for sso callback: %v", err)
+	}
+
+	redirectUri := fmt.Sprintf("http://127.0.0.1:%d%s", listener.Addr().(*net.TCPAddr).Port, oidcCallbackPath)
+
+	resultCh := make(chan oidcCallbackResult, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var result oidcCallbackResult
+		if q.Get("state") != state {
+			result.err = fmt.Errorf("state mismatch in sso callback")
+		} else if errCode := q.Get("error"); errCode != "" {
+			msg := errCode
+			if desc := q.Get("error_description"); desc != "" {
+				msg = fmt.Sprintf("%s: %s", errCode, desc)
+			}
+			result.err = fmt.Errorf("identity provider returned an error: %s", msg)
+		} else if q.Get("code") == "" {
+			result.err = fmt.Errorf("no authorization code in sso callback")
+		} else {
+			result.code = q.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, oidcCallbackHtml, "Sign in failed")
+		} else {
+			fmt.Fprintf(w, oidcCallbackHtml, "Signed in to Plandex")
+		}
+
+		select {
+		case resultCh <- result:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	authUrl, err := url.Parse(config.AuthorizationEndpoint)
+	if err != nil {
+		return fmt.Errorf("error parsing authorization endpoint: %v", err)
+	}
+
+	params := authUrl.Query()
+	params.Set("response_type", "code")
+	params.Set("client_id", config.ClientId)
+	params.Set("redirect_uri", redirectUri)
+	params.Set("scope", strings.Join(config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", shared.PkceChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+	authUrl.RawQuery = params.Encode()
+
+	openURL("Opening your identity provider to sign in...", authUrl.String())
+	fmt.Println()
+
+	term.StartSpinner("Waiting for sign in...")
+
+	var result oidcCallbackResult
+	select {
+	case result = <-resultCh:
+	case <-time.After(oidcCallbackTimeout):
+		term.StopSpinner()
+		return fmt.Errorf("timed out waiting for sso sign in")
+	}
+
+	if result.err != nil {
+		term.StopSpinner()
+		return result.err
+	}
+
+	res, apiErr := apiClient.SignInWithOidc(shared.OidcSignInRequest{
+		Code:         result.code,
+		CodeVerifier: verifier,
+		RedirectUri:  redirectUri,
+		Nonce:        nonce,
+	}, host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return fmt.Errorf("error signing in: %v", apiErr.Msg)
+	}
+
+	return handleSignInResponse(res, host)
+}
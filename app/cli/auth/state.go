@@ -91,6 +91,11 @@ func writeCurrentAuth() error {
 		return fmt.Errorf("error writing auth: auth not loaded")
 	}
 
+	// api token auth comes from the environment and isn't persisted
+	if IsApiTokenAuth() {
+		return nil
+	}
+
 	bytes, err := json.Marshal(Current)
 
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 )
 
 var autoCommit, skipCommit, autoExec bool
+var recoverApply bool
 
 func init() {
 	initApplyFlags(applyCmd, false)
@@ -18,6 +19,7 @@ func init() {
 	RootCmd.AddCommand(applyCmd)
 
 	applyCmd.Flags().BoolVar(&fullAuto, "full", false, "Apply the plan and debug in full auto mode")
+	applyCmd.Flags().BoolVar(&recoverApply, "recover", false, "Roll back or finish an interrupted apply")
 }
 
 var applyCmd = &cobra.Command{
@@ -31,6 +33,11 @@ func apply(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveProject()
 
+	if recoverApply {
+		lib.MustRecoverApply()
+		return
+	}
+
 	if fullAuto {
 		term.StartSpinner("")
 		config := lib.MustGetCurrentPlanConfig()
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var auditAction string
+var auditUser string
+var auditCurrentPlan bool
+var auditSince string
+var auditUntil string
+var auditLimit int
+var auditOutput string
+
+const auditExportPageSize = 1000
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the org's audit log",
+	Long: `Show who changed what and when in your org: plan creation, deletion, applies, rewinds, executed commands, loads of ignored files, invites, role changes, and settings and model updates.
+
+Filter by action with --action. Use a full action like 'plan.apply' or a category like 'plan':
+
+  plandex audit --action plan.apply --user dev@example.com --since 7d
+
+Use 'plandex audit export' to write the full log as JSONL. Only org owners can view the audit log.`,
+	Args: cobra.NoArgs,
+	Run:  listAuditEvents,
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the org's audit log as JSONL",
+	Long: `Export every audit event that matches the filters as JSONL—one event per line, newest first. Writes to stdout unless you pass --output:
+
+  plandex audit export --since 2025-07-01 --output audit.jsonl`,
+	Args: cobra.NoArgs,
+	Run:  exportAuditEvents,
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+
+	for _, cmd := range []*cobra.Command{auditCmd, auditExportCmd} {
+		cmd.Flags().StringVarP(&auditAction, "action", "a", "", "Only show this action or category of actions, like 'plan.apply' or 'plan'")
+		cmd.Flags().StringVarP(&auditUser, "user", "u", "", "Only show actions by this user (email or id)")
+		cmd.Flags().BoolVarP(&auditCurrentPlan, "plan", "p", false, "Only show actions on the current plan")
+		cmd.Flags().StringVar(&auditSince, "since", "", "Only show actions since a date (2025-07-01) or duration ago (24h, 7d)")
+		cmd.Flags().StringVar(&auditUntil, "until", "", "Only show actions before a date (2025-07-01) or duration ago (24h, 7d)")
+	}
+
+	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Number of events to show")
+	auditExportCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "File to write to instead of stdout")
+}
+
+func listAuditEvents(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := mustGetAuditEventsRequest()
+	req.Limit = auditLimit
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ListAuditEvents(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing audit events: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("audit", res)
+		return
+	}
+
+	if len(res.Events) == 0 {
+		fmt.Println("🤷‍♂️ No audit events")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Time", "User", "Action", "Plan", "Details"})
+
+	for _, event := range res.Events {
+		user := event.UserEmail
+		if event.ApiTokenId != "" {
+			user += " (token)"
+		}
+
+		plan := event.PlanName
+		if plan != "" && event.Branch != "" && event.Branch != "main" {
+			plan += " (" + event.Branch + ")"
+		}
+
+		table.Append([]string{
+			format.Time(event.CreatedAt),
+			user,
+			string(event.Action),
+			plan,
+			auditEventDetails(event),
+		})
+	}
+
+	table.Render()
+
+	if res.NextCursor != "" {
+		fmt.Println()
+		fmt.Printf("Showing the latest %d events. Use --limit to show more or 'plandex audit export' to get them all.\n", len(res.Events))
+	}
+}
+
+func exportAuditEvents(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := mustGetAuditEventsRequest()
+	req.Limit = auditExportPageSize
+
+	var out io.Writer = os.Stdout
+	if auditOutput != "" {
+		f, err := os.Create(auditOutput)
+		if err != nil {
+			term.OutputErrorAndExit("Error creating %s: %v", auditOutput, err)
+		}
+		defer f.Close()
+		out = f
+
+		term.StartSpinner("Exporting audit log...")
+	}
+
+	enc := json.NewEncoder(out)
+	numEvents := 0
+
+	for {
+		res, apiErr := api.Client.ListAuditEvents(req)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error listing audit events: %v", apiErr.Msg)
+		}
+
+		for _, event := range res.Events {
+			err := enc.Encode(event)
+			if err != nil {
+				term.StopSpinner()
+				term.OutputErrorAndExit("Error writing audit event: %v", err)
+			}
+		}
+		numEvents += len(res.Events)
+
+		if res.NextCursor == "" {
+			break
+		}
+		req.Before = res.NextCursor
+	}
+
+	if auditOutput != "" {
+		term.StopSpinner()
+		fmt.Printf("✅ Exported %d audit events to %s\n", numEvents, auditOutput)
+	}
+}
+
+func mustGetAuditEventsRequest() shared.ListAuditEventsRequest {
+	req := shared.ListAuditEventsRequest{
+		Action: auditAction,
+		User:   auditUser,
+	}
+
+	if auditCurrentPlan {
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		req.PlanId = lib.CurrentPlanId
+	}
+
+	var err error
+	req.Since, err = parseAuditTime(auditSince)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --since: %v", err)
+	}
+	req.Until, err = parseAuditTime(auditUntil)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --until: %v", err)
+	}
+
+	return req
+}
+
+// parseAuditTime accepts a date, an RFC 3339 timestamp, or a duration before now like '24h' or '7d'
+func parseAuditTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err == nil {
+			t := time.Now().AddDate(0, 0, -days)
+			return &t, nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%q isn't a date (2025-07-01), timestamp, or duration (24h, 7d)", s)
+}
+
+// auditEventDetails summarizes an event's data on a single line
+func auditEventDetails(event *shared.AuditEvent) string {
+	if commands, ok := event.Data["commands"].(string); ok {
+		lines := strings.Split(strings.TrimSpace(commands), "\n")
+		var cmds []string
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "set -") {
+				continue
+			}
+			cmds = append(cmds, line)
+		}
+		return truncateAuditDetails(fmt.Sprintf("exit %v: %s", event.Data["exitStatus"], strings.Join(cmds, "; ")))
+	}
+
+	keys := make([]string, 0, len(event.Data))
+	for k := range event.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		v := event.Data[k]
+		switch val := v.(type) {
+		case string:
+			if val == "" {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s=%s", k, strings.ReplaceAll(val, "\n", " ")))
+		case []interface{}:
+			if len(val) == 0 {
+				continue
+			}
+			strs := make([]string, len(val))
+			for i, item := range val {
+				strs[i] = fmt.Sprint(item)
+			}
+			parts = append(parts, fmt.Sprintf("%s=%s", k, strings.Join(strs, ",")))
+		case map[string]interface{}:
+			parts = append(parts, k+"={…}")
+		case nil:
+			continue
+		default:
+			parts = append(parts, fmt.Sprintf("%s=%v", k, val))
+		}
+	}
+
+	if event.TargetId != "" && len(parts) == 0 {
+		parts = append(parts, "id="+event.TargetId)
+	}
+
+	return truncateAuditDetails(strings.Join(parts, " "))
+}
+
+func truncateAuditDetails(s string) string {
+	if len(s) > 80 {
+		return s[:80] + "…"
+	}
+	return s
+}
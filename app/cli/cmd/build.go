@@ -58,6 +58,14 @@ func build(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error building plan: %v", err)
 	}
 
+	if term.IsJsonOutput() && (!didBuild || tellBg) {
+		term.OutputJsonResult("build", map[string]interface{}{
+			"built":      didBuild,
+			"background": tellBg && didBuild,
+		})
+		return
+	}
+
 	if !didBuild {
 		fmt.Println()
 		term.PrintCmds("", "log", "tell", "continue")
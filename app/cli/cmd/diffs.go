@@ -54,6 +54,17 @@ func diffs(cmd *cobra.Command, args []string) {
 		term.OutputNoCurrentPlanErrorAndExit()
 	}
 
+	if term.IsJsonOutput() {
+		term.StartSpinner("")
+		diffs, apiErr := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, true)
+		term.StopSpinner()
+		if apiErr != nil {
+			term.HandleApiError(apiErr)
+		}
+		term.OutputJsonResult("diff", map[string]interface{}{"diff": diffs})
+		return
+	}
+
 	term.StartSpinner("")
 
 	if showDiffUi {
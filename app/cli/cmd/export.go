@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var exportOutputPath string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [name-or-index]",
+	Short: "Export a plan to a portable archive",
+	Long:  `Export a plan—including its full history, branches, context, conversation, pending changes, and config—to a .tar.gz archive that can be imported on any Plandex server with 'plandex import'. Defaults to the current plan.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run:   export,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "Path to write the archive to (defaults to <plan-name>.tar.gz)")
+}
+
+func export(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	var nameOrIdx string
+	if len(args) > 0 {
+		nameOrIdx = strings.TrimSpace(args[0])
+	}
+
+	var plan *shared.Plan
+
+	if nameOrIdx == "" {
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+
+		term.StartSpinner("")
+		var apiErr *shared.ApiError
+		plan, apiErr = api.Client.GetPlan(lib.CurrentPlanId)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr.Msg)
+		}
+	} else {
+		term.StartSpinner("")
+		plans, apiErr := api.Client.ListPlans([]string{lib.CurrentProjectId})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plans: %v", apiErr.Msg)
+		}
+
+		idx, err := strconv.Atoi(nameOrIdx)
+		if err == nil && idx > 0 && idx <= len(plans) {
+			plan = plans[idx-1]
+		} else {
+			for _, p := range plans {
+				if p.Name == nameOrIdx {
+					plan = p
+					break
+				}
+			}
+		}
+	}
+
+	if plan == nil {
+		term.OutputErrorAndExit("Plan not found")
+	}
+
+	outputPath := exportOutputPath
+	if outputPath == "" {
+		outputPath = strings.ReplaceAll(plan.Name, string(os.PathSeparator), "-") + ".tar.gz"
+	}
+
+	// write to a temp file alongside the destination so a failed export doesn't clobber an existing archive
+	tmpFile, err := os.CreateTemp(filepath.Dir(outputPath), ".plandex-export-*")
+	if err != nil {
+		term.OutputErrorAndExit("Error creating output file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	term.StartSpinner("📦 Exporting plan...")
+	apiErr := api.Client.ExportPlan(plan.Id, tmpFile)
+	term.StopSpinner()
+
+	closeErr := tmpFile.Close()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error exporting plan: %v", apiErr.Msg)
+	}
+	if closeErr != nil {
+		term.OutputErrorAndExit("Error writing archive: %v", closeErr)
+	}
+
+	err = os.Rename(tmpFile.Name(), outputPath)
+	if err != nil {
+		term.OutputErrorAndExit("Error writing archive: %v", err)
+	}
+
+	fmt.Printf("✅ Exported plan %s to %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name), outputPath)
+	fmt.Println()
+	term.PrintCmds("", "import")
+}
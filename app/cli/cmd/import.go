@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var importName string
+var importNoSwitch bool
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a plan from an archive created by 'plandex export'",
+	Long:  `Import a plan from an archive created by 'plandex export' into the current project. The imported plan gets new ids and is owned by you. It's set as the current plan unless --no-switch is passed.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   importPlan,
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(&importName, "name", "n", "", "Name for the imported plan (defaults to the exported plan's name)")
+	importCmd.Flags().BoolVar(&importNoSwitch, "no-switch", false, "Don't set the imported plan as the current plan")
+}
+
+func importPlan(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveOrCreateProject()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error opening archive: %v", err)
+	}
+	defer f.Close()
+
+	term.StartSpinner("📦 Importing plan...")
+	res, apiErr := api.Client.ImportPlan(lib.CurrentProjectId, importName, f)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error importing plan: %v", apiErr.Msg)
+	}
+
+	planName := color.New(color.Bold, term.ColorHiGreen).Sprint(res.Name)
+
+	if importNoSwitch {
+		fmt.Printf("✅ Imported plan %s\n", planName)
+		fmt.Println()
+		term.PrintCmds("", "plans", "cd")
+		return
+	}
+
+	err = lib.WriteCurrentPlan(res.Id)
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current plan: %v", err)
+	}
+
+	err = lib.WriteCurrentBranch("main")
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current branch: %v", err)
+	}
+
+	// fire and forget, as in 'cd'
+	go api.Client.SetProjectPlan(lib.CurrentProjectId, shared.SetProjectPlanRequest{PlanId: res.Id})
+	time.Sleep(50 * time.Millisecond)
+
+	fmt.Printf("✅ Imported plan %s and set it to current plan\n", planName)
+	fmt.Println()
+	term.PrintCmds("", "current", "convo", "log")
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var jobCron string
+var jobAuto string
+var jobBranch string
+var jobPromptFile string
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List server-side jobs for the current plan",
+	Args:  cobra.NoArgs,
+	Run:   listJobs,
+}
+
+var jobsAddCmd = &cobra.Command{
+	Use:   "add [prompt]",
+	Short: "Queue a prompt to run on the server, once or on a schedule",
+	Long: `Queue a prompt to run on the server, once or on a cron schedule.
+
+Jobs run on the server without a connected terminal, using the context that's loaded in the plan and the model provider keys in the server's environment. Changes are left pending on the branch so you can review and apply them later.
+
+  plandex jobs add "Fix the failing lint checks and flaky tests" --cron "0 3 * * *" --auto semi
+
+Schedules use 5-field cron syntax (minute hour day-of-month month day-of-week) in UTC, or a macro like @nightly, @hourly, or @weekly.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  addJob,
+}
+
+var jobsRmCmd = &cobra.Command{
+	Use:     "rm <id>",
+	Aliases: []string{"remove", "cancel"},
+	Short:   "Remove a queued or scheduled job",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeJob,
+}
+
+func init() {
+	RootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsAddCmd)
+	jobsCmd.AddCommand(jobsRmCmd)
+
+	jobsAddCmd.Flags().StringVar(&jobCron, "cron", "", "Cron schedule for a recurring job, e.g. '0 3 * * *' or '@nightly'")
+	jobsAddCmd.Flags().StringVar(&jobAuto, "auto", "", "Auto mode for the job's runs (none, basic, plus, semi, full)")
+	jobsAddCmd.Flags().StringVarP(&jobBranch, "branch", "b", "", "Branch to run on (defaults to the current branch)")
+	jobsAddCmd.Flags().StringVarP(&jobPromptFile, "file", "f", "", "File containing the prompt")
+}
+
+func listJobs(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	jobs, apiErr := api.Client.ListPlanJobs(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing jobs: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		if jobs == nil {
+			jobs = []*shared.PlanJob{}
+		}
+		term.OutputJsonResult("jobs", jobs)
+		return
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("🤷‍♂️ No jobs for this plan")
+		fmt.Println()
+		term.PrintCmds("", "jobs add")
+		return
+	}
+
+	printJobsTable(jobs, nil)
+	fmt.Println()
+	term.PrintCmds("", "jobs add", "jobs rm", "ps")
+}
+
+func addJob(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	if jobCron != "" {
+		if _, err := shared.ParseCronSchedule(jobCron); err != nil {
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	if jobAuto != "" {
+		valid := false
+		for _, option := range shared.AutoModeOptions {
+			if option[0] == jobAuto && shared.AutoModeType(jobAuto) != shared.AutoModeCustom {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			term.OutputErrorAndExit("Invalid auto mode '%s'—use none, basic, plus, semi, or full", jobAuto)
+		}
+	}
+
+	branch := jobBranch
+	if branch == "" {
+		branch = lib.CurrentBranch
+	}
+
+	var prompt string
+	if len(args) > 0 {
+		prompt = args[0]
+	} else if jobPromptFile != "" {
+		bytes, err := os.ReadFile(jobPromptFile)
+		if err != nil {
+			term.OutputErrorAndExit("Error reading prompt file: %v", err)
+		}
+		prompt = string(bytes)
+	} else if term.IsJsonOutput() {
+		term.OutputErrorAndExit("A prompt is required with --json/--ndjson—pass it as an argument or with --file")
+	} else {
+		prompt = getEditorPrompt()
+	}
+
+	if strings.TrimSpace(prompt) == "" {
+		fmt.Println("🤷‍♂️ No prompt to queue")
+		return
+	}
+
+	term.StartSpinner("")
+	job, apiErr := api.Client.CreatePlanJob(lib.CurrentPlanId, branch, shared.CreatePlanJobRequest{
+		Prompt:   prompt,
+		AutoMode: shared.AutoModeType(jobAuto),
+		Schedule: jobCron,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error queuing job: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("jobs add", job)
+		return
+	}
+
+	if job.Schedule != "" {
+		fmt.Printf("✅ Scheduled job %s on branch %s (%s)\n", color.New(color.Bold, term.ColorHiCyan).Sprint(shortJobId(job.Id)), color.New(color.Bold, term.ColorHiGreen).Sprint(branch), job.Schedule)
+		if job.NextRunAt != nil {
+			fmt.Printf("Next run %s\n", format.Time(*job.NextRunAt))
+		}
+	} else {
+		fmt.Printf("✅ Queued job %s on branch %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(shortJobId(job.Id)), color.New(color.Bold, term.ColorHiGreen).Sprint(branch))
+	}
+
+	fmt.Println()
+	fmt.Println("Changes will be left pending for you to review")
+	fmt.Println()
+	term.PrintCmds("", "jobs", "ps", "diff")
+}
+
+func removeJob(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	jobs, apiErr := api.Client.ListPlanJobs(lib.CurrentPlanId)
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error listing jobs: %v", apiErr.Msg)
+	}
+
+	var job *shared.PlanJob
+	for _, j := range jobs {
+		if strings.HasPrefix(j.Id, args[0]) {
+			if job != nil {
+				term.StopSpinner()
+				term.OutputErrorAndExit("More than one job starts with %s", args[0])
+			}
+			job = j
+		}
+	}
+
+	if job == nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("No job with id %s", args[0])
+	}
+
+	apiErr = api.Client.DeletePlanJob(job.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing job: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("jobs rm", map[string]string{"id": job.Id})
+		return
+	}
+
+	fmt.Printf("✅ Removed job %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(shortJobId(job.Id)))
+	if job.Status == shared.PlanJobStatusRunning {
+		fmt.Println("Its current run will keep going—use 'plandex stop' to stop it")
+	}
+}
+
+// printJobsTable includes a plan column when plansById is set
+func printJobsTable(jobs []*shared.PlanJob, plansById map[string]*shared.Plan) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+
+	header := []string{"Id"}
+	if plansById != nil {
+		header = append(header, "Plan")
+	}
+	header = append(header, "Branch", "Prompt", "Schedule", "Status", "Next Run", "Last Run")
+	table.SetHeader(header)
+
+	for _, job := range jobs {
+		row := []string{shortJobId(job.Id)}
+		if plansById != nil {
+			planName := ""
+			if plan := plansById[job.PlanId]; plan != nil {
+				planName = plan.Name
+			}
+			row = append(row, planName)
+		}
+
+		schedule := job.Schedule
+		if schedule == "" {
+			schedule = "once"
+		}
+
+		nextRun := ""
+		if job.NextRunAt != nil && job.Status == shared.PlanJobStatusQueued {
+			nextRun = format.Time(*job.NextRunAt)
+		}
+
+		lastRun := ""
+		if job.LastRunAt != nil && job.LastRunStatus != "" {
+			lastRun = fmt.Sprintf("%s %s", job.LastRunStatus, format.Time(*job.LastRunAt))
+		}
+		if job.LastError != "" {
+			lastRun += "\n" + color.New(term.ColorHiRed).Sprint(truncateJobText(job.LastError, 60))
+		}
+
+		row = append(row,
+			job.Branch,
+			truncateJobText(job.Prompt, 40),
+			schedule,
+			string(job.Status),
+			nextRun,
+			lastRun,
+		)
+
+		table.Append(row)
+	}
+
+	table.Render()
+}
+
+func shortJobId(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func truncateJobText(s string, max int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return string(runes)
+}
@@ -8,6 +8,8 @@ import (
 	"plandex-cli/term"
 	"plandex-cli/types"
 
+	shared "plandex-shared"
+
 	"github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
@@ -19,14 +21,24 @@ var (
 	forceSkipIgnore bool
 	imageDetail     string
 	defsOnly        bool
+	loadGitDiff     bool
+	loadGitSince    string
+	loadGitStaged   bool
 )
 
 var contextLoadCmd = &cobra.Command{
 	Use:     "load [files-or-urls...]",
 	Aliases: []string{"l", "add"},
 	Short:   "Load context from various inputs",
-	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data.`,
-	Run:     contextLoad,
+	Long: `Load context from a file path, a directory, a URL, an image, a note, or piped data.
+
+Use --git-diff, --since, or --staged to load the files changed in git along with the diff itself. The diff is kept up to date as the changes are updated.
+
+  plandex load --git-diff       # uncommitted changes
+  plandex load --git-diff main  # everything on this branch since it diverged from main, including uncommitted changes
+  plandex load --since a1b2c3d  # commits since a1b2c3d
+  plandex load --staged         # staged changes`,
+	Run: contextLoad,
 }
 
 func init() {
@@ -36,6 +48,9 @@ func init() {
 	contextLoadCmd.Flags().BoolVarP(&forceSkipIgnore, "force", "f", false, "Load files even when ignored by .gitignore or .plandexignore")
 	contextLoadCmd.Flags().StringVarP(&imageDetail, "detail", "d", "high", "Image detail level (high or low)")
 	contextLoadCmd.Flags().BoolVar(&defsOnly, "map", false, "Load file maps (function/method/class signatures, variable names, types, etc.)")
+	contextLoadCmd.Flags().BoolVar(&loadGitDiff, "git-diff", false, "Load changes in the working tree, compared to HEAD or to the branch passed as an argument")
+	contextLoadCmd.Flags().StringVar(&loadGitSince, "since", "", "Load changes committed since a commit")
+	contextLoadCmd.Flags().BoolVar(&loadGitStaged, "staged", false, "Load staged changes")
 	RootCmd.AddCommand(contextLoadCmd)
 }
 
@@ -48,6 +63,11 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	gitDiff := resolveGitDiffSource(args)
+	if gitDiff != nil {
+		args = nil
+	}
+
 	lib.MustLoadContext(args, &types.LoadContextParams{
 		Note:            note,
 		Recursive:       recursive,
@@ -56,8 +76,48 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		ImageDetail:     openai.ImageURLDetail(imageDetail),
 		DefsOnly:        defsOnly,
 		SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
+		GitDiff:         gitDiff,
 	})
 
 	fmt.Println()
 	term.PrintCmds("", "ls", "tell", "debug")
 }
+
+func resolveGitDiffSource(args []string) *shared.GitDiffSource {
+	numGitFlags := 0
+	for _, set := range []bool{loadGitDiff, loadGitSince != "", loadGitStaged} {
+		if set {
+			numGitFlags++
+		}
+	}
+
+	if numGitFlags == 0 {
+		return nil
+	}
+	if numGitFlags > 1 {
+		term.OutputErrorAndExit("Only one of --git-diff, --since, and --staged can be used at a time")
+	}
+	if defsOnly || namesOnly {
+		term.OutputErrorAndExit("--map and --tree can't be used with git changes")
+	}
+
+	if loadGitDiff {
+		if len(args) > 1 {
+			term.OutputErrorAndExit("--git-diff takes a single branch or commit to compare with")
+		}
+		base := "HEAD"
+		if len(args) == 1 {
+			base = args[0]
+		}
+		return &shared.GitDiffSource{Base: base}
+	}
+
+	if len(args) > 0 {
+		term.OutputErrorAndExit("Files and URLs can't be loaded along with git changes")
+	}
+
+	if loadGitSince != "" {
+		return &shared.GitDiffSource{Since: loadGitSince}
+	}
+	return &shared.GitDiffSource{Staged: true}
+}
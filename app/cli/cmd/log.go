@@ -6,6 +6,8 @@ import (
 	"plandex-cli/auth"
 	"plandex-cli/lib"
 	"plandex-cli/term"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -42,6 +44,13 @@ func runLog(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting logs: %v", apiErr)
 	}
 
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("log", map[string]interface{}{
+			"entries": parseLogEntries(res.Body),
+		})
+		return
+	}
+
 	withLocalTimestamps, err := convertTimestampsToLocal(res.Body)
 
 	if err != nil {
@@ -86,3 +95,41 @@ func convertTimestampsToLocal(input string) (string, error) {
 
 	return result, nil
 }
+
+type logJsonEntry struct {
+	Sha       string    `json:"sha"`
+	CreatedAt time.Time `json:"createdAt"`
+	Message   string    `json:"message"`
+}
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+var logHeaderRegex = regexp.MustCompile(`(?m)^📝 Update ([a-f0-9]+) \| (.+)$`)
+
+// parseLogEntries splits the log body on the header the server writes for each update
+func parseLogEntries(body string) []logJsonEntry {
+	body = ansiRegex.ReplaceAllString(body, "")
+
+	entries := []logJsonEntry{}
+	headers := logHeaderRegex.FindAllStringSubmatchIndex(body, -1)
+
+	for i, idx := range headers {
+		end := len(body)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+
+		entry := logJsonEntry{
+			Sha:     body[idx[2]:idx[3]],
+			Message: strings.TrimSpace(body[idx[1]:end]),
+		}
+
+		t, err := time.Parse(lib.GitLogTimestampFormat+" MST", strings.TrimSpace(body[idx[4]:idx[5]]))
+		if err == nil {
+			entry.CreatedAt = t
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
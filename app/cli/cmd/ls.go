@@ -41,6 +41,16 @@ func listContext(cmd *cobra.Command, args []string) {
 	}
 	term.StopSpinner()
 
+	if term.IsJsonOutput() {
+		if contexts == nil {
+			contexts = []*shared.Context{}
+		}
+		term.OutputJsonResult("ls", map[string]interface{}{
+			"contexts": contexts,
+		})
+		return
+	}
+
 	totalTokens := 0
 	totalPlannerTokens := 0
 	totalMapTokens := 0
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	shared "plandex-shared"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var mcpUrl string
+var mcpEnv []string
+var mcpHeaders []string
+var mcpDisabled bool
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "List the MCP servers whose tools are available to plans in this project",
+	Args:  cobra.NoArgs,
+	Run:   listMcpServers,
+}
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name> [--url <url> | -- <command> [args...]]",
+	Short: "Add or update an MCP server for the project",
+	Long: `Add or update an MCP server for the project. Its tools are offered to the planner and coder during each tell.
+
+A stdio server is started by the Plandex server as a child process. The server's operator must enable them with MCP_STDIO_ENABLED, and it only gets PATH, HOME, and the --env variables you set:
+
+  plandex mcp add schema -- npx -y @acme/schema-mcp --db-env DATABASE_URL
+
+An http server is called at its 'streamable HTTP' endpoint. It must be on localhost unless the server's operator allows other hosts with MCP_HTTP_ALLOWED_HOSTS:
+
+  plandex mcp add tickets --url http://localhost:8931/mcp --header "Authorization=Bearer $TOKEN"`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  addMcpServer,
+}
+
+var mcpRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove an MCP server from the project",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeMcpServer,
+}
+
+var mcpEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Enable an MCP server",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setMcpServerEnabled(args[0], true)
+	},
+}
+
+var mcpDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Disable an MCP server without removing it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setMcpServerEnabled(args[0], false)
+	},
+}
+
+var mcpToolsCmd = &cobra.Command{
+	Use:   "tools <name>",
+	Short: "Connect to an MCP server and list its tools",
+	Args:  cobra.ExactArgs(1),
+	Run:   listMcpServerTools,
+}
+
+func init() {
+	RootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpAddCmd)
+	mcpCmd.AddCommand(mcpRmCmd)
+	mcpCmd.AddCommand(mcpEnableCmd)
+	mcpCmd.AddCommand(mcpDisableCmd)
+	mcpCmd.AddCommand(mcpToolsCmd)
+
+	mcpAddCmd.Flags().StringVar(&mcpUrl, "url", "", "Endpoint of an http MCP server")
+	mcpAddCmd.Flags().StringArrayVarP(&mcpEnv, "env", "e", nil, "Environment variable for a stdio server as KEY=VALUE (repeatable)—values already stored are kept unless replaced, and KEY= removes one")
+	mcpAddCmd.Flags().StringArrayVar(&mcpHeaders, "header", nil, "Request header for an http server as KEY=VALUE (repeatable)—values already stored are kept unless replaced, and KEY= removes one")
+	mcpAddCmd.Flags().BoolVar(&mcpDisabled, "disabled", false, "Add the server without enabling it")
+}
+
+func listMcpServers(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	servers, apiErr := api.Client.ListMcpServers(lib.CurrentProjectId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing MCP servers: %v", apiErr.Msg)
+	}
+
+	if len(servers) == 0 {
+		fmt.Println("🤷‍♂️ No MCP servers")
+		fmt.Println()
+		term.PrintCmds("", "mcp add")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Transport", "Command / Url", "Enabled"})
+
+	for _, server := range servers {
+		target := server.Url
+		if server.Transport == shared.McpTransportStdio {
+			target = strings.Join(append([]string{server.Command}, server.Args...), " ")
+		}
+
+		enabled := color.New(color.FgGreen).Sprint("✓")
+		if !server.Enabled {
+			enabled = color.New(color.FgHiBlack).Sprint("✗")
+		}
+
+		table.Append([]string{server.Name, string(server.Transport), target, enabled})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "mcp tools", "mcp add", "mcp rm", "mcp disable")
+}
+
+func addMcpServer(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+	command := args[1:]
+
+	req := shared.UpsertMcpServerRequest{
+		Name:    name,
+		Enabled: !mcpDisabled,
+	}
+
+	if mcpUrl != "" {
+		if len(command) > 0 {
+			term.OutputErrorAndExit("Use either --url for an http server or -- <command> for a stdio server, not both")
+		}
+		if len(mcpEnv) > 0 {
+			term.OutputErrorAndExit("--env is only used by stdio servers")
+		}
+		req.Transport = shared.McpTransportHttp
+		req.Url = mcpUrl
+		req.Headers = mustParseKeyValues("header", mcpHeaders)
+	} else {
+		if len(command) == 0 {
+			term.OutputErrorAndExit("Pass --url for an http server or -- <command> for a stdio server")
+		}
+		if len(mcpHeaders) > 0 {
+			term.OutputErrorAndExit("--header is only used by http servers")
+		}
+		req.Transport = shared.McpTransportStdio
+		req.Command = command[0]
+		req.Args = command[1:]
+		req.Env = mustParseKeyValues("env", mcpEnv)
+	}
+
+	term.StartSpinner("")
+	_, apiErr := api.Client.UpsertMcpServer(lib.CurrentProjectId, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error adding MCP server: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Added MCP server %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+	fmt.Println()
+	term.PrintCmds("", "mcp tools", "mcp")
+}
+
+func removeMcpServer(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteMcpServer(lib.CurrentProjectId, name)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing MCP server: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Removed MCP server %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+}
+
+func setMcpServerEnabled(name string, enabled bool) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetMcpServerEnabled(lib.CurrentProjectId, name, enabled)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating MCP server: %v", apiErr.Msg)
+	}
+
+	if enabled {
+		fmt.Printf("✅ Enabled MCP server %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+	} else {
+		fmt.Printf("✅ Disabled MCP server %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+	}
+}
+
+func listMcpServerTools(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+
+	term.StartSpinner("Connecting to " + name + "...")
+	tools, apiErr := api.Client.ListMcpServerTools(lib.CurrentProjectId, name)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing MCP server tools: %v", apiErr.Msg)
+	}
+
+	if len(tools) == 0 {
+		fmt.Printf("🤷‍♂️ %s has no tools\n", name)
+		return
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(true)
+	table.SetHeader([]string{"Tool", "Description"})
+	for _, tool := range tools {
+		table.Append([]string{tool.Name, tool.Description})
+	}
+	table.Render()
+}
+
+func mustParseKeyValues(flag string, values []string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			term.OutputErrorAndExit("Invalid --%s '%s'—expected KEY=VALUE", flag, v)
+		}
+		res[key] = value
+	}
+	return res
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var mergeOurs bool
+var mergeTheirs bool
+var cherryPickFrom string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <branch>",
+	Short: "Merge another plan branch into the current branch",
+	Long: `Merge another plan branch into the current branch.
+
+The source branch's conversation, context, and pending changes are added to the current branch. If both branches have pending changes to the same file, they're kept if they can be applied one after the other. Otherwise, you'll be asked how to resolve each conflict—or pass --ours or --theirs to resolve all of them at once.`,
+	Args: cobra.ExactArgs(1),
+	Run:  merge,
+}
+
+var cherryPickCmd = &cobra.Command{
+	Use:     "cherry-pick [sha]",
+	Aliases: []string{"cp"},
+	Short:   "Apply a single update from another plan branch to the current branch",
+	Long: `Apply a single update from another plan branch to the current branch.
+
+Pass a sha from 'plandex log' on another branch, or use --from to select an update from that branch's log. Conflicting pending changes are resolved the same way as with 'plandex merge'.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  cherryPick,
+}
+
+func init() {
+	RootCmd.AddCommand(mergeCmd)
+	RootCmd.AddCommand(cherryPickCmd)
+
+	for _, cmd := range []*cobra.Command{mergeCmd, cherryPickCmd} {
+		cmd.Flags().BoolVar(&mergeOurs, "ours", false, "Resolve conflicts by keeping the current branch's pending changes")
+		cmd.Flags().BoolVar(&mergeTheirs, "theirs", false, "Resolve conflicts by taking the incoming pending changes")
+	}
+
+	cherryPickCmd.Flags().StringVar(&cherryPickFrom, "from", "", "Branch to select an update from")
+}
+
+func merge(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	source := strings.TrimSpace(args[0])
+	if source == lib.CurrentBranch {
+		term.OutputErrorAndExit("Can't merge a branch into itself")
+	}
+
+	res := doMerge(shared.MergeBranchRequest{Source: source})
+	if res == nil {
+		return
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("merge", res)
+		return
+	}
+
+	if res.UpToDate {
+		fmt.Printf("🤷‍♂️ Branch %s is already up to date with %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch), color.New(color.Bold, term.ColorHiCyan).Sprint(source))
+		return
+	}
+
+	if res.FastForward {
+		fmt.Printf("✅ Fast-forwarded %s to %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch), color.New(color.Bold, term.ColorHiCyan).Sprint(source))
+	} else {
+		fmt.Printf("✅ Merged %s into %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(source), color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch))
+	}
+	printMergeSummary(res)
+}
+
+func cherryPick(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	var sha string
+	if len(args) > 0 {
+		sha = strings.TrimSpace(args[0])
+	} else {
+		if cherryPickFrom == "" {
+			term.OutputErrorAndExit("Pass a sha or use --from to select an update from another branch")
+		}
+		if cherryPickFrom == lib.CurrentBranch {
+			term.OutputErrorAndExit("Can't cherry-pick from the current branch")
+		}
+		sha = selectLogSha(cherryPickFrom)
+	}
+
+	res := doMerge(shared.MergeBranchRequest{Source: cherryPickFrom, Sha: sha})
+	if res == nil {
+		return
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("cherry-pick", res)
+		return
+	}
+
+	if res.UpToDate {
+		fmt.Printf("🤷‍♂️ %s is already in branch %s\n", sha, color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch))
+		return
+	}
+
+	fmt.Printf("✅ Cherry-picked %s into %s\n", sha, color.New(color.Bold, term.ColorHiGreen).Sprint(lib.CurrentBranch))
+	printMergeSummary(res)
+}
+
+// doMerge checks for conflicts with a dry run, resolves any it finds with the --ours/--theirs flags or by prompting, then merges. Returns nil if the merge is cancelled.
+func doMerge(req shared.MergeBranchRequest) *shared.MergeBranchResponse {
+	if mergeOurs && mergeTheirs {
+		term.OutputErrorAndExit("Cannot pass both --ours and --theirs")
+	}
+
+	if mergeOurs {
+		req.DefaultResolution = shared.MergeConflictResolutionOurs
+	} else if mergeTheirs {
+		req.DefaultResolution = shared.MergeConflictResolutionTheirs
+	}
+
+	if req.DefaultResolution == "" {
+		dryRun := req
+		dryRun.DryRun = true
+
+		term.StartSpinner("")
+		res, apiErr := api.Client.MergeBranch(lib.CurrentPlanId, lib.CurrentBranch, dryRun)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error merging: %v", apiErr)
+		}
+
+		if res.UpToDate {
+			return res
+		}
+
+		if len(res.Conflicts) > 0 {
+			req.Resolutions = resolveMergeConflicts(res.Conflicts)
+			if req.Resolutions == nil {
+				return nil
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.MergeBranch(lib.CurrentPlanId, lib.CurrentBranch, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error merging: %v", apiErr)
+	}
+
+	if len(res.Conflicts) > 0 {
+		// pending changes were added on one of the branches after the dry run
+		term.OutputErrorAndExit("Pending changes were updated during the merge. Run the command again to resolve the new conflicts.")
+	}
+
+	return res
+}
+
+// resolveMergeConflicts prompts for a resolution for each conflict. Returns nil if the user cancels.
+func resolveMergeConflicts(conflicts []*shared.MergeConflict) map[string]shared.MergeConflictResolution {
+	if term.IsJsonOutput() {
+		term.OutputErrorAndExit("%d conflicting pending changes—pass --ours or --theirs to resolve them", len(conflicts))
+	}
+
+	s := "files have"
+	if len(conflicts) == 1 {
+		s = "file has"
+	}
+	fmt.Printf("⚠️  %d %s pending changes on both branches that can't be combined\n\n", len(conflicts), s)
+
+	const (
+		optOurs   = "Keep current branch's changes"
+		optTheirs = "Take incoming changes"
+		optDiffs  = "View both diffs"
+		optCancel = "Cancel merge"
+	)
+
+	resolutions := map[string]shared.MergeConflictResolution{}
+
+	for _, conflict := range conflicts {
+		fmt.Printf("📄 %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(conflict.Path))
+		fmt.Printf("   current branch: %d pending %s\n", conflict.OursNumChanges, pluralizeChanges(conflict.OursNumChanges))
+		fmt.Printf("   incoming:       %d pending %s\n", conflict.TheirsNumChanges, pluralizeChanges(conflict.TheirsNumChanges))
+		fmt.Println()
+
+		for {
+			selected, err := term.SelectFromList("How do you want to resolve it?", []string{optOurs, optTheirs, optDiffs, optCancel})
+			if err != nil {
+				term.OutputErrorAndExit("Error getting user input: %v", err)
+			}
+
+			if selected == optDiffs {
+				term.PageOutput(fmt.Sprintf("Current branch's changes to %s:\n\n%s\n\nIncoming changes to %s:\n\n%s", conflict.Path, conflict.OursDiff, conflict.Path, conflict.TheirsDiff))
+				continue
+			}
+
+			switch selected {
+			case optOurs:
+				resolutions[conflict.Path] = shared.MergeConflictResolutionOurs
+			case optTheirs:
+				resolutions[conflict.Path] = shared.MergeConflictResolutionTheirs
+			case optCancel:
+				fmt.Println("🙅‍♂️ Merge cancelled")
+				return nil
+			}
+			break
+		}
+
+		fmt.Println()
+	}
+
+	return resolutions
+}
+
+// selectLogSha prompts for an update from a branch's log and returns its sha
+func selectLogSha(branch string) string {
+	term.StartSpinner("")
+	logsRes, apiErr := api.Client.ListLogs(lib.CurrentPlanId, branch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting logs: %v", apiErr)
+	}
+
+	var options []string
+	for _, entry := range strings.Split(logsRes.Body, "\n\n") {
+		lines := strings.Split(entry, "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		parts := strings.Split(lines[0], "|")
+		fields := strings.Fields(parts[0])
+		if len(fields) == 0 {
+			continue
+		}
+		sha := fields[len(fields)-1]
+		options = append(options, fmt.Sprintf("%s | %s", sha, formatLogMessage(strings.TrimSpace(lines[1]))))
+	}
+
+	if len(options) == 0 {
+		term.OutputErrorAndExit("No updates found on branch %s", branch)
+	}
+
+	selected, err := term.SelectFromList(fmt.Sprintf("Select an update from %s to cherry-pick:", branch), options)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting update: %v", err)
+	}
+
+	return strings.Split(selected, " | ")[0]
+}
+
+func printMergeSummary(res *shared.MergeBranchResponse) {
+	fmt.Println()
+
+	if res.NumConvoMessages > 0 {
+		fmt.Printf("💬 %d conversation %s added\n", res.NumConvoMessages, pluralize(res.NumConvoMessages, "message", "messages"))
+	}
+	if res.NumContexts > 0 {
+		fmt.Printf("📚 %d %s loaded\n", res.NumContexts, pluralize(res.NumContexts, "context", "contexts"))
+	}
+
+	if len(res.PendingPaths) > 0 {
+		fmt.Println("📄 Pending changes to:")
+		for _, path := range res.PendingPaths {
+			fmt.Printf(" • %s\n", path)
+		}
+	}
+
+	if len(res.AutoResolvedPaths) > 0 {
+		fmt.Println("🔀 Combined pending changes from both branches for:")
+		for _, path := range res.AutoResolvedPaths {
+			fmt.Printf(" • %s\n", path)
+		}
+	}
+
+	fmt.Println()
+
+	if len(res.PendingPaths) > 0 {
+		term.PrintCmds("", "diff", "review", "apply", "log")
+	} else {
+		term.PrintCmds("", "log", "convo", "tell")
+	}
+}
+
+func pluralizeChanges(n int) string {
+	return pluralize(n, "change", "changes")
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
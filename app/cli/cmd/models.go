@@ -330,6 +330,11 @@ func listAvailableModels(cmd *cobra.Command, args []string) {
 				continue
 			}
 
+			if model.IsEmbeddingModel {
+				table.Append([]string{string(model.ModelId), fmt.Sprintf("%d 🪙", model.MaxTokens), "embeddings", ""})
+				continue
+			}
+
 			table.Append([]string{string(model.ModelId), fmt.Sprintf("%d 🪙", model.MaxTokens), fmt.Sprintf("%d 🪙", model.MaxOutputTokens), fmt.Sprintf("%d 🪙", model.ReservedOutputTokens)})
 		}
 		table.Render()
@@ -434,7 +439,7 @@ func renderModelPack(modelPack *shared.ModelPack, customModelsById map[shared.Mo
 
 		sharedBaseConfig := config.GetSharedBaseConfigWithCustomModels(customModelsById)
 
-		if sharedBaseConfig.RoleParamsDisabled {
+		if sharedBaseConfig.RoleParamsDisabled || sharedBaseConfig.IsEmbeddingModel {
 			temp = 1
 			topP = 1
 			disabled = true
@@ -496,6 +501,9 @@ func renderModelPack(modelPack *shared.ModelPack, customModelsById map[shared.Mo
 	addModelRow(string(shared.ModelRoleName), modelPack.Namer, 0)
 	addModelRow(string(shared.ModelRoleCommitMsg), modelPack.CommitMsg, 0)
 	addModelRow(string(shared.ModelRoleExecStatus), modelPack.ExecStatus, 0)
+	if modelPack.Embeddings != nil {
+		addModelRow(string(shared.ModelRoleEmbeddings), *modelPack.Embeddings, 0)
+	}
 	table.Render()
 
 	if anyRoleParamsDisabled && allProperties {
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var discoverBaseUrl string
+var discoverSkipProbe bool
+var discoverPackName string
+var discoverProviderName string
+
+func init() {
+	modelsCmd.AddCommand(discoverModelsCmd)
+
+	discoverModelsCmd.Flags().StringVar(&discoverBaseUrl, "base-url", "", "Base URL of the Ollama or OpenAI-compatible server (defaults to OLLAMA_BASE_URL or "+lib.DefaultOllamaBaseUrl+")")
+	discoverModelsCmd.Flags().BoolVar(&discoverSkipProbe, "skip-probe", false, "Skip sending a test request to each model")
+	discoverModelsCmd.Flags().StringVar(&discoverPackName, "pack", "local", "Name of the suggested model pack")
+	discoverModelsCmd.Flags().StringVar(&discoverProviderName, "provider", "local", "Name of the custom provider to add for servers that aren't Ollama")
+}
+
+var discoverModelsCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Add models from a local Ollama or OpenAI-compatible server",
+	Args:  cobra.NoArgs,
+	Run:   discoverModels,
+}
+
+func discoverModels(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if auth.Current.IsCloud {
+		term.OutputErrorAndExit("Local models aren't supported on Plandex Cloud. Self-host Plandex to use them.")
+		return
+	}
+
+	baseUrl := discoverBaseUrl
+	if baseUrl == "" {
+		baseUrl = os.Getenv("OLLAMA_BASE_URL")
+	}
+	if baseUrl == "" {
+		baseUrl = lib.DefaultOllamaBaseUrl
+	}
+
+	term.StartSpinner("")
+
+	var serverModelsInput *shared.ModelsInput
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		serverModelsInput, err = lib.GetServerModelsInput()
+		errCh <- err
+	}()
+
+	res, err := lib.DiscoverLocalModels(baseUrl)
+	if err != nil {
+		term.OutputErrorAndExit("Error discovering models: %v", err)
+		return
+	}
+
+	err = <-errCh
+	if err != nil {
+		term.OutputErrorAndExit("Error getting server models input: %v", err)
+		return
+	}
+
+	term.StopSpinner()
+
+	if len(res.Models) == 0 {
+		fmt.Printf("🤷‍♂️ No models found at %s\n", res.BaseUrl)
+		if res.IsOllama {
+			fmt.Println()
+			fmt.Println("Pull a model with 'ollama pull', then try again")
+		}
+		return
+	}
+
+	serverName := "OpenAI-compatible server"
+	if res.IsOllama {
+		serverName = "Ollama"
+	}
+	fmt.Printf("🔎 Found %d models on %s at %s\n\n", len(res.Models), serverName, res.BaseUrl)
+
+	if !discoverSkipProbe {
+		fmt.Println("🧪 Sending a test request to each model. Models that aren't loaded yet may take a while.")
+		lib.ProbeDiscoveredModels(res, func(model *lib.DiscoveredModel) {
+			if model.ProbeErr == nil {
+				fmt.Printf("  ✅ %s\n", model.Name)
+			} else {
+				fmt.Printf("  ❌ %s\n", model.Name)
+			}
+		})
+		fmt.Println()
+	}
+
+	renderDiscoveredModels(res.Models)
+
+	discoveredInput, err := lib.DiscoveredModelsInput(res, lib.DiscoveredModelsInputParams{
+		ProviderName: discoverProviderName,
+		PackName:     discoverPackName,
+	})
+	if err != nil {
+		term.OutputErrorAndExit("Error creating models: %v", err)
+		return
+	}
+
+	modelsInput, skipped := mergeDiscoveredModelsInput(serverModelsInput, discoveredInput)
+
+	if len(skipped) > 0 {
+		fmt.Println("ℹ️  These models already exist as custom models, so they were left as is:")
+		for _, modelId := range skipped {
+			fmt.Printf("  • %s\n", modelId)
+		}
+		fmt.Println()
+	}
+
+	pack := discoveredInput.CustomModelPacks[0]
+	color.New(color.Bold, term.ColorHiCyan).Printf("🎛️  Suggested model pack → %s\n", pack.Name)
+	customModelsById := map[shared.ModelId]*shared.CustomModel{}
+	for _, model := range modelsInput.CustomModels {
+		customModelsById[model.ModelId] = model
+	}
+	modelPack := pack.ToModelPack()
+	renderModelPack(&modelPack, customModelsById, false)
+
+	customModelsPath := lib.GetCustomModelsPath(auth.Current.UserId)
+
+	localChanges, err := lib.CustomModelsCheckLocalChanges(customModelsPath)
+	if err != nil {
+		term.OutputErrorAndExit("Error checking local changes: %v", err)
+		return
+	}
+	if localChanges.HasLocalChanges {
+		res, err := warnModelsFileLocalChanges(customModelsPath, "models custom")
+		if err != nil {
+			term.OutputErrorAndExit("Error confirming: %v", err)
+			return
+		}
+		if !res {
+			return
+		}
+		fmt.Println()
+	}
+
+	err = lib.WriteCustomModelsFile(customModelsPath, modelsInput)
+	if err != nil {
+		term.OutputErrorAndExit("Error saving custom models file: %v", err)
+		return
+	}
+
+	fmt.Printf("🧠 %s → %s\n\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Models file"), customModelsPath)
+
+	confirmed, err := term.ConfirmYesNo("Save the discovered models and model pack?")
+	if err != nil {
+		term.OutputErrorAndExit("Error confirming: %v", err)
+		return
+	}
+
+	if !confirmed {
+		fmt.Println()
+		fmt.Println("👨‍💻 The models file has been updated with the discovered models. Edit it if you like, then save it with:")
+		fmt.Println(term.ShowCmd("models custom --save"))
+		fmt.Println()
+		return
+	}
+
+	didUpdate := lib.MustSyncCustomModels(customModelsPath, serverModelsInput)
+	if !didUpdate {
+		fmt.Println("🤷‍♂️ No changes to custom models/providers/model packs")
+	}
+	fmt.Println()
+
+	term.PrintCmds("", "set-model "+pack.Name, "set-model default "+pack.Name, "models available --custom")
+}
+
+func renderDiscoveredModels(models []*lib.DiscoveredModel) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Model", "Context", "Capabilities", "Probe"})
+
+	hasDefaultContext := false
+	var probeErrs []string
+
+	for _, model := range models {
+		context := fmt.Sprintf("%d 🪙", model.ContextLength)
+		if !model.ContextReported {
+			context += " *"
+			hasDefaultContext = true
+		}
+
+		capabilities := []string{}
+		if model.IsEmbeddingModel {
+			capabilities = append(capabilities, "embeddings")
+		} else {
+			capabilities = append(capabilities, "chat")
+		}
+		if model.HasImageSupport {
+			capabilities = append(capabilities, "images")
+		}
+
+		probe := "skipped"
+		if model.Probed {
+			if model.ProbeErr == nil {
+				probe = "✅"
+			} else {
+				probe = "❌"
+				probeErrs = append(probeErrs, fmt.Sprintf("  • %s: %v", model.Name, model.ProbeErr))
+			}
+		}
+
+		table.Append([]string{model.Name, context, strings.Join(capabilities, ", "), probe})
+	}
+	table.Render()
+	fmt.Println()
+
+	if hasDefaultContext {
+		fmt.Println("* The server didn't report a context window for this model, so a conservative default was used. You can change it in the models file.")
+		fmt.Println()
+	}
+
+	if len(probeErrs) > 0 {
+		fmt.Println("🚨 These models failed their probe request and won't be added:")
+		fmt.Println(strings.Join(probeErrs, "\n"))
+		fmt.Println()
+	}
+}
+
+// mergeDiscoveredModelsInput adds discovered models, providers, and the model pack to the existing custom models. Existing models and providers are kept as is so that manual edits aren't lost, while a model pack with the same name is replaced.
+func mergeDiscoveredModelsInput(existing *shared.ModelsInput, discovered *shared.ModelsInput) (*shared.ModelsInput, []shared.ModelId) {
+	res := &shared.ModelsInput{
+		CustomModels:    append([]*shared.CustomModel{}, existing.CustomModels...),
+		CustomProviders: append([]*shared.CustomProvider{}, existing.CustomProviders...),
+	}
+
+	existingModelIds := map[shared.ModelId]bool{}
+	for _, model := range existing.CustomModels {
+		existingModelIds[model.ModelId] = true
+	}
+	existingProviderNames := map[string]bool{}
+	for _, provider := range existing.CustomProviders {
+		existingProviderNames[provider.Name] = true
+	}
+
+	var skipped []shared.ModelId
+	for _, model := range discovered.CustomModels {
+		if existingModelIds[model.ModelId] {
+			skipped = append(skipped, model.ModelId)
+			continue
+		}
+		res.CustomModels = append(res.CustomModels, model)
+	}
+
+	for _, provider := range discovered.CustomProviders {
+		if !existingProviderNames[provider.Name] {
+			res.CustomProviders = append(res.CustomProviders, provider)
+		}
+	}
+
+	discoveredPackNames := map[string]bool{}
+	for _, pack := range discovered.CustomModelPacks {
+		discoveredPackNames[pack.Name] = true
+	}
+	for _, pack := range existing.CustomModelPacks {
+		if !discoveredPackNames[pack.Name] {
+			res.CustomModelPacks = append(res.CustomModelPacks, pack)
+		}
+	}
+	res.CustomModelPacks = append(res.CustomModelPacks, discovered.CustomModelPacks...)
+
+	return res, skipped
+}
@@ -17,13 +17,18 @@ import (
 
 var name string
 var contextBaseDir string
+var newTemplateName string
+var newTemplateVars []string
+var newNoTell bool
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
 	Use:     "new",
 	Aliases: []string{"n"},
 	Short:   "Start a new plan",
-	// Long:  ``,
+	Long: `Start a new plan.
+
+Pass --template to start from one of your org's plan templates (see 'plandex templates'). The template's context is loaded, its settings are applied, and its prompt is sent with the values of its variables filled in from --var key=value flags—you'll be asked for any that are missing.`,
 	Args: cobra.ExactArgs(0),
 	Run:  new,
 }
@@ -32,6 +37,9 @@ func init() {
 	RootCmd.AddCommand(newCmd)
 	newCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the new plan")
 	newCmd.Flags().StringVar(&contextBaseDir, "context-dir", ".", "Base directory to auto-load context from")
+	newCmd.Flags().StringVar(&newTemplateName, "template", "", "Start the plan from a plan template")
+	newCmd.Flags().StringArrayVar(&newTemplateVars, "var", nil, "Set a template variable (key=value)")
+	newCmd.Flags().BoolVar(&newNoTell, "no-tell", false, "Set up the plan from the template without sending its prompt")
 
 	AddNewPlanFlags(newCmd)
 }
@@ -40,6 +48,15 @@ func new(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveOrCreateProject()
 
+	var template *shared.PlanTemplate
+	var templatePrompt string
+	var templateGlobs []string
+	if newTemplateName != "" {
+		template, templatePrompt, templateGlobs = mustRenderPlanTemplate(newTemplateName, newTemplateVars)
+	} else if len(newTemplateVars) > 0 || newNoTell {
+		term.OutputErrorAndExit("--var and --no-tell can only be used with --template")
+	}
+
 	term.StartSpinner("")
 
 	errCh := make(chan error, 2)
@@ -92,9 +109,16 @@ func new(cmd *cobra.Command, args []string) {
 	term.StopSpinner()
 
 	fmt.Printf("✅ Started new plan %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(name))
-	fmt.Printf("⚙️  Using default config\n")
+	if template != nil {
+		fmt.Printf("📋 Using template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+		templateAutoMode = template.AutoMode
+		templateModelPackName = template.ModelPackName
+		mustAddPlanTemplateIgnore(template)
+	} else {
+		fmt.Printf("⚙️  Using default config\n")
+	}
 
-	resolveAutoMode(config)
+	_, config = resolveAutoMode(config)
 
 	resolveModelPack()
 
@@ -118,6 +142,23 @@ func new(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
+	if template != nil {
+		mustLoadPlanTemplateContext(templateGlobs)
+
+		if !newNoTell {
+			doTell(tellCmd, []string{templatePrompt})
+			return
+		}
+
+		fmt.Println()
+		fmt.Println("📝 Template prompt:")
+		fmt.Println()
+		fmt.Println(templatePrompt)
+		fmt.Println()
+		term.PrintCmds("", "tell", "load", "context")
+		return
+	}
+
 	var cmds []string
 	if term.IsRepl {
 		cmds = []string{"config", "plans", "cd", "models"}
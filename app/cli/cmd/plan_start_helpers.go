@@ -33,6 +33,10 @@ var (
 	opusPlannerModels       bool
 )
 
+// set by 'new --template' and used when no auto mode or model pack flag is passed
+var templateAutoMode shared.AutoModeType
+var templateModelPackName string
+
 func AddNewPlanFlags(cmd *cobra.Command) {
 	// Add tier flags
 	cmd.Flags().BoolVar(&noAuto, "no-auto", false, shared.AutoModeDescriptions[shared.AutoModeNone])
@@ -77,6 +81,8 @@ func resolveAutoModeWithArgs(config *shared.PlanConfig, silent bool) (bool, *sha
 		toSetAutoMode = shared.AutoModeSemi
 	} else if fullAuto {
 		toSetAutoMode = shared.AutoModeFull
+	} else if templateAutoMode != "" {
+		toSetAutoMode = templateAutoMode
 	}
 
 	if toSetAutoMode != "" && toSetAutoMode != currentAutoMode {
@@ -159,6 +165,8 @@ func resolveModelPackWithArgs(settings *shared.PlanSettings, silent bool) (*shar
 		packName = shared.PerplexityPlannerModelPack.Name
 	} else if opusPlannerModels {
 		packName = shared.OpusPlannerModelPack.Name
+	} else if templateModelPackName != "" {
+		packName = templateModelPackName
 	}
 
 	if packName != "" && packName != originalSettings.GetModelPack().Name {
@@ -42,8 +42,41 @@ func ps(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput() {
+		streams := []map[string]interface{}{}
+		for _, b := range res.Branches {
+			stream := map[string]interface{}{
+				"streamId":  res.StreamIdByBranchId[b.Id],
+				"planId":    b.PlanId,
+				"branch":    b.Name,
+				"status":    b.Status,
+				"startedAt": res.StreamStartedAtByBranchId[b.Id],
+			}
+			if plan := res.PlansById[b.PlanId]; plan != nil {
+				stream["planName"] = plan.Name
+			}
+			if finishedAt, ok := res.StreamFinishedAtByBranchId[b.Id]; ok {
+				stream["finishedAt"] = finishedAt
+			}
+			streams = append(streams, stream)
+		}
+		jobs := res.Jobs
+		if jobs == nil {
+			jobs = []*shared.PlanJob{}
+		}
+		term.OutputJsonResult("ps", map[string]interface{}{"streams": streams, "jobs": jobs})
+		return
+	}
+
+	if len(res.Branches) == 0 && len(res.Jobs) == 0 {
+		fmt.Println("🤷‍♂️ No active or recently finished streams")
+		return
+	}
+
 	if len(res.Branches) == 0 {
 		fmt.Println("🤷‍♂️ No active or recently finished streams")
+		fmt.Println()
+		printPsJobs(res)
 		return
 	}
 
@@ -92,7 +125,20 @@ func ps(cmd *cobra.Command, args []string) {
 	}
 	table.Render()
 
+	if len(res.Jobs) > 0 {
+		fmt.Println()
+		printPsJobs(res)
+		return
+	}
+
 	fmt.Println()
 	term.PrintCmds("", "connect", "stop")
 
 }
+
+func printPsJobs(res *shared.ListPlansRunningResponse) {
+	fmt.Println("⏱️  Server jobs")
+	printJobsTable(res.Jobs, res.PlansById)
+	fmt.Println()
+	term.PrintCmds("", "connect", "stop", "jobs")
+}
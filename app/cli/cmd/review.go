@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	reviewtui "plandex-cli/review_tui"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	initApplyFlags(reviewCmd, false)
+	initExecScriptFlags(reviewCmd)
+	RootCmd.AddCommand(reviewCmd)
+}
+
+var reviewCmd = &cobra.Command{
+	Use:     "review [files...]",
+	Aliases: []string{"rv"},
+	Short:   "Review pending changes one at a time—accept, reject, or edit each before applying",
+	Run:     review,
+}
+
+func review(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	currentPlanState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr)
+	}
+
+	currentFiles := currentPlanState.CurrentPlanFiles.Files
+	removed := currentPlanState.CurrentPlanFiles.Removed
+
+	if len(currentFiles) == 0 && len(removed) == 0 {
+		term.OutputErrorAndExit("No pending changes to review")
+	}
+
+	for _, path := range args {
+		if _, ok := currentFiles[path]; !ok && !removed[path] {
+			term.OutputErrorAndExit("File %s not found in plan or has no changes to review", path)
+		}
+	}
+
+	res, err := reviewtui.StartReviewUI(currentPlanState, args, defaultEditor)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if res.Canceled {
+		fmt.Println("🤷‍♂️ Review canceled—no changes were made")
+		return
+	}
+
+	term.StartSpinner("")
+
+	// edits go first so that rejecting a change can be checked against the edited versions of the others
+	if len(res.Edits) > 0 {
+		apiErr = api.Client.EditReplacements(lib.CurrentPlanId, lib.CurrentBranch, res.Edits)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error saving edited changes: %v", apiErr.Msg)
+		}
+	}
+
+	if len(res.RejectedReplacementIds) > 0 {
+		apiErr = api.Client.RejectReplacements(lib.CurrentPlanId, lib.CurrentBranch, res.RejectedReplacementIds)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error rejecting changes: %v", apiErr.Msg)
+		}
+	}
+
+	for _, resultId := range res.RejectedResultIds {
+		apiErr = api.Client.RejectFile(lib.CurrentPlanId, lib.CurrentBranch, resultId)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error rejecting changes: %v", apiErr.Msg)
+		}
+	}
+
+	term.StopSpinner()
+
+	numReviewed := res.NumKept + res.NumRejected
+	suffix := ""
+	if numReviewed > 1 {
+		suffix = "s"
+	}
+	fmt.Printf("✅ Reviewed %d change%s\n", numReviewed, suffix)
+	fmt.Printf("• Kept %d", res.NumKept)
+	if res.NumEdited > 0 {
+		fmt.Printf(" (%d edited)", res.NumEdited)
+	}
+	fmt.Println()
+	fmt.Printf("• Rejected %d\n", res.NumRejected)
+	fmt.Println()
+
+	if res.NumKept == 0 {
+		return
+	}
+
+	shouldApply, err := term.ConfirmYesNo("Apply the kept changes now?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting confirmation: %v", err)
+	}
+
+	if !shouldApply {
+		term.PrintCmds("", "diff", "diff --ui", "apply", "review")
+		return
+	}
+
+	mustSetPlanExecFlags(cmd, true)
+
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  autoCommit,
+		NoCommit:    skipCommit,
+		AutoExec:    autoExec,
+		NoExec:      noExec,
+		AutoDebug:   autoDebug,
+	}
+
+	tellFlags := types.TellFlags{
+		TellBg:      tellBg,
+		TellStop:    tellStop,
+		TellNoBuild: tellNoBuild,
+		AutoContext: tellAutoContext,
+		ExecEnabled: !noExec,
+		AutoApply:   tellAutoApply,
+	}
+
+	lib.MustApplyPlan(lib.ApplyPlanParams{
+		PlanId:     lib.CurrentPlanId,
+		Branch:     lib.CurrentBranch,
+		ApplyFlags: applyFlags,
+		TellFlags:  tellFlags,
+		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+	})
+}
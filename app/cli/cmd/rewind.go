@@ -436,7 +436,7 @@ func rewind(cmd *cobra.Command, args []string) {
 		shouldCommit := false
 		needsPrompt := true
 
-		if !fs.ProjectRootIsGitRepo() {
+		if !fs.ProjectRootIsGitRepo() && !fs.AnyRepoRootIsGitRepo() {
 			shouldCommit = false
 			needsPrompt = false
 		} else {
@@ -479,7 +479,7 @@ func rewind(cmd *cobra.Command, args []string) {
 				paths = append(paths, path)
 			}
 
-			err := lib.GitAddAndCommitPaths(fs.ProjectRoot, msg, paths, true)
+			err := lib.GitAddAndCommitRepoRoots(msg, paths)
 			if err != nil {
 				term.OutputErrorAndExit("Error committing changes: %v", err)
 			}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var roleLabel string
+var roleDescription string
+var rolePermissions []string
+var roleAddPermissions []string
+var roleRemovePermissions []string
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List the org's roles and their permissions",
+	Args:  cobra.NoArgs,
+	Run:   listRoles,
+}
+
+var rolesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a custom org role",
+	Long: `Create a custom org role composed of permissions, then assign it with 'plandex invite' or 'plandex users set-role':
+
+  plandex roles create junior --label "Junior Engineer" --permissions create-project,create-plan,exec-commands
+
+You can only grant permissions your own role has. Permissions a custom role can include:
+
+` + customRolePermissionsHelp(),
+	Args: cobra.ExactArgs(1),
+	Run:  createRole,
+}
+
+var rolesUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update a custom org role",
+	Long: `Update a custom org role's label, description, or permissions. --permissions replaces all of the role's permissions, while --add and --remove change them one at a time:
+
+  plandex roles update junior --add force-load-ignored --remove exec-commands
+
+Users with the role get the new permissions immediately.`,
+	Args: cobra.ExactArgs(1),
+	Run:  updateRole,
+}
+
+var rolesDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a custom org role",
+	Long:  `Delete a custom org role. Give its users another role with 'plandex users set-role' and revoke its pending invites first.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   deleteRole,
+}
+
+func init() {
+	RootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(rolesCreateCmd)
+	rolesCmd.AddCommand(rolesUpdateCmd)
+	rolesCmd.AddCommand(rolesDeleteCmd)
+
+	for _, cmd := range []*cobra.Command{rolesCreateCmd, rolesUpdateCmd} {
+		cmd.Flags().StringVarP(&roleLabel, "label", "l", "", "Label shown for the role")
+		cmd.Flags().StringVarP(&roleDescription, "description", "d", "", "What the role is for")
+		cmd.Flags().StringSliceVarP(&rolePermissions, "permissions", "p", nil, "Comma-separated permissions for the role")
+	}
+
+	rolesUpdateCmd.Flags().StringSliceVar(&roleAddPermissions, "add", nil, "Permissions to add to the role")
+	rolesUpdateCmd.Flags().StringSliceVar(&roleRemovePermissions, "remove", nil, "Permissions to remove from the role")
+}
+
+func listRoles(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing org roles: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("roles", orgRoles)
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Label", "Type", "Permissions"})
+
+	for _, orgRole := range orgRoles {
+		roleType := "Custom"
+		if orgRole.IsDefault {
+			roleType = "Default"
+		}
+
+		perms := make([]string, len(orgRole.Permissions))
+		for i, p := range orgRole.Permissions {
+			perms[i] = strings.ReplaceAll(string(p), "_", "-")
+		}
+
+		table.Append([]string{orgRole.Name, orgRole.Label, roleType, strings.Join(perms, "\n")})
+	}
+
+	table.Render()
+}
+
+func createRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRole, apiErr := api.Client.CreateOrgRole(shared.CreateOrgRoleRequest{
+		Name:        args[0],
+		Label:       roleLabel,
+		Description: roleDescription,
+		Permissions: parseRolePermissions(rolePermissions),
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating org role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Created role %s\n", orgRole.Name)
+	fmt.Println()
+	term.PrintCmds("", "users set-role", "invite")
+}
+
+func updateRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing org roles: %v", apiErr.Msg)
+	}
+
+	orgRole := findOrgRole(orgRoles, args[0])
+	if orgRole == nil {
+		term.OutputErrorAndExit("Org role '%s' not found", args[0])
+	}
+
+	permissions := orgRole.Permissions
+	if cmd.Flags().Changed("permissions") {
+		permissions = parseRolePermissions(rolePermissions)
+	}
+
+	permissions = append(permissions, parseRolePermissions(roleAddPermissions)...)
+
+	toRemove := map[shared.Permission]bool{}
+	for _, p := range parseRolePermissions(roleRemovePermissions) {
+		toRemove[p] = true
+	}
+
+	updated := []shared.Permission{}
+	for _, p := range permissions {
+		if !toRemove[p] {
+			updated = append(updated, p)
+		}
+	}
+
+	term.StartSpinner("")
+	_, apiErr = api.Client.UpdateOrgRole(orgRole.Id, shared.UpdateOrgRoleRequest{
+		Label:       roleLabel,
+		Description: roleDescription,
+		Permissions: updated,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating org role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Updated role %s\n", orgRole.Name)
+}
+
+func deleteRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing org roles: %v", apiErr.Msg)
+	}
+
+	orgRole := findOrgRole(orgRoles, args[0])
+	if orgRole == nil {
+		term.OutputErrorAndExit("Org role '%s' not found", args[0])
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.DeleteOrgRole(orgRole.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting org role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Deleted role %s\n", orgRole.Name)
+}
+
+// findOrgRole matches a role by its name or label
+func findOrgRole(orgRoles []*shared.OrgRole, nameOrLabel string) *shared.OrgRole {
+	for _, orgRole := range orgRoles {
+		if strings.EqualFold(orgRole.Name, nameOrLabel) || strings.EqualFold(orgRole.Label, nameOrLabel) {
+			return orgRole
+		}
+	}
+	return nil
+}
+
+// parseRolePermissions accepts permissions written with dashes or underscores, like 'exec-commands' or 'exec_commands'
+func parseRolePermissions(names []string) []shared.Permission {
+	res := []shared.Permission{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		res = append(res, shared.Permission(strings.ReplaceAll(strings.ToLower(name), "-", "_")))
+	}
+	return res
+}
+
+func customRolePermissionsHelp() string {
+	var lines []string
+	for _, p := range shared.CustomOrgRolePermissions {
+		lines = append(lines, "  "+strings.ReplaceAll(string(p), "_", "-"))
+	}
+	return strings.Join(lines, "\n")
+}
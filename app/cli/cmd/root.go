@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"plandex-cli/lib"
 	"plandex-cli/term"
 	"strings"
 
@@ -12,12 +13,33 @@ import (
 
 var helpShowAll bool
 
+var jsonOutput bool
+var ndjsonOutput bool
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use: `plandex [command] [flags]`,
 	// Short: "Plandex: iterative development with AI",
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput && ndjsonOutput {
+			return fmt.Errorf("--json and --ndjson can't be used together")
+		}
+
+		if jsonOutput {
+			term.SetOutputFormat(term.OutputFormatJson)
+		} else if ndjsonOutput {
+			term.SetOutputFormat(term.OutputFormatNdjson)
+		}
+
+		// apply checks for itself
+		if cmd.Name() != "apply" {
+			lib.WarnIfInterruptedApply()
+		}
+
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		run(cmd, args)
 	},
@@ -41,6 +63,18 @@ func Execute() {
 		// term.OutputErrorAndExit("Error executing root command: %v", err)
 		// log.Fatalf("Error executing root command: %v", err)
 
+		// flags may not have been parsed if that's what failed
+		for _, arg := range os.Args[1:] {
+			if arg == "--json" {
+				term.SetOutputFormat(term.OutputFormatJson)
+			} else if arg == "--ndjson" {
+				term.SetOutputFormat(term.OutputFormatNdjson)
+			}
+		}
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("%v", err)
+		}
+
 		// output the error message to stderr
 		term.OutputSimpleError("Error: %v", err)
 
@@ -90,4 +124,7 @@ func init() {
 
 	// add an --all/-a flag
 	helpCmd.Flags().BoolVarP(&helpShowAll, "all", "a", false, "Show all commands")
+
+	RootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output structured JSON on stdout and never prompt")
+	RootCmd.PersistentFlags().BoolVar(&ndjsonOutput, "ndjson", false, "Output newline-delimited JSON events on stdout as they happen and never prompt")
 }
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var rootsCmd = &cobra.Command{
+	Use:   "roots",
+	Short: "List the other repositories that are part of this project",
+	Args:  cobra.NoArgs,
+	Run:   listRoots,
+}
+
+var rootsAddCmd = &cobra.Command{
+	Use:   "add <alias> <path>",
+	Short: "Add another repository to the project",
+	Long: `Add another repository to the project so that plans can span multiple repos.
+
+Files in the repository are referred to by '@<alias>/<path>'—for example, after running:
+
+  plandex roots add frontend ../frontend
+
+you can load context from it with 'plandex load @frontend/src -r'. When changes are applied, each repository gets its own commit with the same message, and _apply.sh can find the repository's directory in the $PLANDEX_ROOT_FRONTEND environment variable.`,
+	Args: cobra.ExactArgs(2),
+	Run:  addRoot,
+}
+
+var rootsRmCmd = &cobra.Command{
+	Use:     "rm <alias>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a repository from the project",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeRoot,
+}
+
+func init() {
+	RootCmd.AddCommand(rootsCmd)
+	rootsCmd.AddCommand(rootsAddCmd)
+	rootsCmd.AddCommand(rootsRmCmd)
+}
+
+func listRoots(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	roots := fs.GetRepoRoots()
+
+	if term.IsJsonOutput() {
+		if roots == nil {
+			roots = []*types.RepoRoot{}
+		}
+		term.OutputJsonResult("roots", roots)
+		return
+	}
+
+	if len(roots) == 0 {
+		fmt.Println("🤷‍♂️ This project only has its main repository")
+		fmt.Println()
+		term.PrintCmds("", "roots add")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Alias", "Path", "Env Var", "Git"})
+
+	table.Append([]string{"(main)", fs.ProjectRoot, "", gitMark(fs.ProjectRootIsGitRepo())})
+	for _, root := range roots {
+		table.Append([]string{fs.RepoRootPrefix + root.Alias, root.AbsPath, "$" + fs.RepoRootEnvVar(root.Alias), gitMark(fs.IsGitRepo(root.AbsPath))})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "roots add", "roots rm")
+}
+
+func addRoot(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	alias := strings.TrimPrefix(args[0], fs.RepoRootPrefix)
+	path := args[1]
+
+	err := fs.ValidateRepoRootAlias(alias)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		term.OutputErrorAndExit("Error resolving %s: %v", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil || !info.IsDir() {
+		term.OutputErrorAndExit("%s isn't a directory", path)
+	}
+
+	if isSubpath, _ := fs.IsSubpathOf(absPath, fs.ProjectRoot, fs.ProjectRoot); isSubpath {
+		term.OutputErrorAndExit("%s contains the project root—add repositories that are next to or inside it", path)
+	}
+
+	roots := fs.GetRepoRoots()
+	for _, root := range roots {
+		if root.AbsPath == absPath && root.Alias != alias {
+			term.OutputErrorAndExit("%s is already in the project as %s%s", path, fs.RepoRootPrefix, root.Alias)
+		}
+	}
+
+	// store the path relative to the project root when possible so the config still works if the directories are moved together
+	storedPath := absPath
+	if rel, err := filepath.Rel(fs.ProjectRoot, absPath); err == nil {
+		storedPath = rel
+	}
+
+	var updated []*types.RepoRoot
+	replaced := false
+	for _, root := range roots {
+		if root.Alias == alias {
+			updated = append(updated, &types.RepoRoot{Alias: alias, Path: storedPath})
+			replaced = true
+			continue
+		}
+		updated = append(updated, root)
+	}
+	if !replaced {
+		updated = append(updated, &types.RepoRoot{Alias: alias, Path: storedPath})
+	}
+
+	err = fs.SaveRepoRoots(updated)
+	if err != nil {
+		term.OutputErrorAndExit("Error saving repo roots: %v", err)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("roots add", fs.GetRepoRoot(alias))
+		return
+	}
+
+	fmt.Printf("✅ Added %s → %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(fs.RepoRootPrefix+alias), absPath)
+	if !fs.IsGitRepo(absPath) {
+		fmt.Println()
+		fmt.Println("⚠️  It isn't a git repository, so applied changes won't be committed there")
+	}
+	fmt.Println()
+	fmt.Printf("Load files from it with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("plandex load %s%s/<path>", fs.RepoRootPrefix, alias))
+}
+
+func removeRoot(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	alias := strings.TrimPrefix(args[0], fs.RepoRootPrefix)
+
+	roots := fs.GetRepoRoots()
+	var updated []*types.RepoRoot
+	for _, root := range roots {
+		if root.Alias != alias {
+			updated = append(updated, root)
+		}
+	}
+
+	if len(updated) == len(roots) {
+		term.OutputErrorAndExit("No repository with alias %s", alias)
+	}
+
+	err := fs.SaveRepoRoots(updated)
+	if err != nil {
+		term.OutputErrorAndExit("Error saving repo roots: %v", err)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("roots rm", map[string]string{"alias": alias})
+		return
+	}
+
+	fmt.Printf("✅ Removed %s from the project\n", color.New(color.Bold, term.ColorHiCyan).Sprint(fs.RepoRootPrefix+alias))
+	fmt.Println("Context loaded from it will be removed from plans the next time they check for outdated context")
+}
+
+func gitMark(isGitRepo bool) string {
+	if isGitRepo {
+		return color.New(color.FgGreen).Sprint("✓")
+	}
+	return color.New(color.FgHiBlack).Sprint("✗")
+}
@@ -115,6 +115,13 @@ func setConfig(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
+	if updatedConfig.CanExec && updatedConfig.IsExecSandboxed() && !config.IsExecSandboxed() {
+		if err := lib.CheckExecSandboxAvailable(); err != nil {
+			color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  %v\n", err)
+			fmt.Println()
+		}
+	}
+
 	term.StopSpinner()
 
 	term.PrintCmds("", "config", "config default", "set-config default")
@@ -302,6 +309,10 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 			}
 			cfgSetting.IntSetter(&config, n)
 		} else if cfgSetting.StringSetter != nil {
+			if cfgSetting.KeyToLabel != nil && cfgSetting.KeyToLabel(value) == "" {
+				term.OutputErrorAndExit("Invalid value for %s (%s)", cfgSetting.Name, value)
+				return "", nil
+			}
 			cfgSetting.StringSetter(&config, value)
 		} else if cfgSetting.EditorSetter != nil {
 			fields := strings.Fields(value)
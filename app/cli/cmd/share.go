@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var shareRole string
+
+var shareCmd = &cobra.Command{
+	Use:   "share [email]",
+	Short: "Share the current plan with a user in your org",
+	Long: `Share the current plan with a user in your org, or change their role if it's already shared with them.
+
+Roles:
+  viewer     see the plan's conversation, context, diffs, and history, and connect to its streams
+  commenter  also chat with the plan, without making changes or loading context
+  editor     also tell, build, apply, reject, load context, rewind, and manage branches
+
+Only the plan's owner, or a user whose org role can manage any plan's shares, can share a plan.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  sharePlan,
+}
+
+var sharesCmd = &cobra.Command{
+	Use:   "shares",
+	Short: "List who the current plan is shared with",
+	Args:  cobra.NoArgs,
+	Run:   listShares,
+}
+
+var unshareCmd = &cobra.Command{
+	Use:   "unshare [email]",
+	Short: "Stop sharing the current plan with a user",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   unsharePlan,
+}
+
+func init() {
+	RootCmd.AddCommand(shareCmd)
+	RootCmd.AddCommand(sharesCmd)
+	RootCmd.AddCommand(unshareCmd)
+
+	shareCmd.Flags().StringVar(&shareRole, "role", string(shared.PlanShareRoleViewer), "Role to grant (viewer, commenter, editor)")
+}
+
+func sharePlan(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	role := shared.PlanShareRole(strings.ToLower(shareRole))
+	if !role.IsValid() {
+		term.OutputErrorAndExit("Invalid role '%s'—use viewer, commenter, or editor", shareRole)
+	}
+
+	email := ""
+	if len(args) > 0 {
+		email = strings.ToLower(strings.TrimSpace(args[0]))
+	}
+
+	if email == "" {
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("An email is required with --json/--ndjson")
+		}
+
+		term.StartSpinner("")
+		userResp, apiErr := api.Client.ListUsers()
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error fetching users: %s", apiErr.Msg)
+		}
+
+		labelToEmail := map[string]string{}
+		var opts []string
+		for _, user := range userResp.Users {
+			if user.Id == auth.Current.UserId {
+				continue
+			}
+			label := fmt.Sprintf("%s <%s>", user.Name, user.Email)
+			labelToEmail[label] = user.Email
+			opts = append(opts, label)
+		}
+
+		if len(opts) == 0 {
+			fmt.Println("🤷‍♂️ No other users in your org")
+			fmt.Println()
+			term.PrintCmds("", "invite")
+			return
+		}
+
+		selected, err := term.SelectFromList("Select a user:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+		email = labelToEmail[selected]
+	}
+
+	term.StartSpinner("")
+	share, apiErr := api.Client.SharePlan(lib.CurrentPlanId, shared.SharePlanRequest{
+		Email: email,
+		Role:  role,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error sharing plan: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("share", share)
+		return
+	}
+
+	fmt.Printf("✅ Shared plan with %s as %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(share.UserEmail), color.New(color.Bold, term.ColorHiGreen).Sprint(share.Role))
+	fmt.Println()
+	fmt.Println("They can switch to it with 'plandex cd' from the same project")
+	fmt.Println()
+	term.PrintCmds("", "shares", "unshare")
+}
+
+func listShares(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing shares: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		if shares == nil {
+			shares = []*shared.PlanShare{}
+		}
+		term.OutputJsonResult("shares", shares)
+		return
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ This plan isn't shared with anyone")
+		fmt.Println()
+		term.PrintCmds("", "share")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Email", "Role", "Shared"})
+	for _, share := range shares {
+		table.Append([]string{share.UserName, share.UserEmail, string(share.Role), format.Time(share.CreatedAt)})
+	}
+	table.Render()
+
+	fmt.Println()
+	term.PrintCmds("", "share", "unshare")
+}
+
+func unsharePlan(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ This plan isn't shared with anyone")
+		return
+	}
+
+	email := ""
+	if len(args) > 0 {
+		email = strings.ToLower(strings.TrimSpace(args[0]))
+	} else if term.IsJsonOutput() {
+		term.OutputErrorAndExit("An email is required with --json/--ndjson")
+	} else {
+		labelToEmail := map[string]string{}
+		var opts []string
+		for _, share := range shares {
+			label := fmt.Sprintf("%s <%s> (%s)", share.UserName, share.UserEmail, share.Role)
+			labelToEmail[label] = share.UserEmail
+			opts = append(opts, label)
+		}
+
+		selected, err := term.SelectFromList("Select a user:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+		email = labelToEmail[selected]
+	}
+
+	var share *shared.PlanShare
+	for _, s := range shares {
+		if strings.EqualFold(s.UserEmail, email) {
+			share = s
+			break
+		}
+	}
+
+	if share == nil {
+		term.OutputErrorAndExit("Plan isn't shared with %s", email)
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.UnsharePlan(lib.CurrentPlanId, share.UserId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error unsharing plan: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("unshare", map[string]string{"userId": share.UserId, "email": share.UserEmail})
+		return
+	}
+
+	fmt.Printf("✅ Stopped sharing plan with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(share.UserEmail))
+}
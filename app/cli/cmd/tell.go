@@ -129,6 +129,9 @@ func getTellPrompt(args []string) string {
 	}
 
 	if prompt == "" && pipedData == "" {
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("A prompt is required with --json/--ndjson—pass it as an argument, with --file, or on stdin")
+		}
 		prompt = getEditorPrompt()
 	} else if pipedData != "" {
 		if prompt != "" {
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var createTemplatePrompt string
+var createTemplatePromptFile string
+var createTemplateDescription string
+var createTemplateContext []string
+var createTemplateModelPack string
+var createTemplateAuto string
+var createTemplateIgnore []string
+var createTemplateForce bool
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List your org's plan templates",
+	Args:  cobra.NoArgs,
+	Run:   listTemplates,
+}
+
+var templatesLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your org's plan templates",
+	Args:  cobra.NoArgs,
+	Run:   listTemplates,
+}
+
+var templatesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a plan template",
+	Long: `Create a plan template that everyone in your org can start plans from with 'plandex new --template <name>'.
+
+The prompt and context globs can include {{variable}} placeholders, which are filled in with --var key=value flags when a plan is started. Context globs use .gitignore syntax and are relative to the project root.
+
+If you don't pass --prompt or --file, an editor opens to write the prompt.`,
+	Args: cobra.ExactArgs(1),
+	Run:  createTemplate,
+}
+
+var templatesRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a plan template",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeTemplate,
+}
+
+func init() {
+	RootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesLsCmd)
+	templatesCmd.AddCommand(templatesCreateCmd)
+	templatesCmd.AddCommand(templatesRmCmd)
+
+	templatesCreateCmd.Flags().StringVarP(&createTemplatePrompt, "prompt", "p", "", "Prompt for plans started from the template")
+	templatesCreateCmd.Flags().StringVarP(&createTemplatePromptFile, "file", "f", "", "File containing the prompt")
+	templatesCreateCmd.Flags().StringVarP(&createTemplateDescription, "description", "d", "", "Short description of the template")
+	templatesCreateCmd.Flags().StringArrayVarP(&createTemplateContext, "context", "c", nil, "Glob of files to load as context (repeatable)")
+	templatesCreateCmd.Flags().StringVar(&createTemplateModelPack, "model-pack", "", "Model pack to use")
+	templatesCreateCmd.Flags().StringVar(&createTemplateAuto, "auto", "", "Auto mode to use (none, basic, plus, semi, full)")
+	templatesCreateCmd.Flags().StringArrayVar(&createTemplateIgnore, "ignore", nil, "Line to add to the project's .plandexignore (repeatable)")
+	templatesCreateCmd.Flags().BoolVar(&createTemplateForce, "force", false, "Replace an existing template with the same name")
+}
+
+func listTemplates(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	templates, apiErr := api.Client.ListPlanTemplates()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing templates: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		if templates == nil {
+			templates = []*shared.PlanTemplate{}
+		}
+		term.OutputJsonResult("templates", templates)
+		return
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("🤷‍♂️ No plan templates")
+		fmt.Println()
+		term.PrintCmds("", "templates create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Description", "Variables", "Context", "Settings", "Updated"})
+
+	for _, template := range templates {
+		var settings []string
+		if template.AutoMode != "" {
+			settings = append(settings, "auto: "+string(template.AutoMode))
+		}
+		if template.ModelPackName != "" {
+			settings = append(settings, "models: "+template.ModelPackName)
+		}
+
+		table.Append([]string{
+			color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name),
+			template.Description,
+			strings.Join(template.Variables(), ", "),
+			strings.Join(template.ContextGlobs, "\n"),
+			strings.Join(settings, "\n"),
+			format.Time(template.UpdatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "new --template", "templates create", "templates rm")
+}
+
+func createTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	templateName := strings.TrimSpace(args[0])
+	err := shared.ValidatePlanTemplateName(templateName)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if createTemplateAuto != "" {
+		valid := false
+		for _, option := range shared.AutoModeOptions {
+			if option[0] == createTemplateAuto && shared.AutoModeType(createTemplateAuto) != shared.AutoModeCustom {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			term.OutputErrorAndExit("Invalid auto mode '%s'—use none, basic, plus, semi, or full", createTemplateAuto)
+		}
+	}
+
+	prompt := createTemplatePrompt
+	if prompt == "" && createTemplatePromptFile != "" {
+		bytes, err := os.ReadFile(createTemplatePromptFile)
+		if err != nil {
+			term.OutputErrorAndExit("Error reading prompt file: %v", err)
+		}
+		prompt = string(bytes)
+	}
+	if prompt == "" {
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("A prompt is required with --json/--ndjson—pass it with --prompt or --file")
+		}
+		prompt = getEditorPrompt()
+	}
+	if strings.TrimSpace(prompt) == "" {
+		term.OutputErrorAndExit("🤷‍♂️ No prompt for the template")
+	}
+
+	term.StartSpinner("")
+	template, apiErr := api.Client.CreatePlanTemplate(shared.CreatePlanTemplateRequest{
+		Name:          templateName,
+		Description:   createTemplateDescription,
+		Prompt:        prompt,
+		ContextGlobs:  createTemplateContext,
+		ModelPackName: createTemplateModelPack,
+		AutoMode:      shared.AutoModeType(createTemplateAuto),
+		PlandexIgnore: createTemplateIgnore,
+		Replace:       createTemplateForce,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		if apiErr.Status == 409 {
+			term.OutputErrorAndExit("Template '%s' already exists—pass --force to replace it", templateName)
+		}
+		term.OutputErrorAndExit("Error creating template: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("templates create", template)
+		return
+	}
+
+	fmt.Printf("✅ Saved template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+
+	vars := template.Variables()
+	if len(vars) > 0 {
+		fmt.Printf("Variables: %s\n", strings.Join(vars, ", "))
+	}
+
+	fmt.Println()
+	fmt.Printf("Start a plan from it with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("plandex new --template %s%s", template.Name, exampleTemplateVarFlags(vars)))
+}
+
+func removeTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	templateName := strings.TrimSpace(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeletePlanTemplate(templateName)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing template: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("templates rm", map[string]string{"name": templateName})
+		return
+	}
+
+	fmt.Printf("✅ Removed template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(templateName))
+}
+
+// mustRenderPlanTemplate gets a template and fills in its variables from 'key=value' flags, asking for any that are missing
+func mustRenderPlanTemplate(templateName string, varFlags []string) (*shared.PlanTemplate, string, []string) {
+	term.StartSpinner("")
+	template, apiErr := api.Client.GetPlanTemplate(templateName)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting template: %v", apiErr.Msg)
+	}
+
+	templateVars := template.Variables()
+	known := map[string]bool{}
+	for _, v := range templateVars {
+		known[v] = true
+	}
+
+	vars := map[string]string{}
+	for _, flag := range varFlags {
+		key, value, ok := strings.Cut(flag, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			term.OutputErrorAndExit("Invalid --var '%s'—use key=value", flag)
+		}
+		if !known[key] {
+			term.OutputErrorAndExit("Template '%s' doesn't have a variable named '%s'", template.Name, key)
+		}
+		vars[key] = value
+	}
+
+	for _, v := range templateVars {
+		if _, ok := vars[v]; ok {
+			continue
+		}
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("Missing value for template variable '%s'—pass it with --var %s=<value>", v, v)
+		}
+		value, err := term.GetRequiredUserStringInput(fmt.Sprintf("Value for %s:", v))
+		if err != nil {
+			term.OutputErrorAndExit("Error getting user input: %v", err)
+		}
+		vars[v] = value
+	}
+
+	prompt, globs, err := template.Render(vars)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	return template, prompt, globs
+}
+
+func mustAddPlanTemplateIgnore(template *shared.PlanTemplate) {
+	added, err := lib.AddPlandexIgnoreLines(template.PlandexIgnore)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if len(added) > 0 {
+		fmt.Printf("🙈 Added to .plandexignore: %s\n", strings.Join(added, ", "))
+	}
+}
+
+func mustLoadPlanTemplateContext(globs []string) {
+	if len(globs) == 0 {
+		return
+	}
+
+	paths, err := lib.ExpandContextGlobs(globs)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if len(paths) == 0 {
+		fmt.Printf("⚠️  No files matched the template's context: %s\n", strings.Join(globs, ", "))
+		return
+	}
+
+	lib.MustLoadContext(paths, &types.LoadContextParams{
+		SkipIgnoreWarning: true,
+	})
+}
+
+func exampleTemplateVarFlags(vars []string) string {
+	var res string
+	for _, v := range vars {
+		res += fmt.Sprintf(" --var %s=...", v)
+	}
+	return res
+}
@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var tokenScopes []string
+var tokenExpiresInDays int
+var tokenServiceAccount string
+
+var tokensCmd = &cobra.Command{
+	Use:     "tokens",
+	Aliases: []string{"token"},
+	Short:   "List your API tokens and your org's service account tokens",
+	Args:    cobra.NoArgs,
+	Run:     listApiTokens,
+}
+
+var tokensCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an API token",
+	Long: `Create a long-lived API token for CI and scripts. Set it as PLANDEX_TOKEN and the CLI uses it instead of your signed in account:
+
+  PLANDEX_TOKEN=plx_... plandex tell -f prompt.txt --apply
+
+Scopes:
+  read   read-only access to plans and org info
+  write  also create and run plans (default)
+  admin  also the org administration permissions of the token user's role
+
+Use --service-account to create a token for one of your org's service accounts rather than yourself.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  createApiToken,
+}
+
+var tokensRevokeCmd = &cobra.Command{
+	Use:     "revoke <token>",
+	Aliases: []string{"rm"},
+	Short:   "Revoke an API token by number, id, or prefix",
+	Args:    cobra.ExactArgs(1),
+	Run:     revokeApiToken,
+}
+
+var serviceAccountsCmd = &cobra.Command{
+	Use:     "service-accounts",
+	Aliases: []string{"service-account"},
+	Short:   "List your org's service accounts",
+	Args:    cobra.NoArgs,
+	Run:     listServiceAccounts,
+}
+
+var serviceAccountsCreateCmd = &cobra.Command{
+	Use:   "create [name] [org-role]",
+	Short: "Create a service account for CI—an org member that only authenticates with API tokens",
+	Args:  cobra.MaximumNArgs(2),
+	Run:   createServiceAccount,
+}
+
+var serviceAccountsRmCmd = &cobra.Command{
+	Use:     "rm <service-account>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a service account from the org and revoke its tokens",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeServiceAccount,
+}
+
+func init() {
+	RootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(tokensCreateCmd)
+	tokensCmd.AddCommand(tokensRevokeCmd)
+
+	tokensCreateCmd.Flags().StringArrayVarP(&tokenScopes, "scope", "s", nil, "Scope to grant (read, write, admin)—defaults to write")
+	tokensCreateCmd.Flags().IntVar(&tokenExpiresInDays, "expires", 0, "Days until the token expires—0 for no expiry")
+	tokensCreateCmd.Flags().StringVar(&tokenServiceAccount, "service-account", "", "Name or id of a service account to create the token for")
+
+	RootCmd.AddCommand(serviceAccountsCmd)
+	serviceAccountsCmd.AddCommand(serviceAccountsCreateCmd)
+	serviceAccountsCmd.AddCommand(serviceAccountsRmCmd)
+}
+
+func listApiTokens(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing tokens: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		if tokens == nil {
+			tokens = []*shared.ApiToken{}
+		}
+		term.OutputJsonResult("tokens", tokens)
+		return
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No API tokens")
+		fmt.Println()
+		term.PrintCmds("", "tokens create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Token", "User", "Scopes", "Expires", "Last Used", "Created"})
+
+	for i, token := range tokens {
+		user := "you"
+		if token.UserId != auth.Current.UserId {
+			user = token.UserName
+		}
+		if token.IsServiceAccount {
+			user += " (service account)"
+		}
+
+		scopes := make([]string, len(token.Scopes))
+		for j, scope := range token.Scopes {
+			scopes[j] = string(scope)
+		}
+
+		expires := "never"
+		if token.ExpiresAt != nil {
+			expires = format.Time(*token.ExpiresAt)
+		}
+
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = format.Time(*token.LastUsedAt)
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			token.Name,
+			token.TokenPrefix + "…",
+			user,
+			strings.Join(scopes, ", "),
+			expires,
+			lastUsed,
+			format.Time(token.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "tokens create", "tokens revoke")
+}
+
+func createApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name := ""
+	if len(args) > 0 {
+		name = strings.TrimSpace(args[0])
+	}
+
+	if name == "" {
+		if term.IsJsonOutput() {
+			term.OutputErrorAndExit("A name is required with --json/--ndjson")
+		}
+
+		var err error
+		name, err = term.GetRequiredUserStringInput("Name:")
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get name: %v", err)
+		}
+	}
+
+	req := shared.CreateApiTokenRequest{
+		Name:          name,
+		ExpiresInDays: tokenExpiresInDays,
+	}
+
+	for _, scope := range tokenScopes {
+		s := shared.ApiTokenScope(strings.ToLower(scope))
+		if !s.IsValid() {
+			term.OutputErrorAndExit("Invalid scope '%s'—use read, write, or admin", scope)
+		}
+		req.Scopes = append(req.Scopes, s)
+	}
+
+	if tokenServiceAccount != "" {
+		req.ServiceAccountId = mustResolveServiceAccount(tokenServiceAccount).Id
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateApiToken(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating token: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("token", res)
+		return
+	}
+
+	fmt.Printf("✅ Created token %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.ApiToken.Name))
+	fmt.Println()
+	fmt.Println("🔑 Token—it won't be shown again:")
+	fmt.Println(color.New(color.Bold).Sprint(res.Token))
+	fmt.Println()
+	fmt.Println("Set it as PLANDEX_TOKEN to authenticate without signing in. For a self-hosted server, also set PLANDEX_HOST.")
+	fmt.Println()
+	term.PrintCmds("", "tokens")
+}
+
+func revokeApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing tokens: %v", apiErr.Msg)
+	}
+
+	var token *shared.ApiToken
+	arg := strings.TrimSuffix(args[0], "…")
+
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(tokens) {
+			term.OutputErrorAndExit("Token %d not found", n)
+		}
+		token = tokens[n-1]
+	} else {
+		for _, t := range tokens {
+			if t.Id == arg || (shared.IsApiToken(arg) && strings.HasPrefix(arg, t.TokenPrefix)) || t.TokenPrefix == arg {
+				token = t
+				break
+			}
+		}
+	}
+
+	if token == nil {
+		term.OutputErrorAndExit("Token %s not found", arg)
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.RevokeApiToken(token.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error revoking token: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Revoked token %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(token.Name))
+}
+
+func listServiceAccounts(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	serviceAccounts, apiErr := api.Client.ListServiceAccounts()
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error listing service accounts: %v", apiErr.Msg)
+	}
+
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing org roles: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		if serviceAccounts == nil {
+			serviceAccounts = []*shared.ServiceAccount{}
+		}
+		term.OutputJsonResult("serviceAccounts", serviceAccounts)
+		return
+	}
+
+	if len(serviceAccounts) == 0 {
+		fmt.Println("🤷‍♂️ No service accounts")
+		fmt.Println()
+		term.PrintCmds("", "service-accounts create")
+		return
+	}
+
+	orgRoleLabels := map[string]string{}
+	for _, orgRole := range orgRoles {
+		orgRoleLabels[orgRole.Id] = orgRole.Label
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Role", "Created"})
+	for _, sa := range serviceAccounts {
+		table.Append([]string{sa.Name, orgRoleLabels[sa.OrgRoleId], format.Time(sa.CreatedAt)})
+	}
+	table.Render()
+
+	fmt.Println()
+	term.PrintCmds("", "tokens create", "service-accounts create", "service-accounts rm")
+}
+
+func createServiceAccount(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name, orgRoleName := "", ""
+	if len(args) >= 1 {
+		name = strings.TrimSpace(args[0])
+	}
+	if len(args) == 2 {
+		orgRoleName = args[1]
+	}
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Failed to list org roles: %v", apiErr.Msg)
+	}
+
+	if name == "" {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Name:")
+		if err != nil {
+			term.OutputErrorAndExit("Failed to get name: %v", err)
+		}
+	}
+
+	if orgRoleName == "" {
+		var orgRoleNames []string
+		for _, orgRole := range orgRoles {
+			orgRoleNames = append(orgRoleNames, orgRole.Label)
+		}
+
+		var err error
+		orgRoleName, err = term.SelectFromList("Org role:", orgRoleNames)
+		if err != nil {
+			term.OutputErrorAndExit("Failed to select org role: %v", err)
+		}
+	}
+
+	var orgRoleId string
+	for _, orgRole := range orgRoles {
+		if strings.EqualFold(orgRole.Label, orgRoleName) {
+			orgRoleId = orgRole.Id
+			break
+		}
+	}
+
+	if orgRoleId == "" {
+		term.OutputErrorAndExit("Org role '%s' not found", orgRoleName)
+	}
+
+	term.StartSpinner("")
+	serviceAccount, apiErr := api.Client.CreateServiceAccount(shared.CreateServiceAccountRequest{
+		Name:      name,
+		OrgRoleId: orgRoleId,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Failed to create service account: %v", apiErr.Msg)
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputJsonResult("serviceAccount", serviceAccount)
+		return
+	}
+
+	fmt.Printf("✅ Created service account %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(serviceAccount.Name))
+	fmt.Println()
+	fmt.Println("Create a token for it with:")
+	fmt.Printf("plandex tokens create --service-account %q\n", serviceAccount.Name)
+}
+
+func removeServiceAccount(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	serviceAccount := mustResolveServiceAccount(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteServiceAccount(serviceAccount.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing service account: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Removed service account %s and revoked its tokens\n", color.New(color.Bold, term.ColorHiCyan).Sprint(serviceAccount.Name))
+}
+
+func mustResolveServiceAccount(arg string) *shared.ServiceAccount {
+	term.StartSpinner("")
+	serviceAccounts, apiErr := api.Client.ListServiceAccounts()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing service accounts: %v", apiErr.Msg)
+	}
+
+	for _, sa := range serviceAccounts {
+		if sa.Id == arg || strings.EqualFold(sa.Name, arg) {
+			return sa
+		}
+	}
+
+	term.OutputErrorAndExit("Service account %s not found", arg)
+	return nil
+}
@@ -37,7 +37,7 @@ func update(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("failed to list context: %s", apiErr)
 	}
 
-	paths, err := fs.GetProjectPaths(fs.ProjectRoot)
+	paths, err := fs.GetProjectPathsWithRepoRoots(fs.ProjectRoot)
 
 	if err != nil {
 		term.OutputErrorAndExit("error getting project paths: %v", err)
@@ -38,7 +38,7 @@ var creditsCurrentPlan bool
 
 var usageCmd = &cobra.Command{
 	Use:   "usage",
-	Short: "Display credits balance and usage report",
+	Short: "Display usage report and credits balance",
 	Run:   usage,
 }
 
@@ -120,16 +120,17 @@ func showUsage() {
 
 	builder := strings.Builder{}
 
-	balance := res.Balance
-	balanceStr := formatSpend(balance)
-
 	spendLbl := "💸 Spent"
 	if creditsSession {
 		spendLbl += " This Session"
 	} else if creditsToday {
 		spendLbl += " Today"
 	} else if creditsMonth {
-		spendLbl += " This Billing Month"
+		if auth.Current.IsCloud {
+			spendLbl += " This Billing Month"
+		} else {
+			spendLbl += " This Month"
+		}
 		spendLbl += fmt.Sprintf(" (since %s)", res.MonthStart.Format("Jan 2"))
 	} else if creditsCurrentPlan {
 		spendLbl += fmt.Sprintf(" On Plan 📋 %s", currentPlanName)
@@ -144,8 +145,19 @@ func showUsage() {
 
 	table := tablewriter.NewWriter(&builder)
 	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"💰 Current Balance", spendLbl})
-	table.Append([]string{balanceStr, spendStr})
+	if auth.Current.IsCloud {
+		table.SetHeader([]string{"💰 Current Balance", spendLbl})
+		table.Append([]string{formatSpend(res.Balance), spendStr})
+	} else {
+		// self-hosted usage ledger—there's no balance, but token totals are reported
+		table.SetHeader([]string{spendLbl, "🪙 Input Tokens", "🪙 Output Tokens", "🎯 Cached Tokens"})
+		table.Append([]string{
+			spendStr,
+			strconv.Itoa(res.TotalInputTokens),
+			strconv.Itoa(res.TotalOutputTokens),
+			strconv.Itoa(res.TotalCachedTokens),
+		})
+	}
 	table.Render()
 	fmt.Fprintln(&builder)
 
@@ -158,79 +170,22 @@ func showUsage() {
 		fmt.Fprintln(&builder)
 	}
 
-	amountByStr := map[string]float64{}
-	if len(res.ByPlanId) > 0 {
-		if !creditsCurrentPlan {
-			table := tablewriter.NewWriter(&builder)
-			table.SetAutoWrapText(false)
-			table.SetHeader([]string{"📋 Plan", "💸 Spent"})
-
-			rows := [][]string{}
-
-			for id, spend := range res.ByPlanId {
-				name := res.PlanNamesById[id]
-				spendStr := formatSpend(spend)
-				rows = append(rows, []string{name, spendStr})
-				amountByStr[spendStr] = spend.InexactFloat64()
-			}
-			sort.Slice(rows, func(i, j int) bool {
-				return amountByStr[rows[i][1]] > amountByStr[rows[j][1]]
-			})
-			for _, row := range rows {
-				table.Append(row)
-			}
-
-			table.Render()
-			fmt.Fprintln(&builder)
-		}
-	}
-
-	if len(res.ByPurpose) > 0 {
-		table = tablewriter.NewWriter(&builder)
-		table.SetAutoWrapText(false)
-		table.SetHeader([]string{"⚡️ Purpose", "💸 Spent"})
-
-		rows := [][]string{}
-		for name, spend := range res.ByPurpose {
-			spendStr := formatSpend(spend)
-			rows = append(rows, []string{name, spendStr})
-			amountByStr[spendStr] = spend.InexactFloat64()
-		}
-		sort.Slice(rows, func(i, j int) bool {
-			return amountByStr[rows[i][1]] > amountByStr[rows[j][1]]
-		})
-		for _, row := range rows {
-			table.Append(row)
-		}
-
-		table.Render()
-		fmt.Fprintln(&builder)
-	}
-
-	if len(res.ByModelName) > 0 {
-		table = tablewriter.NewWriter(&builder)
-		table.SetAutoWrapText(false)
-		table.SetHeader([]string{"🤖 Model", "💸 Spent"})
-
-		rows := [][]string{}
-		for name, spend := range res.ByModelName {
-			spendStr := formatSpend(spend)
-			rows = append(rows, []string{name, spendStr})
-			amountByStr[spendStr] = spend.InexactFloat64()
-		}
-		sort.Slice(rows, func(i, j int) bool {
-			return amountByStr[rows[i][1]] > amountByStr[rows[j][1]]
-		})
-		for _, row := range rows {
-			table.Append(row)
-		}
-		table.Render()
-		fmt.Fprintln(&builder)
+	if !creditsCurrentPlan {
+		renderSpendTable(&builder, "📋 Plan", res.ByPlanId, res.PlanNamesById, false)
 	}
+	renderSpendTable(&builder, "👤 User", res.ByUserId, res.UserNamesById, false)
+	renderSpendTable(&builder, "📅 Day", res.ByDay, nil, true)
+	renderSpendTable(&builder, "⚡️ Purpose", res.ByPurpose, nil, false)
+	renderSpendTable(&builder, "🎭 Role", res.ByModelRole, nil, false)
+	renderSpendTable(&builder, "🤖 Model", res.ByModelName, nil, false)
 
 	term.PageOutput(builder.String())
 
-	term.PrintCmds("", "usage", "billing")
+	if auth.Current.IsCloud {
+		term.PrintCmds("", "usage", "billing")
+	} else {
+		term.PrintCmds("", "usage", "usage prices")
+	}
 }
 
 func showLog(cmd *cobra.Command, args []string) {
@@ -320,7 +275,11 @@ func showLog(cmd *cobra.Command, args []string) {
 	tableString := &strings.Builder{}
 	table := tablewriter.NewWriter(tableString)
 	table.SetAutoWrapText(false)
-	table.SetHeader([]string{"Amount", "Balance", "Transaction"})
+	if auth.Current.IsCloud {
+		table.SetHeader([]string{"Amount", "Balance", "Transaction"})
+	} else {
+		table.SetHeader([]string{"Amount", "Transaction"})
+	}
 
 	for _, transaction := range transactions {
 		var sign string
@@ -337,7 +296,20 @@ func showLog(cmd *cobra.Command, args []string) {
 				desc += fmt.Sprintf("Plan → %s\n", planName)
 			}
 
-			surchargePct := transaction.DebitSurcharge.Div(*transaction.DebitBaseAmount)
+			if !auth.Current.IsCloud && (transaction.UserName != nil || transaction.UserEmail != nil) {
+				userLabel := ""
+				if transaction.UserName != nil && *transaction.UserName != "" {
+					userLabel = *transaction.UserName
+				} else if transaction.UserEmail != nil {
+					userLabel = *transaction.UserEmail
+				}
+				desc += fmt.Sprintf("👤 %s\n", userLabel)
+			}
+
+			surchargePct := decimal.Zero
+			if transaction.DebitSurcharge != nil && transaction.DebitBaseAmount != nil && !transaction.DebitBaseAmount.IsZero() {
+				surchargePct = transaction.DebitSurcharge.Div(*transaction.DebitBaseAmount)
+			}
 
 			inputPrice := transaction.DebitModelInputPricePerToken.Mul(decimal.NewFromInt(1000000)).Mul(surchargePct.Add(decimal.NewFromInt(1))).StringFixed(4)
 			outputPrice := transaction.DebitModelOutputPricePerToken.Mul(decimal.NewFromInt(1000000)).Mul(surchargePct.Add(decimal.NewFromInt(1))).StringFixed(4)
@@ -347,7 +319,9 @@ func showLog(cmd *cobra.Command, args []string) {
 			if transaction.DebitCacheDiscount != nil {
 				cacheDiscountStr = transaction.DebitCacheDiscount.StringFixed(4)
 				totalAmount := transaction.DebitBaseAmount.Add(*transaction.DebitCacheDiscount)
-				cacheDiscountPct = transaction.DebitCacheDiscount.Div(totalAmount).Mul(decimal.NewFromInt(100)).InexactFloat64()
+				if !totalAmount.IsZero() {
+					cacheDiscountPct = transaction.DebitCacheDiscount.Div(totalAmount).Mul(decimal.NewFromInt(100)).InexactFloat64()
+				}
 			}
 
 			for i := 0; i < 2; i++ {
@@ -393,12 +367,19 @@ func showLog(cmd *cobra.Command, args []string) {
 			balanceStr = strings.TrimSuffix(balanceStr, "0")
 		}
 
-		table.Append([]string{
-			color.New(c).Sprint(sign + "$" + amountStr),
-			"$" + balanceStr,
+		if auth.Current.IsCloud {
+			table.Append([]string{
+				color.New(c).Sprint(sign + "$" + amountStr),
+				"$" + balanceStr,
 
-			desc,
-		})
+				desc,
+			})
+		} else {
+			table.Append([]string{
+				color.New(c).Sprint(sign + "$" + amountStr),
+				desc,
+			})
+		}
 	}
 
 	table.Render()
@@ -539,6 +520,43 @@ func showLog(cmd *cobra.Command, args []string) {
 	}
 }
 
+// renderSpendTable renders spend by key, largest first (or most recent key first when sortByKey is set), labeling keys with names when provided
+func renderSpendTable(builder *strings.Builder, header string, spendByKey map[string]decimal.Decimal, namesByKey map[string]string, sortByKey bool) {
+	if len(spendByKey) == 0 {
+		return
+	}
+
+	type row struct {
+		key   string
+		label string
+		spend decimal.Decimal
+	}
+
+	rows := []row{}
+	for key, spend := range spendByKey {
+		label := key
+		if name, ok := namesByKey[key]; ok && name != "" {
+			label = name
+		}
+		rows = append(rows, row{key: key, label: label, spend: spend})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if sortByKey {
+			return rows[i].key > rows[j].key
+		}
+		return rows[i].spend.GreaterThan(rows[j].spend)
+	})
+
+	table := tablewriter.NewWriter(builder)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{header, "💸 Spent"})
+	for _, r := range rows {
+		table.Append([]string{r.label, formatSpend(r.spend)})
+	}
+	table.Render()
+	fmt.Fprintln(builder)
+}
+
 func formatSpend(spend decimal.Decimal) string {
 	if spend.IsZero() {
 		return "$0.00"
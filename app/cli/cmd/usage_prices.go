@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	shared "plandex-shared"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var showAllPrices bool
+
+var usagePricesCmd = &cobra.Command{
+	Use:   "prices",
+	Short: "Show per-model prices used by the self-hosted usage ledger",
+	Args:  cobra.NoArgs,
+	Run:   usagePrices,
+}
+
+var usageSetPriceCmd = &cobra.Command{
+	Use:   "set-price <model-id> <input-per-1m> <output-per-1m> [cached-input-per-1m]",
+	Short: "Override a model's price (USD per 1M tokens) for the self-hosted usage ledger",
+	Args:  cobra.RangeArgs(3, 4),
+	Run:   usageSetPrice,
+}
+
+var usageResetPriceCmd = &cobra.Command{
+	Use:   "reset-price <model-id>",
+	Short: "Remove a model price override and go back to the model's list price",
+	Args:  cobra.ExactArgs(1),
+	Run:   usageResetPrice,
+}
+
+func init() {
+	usageCmd.AddCommand(usagePricesCmd)
+	usageCmd.AddCommand(usageSetPriceCmd)
+	usageCmd.AddCommand(usageResetPriceCmd)
+
+	usagePricesCmd.Flags().BoolVarP(&showAllPrices, "all", "a", false, "Include models without a price")
+}
+
+func usagePrices(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForPrices()
+
+	term.StartSpinner("")
+	prices, apiErr := api.Client.ListModelPrices()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting model prices: %v", apiErr.Msg)
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].ModelId < prices[j].ModelId
+	})
+
+	builder := strings.Builder{}
+	table := tablewriter.NewWriter(&builder)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Model", "Input / 1M", "Output / 1M", "Cached Input / 1M", "Source"})
+
+	for _, price := range prices {
+		isZero := price.InputPricePerMillion.IsZero() && price.OutputPricePerMillion.IsZero()
+		if isZero && !price.IsOverride && !showAllPrices {
+			continue
+		}
+
+		source := "list price"
+		if price.IsOverride {
+			source = "override"
+		}
+
+		cached := "same as input"
+		if !price.CachedInputPricePerMillion.IsZero() {
+			cached = "$" + formatPrice(price.CachedInputPricePerMillion)
+		}
+
+		table.Append([]string{
+			string(price.ModelId),
+			"$" + formatPrice(price.InputPricePerMillion),
+			"$" + formatPrice(price.OutputPricePerMillion),
+			cached,
+			source,
+		})
+	}
+
+	table.Render()
+
+	term.PageOutput(builder.String())
+
+	fmt.Println()
+	term.PrintCmds("", "usage set-price", "usage reset-price", "usage")
+}
+
+func usageSetPrice(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForPrices()
+
+	req := shared.SetModelPriceRequest{
+		ModelId:               shared.ModelId(args[0]),
+		InputPricePerMillion:  mustParsePrice(args[1]),
+		OutputPricePerMillion: mustParsePrice(args[2]),
+	}
+	if len(args) > 3 {
+		req.CachedInputPricePerMillion = mustParsePrice(args[3])
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetModelPrice(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting model price: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Set price for %s → $%s input / $%s output per 1M\n", req.ModelId, formatPrice(req.InputPricePerMillion), formatPrice(req.OutputPricePerMillion))
+	fmt.Println()
+	term.PrintCmds("", "usage prices", "usage")
+}
+
+func usageResetPrice(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForPrices()
+
+	modelId := shared.ModelId(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteModelPrice(modelId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error resetting model price: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Reset price for %s to its list price\n", modelId)
+	fmt.Println()
+	term.PrintCmds("", "usage prices", "usage")
+}
+
+func mustBeSelfHostedForPrices() {
+	if auth.Current.IsCloud {
+		fmt.Println("🙅‍♂️ Model prices are set by Plandex Cloud. This command is only available for self-hosted servers.")
+		os.Exit(1)
+	}
+}
+
+func mustParsePrice(s string) decimal.Decimal {
+	price, err := decimal.NewFromString(strings.TrimPrefix(s, "$"))
+	if err != nil || price.IsNegative() {
+		term.OutputErrorAndExit("Invalid price: %s", s)
+	}
+	return price
+}
+
+func formatPrice(price decimal.Decimal) string {
+	priceStr := price.StringFixed(4)
+	for i := 0; i < 2; i++ {
+		priceStr = strings.TrimSuffix(priceStr, "0")
+	}
+	return priceStr
+}
@@ -6,6 +6,7 @@ import (
 	"plandex-cli/api"
 	"plandex-cli/auth"
 	"plandex-cli/term"
+	"strings"
 
 	shared "plandex-shared"
 
@@ -19,8 +20,19 @@ var usersCmd = &cobra.Command{
 	Run:   listUsersAndInvites,
 }
 
+var usersSetRoleCmd = &cobra.Command{
+	Use:   "set-role [email] [org-role]",
+	Short: "Change a user's org role",
+	Long: `Change a user's org role to a default role or a custom role from 'plandex roles'. The role can be given by name or label:
+
+  plandex users set-role dev@example.com junior`,
+	Run:  setUserRole,
+	Args: cobra.MaximumNArgs(2),
+}
+
 func init() {
 	RootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersSetRoleCmd)
 }
 
 func listUsersAndInvites(cmd *cobra.Command, args []string) {
@@ -85,7 +97,11 @@ func listUsersAndInvites(cmd *cobra.Command, args []string) {
 	table.SetHeader([]string{"Email", "Name", "Role", "Status"})
 
 	for _, user := range userResp.Users {
-		table.Append([]string{user.Email, user.Name, orgRolesById[userResp.OrgUsersByUserId[user.Id].OrgRoleId].Label, "Active"})
+		status := "Active"
+		if user.IsServiceAccount {
+			status = "Service Account"
+		}
+		table.Append([]string{user.Email, user.Name, orgRolesById[userResp.OrgUsersByUserId[user.Id].OrgRoleId].Label, status})
 	}
 
 	for _, invite := range pendingInvites {
@@ -94,3 +110,108 @@ func listUsersAndInvites(cmd *cobra.Command, args []string) {
 
 	table.Render()
 }
+
+func setUserRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	email, orgRoleName := "", ""
+	if len(args) >= 1 {
+		email = args[0]
+	}
+	if len(args) == 2 {
+		orgRoleName = args[1]
+	}
+
+	var userResp *shared.ListUsersResponse
+	var orgRoles []*shared.OrgRole
+
+	errCh := make(chan error)
+
+	term.StartSpinner("")
+
+	go func() {
+		var err *shared.ApiError
+		userResp, err = api.Client.ListUsers()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching users: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	go func() {
+		var err *shared.ApiError
+		orgRoles, err = api.Client.ListOrgRoles()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching org roles: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	for i := 0; i < 2; i++ {
+		err := <-errCh
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	term.StopSpinner()
+
+	var user *shared.User
+	if email == "" {
+		labelToUser := make(map[string]*shared.User)
+		var labels []string
+		for _, u := range userResp.Users {
+			label := fmt.Sprintf("%s <%s>", u.Name, u.Email)
+			labelToUser[label] = u
+			labels = append(labels, label)
+		}
+
+		selected, err := term.SelectFromList("Select a user:", labels)
+		if err != nil {
+			term.OutputErrorAndExit("Failed to select user: %v", err)
+		}
+		user = labelToUser[selected]
+	} else {
+		for _, u := range userResp.Users {
+			if strings.EqualFold(u.Email, email) {
+				user = u
+				break
+			}
+		}
+	}
+
+	if user == nil {
+		term.OutputErrorAndExit("User '%s' not found in org", email)
+	}
+
+	if orgRoleName == "" {
+		var orgRoleLabels []string
+		for _, orgRole := range orgRoles {
+			orgRoleLabels = append(orgRoleLabels, orgRole.Label)
+		}
+
+		var err error
+		orgRoleName, err = term.SelectFromList("Org role:", orgRoleLabels)
+		if err != nil {
+			term.OutputErrorAndExit("Failed to select org role: %v", err)
+		}
+	}
+
+	orgRole := findOrgRole(orgRoles, orgRoleName)
+	if orgRole == nil {
+		term.OutputErrorAndExit("Org role '%s' not found", orgRoleName)
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetOrgUserRole(user.Id, shared.SetOrgUserRoleRequest{OrgRoleId: orgRole.Id})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Failed to set role: %s", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ %s is now %s\n", user.Email, orgRole.Label)
+}
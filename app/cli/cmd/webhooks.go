@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	shared "plandex-shared"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var webhookEvents []string
+var webhookForProject bool
+var webhookLogLimit int
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	Aliases: []string{"webhook"},
+	Short:   "List the org's webhooks for plan lifecycle events",
+	Args:    cobra.NoArgs,
+	Run:     listWebhooks,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook",
+	Long: `Add a webhook that receives a signed POST request when plan lifecycle events happen.
+
+By default the webhook receives every event for every project in the org. Use --event to choose events and --project to limit it to the current project:
+
+  plandex webhooks add https://example.com/hooks/plandex --event plan.finished --event build.failed --project
+
+Events: ` + webhookEventsList(),
+	Args: cobra.ExactArgs(1),
+	Run:  addWebhook,
+}
+
+var webhooksRmCmd = &cobra.Command{
+	Use:     "rm <webhook>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a webhook by number or id",
+	Args:    cobra.ExactArgs(1),
+	Run:     removeWebhook,
+}
+
+var webhooksEnableCmd = &cobra.Command{
+	Use:   "enable <webhook>",
+	Short: "Enable a webhook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setWebhookEnabled(args[0], true)
+	},
+}
+
+var webhooksDisableCmd = &cobra.Command{
+	Use:   "disable <webhook>",
+	Short: "Stop sending events to a webhook without removing it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setWebhookEnabled(args[0], false)
+	},
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <webhook>",
+	Short: "Send a test 'ping' event to a webhook",
+	Args:  cobra.ExactArgs(1),
+	Run:   testWebhook,
+}
+
+var webhooksLogCmd = &cobra.Command{
+	Use:   "log [webhook]",
+	Short: "Show recent webhook deliveries",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   listWebhookDeliveries,
+}
+
+func init() {
+	RootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksRmCmd)
+	webhooksCmd.AddCommand(webhooksEnableCmd)
+	webhooksCmd.AddCommand(webhooksDisableCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	webhooksCmd.AddCommand(webhooksLogCmd)
+
+	webhooksAddCmd.Flags().StringArrayVarP(&webhookEvents, "event", "e", nil, "Event to send (repeatable)—defaults to all events")
+	webhooksAddCmd.Flags().BoolVarP(&webhookForProject, "project", "p", false, "Only send events for the current project")
+
+	webhooksLogCmd.Flags().IntVarP(&webhookLogLimit, "limit", "n", 25, "Number of deliveries to show")
+}
+
+func listWebhooks(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MaybeResolveProject()
+
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing webhooks: %v", apiErr.Msg)
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		fmt.Println()
+		term.PrintCmds("", "webhooks add")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Url", "Events", "Scope", "Enabled"})
+
+	for i, webhook := range webhooks {
+		events := "all"
+		if len(webhook.Events) > 0 {
+			strs := make([]string, len(webhook.Events))
+			for j, event := range webhook.Events {
+				strs[j] = string(event)
+			}
+			events = strings.Join(strs, ", ")
+		}
+
+		scope := "org"
+		if webhook.ProjectId != nil {
+			scope = "project"
+			if *webhook.ProjectId == lib.CurrentProjectId {
+				scope = "this project"
+			}
+		}
+
+		enabled := color.New(color.FgGreen).Sprint("✓")
+		if !webhook.Enabled {
+			enabled = color.New(color.FgHiBlack).Sprint("✗")
+		}
+
+		table.Append([]string{strconv.Itoa(i + 1), webhook.Url, events, scope, enabled})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "webhooks log", "webhooks test", "webhooks add", "webhooks rm")
+}
+
+func addWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := shared.CreateWebhookRequest{
+		Url: args[0],
+	}
+
+	for _, event := range webhookEvents {
+		req.Events = append(req.Events, shared.WebhookEvent(event))
+	}
+
+	if webhookForProject {
+		lib.MustResolveProject()
+		req.ProjectId = lib.CurrentProjectId
+	}
+
+	term.StartSpinner("")
+	webhook, apiErr := api.Client.CreateWebhook(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error adding webhook: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Added webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+	fmt.Println()
+	fmt.Println("🔑 Signing secret—it won't be shown again:")
+	fmt.Println(color.New(color.Bold).Sprint(webhook.Secret))
+	fmt.Println()
+	fmt.Println("Use it to verify the X-Plandex-Signature header on each request—see the docs for details.")
+	fmt.Println()
+	term.PrintCmds("", "webhooks test", "webhooks")
+}
+
+func removeWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing webhook: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Removed webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+}
+
+func setWebhookEnabled(arg string, enabled bool) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(arg)
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetWebhookEnabled(webhook.Id, enabled)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating webhook: %v", apiErr.Msg)
+	}
+
+	action := "Enabled"
+	if !enabled {
+		action = "Disabled"
+	}
+
+	fmt.Printf("✅ %s webhook for %s\n", action, color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+}
+
+func testWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.TestWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error sending test event: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Queued a test event for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+	fmt.Println()
+	term.PrintCmds("", "webhooks log")
+}
+
+func listWebhookDeliveries(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var webhookId string
+	if len(args) > 0 {
+		webhookId = mustResolveWebhook(args[0]).Id
+	}
+
+	term.StartSpinner("")
+	deliveries, apiErr := api.Client.ListWebhookDeliveries(webhookId, webhookLogLimit)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing webhook deliveries: %v", apiErr.Msg)
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Println("🤷‍♂️ No deliveries")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Event", "Status", "Attempts", "Response", "Created", "Last Attempt"})
+
+	for _, delivery := range deliveries {
+		var status string
+		switch delivery.Status {
+		case shared.WebhookDeliveryStatusSucceeded:
+			status = color.New(color.FgGreen).Sprint("succeeded")
+		case shared.WebhookDeliveryStatusFailed:
+			status = color.New(color.FgRed).Sprint("failed")
+		default:
+			status = color.New(color.FgYellow).Sprint("pending")
+			if delivery.Attempts > 0 && delivery.NextAttemptAt != nil {
+				status += " (retry " + format.Time(*delivery.NextAttemptAt) + ")"
+			}
+		}
+
+		response := ""
+		if delivery.ResponseStatus != 0 {
+			response = strconv.Itoa(delivery.ResponseStatus)
+		}
+		if delivery.Error != "" && delivery.Status != shared.WebhookDeliveryStatusSucceeded {
+			errMsg := delivery.Error
+			if len(errMsg) > 60 {
+				errMsg = errMsg[:60] + "…"
+			}
+			if response != "" {
+				response += " "
+			}
+			response += errMsg
+		}
+
+		lastAttempt := ""
+		if delivery.LastAttemptAt != nil {
+			lastAttempt = format.Time(*delivery.LastAttemptAt)
+		}
+
+		table.Append([]string{
+			string(delivery.Event),
+			status,
+			strconv.Itoa(delivery.Attempts),
+			response,
+			format.Time(delivery.CreatedAt),
+			lastAttempt,
+		})
+	}
+
+	table.Render()
+}
+
+// mustResolveWebhook accepts a webhook's number from 'plandex webhooks' or its id
+func mustResolveWebhook(arg string) *shared.Webhook {
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing webhooks: %v", apiErr.Msg)
+	}
+
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(webhooks) {
+			term.OutputErrorAndExit("Webhook %d not found", n)
+		}
+		return webhooks[n-1]
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.Id == arg {
+			return webhook
+		}
+	}
+
+	term.OutputErrorAndExit("Webhook %s not found", arg)
+	return nil
+}
+
+func webhookEventsList() string {
+	strs := make([]string, len(shared.WebhookEvents))
+	for i, event := range shared.WebhookEvents {
+		strs[i] = string(event)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -50,6 +50,7 @@ func GetPaths(baseDir, currentDir string) (*types.ProjectPaths, error) {
 		numRoutines++
 		go func() {
 			cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+			cmd.Dir = baseDir
 			output, err := cmd.Output()
 			if err != nil {
 				errCh <- fmt.Errorf("error getting git root: %s", err)
@@ -371,6 +372,10 @@ func GetBaseDirForContexts(contexts []*shared.Context) string {
 	var paths []string
 
 	for _, context := range contexts {
+		// additional repo roots are scanned separately by GetProjectPathsWithRepoRoots
+		if root, _ := SplitRepoRootPath(context.FilePath); root != nil {
+			continue
+		}
 		if context.FilePath != "" {
 			paths = append(paths, context.FilePath)
 		}
@@ -380,6 +385,11 @@ func GetBaseDirForContexts(contexts []*shared.Context) string {
 }
 
 func GetBaseDirForFilePaths(paths []string) string {
+	// if every path is in the same additional repo root, there's no need to scan any further up than that root
+	if root := commonRepoRoot(paths); root != nil {
+		return root.AbsPath
+	}
+
 	baseDir := ProjectRoot
 	dirsUp := 0
 
@@ -407,6 +417,22 @@ func GetBaseDirForFilePaths(paths []string) string {
 	return baseDir
 }
 
+func commonRepoRoot(paths []string) *types.RepoRoot {
+	if len(paths) == 0 || len(GetRepoRoots()) == 0 {
+		return nil
+	}
+
+	var res *types.RepoRoot
+	for _, path := range paths {
+		root, _ := SplitRepoRootPath(ToPlanPath(path))
+		if root == nil || (res != nil && root != res) {
+			return nil
+		}
+		res = root
+	}
+	return res
+}
+
 // isSubpathOf checks if 'child' is within 'parent' (same path or deeper).
 // Both 'parent' and 'child' can be absolute or relative to 'baseDir';
 // we’ll convert them to absolute paths based on 'baseDir' and then compare.
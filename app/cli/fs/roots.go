@@ -0,0 +1,291 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A multi-repo project lists additional repository roots in roots.json in the project's plandex dir. Paths inside them are stored in plans as '@<alias>/<path>' ("plan paths"), and converted to paths relative to the project root ("local paths") wherever the file system is touched.
+
+const RepoRootPrefix = "@"
+
+var repoRoots []*types.RepoRoot
+var repoRootsLoaded bool
+var repoRootsMu sync.Mutex
+
+var repoRootAliasRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+func GetRepoRootsPath() string {
+	return filepath.Join(PlandexDir, "roots.json")
+}
+
+// LoadRepoRoots reads the project's additional repo roots from disk. Returns an empty list if the project doesn't have any.
+func LoadRepoRoots() ([]*types.RepoRoot, error) {
+	if PlandexDir == "" || ProjectRoot == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(GetRepoRootsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading repo roots: %v", err)
+	}
+
+	var config types.RepoRootsConfig
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing repo roots %s: %v", GetRepoRootsPath(), err)
+	}
+
+	for _, root := range config.Roots {
+		if filepath.IsAbs(root.Path) {
+			root.AbsPath = filepath.Clean(root.Path)
+		} else {
+			root.AbsPath = filepath.Join(ProjectRoot, root.Path)
+		}
+	}
+
+	return config.Roots, nil
+}
+
+func SaveRepoRoots(roots []*types.RepoRoot) error {
+	bytes, err := json.MarshalIndent(types.RepoRootsConfig{Roots: roots}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling repo roots: %v", err)
+	}
+
+	err = os.WriteFile(GetRepoRootsPath(), bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing repo roots: %v", err)
+	}
+
+	repoRootsMu.Lock()
+	repoRootsLoaded = false
+	repoRootsMu.Unlock()
+
+	return nil
+}
+
+// GetRepoRoots returns the project's additional repo roots, loading them on first use
+func GetRepoRoots() []*types.RepoRoot {
+	repoRootsMu.Lock()
+	defer repoRootsMu.Unlock()
+
+	if !repoRootsLoaded {
+		roots, err := LoadRepoRoots()
+		if err != nil {
+			term.OutputErrorAndExit("%v", err)
+		}
+		repoRoots = roots
+		repoRootsLoaded = true
+	}
+
+	return repoRoots
+}
+
+func GetRepoRoot(alias string) *types.RepoRoot {
+	for _, root := range GetRepoRoots() {
+		if root.Alias == alias {
+			return root
+		}
+	}
+	return nil
+}
+
+func ValidateRepoRootAlias(alias string) error {
+	if !repoRootAliasRegex.MatchString(alias) {
+		return fmt.Errorf("invalid alias '%s'—use letters, numbers, '-', and '_'", alias)
+	}
+	return nil
+}
+
+// SplitRepoRootPath returns the repo root and the path within it for a plan path starting with '@<alias>'. Returns a nil root for paths in the main project root.
+func SplitRepoRootPath(path string) (*types.RepoRoot, string) {
+	if !strings.HasPrefix(path, RepoRootPrefix) {
+		return nil, path
+	}
+
+	alias, rel, _ := strings.Cut(strings.TrimPrefix(path, RepoRootPrefix), "/")
+	root := GetRepoRoot(alias)
+	if root == nil {
+		return nil, path
+	}
+
+	return root, rel
+}
+
+// ResolvePath returns the absolute path for a plan path
+func ResolvePath(path string) string {
+	root, rel := SplitRepoRootPath(path)
+	if root == nil {
+		return filepath.Join(ProjectRoot, path)
+	}
+	return filepath.Join(root.AbsPath, filepath.FromSlash(rel))
+}
+
+// ToLocalPath converts a plan path to a path relative to the project root
+func ToLocalPath(path string) string {
+	root, _ := SplitRepoRootPath(path)
+	if root == nil {
+		return path
+	}
+
+	local, err := filepath.Rel(ProjectRoot, ResolvePath(path))
+	if err != nil {
+		return path
+	}
+	return local
+}
+
+// ToPlanPath converts a path relative to the project root (or an absolute path) to a plan path. Paths that aren't in an additional repo root are returned unchanged.
+func ToPlanPath(path string) string {
+	roots := GetRepoRoots()
+	if len(roots) == 0 || strings.HasPrefix(path, RepoRootPrefix) {
+		return path
+	}
+
+	absPath := path
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(ProjectRoot, path)
+	}
+
+	// the most deeply nested root wins
+	var match *types.RepoRoot
+	var matchRel string
+	for _, root := range roots {
+		rel, err := filepath.Rel(root.AbsPath, absPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		if match == nil || len(root.AbsPath) > len(match.AbsPath) {
+			match = root
+			matchRel = rel
+		}
+	}
+
+	if match == nil {
+		return path
+	}
+
+	if matchRel == "." {
+		return RepoRootPrefix + match.Alias
+	}
+	return RepoRootPrefix + match.Alias + "/" + filepath.ToSlash(matchRel)
+}
+
+func ToPlanPaths(paths map[string]bool) map[string]bool {
+	if len(GetRepoRoots()) == 0 {
+		return paths
+	}
+
+	res := make(map[string]bool, len(paths))
+	for path, v := range paths {
+		res[ToPlanPath(path)] = v
+	}
+	return res
+}
+
+// RepoDirForPath returns the root directory a plan path belongs to, and the path relative to that directory
+func RepoDirForPath(path string) (string, string) {
+	root, _ := SplitRepoRootPath(ToPlanPath(path))
+	if root == nil {
+		return ProjectRoot, path
+	}
+
+	rel, err := filepath.Rel(root.AbsPath, ResolvePath(ToPlanPath(path)))
+	if err != nil {
+		return ProjectRoot, path
+	}
+	return root.AbsPath, rel
+}
+
+// RepoRootDirs returns the project root followed by each additional repo root
+func RepoRootDirs() []string {
+	dirs := []string{ProjectRoot}
+	for _, root := range GetRepoRoots() {
+		dirs = append(dirs, root.AbsPath)
+	}
+	return dirs
+}
+
+func AnyRepoRootIsGitRepo() bool {
+	for _, root := range GetRepoRoots() {
+		if IsGitRepo(root.AbsPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoRootEnvVar is the environment variable that's set to a repo root's absolute path when _apply.sh runs, e.g. PLANDEX_ROOT_FRONTEND
+func RepoRootEnvVar(alias string) string {
+	return "PLANDEX_ROOT_" + strings.ToUpper(strings.ReplaceAll(alias, "-", "_"))
+}
+
+func RepoRootEnv() []string {
+	var env []string
+	for _, root := range GetRepoRoots() {
+		env = append(env, RepoRootEnvVar(root.Alias)+"="+root.AbsPath)
+	}
+	return env
+}
+
+// RepoRootDescriptions describes each additional repo root for the model as '@<alias> ($PLANDEX_ROOT_<ALIAS>)'
+func RepoRootDescriptions() []string {
+	var res []string
+	for _, root := range GetRepoRoots() {
+		res = append(res, fmt.Sprintf("%s%s ($%s)", RepoRootPrefix, root.Alias, RepoRootEnvVar(root.Alias)))
+	}
+	sort.Strings(res)
+	return res
+}
+
+// GetProjectPathsWithRepoRoots adds the paths in each additional repo root to the project paths for baseDir. Paths are local paths.
+func GetProjectPathsWithRepoRoots(baseDir string) (*types.ProjectPaths, error) {
+	paths, err := GetProjectPaths(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range GetRepoRoots() {
+		if _, err := os.Stat(root.AbsPath); err != nil {
+			return nil, fmt.Errorf("repo root @%s at %s: %v", root.Alias, root.AbsPath, err)
+		}
+
+		rootPaths, err := GetPaths(root.AbsPath, ProjectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("error getting paths for repo root @%s: %v", root.Alias, err)
+		}
+
+		for path := range rootPaths.ActivePaths {
+			paths.ActivePaths[path] = true
+		}
+		for path := range rootPaths.AllPaths {
+			paths.AllPaths[path] = true
+		}
+		for dir := range rootPaths.ActiveDirs {
+			paths.ActiveDirs[dir] = true
+		}
+		for dir := range rootPaths.AllDirs {
+			paths.AllDirs[dir] = true
+		}
+		for path, reason := range rootPaths.IgnoredPaths {
+			paths.IgnoredPaths[path] = reason
+		}
+		for dir := range rootPaths.GitIgnoredDirs {
+			paths.GitIgnoredDirs[dir] = true
+		}
+	}
+
+	return paths, nil
+}
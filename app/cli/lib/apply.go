@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -46,6 +47,8 @@ func MustApplyPlanAttempt(
 ) {
 	log.Println("Applying plan")
 
+	MustCheckNoInterruptedApply()
+
 	applyFlags := params.ApplyFlags
 	planId := params.PlanId
 	branch := params.Branch
@@ -82,6 +85,9 @@ func MustApplyPlanAttempt(
 
 		for _, b := range plansRunningRes.Branches {
 			if b.PlanId == planId && b.Name == branch {
+				if term.IsJsonOutput() {
+					term.OutputErrorAndExit("This plan is currently active. Please wait for it to finish before applying.")
+				}
 				fmt.Println("This plan is currently active. Please wait for it to finish before applying.")
 				fmt.Println()
 				term.PrintCmds("", "ps", "connect")
@@ -112,7 +118,7 @@ func MustApplyPlanAttempt(
 		}
 	}
 
-	paths, err := fs.GetProjectPaths(fs.ProjectRoot)
+	paths, err := fs.GetProjectPathsWithRepoRoots(fs.ProjectRoot)
 
 	if err != nil {
 		term.OutputErrorAndExit("error getting project paths: %v", err)
@@ -133,7 +139,7 @@ func MustApplyPlanAttempt(
 	term.ResumeSpinner()
 
 	currentPlanFiles := currentPlanState.CurrentPlanFiles
-	isRepo := fs.ProjectRootIsGitRepo()
+	isRepo := fs.ProjectRootIsGitRepo() || fs.AnyRepoRootIsGitRepo()
 
 	toApply := currentPlanFiles.Files
 	toRemove := currentPlanFiles.Removed
@@ -143,10 +149,42 @@ func MustApplyPlanAttempt(
 
 	if len(toApply) == 0 && !hasExec {
 		term.StopSpinner()
+		if term.IsJsonOutput() {
+			term.OutputJsonResult("apply", types.ApplyJsonResult{UpdatedFiles: []string{}})
+			return
+		}
 		fmt.Println("🤷‍♂️ No changes to apply")
 		return
 	}
 
+	// check before any files are touched, since there's no one to ask once the changes are tentatively applied
+	if hasExec && !noExec && !applyFlags.AutoExec && term.IsJsonOutput() {
+		term.OutputErrorAndExit("This plan has commands to execute—pass --auto-exec or --no-exec with --json/--ndjson")
+	}
+
+	// the server won't mark the plan applied while it has commands the user's org role doesn't allow executing, so check before any files are touched
+	if hasExec {
+		canExec, err := canExecCommands()
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%s", err)
+		}
+		if !canExec {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Your org role doesn't allow executing commands—run 'plandex reject _apply.sh' to apply the plan's file changes without them")
+		}
+	}
+
+	// a missing or misconfigured sandbox has to fail before files are written, since exiting later would leave them applied with no rollback
+	var executor ApplyExecutor
+	if _, ok := toApply["_apply.sh"]; ok && !noExec {
+		executor, err = GetApplyExecutor(MustGetCurrentPlanConfig())
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%s", err)
+		}
+	}
+
 	hasFileChanges := !hasExec || len(toApply) > 1
 
 	var toRollback *types.ApplyRollbackPlan
@@ -198,7 +236,7 @@ func MustApplyPlanAttempt(
 			term.ResumeSpinner()
 		}
 
-		updatedFiles, toRollback, err = ApplyFiles(toApply, toRemove, paths)
+		updatedFiles, toRollback, err = ApplyFiles(planId, branch, toApply, toRemove, paths)
 
 		if err != nil {
 			onErr("failed to apply files: %s", err)
@@ -215,6 +253,31 @@ func MustApplyPlanAttempt(
 			onErr("apply plan server error: %s", err)
 		}
 
+		err = ClearApplyJournal()
+		if err != nil {
+			onErr("%s", err)
+		}
+
+		if term.IsJsonOutput() {
+			res := types.ApplyJsonResult{
+				UpdatedFiles:  updatedFiles,
+				CommitSummary: commitSummary,
+			}
+			if res.UpdatedFiles == nil {
+				res.UpdatedFiles = []string{}
+			}
+			if isRepo && !noCommit && autoCommit && len(updatedFiles) > 0 {
+				gitErr := commitApplied(autoCommit, commitSummary, updatedFiles, currentPlanState)
+				if gitErr != nil {
+					res.CommitError = gitErr.Error()
+				} else {
+					res.Committed = true
+				}
+			}
+			term.OutputJsonResult("apply", res)
+			return
+		}
+
 		if len(updatedFiles) == 0 {
 			term.StopSpinner()
 			fmt.Println("✅ Applied changes, but no files were updated")
@@ -245,7 +308,7 @@ func MustApplyPlanAttempt(
 	}
 
 	if _, ok := toApply["_apply.sh"]; ok && !noExec {
-		handleApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onExecSuccess)
+		handleApplyScript(params, executor, toApply, onErr, toRollback, onExecFail, attempt, onExecSuccess)
 	} else {
 		onExecSuccess()
 	}
@@ -253,6 +316,7 @@ func MustApplyPlanAttempt(
 
 func handleApplyScript(
 	params ApplyPlanParams,
+	executor ApplyExecutor,
 	toApply map[string]string,
 	onErr types.OnErrFn,
 	toRollback *types.ApplyRollbackPlan,
@@ -281,12 +345,51 @@ func handleApplyScript(
 
 	fmt.Println(strings.TrimSpace(md))
 
+	policy, err := LoadExecPolicy()
+	if err != nil {
+		onErr("%s", err)
+	}
+
+	var policyRes ExecPolicyResult
+	if policy != nil {
+		policyRes = policy.Check(content)
+	}
+
+	if len(policyRes.Denied) > 0 {
+		log.Println("Apply script blocked by exec policy")
+
+		color.New(term.ColorHiRed, color.Bold).Println("🚫 Blocked by exec policy")
+		for _, match := range policyRes.Denied {
+			fmt.Println(" • " + match.Describe())
+		}
+		fmt.Println()
+
+		// goes back to the model through the debug loop like any other failed execution
+		onExecFail(-1, getExecPolicyViolationOutput(policy, policyRes.Denied), attempt, toRollback, onErr, onSuccess)
+		return
+	}
+
 	log.Println("Asking user to confirm executing apply script")
 
 	var confirmed bool
-	if params.ApplyFlags.AutoExec {
+	if params.ApplyFlags.AutoExec && len(policyRes.ToConfirm) == 0 {
 		confirmed = true
 	} else {
+		if len(policyRes.ToConfirm) > 0 {
+			color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Exec policy requires confirmation for")
+			for _, match := range policyRes.ToConfirm {
+				fmt.Println(" • " + match.Describe())
+			}
+			fmt.Println()
+		}
+
+		if term.IsJsonOutput() {
+			if toRollback != nil && toRollback.HasChanges() {
+				Rollback(toRollback, false)
+			}
+			onErr("exec policy requires confirmation to run these commands, which isn't possible with --json/--ndjson—changes were rolled back")
+		}
+
 		confirmed, err = term.ConfirmYesNo("Execute now?")
 		if err != nil {
 			onErr("failed to get confirmation user input: %s", err)
@@ -295,26 +398,79 @@ func handleApplyScript(
 
 	if confirmed {
 		log.Println("Executing apply script")
-		execApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onSuccess)
+
+		err = setApplyJournalStatus(types.ApplyJournalStatusExecuting)
+		if err != nil {
+			onErr("%s", err)
+		}
+
+		execApplyScript(params, executor, toApply, onErr, toRollback, onExecFail, attempt, onSuccess)
 	} else {
-		if toRollback != nil && toRollback.HasChanges() {
-			res, err := term.SelectFromList("Skipping execution. Apply file changes or roll back?", []string{string(types.ApplyRollbackOptionKeep), string(types.ApplyRollbackOptionRollback)})
+		skipApplyScript(toRollback, onErr, onSuccess)
+	}
+}
 
-			if err != nil {
-				onErr("failed to get rollback confirmation user input: %s", err)
-			}
+func skipApplyScript(toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
+	if toRollback != nil && toRollback.HasChanges() {
+		res, err := term.SelectFromList("Skipping execution. Apply file changes or roll back?", []string{string(types.ApplyRollbackOptionKeep), string(types.ApplyRollbackOptionRollback)})
 
-			if res == string(types.ApplyRollbackOptionRollback) {
-				Rollback(toRollback, true)
-				os.Exit(0)
-			} else {
-				onSuccess()
-			}
+		if err != nil {
+			onErr("failed to get rollback confirmation user input: %s", err)
+		}
+
+		if res == string(types.ApplyRollbackOptionRollback) {
+			Rollback(toRollback, true)
+			os.Exit(0)
 		} else {
-			fmt.Println("🙅‍♂️ Skipped execution")
-			fmt.Println("🤷‍♂️ No changes to apply")
+			onSuccess()
+		}
+	} else {
+		fmt.Println("🙅‍♂️ Skipped execution")
+		fmt.Println("🤷‍♂️ No changes to apply")
+	}
+}
+
+// canExecCommands checks that the user's org role allows executing a plan's commands. Servers from before the permission existed don't have the endpoint and don't enforce it, so they allow it.
+func canExecCommands() (bool, error) {
+	perms, apiErr := api.Client.ListPermissions()
+	if apiErr != nil {
+		if apiErr.Status == http.StatusNotFound {
+			return true, nil
+		}
+		return false, fmt.Errorf("error checking permission to execute commands: %v", apiErr.Msg)
+	}
+
+	return perms.HasPermission(shared.PermissionExecCommands), nil
+}
+
+// reportExecutedCommands records the commands in the org's audit log. Failing to report shouldn't interrupt the user.
+func reportExecutedCommands(content string, execErr error, attempt int) {
+	exitStatus := 0
+	if execErr != nil {
+		exitStatus = -1
+		if exitErr, ok := execErr.(*exec.ExitError); ok {
+			exitStatus = exitErr.ExitCode()
 		}
 	}
+
+	apiErr := api.Client.ReportExecutedCommands(CurrentPlanId, CurrentBranch, shared.ReportExecutedCommandsRequest{
+		Commands:   content,
+		ExitStatus: exitStatus,
+		Attempt:    attempt,
+	})
+	if apiErr != nil {
+		log.Printf("Error reporting executed commands: %v\n", apiErr.Msg)
+	}
+}
+
+func getExecPolicyViolationOutput(policy *ExecPolicy, denied []ExecPolicyMatch) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("The commands in _apply.sh were NOT executed because they violate the project's exec policy (%s).\n\nBlocked commands:\n", policy.Path))
+	for _, match := range denied {
+		b.WriteString("- " + match.Describe() + "\n")
+	}
+	b.WriteString("\nUpdate _apply.sh so that it doesn't run these commands. Find another way to accomplish the task or leave these steps for the user to run manually.")
+	return b.String()
 }
 
 var shellShebangs = map[string]string{
@@ -331,6 +487,7 @@ var applyScriptErrorHandling = map[string]string{
 
 func execApplyScript(
 	params ApplyPlanParams,
+	executor ApplyExecutor,
 	toApply map[string]string,
 	onErr types.OnErrFn,
 	toRollback *types.ApplyRollbackPlan,
@@ -341,6 +498,9 @@ func execApplyScript(
 	log.Println("Executing apply script")
 
 	color.New(term.ColorHiYellow, color.Bold).Println("👉 For long-running commands, use ctrl+c to exit")
+	if timeout := executor.Timeout(); timeout > 0 {
+		color.New(term.ColorHiCyan, color.Bold).Printf("📦 Running in sandbox with a %s time limit\n", timeout)
+	}
 	color.New(term.ColorHiCyan, color.Bold).Println("🚀 Executing... output below 👇")
 
 	fmt.Println()
@@ -396,9 +556,7 @@ func execApplyScript(
 		onErr("failed to write _apply.sh: %s", err)
 	}
 
-	execCmd := exec.Command(shell, "-c", scriptPath)
-	execCmd.Dir = fs.ProjectRoot
-	execCmd.Env = os.Environ()
+	execCmd := executor.Command(shell, scriptPath)
 	execCmd.Stdin = os.Stdin
 
 	// Create a pipe for both stdout and stderr
@@ -419,7 +577,7 @@ func execApplyScript(
 		onErr("failed to start command: %s", err)
 	}
 
-	maybeDeleteCgroup := MaybeIsolateCgroup(execCmd)
+	maybeDeleteCgroup := executor.Isolate(execCmd)
 
 	pgid, err := syscall.Getpgid(execCmd.Process.Pid)
 	if err != nil {
@@ -504,6 +662,25 @@ func execApplyScript(
 		}
 	}()
 
+	var timedOut atomic.Bool
+	if timeout := executor.Timeout(); timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			if interruptHandled.Load() {
+				return
+			}
+			timedOut.Store(true)
+			if err := KillProcessGroup(execCmd, syscall.SIGTERM); err != nil {
+				log.Printf("Failed to send SIGTERM to process group after timeout: %v", err)
+			}
+			time.AfterFunc(2*time.Second, func() {
+				if ctx.Err() == nil {
+					KillProcessGroup(execCmd, syscall.SIGKILL)
+				}
+			})
+		})
+		defer timer.Stop()
+	}
+
 	// Read and display output in real-time
 	scanner := bufio.NewScanner(pipe)
 	var outputBuilder strings.Builder
@@ -526,13 +703,30 @@ func execApplyScript(
 	signal.Stop(sigChan)
 	close(sigChan)
 
+	reportExecutedCommands(content, err, attempt)
+
 	success := err == nil
 
+	if timedOut.Load() {
+		fmt.Println()
+		msg := fmt.Sprintf("Commands exceeded the sandbox time limit of %s and were stopped", executor.Timeout())
+		color.New(term.ColorHiYellow, color.Bold).Println("⏱️  " + msg)
+		outputBuilder.WriteString("\n" + msg + "\n")
+		success = false
+	}
+
 	if interrupted.Load() {
 		os.Remove(scriptPath)
 
 		color.New(term.ColorHiYellow, color.Bold).Println("👉 Execution interrupted")
 
+		if term.IsJsonOutput() {
+			if toRollback != nil && toRollback.HasChanges() {
+				Rollback(toRollback, false)
+			}
+			onErr("execution was interrupted and changes were rolled back")
+		}
+
 		didSucceed, canceled, err := term.ConfirmYesNoCancel("Did the commands succeed?")
 
 		if err != nil {
@@ -610,7 +804,7 @@ func commitApplied(autoCommit bool, commitSummary string, updatedFiles []string,
 		// log.Println("Committing changes with message:")
 		// log.Println(msg)
 		// spew.Dump(currentPlanState)
-		err = GitAddAndCommitPaths(fs.ProjectRoot, msg, updatedFiles, true)
+		err = GitAddAndCommitRepoRoots(msg, updatedFiles)
 		if err != nil {
 			return fmt.Errorf("failed to commit changes: %s", err.Error())
 		}
@@ -619,11 +813,15 @@ func commitApplied(autoCommit bool, commitSummary string, updatedFiles []string,
 	return nil
 }
 
-func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPaths *types.ProjectPaths) ([]string, *types.ApplyRollbackPlan, error) {
+// ApplyFiles writes and removes plan files in three steps: it reads the current state of every file it will touch, records that in the apply journal, and only then makes changes—so an apply that's interrupted at any point can be rolled back with 'apply --recover'
+func ApplyFiles(planId, branch string, toApply map[string]string, toRemove map[string]bool, projectPaths *types.ProjectPaths) ([]string, *types.ApplyRollbackPlan, error) {
 	var updatedFiles []string
 	toRevert := map[string]types.ApplyReversion{}
 	var toRemoveOnRollback []string
 
+	toWrite := map[string]string{}
+	var toDelete []string
+
 	var mu sync.Mutex
 	totalOps := len(toApply) + len(toRemove)
 	errCh := make(chan error, totalOps)
@@ -635,7 +833,7 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 		}
 		go func(path, content string) {
 			// Compute destination path
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.ResolvePath(path)
 			content = strings.ReplaceAll(content, "\\`\\`\\`", "```")
 			// Check if the file exists
 			var exists bool
@@ -669,25 +867,15 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 					mu.Lock()
 					updatedFiles = append(updatedFiles, path)
 					toRevert[dstPath] = types.ApplyReversion{Content: string(bytes), Mode: mode}
+					toWrite[path] = content
 					mu.Unlock()
 				}
 			} else {
 				mu.Lock()
 				updatedFiles = append(updatedFiles, path)
 				toRemoveOnRollback = append(toRemoveOnRollback, dstPath)
+				toWrite[path] = content
 				mu.Unlock()
-				// Create the directory if it doesn't exist
-				err := os.MkdirAll(filepath.Dir(dstPath), 0755)
-				if err != nil {
-					errCh <- fmt.Errorf("failed to create directory %s: %s", filepath.Dir(dstPath), err.Error())
-					return
-				}
-			}
-			// Write the file
-			err = os.WriteFile(dstPath, []byte(content), 0644)
-			if err != nil {
-				errCh <- fmt.Errorf("failed to write %s: %s", dstPath, err.Error())
-				return
 			}
 			errCh <- nil
 		}(path, content)
@@ -700,7 +888,7 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 				return
 			}
 			// Compute destination path
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.ResolvePath(path)
 			// Check if the file exists
 			var exists bool
 			var mode os.FileMode
@@ -722,13 +910,9 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 					errCh <- fmt.Errorf("failed to read %s: %s", dstPath, err.Error())
 					return
 				}
-				err = os.Remove(dstPath)
-				if err != nil && !os.IsNotExist(err) {
-					errCh <- fmt.Errorf("failed to remove %s: %s", dstPath, err.Error())
-					return
-				}
 				mu.Lock()
 				toRevert[dstPath] = types.ApplyReversion{Content: string(content), Mode: mode}
+				toDelete = append(toDelete, path)
 				mu.Unlock()
 			}
 			errCh <- nil
@@ -742,11 +926,90 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 		}
 	}
 
-	return updatedFiles, &types.ApplyRollbackPlan{
+	rollbackPlan := &types.ApplyRollbackPlan{
 		PreviousProjectPaths: projectPaths,
 		ToRevert:             toRevert,
 		ToRemove:             toRemoveOnRollback,
-	}, nil
+	}
+
+	if len(toWrite) == 0 && len(toDelete) == 0 {
+		return updatedFiles, rollbackPlan, nil
+	}
+
+	previousPaths := make([]string, 0, len(projectPaths.AllPaths))
+	for path := range projectPaths.AllPaths {
+		previousPaths = append(previousPaths, path)
+	}
+
+	err := writeApplyJournal(&types.ApplyJournal{
+		PlanId:        planId,
+		Branch:        branch,
+		Status:        types.ApplyJournalStatusWriting,
+		StartedAt:     time.Now(),
+		ToWrite:       toWrite,
+		ToDelete:      toDelete,
+		UpdatedFiles:  updatedFiles,
+		ToRevert:      toRevert,
+		ToRemove:      toRemoveOnRollback,
+		PreviousPaths: previousPaths,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = writeApplyFiles(toWrite, toDelete)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedFiles, rollbackPlan, nil
+}
+
+// writeApplyFiles writes and removes files by plan path. Writes are idempotent, so it's also used to finish an interrupted apply.
+func writeApplyFiles(toWrite map[string]string, toDelete []string) error {
+	errCh := make(chan error, len(toWrite)+len(toDelete))
+
+	for path, content := range toWrite {
+		go func(path, content string) {
+			dstPath := fs.ResolvePath(path)
+
+			// Create the directory if it doesn't exist
+			err := os.MkdirAll(filepath.Dir(dstPath), 0755)
+			if err != nil {
+				errCh <- fmt.Errorf("failed to create directory %s: %s", filepath.Dir(dstPath), err.Error())
+				return
+			}
+
+			// Write the file
+			err = os.WriteFile(dstPath, []byte(content), 0644)
+			if err != nil {
+				errCh <- fmt.Errorf("failed to write %s: %s", dstPath, err.Error())
+				return
+			}
+			errCh <- nil
+		}(path, content)
+	}
+
+	for _, path := range toDelete {
+		go func(path string) {
+			dstPath := fs.ResolvePath(path)
+			err := os.Remove(dstPath)
+			if err != nil && !os.IsNotExist(err) {
+				errCh <- fmt.Errorf("failed to remove %s: %s", dstPath, err.Error())
+				return
+			}
+			errCh <- nil
+		}(path)
+	}
+
+	for i := 0; i < len(toWrite)+len(toDelete); i++ {
+		err := <-errCh
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
@@ -766,7 +1029,8 @@ func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
 	for _, path := range rollbackPlan.ToRemove {
 		go func(path string) {
 			err := os.Remove(path)
-			if err != nil {
+			// an interrupted apply may not have gotten to this file yet
+			if err != nil && !os.IsNotExist(err) {
 				errCh <- fmt.Errorf("failed to remove %s: %s", path, err.Error())
 				return
 			}
@@ -776,9 +1040,10 @@ func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
 
 	go func() {
 		var err error
-		updatedProjectPaths, err := fs.GetProjectPaths(fs.ProjectRoot)
+		updatedProjectPaths, err := fs.GetProjectPathsWithRepoRoots(fs.ProjectRoot)
 		if err != nil {
 			errCh <- fmt.Errorf("failed to get project paths: %v", err)
+			return
 		}
 		var toRemove []string
 		for path := range updatedProjectPaths.AllPaths {
@@ -813,6 +1078,12 @@ func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to rollback: %s", errs)
 	}
+
+	err := ClearApplyJournal()
+	if err != nil {
+		return err
+	}
+
 	if msg {
 		fmt.Println("🚫 Rolled back all changes")
 	}
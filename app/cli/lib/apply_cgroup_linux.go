@@ -18,16 +18,45 @@ import (
 const cgroupCallTimeout = 1 * time.Second
 
 func MaybeIsolateCgroup(cmd *exec.Cmd) (deleteFn func()) {
-	noop := func() {}
+	deleteFn, err := startCgroupScope(cmd, nil)
+	if err != nil {
+		// Fallback, no isolation
+		log.Printf("⚠️  %v. No cgroup isolation for PID %d.", err, cmd.Process.Pid)
+		return func() {}
+	}
+	return deleteFn
+}
+
+// IsolateCgroupWithLimits is like MaybeIsolateCgroup, but also caps memory and CPU for the scope
+// Unlike MaybeIsolateCgroup, it returns an error if the scope can't be created so the caller can decide whether to go ahead without limits
+func IsolateCgroupWithLimits(cmd *exec.Cmd, memoryMB, cpus int) (deleteFn func(), err error) {
+	props := []systemdDbus.Property{}
+
+	if memoryMB > 0 {
+		props = append(props,
+			systemdDbus.Property{Name: "MemoryMax", Value: dbus.MakeVariant(uint64(memoryMB) * 1024 * 1024)},
+			systemdDbus.Property{Name: "MemorySwapMax", Value: dbus.MakeVariant(uint64(0))},
+		)
+	}
+
+	if cpus > 0 {
+		// quota is CPU time per second of wall time, so 1s = 1 core
+		props = append(props, systemdDbus.Property{Name: "CPUQuotaPerSecUSec", Value: dbus.MakeVariant(uint64(cpus) * 1000000)})
+	}
+
+	return startCgroupScope(cmd, props)
+}
+
+func startCgroupScope(cmd *exec.Cmd, extraProps []systemdDbus.Property) (deleteFn func(), err error) {
 	pid := cmd.Process.Pid
 
 	// 1. Connect to the user manager (no prompt on typical distros).
-	ctx, _ := context.WithTimeout(context.Background(), cgroupCallTimeout)
+	ctx, connCancel := context.WithTimeout(context.Background(), cgroupCallTimeout)
 
 	conn, err := systemdDbus.NewUserConnectionContext(ctx)
 	if err != nil {
-		log.Printf("⚠️  Could not connect to user systemd manager. No cgroup isolation for PID %d. Error: %v", pid, err)
-		return noop
+		connCancel()
+		return nil, fmt.Errorf("could not connect to user systemd manager: %v", err)
 	}
 	// We'll keep 'conn' open while scope is active. The scope isn't strictly tied
 	// to the connection's lifetime, but it's nice to keep it in case we want to stop the unit.
@@ -49,16 +78,18 @@ func MaybeIsolateCgroup(cmd *exec.Cmd) (deleteFn func()) {
 		// Optional: auto-remove the scope once no processes remain.
 		systemdDbus.Property{Name: "CollectMode", Value: dbus.MakeVariant("inactive-or-failed")},
 	}
+	props = append(props, extraProps...)
 
 	_, err = conn.StartTransientUnitContext(ctx, scopeName, "replace", props, nil)
 	if err != nil {
-		// Fallback, no isolation
-		log.Printf("⚠️  Failed to start transient scope for PID %d: %v", pid, err)
-		return noop
+		conn.Close()
+		connCancel()
+		return nil, fmt.Errorf("failed to start transient scope for PID %d: %v", pid, err)
 	}
 
 	return func() {
 		// Close the connection to the user manager.
+		defer connCancel()
 		defer conn.Close()
 
 		ctx, cancel := context.WithTimeout(context.Background(), cgroupCallTimeout)
@@ -69,5 +100,5 @@ func MaybeIsolateCgroup(cmd *exec.Cmd) (deleteFn func()) {
 		if stopErr != nil {
 			log.Printf("⚠️  Failed to stop scope %s: %v", scopeName, stopErr)
 		}
-	}
+	}, nil
 }
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"plandex-cli/fs"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// ApplyExecutor runs the _apply.sh script. The host executor runs it directly; the sandbox executor wraps it in bubblewrap with resource limits.
+type ApplyExecutor interface {
+	// Command returns the command to run the script—it isn't started yet
+	Command(shell, scriptPath string) *exec.Cmd
+	// Isolate is called right after the command starts and returns a cleanup function
+	Isolate(cmd *exec.Cmd) (deleteFn func())
+	// Timeout is the maximum time commands can run for, or 0 for no limit
+	Timeout() time.Duration
+}
+
+func GetApplyExecutor(config *shared.PlanConfig) (ApplyExecutor, error) {
+	if config != nil && config.IsExecSandboxed() {
+		return newSandboxExecutor(config)
+	}
+	return hostExecutor{}, nil
+}
+
+// CheckExecSandboxAvailable returns an error explaining why sandboxed execution won't work on this machine, if it won't
+func CheckExecSandboxAvailable() error {
+	_, err := newSandboxExecutor(&shared.PlanConfig{ExecSandbox: shared.ExecSandboxBubblewrap})
+	return err
+}
+
+type hostExecutor struct{}
+
+func (hostExecutor) Command(shell, scriptPath string) *exec.Cmd {
+	cmd := exec.Command(shell, "-c", scriptPath)
+	cmd.Dir = fs.ProjectRoot
+	cmd.Env = append(os.Environ(), fs.RepoRootEnv()...)
+	return cmd
+}
+
+func (hostExecutor) Isolate(cmd *exec.Cmd) func() {
+	return MaybeIsolateCgroup(cmd)
+}
+
+func (hostExecutor) Timeout() time.Duration {
+	return 0
+}
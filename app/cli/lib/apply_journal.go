@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/format"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+const (
+	recoverOptionRollback = "Roll back file changes"
+	recoverOptionComplete = "Finish applying file changes"
+	recoverOptionCancel   = "Cancel"
+)
+
+func applyJournalPath() string {
+	return filepath.Join(fs.PlandexDir, "apply-journal.json")
+}
+
+// writeApplyJournal writes to a temp file and renames it into place so that a crash can't leave a partial journal behind
+func writeApplyJournal(journal *types.ApplyJournal) error {
+	if fs.PlandexDir == "" {
+		return fmt.Errorf("no plandex dir for apply journal")
+	}
+
+	bytes, err := json.Marshal(journal)
+	if err != nil {
+		return fmt.Errorf("error marshalling apply journal: %v", err)
+	}
+
+	path := applyJournalPath()
+	tmpPath := path + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating apply journal: %v", err)
+	}
+
+	_, err = f.Write(bytes)
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing apply journal: %v", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing apply journal: %v", err)
+	}
+
+	return nil
+}
+
+// GetApplyJournal returns the journal of an interrupted apply, or nil if there isn't one
+func GetApplyJournal() (*types.ApplyJournal, error) {
+	if fs.PlandexDir == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(applyJournalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading apply journal: %v", err)
+	}
+
+	var journal types.ApplyJournal
+	err = json.Unmarshal(bytes, &journal)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing apply journal %s: %v", applyJournalPath(), err)
+	}
+
+	return &journal, nil
+}
+
+func ClearApplyJournal() error {
+	if fs.PlandexDir == "" {
+		return nil
+	}
+
+	err := os.Remove(applyJournalPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing apply journal: %v", err)
+	}
+	return nil
+}
+
+func setApplyJournalStatus(status types.ApplyJournalStatus) error {
+	journal, err := GetApplyJournal()
+	if err != nil || journal == nil {
+		return err
+	}
+
+	journal.Status = status
+	return writeApplyJournal(journal)
+}
+
+// WarnIfInterruptedApply is called on startup so an interrupted apply isn't forgotten about
+func WarnIfInterruptedApply() {
+	if term.IsJsonOutput() {
+		return
+	}
+
+	journal, err := GetApplyJournal()
+	if err != nil {
+		log.Printf("Error checking for interrupted apply: %v", err)
+		return
+	}
+	if journal == nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  An apply was interrupted %s. Run %s to roll it back or finish it.\n\n", format.Time(journal.StartedAt), color.New(color.Bold, term.ColorHiCyan).Sprint("plandex apply --recover"))
+}
+
+func MustCheckNoInterruptedApply() {
+	journal, err := GetApplyJournal()
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+	if journal != nil {
+		term.OutputErrorAndExit("An earlier apply was interrupted %s—run 'plandex apply --recover' to roll it back or finish it before applying again", format.Time(journal.StartedAt))
+	}
+}
+
+// MustRecoverApply offers to roll back or finish an interrupted apply
+func MustRecoverApply() {
+	journal, err := GetApplyJournal()
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if journal == nil {
+		fmt.Println("🤷‍♂️ No interrupted apply to recover")
+		return
+	}
+
+	if term.IsJsonOutput() {
+		term.OutputErrorAndExit("apply --recover can't be used with --json/--ndjson")
+	}
+
+	var paths []string
+	for path := range journal.ToWrite {
+		paths = append(paths, path)
+	}
+	paths = append(paths, journal.ToDelete...)
+	sort.Strings(paths)
+
+	during := "while writing files"
+	if journal.Status == types.ApplyJournalStatusExecuting {
+		during = "while running commands"
+	}
+
+	color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  An apply to branch %s was interrupted %s, %s\n", journal.Branch, format.Time(journal.StartedAt), during)
+	fmt.Println()
+	fmt.Println("Files in the apply:")
+	for _, path := range paths {
+		fmt.Println(" • 📄 " + path)
+	}
+	fmt.Println()
+
+	if journal.Status == types.ApplyJournalStatusExecuting {
+		fmt.Println("Rolling back restores these files, but can't undo other effects of the commands. Finishing the apply keeps the file changes without running the commands again.")
+		fmt.Println()
+	}
+
+	selected, err := term.SelectFromList("What do you want to do?", []string{recoverOptionRollback, recoverOptionComplete, recoverOptionCancel})
+	if err != nil {
+		term.OutputErrorAndExit("failed to get user input: %s", err)
+	}
+
+	switch selected {
+	case recoverOptionRollback:
+		err = Rollback(journal.RollbackPlan(), true)
+		if err != nil {
+			term.OutputErrorAndExit("%v", err)
+		}
+
+	case recoverOptionComplete:
+		mustCompleteApply(journal)
+
+	case recoverOptionCancel:
+		return
+	}
+}
+
+func mustCompleteApply(journal *types.ApplyJournal) {
+	term.StartSpinner("")
+
+	err := writeApplyFiles(journal.ToWrite, journal.ToDelete)
+	if err != nil {
+		term.OutputErrorAndExit("failed to apply files: %v", err)
+	}
+
+	// only mark the plan applied if its pending changes are still what was written—they could have changed since the apply was interrupted
+	currentPlanState, apiErr := api.Client.GetCurrentPlanState(journal.PlanId, journal.Branch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr)
+	}
+
+	planMatches := true
+	for path, content := range currentPlanState.CurrentPlanFiles.Files {
+		if path == "_apply.sh" {
+			continue
+		}
+		bytes, err := os.ReadFile(fs.ResolvePath(path))
+		if err != nil || string(bytes) != strings.ReplaceAll(content, "\\`\\`\\`", "```") {
+			planMatches = false
+			break
+		}
+	}
+	for path, removed := range currentPlanState.CurrentPlanFiles.Removed {
+		if !removed {
+			continue
+		}
+		if _, err := os.Stat(fs.ResolvePath(path)); err == nil {
+			planMatches = false
+			break
+		}
+	}
+
+	if planMatches {
+		_, err = apiApplyPlan(journal.PlanId, journal.Branch)
+		if err != nil {
+			term.OutputErrorAndExit("apply plan server error: %s", err)
+		}
+	}
+
+	err = ClearApplyJournal()
+	term.StopSpinner()
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	suffix := ""
+	if len(journal.UpdatedFiles) != 1 {
+		suffix = "s"
+	}
+	fmt.Printf("✅ Finished applying changes, %d file%s updated\n", len(journal.UpdatedFiles), suffix)
+	for _, file := range journal.UpdatedFiles {
+		fmt.Println(" • 📄 " + file)
+	}
+
+	if !planMatches {
+		fmt.Println()
+		fmt.Println("The plan's pending changes were updated after the apply was interrupted, so they're still pending")
+		term.PrintCmds("", "diff", "apply")
+	}
+}
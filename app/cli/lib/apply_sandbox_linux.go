@@ -0,0 +1,167 @@
+//go:build linux
+// +build linux
+
+package lib
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+type sandboxExecutor struct {
+	bwrapPath string
+	config    *shared.PlanConfig
+}
+
+func newSandboxExecutor(config *shared.PlanConfig) (ApplyExecutor, error) {
+	bwrapPath, err := exec.LookPath("bwrap")
+	if err != nil {
+		return nil, fmt.Errorf("exec-sandbox is enabled, but bubblewrap ('bwrap') isn't installed—install it with your package manager or run 'plandex set-config exec-sandbox none' to execute commands on the host")
+	}
+
+	return &sandboxExecutor{
+		bwrapPath: bwrapPath,
+		config:    config,
+	}, nil
+}
+
+// system dirs needed to run shells and installed toolchains—mounted read-only, skipped if missing
+var sandboxSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/nix"}
+
+// env vars passed through to the sandbox—anything else (API keys, tokens, cloud credentials) stays on the host
+var sandboxEnvAllowList = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LANGUAGE", "TZ",
+	"GOROOT", "GOPATH", "GOMODCACHE", "GOFLAGS", "GOTOOLCHAIN",
+	"CARGO_HOME", "RUSTUP_HOME", "RUSTUP_TOOLCHAIN",
+	"NVM_DIR", "NVM_BIN", "NODE_PATH", "VOLTA_HOME", "PNPM_HOME", "BUN_INSTALL", "DENO_DIR",
+	"PYENV_ROOT", "VIRTUAL_ENV", "CONDA_PREFIX", "PIPX_HOME",
+	"JAVA_HOME", "GRADLE_HOME", "MAVEN_HOME", "SDKMAN_DIR",
+	"RBENV_ROOT", "GEM_HOME", "GEM_PATH", "ASDF_DIR", "ASDF_DATA_DIR", "MISE_DATA_DIR",
+}
+
+// env vars whose values are toolchain install dirs outside the system dirs, e.g. ~/.cargo or ~/go
+var sandboxToolchainDirEnv = []string{
+	"GOROOT", "GOPATH", "GOMODCACHE",
+	"CARGO_HOME", "RUSTUP_HOME",
+	"NVM_DIR", "VOLTA_HOME", "PNPM_HOME", "BUN_INSTALL",
+	"PYENV_ROOT", "VIRTUAL_ENV", "CONDA_PREFIX",
+	"JAVA_HOME", "SDKMAN_DIR", "RBENV_ROOT", "GEM_HOME", "ASDF_DIR", "ASDF_DATA_DIR", "MISE_DATA_DIR",
+}
+
+func (e *sandboxExecutor) Command(shell, scriptPath string) *exec.Cmd {
+	args := []string{}
+
+	for _, dir := range sandboxSystemDirs {
+		args = append(args, "--ro-bind-try", dir, dir)
+	}
+
+	args = append(args,
+		// private /dev, /proc, and /tmp
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-all",
+		"--die-with-parent",
+		"--setenv", "TMPDIR", "/tmp",
+	)
+
+	// an empty, writable home so tools can write caches and config without seeing the real one
+	home, err := os.UserHomeDir()
+	if err == nil && home != "/" {
+		args = append(args, "--tmpfs", home)
+	}
+
+	for _, dir := range sandboxToolchainDirs() {
+		args = append(args, "--ro-bind-try", dir, dir)
+	}
+
+	// the project's repo roots are the only writable host paths—bound last in case they're inside home or /tmp
+	for _, dir := range fs.RepoRootDirs() {
+		args = append(args, "--bind", dir, dir)
+	}
+	args = append(args, "--chdir", fs.ProjectRoot)
+
+	if e.config.ExecSandboxNetwork {
+		args = append(args, "--share-net")
+	}
+
+	args = append(args, "--", shell, "-c", scriptPath)
+
+	cmd := exec.Command(e.bwrapPath, args...)
+	cmd.Dir = fs.ProjectRoot
+	cmd.Env = append(sandboxEnv(), fs.RepoRootEnv()...)
+	return cmd
+}
+
+func sandboxEnv() []string {
+	var env []string
+	for _, key := range sandboxEnvAllowList {
+		if val, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+val)
+		}
+	}
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "LC_") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// sandboxToolchainDirs returns toolchain dirs that aren't under the system dirs: PATH entries (e.g. ~/.local/bin, ~/.cargo/bin) and well-known toolchain env vars
+func sandboxToolchainDirs() []string {
+	candidates := filepath.SplitList(os.Getenv("PATH"))
+	for _, key := range sandboxToolchainDirEnv {
+		candidates = append(candidates, os.Getenv(key))
+	}
+
+	seen := map[string]bool{}
+	var dirs []string
+	for _, dir := range candidates {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		if seen[dir] || dir == "/" || isUnderSandboxSystemDir(dir) {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+func isUnderSandboxSystemDir(dir string) bool {
+	for _, sysDir := range sandboxSystemDirs {
+		if dir == sysDir || strings.HasPrefix(dir, sysDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *sandboxExecutor) Isolate(cmd *exec.Cmd) func() {
+	deleteFn, err := IsolateCgroupWithLimits(cmd, e.config.GetExecSandboxMemoryMB(), e.config.GetExecSandboxCPUs())
+	if err != nil {
+		log.Printf("Failed to apply sandbox resource limits: %v", err)
+		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Couldn't apply CPU and memory limits (requires a systemd user session). Commands are still sandboxed and time-limited.")
+		fmt.Println()
+		return func() {}
+	}
+	return deleteFn
+}
+
+func (e *sandboxExecutor) Timeout() time.Duration {
+	return time.Duration(e.config.GetExecSandboxTimeoutSecs()) * time.Second
+}
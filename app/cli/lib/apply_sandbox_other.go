@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package lib
+
+import (
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func newSandboxExecutor(config *shared.PlanConfig) (ApplyExecutor, error) {
+	return nil, fmt.Errorf("exec-sandbox is enabled, but sandboxed execution is only supported on Linux—run 'plandex set-config exec-sandbox none' to execute commands on the host")
+}
@@ -2,9 +2,6 @@ package lib
 
 import (
 	"bytes"
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -170,13 +167,13 @@ func DisconnectClaudeMax() {
 }
 
 func connectClaudeMaxOauth() {
-	verifier, err := genCodeVerifier()
+	verifier, err := shared.GenPkceVerifier()
 	if err != nil {
 		term.OutputErrorAndExit("Error generating code verifier: %v", err)
 	}
-	challenge := sha256Base64(verifier)
+	challenge := shared.PkceChallenge(verifier)
 
-	state, err := genCodeVerifier()
+	state, err := shared.GenPkceVerifier()
 	if err != nil {
 		term.OutputErrorAndExit("Error generating state: %v", err)
 	}
@@ -264,19 +261,6 @@ func exchangeCode(code, verifier, state string) (*types.OauthResponse, error) {
 	return &t, nil
 }
 
-func genCodeVerifier() (string, error) {
-	buf := make([]byte, 32)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(buf), nil
-}
-
-func sha256Base64(verifier string) string {
-	sum := sha256.Sum256([]byte(verifier))
-	return base64.RawURLEncoding.EncodeToString(sum[:])
-}
-
 func needsRefresh(creds *types.OauthCreds) bool {
 	// refresh an hour early so we can make multiple calls before it expires
 	return time.Now().After(creds.ExpiresAt.Add(-1 * time.Hour))
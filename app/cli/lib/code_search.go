@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+const codeSearchConcurrency = 16
+
+// RespondCodeSearch runs a code search requested by the server during a plan stream and sends back the results
+func RespondCodeSearch(req *shared.CodeSearchRequest) error {
+	res := RunCodeSearch(req)
+
+	log.Printf("code search %q (%s): %d matches in %d files searched\n", req.Query, req.Kind, len(res.Matches), res.NumFilesSearched)
+
+	apiErr := api.Client.RespondCodeSearch(CurrentPlanId, CurrentBranch, *res)
+	if apiErr != nil {
+		return fmt.Errorf("error sending code search results: %s", apiErr.Msg)
+	}
+	return nil
+}
+
+// RunCodeSearch searches the project's files for the planner. Paths in the request and the results are plan paths. Errors are returned in the response so the model can see them.
+func RunCodeSearch(req *shared.CodeSearchRequest) *shared.RespondCodeSearchRequest {
+	res := &shared.RespondCodeSearchRequest{Id: req.Id}
+
+	re, err := codeSearchRegexp(req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	projectPaths, err := fs.GetProjectPathsWithRepoRoots(fs.ProjectRoot)
+	if err != nil {
+		res.Error = fmt.Sprintf("error getting project paths: %v", err)
+		return res
+	}
+
+	var filters []string
+	for _, path := range req.Paths {
+		path = strings.TrimSuffix(filepath.Clean(fs.ToLocalPath(path)), string(filepath.Separator))
+		if path != "." && path != "" {
+			filters = append(filters, path)
+		}
+	}
+
+	var paths []string
+	for path := range projectPaths.ActivePaths {
+		if len(filters) > 0 && !matchesCodeSearchFilters(path, filters) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	matchesByPath := make([][]shared.CodeSearchMatch, len(paths))
+	searched := make([]bool, len(paths))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, codeSearchConcurrency)
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			matches, ok := searchFile(path, re)
+			matchesByPath[i] = matches
+			searched[i] = ok
+		}(i, path)
+	}
+	wg.Wait()
+
+	maxResults := req.GetMaxResults()
+	for i, path := range paths {
+		if searched[i] {
+			res.NumFilesSearched++
+		}
+
+		matches := matchesByPath[i]
+		if len(matches) == 0 {
+			continue
+		}
+
+		if len(res.Matches) >= maxResults {
+			res.Truncated = true
+			continue
+		}
+
+		remaining := maxResults - len(res.Matches)
+		if len(matches) > remaining {
+			matches = matches[:remaining]
+			res.Truncated = true
+		}
+		res.Matches = append(res.Matches, matches...)
+
+		if req.Kind == shared.CodeSearchKindSymbol && len(res.Files) < shared.CodeSearchMaxSymbolFiles {
+			body, err := os.ReadFile(filepath.Join(fs.ProjectRoot, path))
+			if err == nil && len(body) <= shared.CodeSearchMaxSymbolFileSize {
+				res.Files = append(res.Files, shared.CodeSearchFile{
+					Path: fs.ToPlanPath(path),
+					Body: string(shared.NormalizeEOL(body)),
+				})
+			}
+		}
+	}
+
+	return res
+}
+
+func codeSearchRegexp(req *shared.CodeSearchRequest) (*regexp.Regexp, error) {
+	var pattern string
+	switch req.Kind {
+	case shared.CodeSearchKindSymbol:
+		// whole identifiers only, so a search for 'User' doesn't match 'UserId'
+		pattern = `(^|[^\w$])` + regexp.QuoteMeta(req.Query) + `($|[^\w$])`
+	case shared.CodeSearchKindText:
+		if req.IsRegex {
+			pattern = req.Query
+		} else {
+			pattern = regexp.QuoteMeta(req.Query)
+		}
+	default:
+		return nil, fmt.Errorf("unknown search kind: %s", req.Kind)
+	}
+
+	if !req.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %v", err)
+	}
+	return re, nil
+}
+
+func matchesCodeSearchFilters(path string, filters []string) bool {
+	for _, filter := range filters {
+		if path == filter || strings.HasPrefix(path, filter+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchFile returns the matching lines in a file. Binary files, images, and files over the size limit are skipped, in which case ok is false.
+func searchFile(path string, re *regexp.Regexp) (matches []shared.CodeSearchMatch, ok bool) {
+	if shared.IsImageFile(path) {
+		return nil, false
+	}
+
+	absPath := filepath.Join(fs.ProjectRoot, path)
+	info, err := os.Stat(absPath)
+	if err != nil || info.IsDir() || info.Size() > shared.CodeSearchMaxFileSize {
+		return nil, false
+	}
+
+	body, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, false
+	}
+
+	// same check git uses to decide whether a file is binary
+	if bytes.IndexByte(body[:min(len(body), 8000)], 0) != -1 {
+		return nil, false
+	}
+
+	planPath := fs.ToPlanPath(path)
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), shared.CodeSearchMaxFileSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if re.MatchString(line) {
+			matches = append(matches, shared.CodeSearchMatch{
+				Path: planPath,
+				Line: lineNum,
+				Text: strings.TrimSuffix(line, "\r"),
+			})
+		}
+	}
+
+	return matches, true
+}
@@ -27,6 +27,9 @@ func GetContextLabelAndIcon(contextType shared.ContextType) (string, string) {
 	case shared.ContextMapType:
 		icon = "🗺️ "
 		lbl = "map"
+	case shared.ContextGitDiffType:
+		icon = "🔀"
+		lbl = "diff"
 	}
 
 	return lbl, icon
@@ -0,0 +1,128 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+type gitDiffResult struct {
+	diff string
+
+	// changed paths that still exist, relative to the project root
+	paths []string
+}
+
+// getGitDiff runs git in the project root for a git diff source. Only changes inside the project root are included.
+func getGitDiff(source *shared.GitDiffSource) (*gitDiffResult, error) {
+	if !fs.ProjectRootIsGitRepo() {
+		return nil, fmt.Errorf("%s isn't in a git repository", fs.ProjectRoot)
+	}
+
+	revArgs, err := gitDiffRevArgs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	diffArgs := append([]string{"diff", "--no-color", "--no-ext-diff", "--relative"}, revArgs...)
+	diff, err := runGitInProjectRoot(diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// deleted files are in the diff, but there's nothing to load for them
+	namesArgs := append([]string{"diff", "--name-only", "--diff-filter=d", "--relative", "-z"}, revArgs...)
+	names, err := runGitInProjectRoot(namesArgs...)
+	if err != nil {
+		return nil, err
+	}
+	paths := splitNullTerminated(names)
+
+	// new files that haven't been added yet are part of the work in progress too
+	if source.Base != "" {
+		untracked, err := runGitInProjectRoot("ls-files", "--others", "--exclude-standard", "-z")
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, splitNullTerminated(untracked)...)
+	}
+
+	// a file can still be removed from the working tree after it was committed or staged
+	var existingPaths []string
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(fs.ProjectRoot, path)); err == nil {
+			existingPaths = append(existingPaths, path)
+		}
+	}
+
+	return &gitDiffResult{
+		diff:  string(shared.NormalizeEOL([]byte(diff))),
+		paths: existingPaths,
+	}, nil
+}
+
+func gitDiffRevArgs(source *shared.GitDiffSource) ([]string, error) {
+	switch {
+	case source.Staged:
+		return []string{"--cached"}, nil
+
+	case source.Since != "":
+		_, err := runGitInProjectRoot("rev-parse", "--verify", "--quiet", source.Since+"^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("%s isn't a commit in this repository", source.Since)
+		}
+		return []string{source.Since, "HEAD"}, nil
+
+	default:
+		base := source.Base
+		if base == "" {
+			base = "HEAD"
+		}
+
+		_, err := runGitInProjectRoot("rev-parse", "--verify", "--quiet", "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("the repository doesn't have any commits yet")
+		}
+
+		if base == "HEAD" {
+			return []string{"HEAD"}, nil
+		}
+
+		// compare with where the branch diverged, like a pull request does, so that later commits on the base branch aren't included
+		mergeBase, err := runGitInProjectRoot("merge-base", base, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find a common ancestor of %s and HEAD: %v", base, err)
+		}
+		return []string{strings.TrimSpace(mergeBase)}, nil
+	}
+}
+
+func runGitInProjectRoot(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = fs.ProjectRoot
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	res, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running git %s: %v, output: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(res), nil
+}
+
+func splitNullTerminated(s string) []string {
+	var res []string
+	for _, part := range strings.Split(s, "\x00") {
+		if part != "" {
+			res = append(res, part)
+		}
+	}
+	return res
+}
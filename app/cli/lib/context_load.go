@@ -88,6 +88,23 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	var gitDiffRes *gitDiffResult
+	if params.GitDiff != nil {
+		gitDiffRes, err = getGitDiff(params.GitDiff)
+		if err != nil {
+			onErr(err)
+		}
+
+		if gitDiffRes.diff == "" && len(gitDiffRes.paths) == 0 {
+			term.StopSpinner()
+			fmt.Printf("🤷‍♂️ No %s to load\n", params.GitDiff.Label())
+			os.Exit(0)
+		}
+
+		// the changed files are loaded along with the diff itself
+		resources = append(resources, gitDiffRes.paths...)
+	}
+
 	var inputUrls []string
 	var inputFilePaths []string
 
@@ -101,6 +118,9 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					resource = resource[2:]
 				}
 
+				// '@<alias>/...' paths in other repo roots are loaded by their local path
+				resource = fs.ToLocalPath(resource)
+
 				inputFilePaths = append(inputFilePaths, resource)
 			}
 		}
@@ -133,9 +153,32 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	for _, context := range existingContexts {
 		switch context.ContextType {
 		case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType:
-			existsByComposite[strings.Join([]string{string(context.ContextType), context.FilePath}, "|")] = context
+			existsByComposite[strings.Join([]string{string(context.ContextType), fs.ToLocalPath(context.FilePath)}, "|")] = context
 		case shared.ContextURLType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.Url}, "|")] = context
+		case shared.ContextGitDiffType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Name}, "|")] = context
+		}
+	}
+
+	if gitDiffRes != nil && gitDiffRes.diff != "" {
+		name := params.GitDiff.Label()
+		composite := strings.Join([]string{string(shared.ContextGitDiffType), name}, "|")
+		size := int64(len(gitDiffRes.diff))
+
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+		} else if size > shared.MaxContextBodySize {
+			filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: name, Size: size})
+		} else {
+			totalSize += size
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType: shared.ContextGitDiffType,
+				Name:        name,
+				Body:        gitDiffRes.diff,
+				GitDiff:     params.GitDiff,
+				AutoLoaded:  params.AutoLoaded,
+			})
 		}
 	}
 
@@ -171,8 +214,13 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 			var uncachedMapPaths []string
 
+			var cachedFilePaths []string
+			for _, path := range toLoadMapPaths {
+				cachedFilePaths = append(cachedFilePaths, fs.ToPlanPath(path))
+			}
+
 			res, err := api.Client.LoadCachedFileMap(CurrentPlanId, CurrentBranch, shared.LoadCachedFileMapRequest{
-				FilePaths: toLoadMapPaths,
+				FilePaths: cachedFilePaths,
 			})
 
 			if err != nil {
@@ -191,7 +239,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 				cachedMapPaths = res.CachedByPath
 
 				for _, path := range toLoadMapPaths {
-					if !cachedMapPaths[path] {
+					if !cachedMapPaths[fs.ToPlanPath(path)] {
 						uncachedMapPaths = append(uncachedMapPaths, path)
 					}
 				}
@@ -662,6 +710,8 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	contextParamsToPlanPaths(loadContextReq)
+
 	filesToLoad := map[string]string{}
 	for _, context := range loadContextReq {
 		if context.ContextType == shared.ContextFileType {
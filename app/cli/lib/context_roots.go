@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"plandex-cli/fs"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// Context is loaded and updated with local paths, since that's what the file system needs, but stored with plan paths so that files in other repo roots keep their '@<alias>/' prefix. These helpers convert at the boundary.
+
+func contextParamsToPlanPaths(req shared.LoadContextRequest) {
+	if len(fs.GetRepoRoots()) == 0 {
+		return
+	}
+
+	for _, params := range req {
+		if params.FilePath == "" {
+			continue
+		}
+
+		planPath := fs.ToPlanPath(params.FilePath)
+		if params.Name == params.FilePath {
+			params.Name = planPath
+		}
+		params.FilePath = planPath
+
+		switch params.ContextType {
+		case shared.ContextDirectoryTreeType:
+			params.Body = treeBodyToPlanPaths(params.Body)
+		case shared.ContextMapType:
+			params.MapBodies = keysToPlanPaths(params.MapBodies)
+			params.InputShas = keysToPlanPaths(params.InputShas)
+			params.InputTokens = keysToPlanPaths(params.InputTokens)
+			params.InputSizes = keysToPlanPaths(params.InputSizes)
+		}
+	}
+}
+
+func treeBodyToPlanPaths(body string) string {
+	if len(fs.GetRepoRoots()) == 0 || body == "" {
+		return body
+	}
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = fs.ToPlanPath(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// localMapContext returns a copy of a map context with its per-file maps keyed by local path
+func localMapContext(ctx *shared.Context) *shared.Context {
+	if len(fs.GetRepoRoots()) == 0 {
+		return ctx
+	}
+
+	res := *ctx
+	res.FilePath = fs.ToLocalPath(ctx.FilePath)
+	res.MapShas = keysToLocalPaths(ctx.MapShas)
+	res.MapTokens = keysToLocalPaths(ctx.MapTokens)
+	res.MapSizes = keysToLocalPaths(ctx.MapSizes)
+	return &res
+}
+
+func mapUpdateToPlanPaths(params *shared.UpdateContextParams) {
+	if len(fs.GetRepoRoots()) == 0 {
+		return
+	}
+
+	params.MapBodies = keysToPlanPaths(params.MapBodies)
+	params.InputShas = keysToPlanPaths(params.InputShas)
+	params.InputTokens = keysToPlanPaths(params.InputTokens)
+	params.InputSizes = keysToPlanPaths(params.InputSizes)
+	for i, path := range params.RemovedMapPaths {
+		params.RemovedMapPaths[i] = fs.ToPlanPath(path)
+	}
+}
+
+func keysToPlanPaths[M ~map[string]V, V any](m M) M {
+	if m == nil {
+		return nil
+	}
+	res := make(M, len(m))
+	for path, v := range m {
+		res[fs.ToPlanPath(path)] = v
+	}
+	return res
+}
+
+func keysToLocalPaths[M ~map[string]V, V any](m M) M {
+	if m == nil {
+		return nil
+	}
+	res := make(M, len(m))
+	for path, v := range m {
+		res[fs.ToLocalPath(path)] = v
+	}
+	return res
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/auth"
 	"strings"
 	"sync"
 
@@ -138,7 +140,20 @@ func getMapFileContent(path string) (mapFileContent, error) {
 func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBodies, error) {
 	allMapBodies := shared.FileMapBodies{}
 
+	// if the plan's model pack has an embeddings role, the server also adds the inputs to the project's semantic index, which needs model credentials
+	var authVars map[string]string
+	if CurrentPlanId != "" {
+		settings, apiErr := api.Client.GetSettings(CurrentPlanId, CurrentBranch)
+		if apiErr != nil {
+			return nil, fmt.Errorf("failed to get plan settings: %v", apiErr.Msg)
+		}
+		if settings.SemanticRetrievalEnabled() {
+			authVars = MustVerifyAuthVarsSilent(auth.Current.IntegratedModelsMode)
+		}
+	}
+
 	var mapMu sync.Mutex
+	var semanticIndexErr string
 	errCh := make(chan error, len(mapInputBatches))
 
 	for _, batch := range mapInputBatches {
@@ -148,17 +163,34 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 		}
 
 		go func(batch shared.FileMapInputs) {
-			mapRes, apiErr := api.Client.GetFileMap(shared.GetFileMapRequest{
+			req := shared.GetFileMapRequest{
 				MapInputs: batch,
-			})
+			}
+			if authVars != nil {
+				req.MapInputs = keysToPlanPaths(batch)
+				req.PlanId = CurrentPlanId
+				req.Branch = CurrentBranch
+				req.AuthVars = authVars
+			}
+
+			mapRes, apiErr := api.Client.GetFileMap(req)
 			if apiErr != nil {
 				errCh <- fmt.Errorf("failed to get file map: %v", apiErr)
 				return
 			}
+
+			mapBodies := mapRes.MapBodies
+			if authVars != nil {
+				mapBodies = keysToLocalPaths(mapBodies)
+			}
+
 			mapMu.Lock()
-			for path, bodies := range mapRes.MapBodies {
+			for path, bodies := range mapBodies {
 				allMapBodies[path] = bodies
 			}
+			if mapRes.SemanticIndexError != "" && semanticIndexErr == "" {
+				semanticIndexErr = mapRes.SemanticIndexError
+			}
 			mapMu.Unlock()
 			errCh <- nil
 		}(batch)
@@ -171,6 +203,12 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 		}
 	}
 
+	// maps are still usable without the semantic index, so this is only a warning
+	if semanticIndexErr != "" {
+		log.Printf("semantic index error: %s", semanticIndexErr)
+		fmt.Fprintf(os.Stderr, "⚠️  Couldn't update the semantic index: %s\nAuto context will fall back to the project map for files that weren't indexed.\n", semanticIndexErr)
+	}
+
 	return allMapBodies, nil
 }
 
@@ -89,6 +89,14 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumMaps) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumDiffs > 0 {
+			lbl := "git diff"
+			if outdatedRes.NumDiffs > 1 {
+				lbl = "git diffs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumDiffs) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -330,6 +338,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 	var numUrls int
 	var numTrees int
 	var numMaps int
+	var numDiffs int
 	var numFilesRemoved int
 	var numTreesRemoved int
 	var mu sync.Mutex
@@ -371,7 +380,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+				localPath := fs.ToLocalPath(ctx.FilePath)
+
+				if _, err := os.Stat(localPath); os.IsNotExist(err) {
 					mu.Lock()
 					defer mu.Unlock()
 
@@ -381,7 +392,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					return
 				}
 
-				fileContent, err := os.ReadFile(ctx.FilePath)
+				fileContent, err := os.ReadFile(localPath)
 				if err != nil {
 					mu.Lock()
 					defer mu.Unlock()
@@ -390,7 +401,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				}
 				fileContent = shared.NormalizeEOL(fileContent)
 
-				fileInfo, err := os.Stat(ctx.FilePath)
+				fileInfo, err := os.Stat(localPath)
 				if err != nil {
 					mu.Lock()
 					defer mu.Unlock()
@@ -481,7 +492,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+				localPath := fs.ToLocalPath(ctx.FilePath)
+
+				if _, err := os.Stat(localPath); os.IsNotExist(err) {
 					mu.Lock()
 					deleteIds[ctx.Id] = true
 					numTreesRemoved++
@@ -490,9 +503,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					return
 				}
 
-				baseDir := fs.GetBaseDirForFilePaths([]string{ctx.FilePath})
+				baseDir := fs.GetBaseDirForFilePaths([]string{localPath})
 				flattenedPaths, err := ParseInputPaths(ParseInputPathsParams{
-					FileOrDirPaths: []string{ctx.FilePath},
+					FileOrDirPaths: []string{localPath},
 					BaseDir:        baseDir,
 					ProjectPaths:   paths,
 					LoadParams: &types.LoadContextParams{
@@ -539,7 +552,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				}
 				mu.Unlock()
 
-				body := strings.Join(kept, "\n")
+				body := treeBodyToPlanPaths(strings.Join(kept, "\n"))
 				newHash := sha256.Sum256([]byte(body))
 				newSha := hex.EncodeToString(newHash[:])
 
@@ -584,9 +597,11 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 			// Instead of reading all files in the same goroutine,
 			// we now spawn one goroutine per map-file to mirror the loading logic concurrency.
 			wg.Add(1)
-			go func(ctx *shared.Context) {
+			go func(mapCtx *shared.Context) {
 				defer wg.Done()
 
+				ctx := localMapContext(mapCtx)
+
 				// We collect paths from the existing map
 				var mapPaths []string
 				for path := range ctx.MapShas {
@@ -822,7 +837,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					mu.Lock()
 					defer mu.Unlock()
 
-					updatedContexts = append(updatedContexts, ctx)
+					updatedContexts = append(updatedContexts, mapCtx)
 
 					numMaps++
 
@@ -832,13 +847,16 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 							return nil, fmt.Errorf("failed to process map batches: %v", err)
 						}
 
-						return &shared.UpdateContextParams{
+						params := &shared.UpdateContextParams{
 							MapBodies:       updatedMapBodies,
 							InputShas:       state.mapInputShas,
 							InputTokens:     state.mapInputTokens,
 							InputSizes:      state.mapInputSizes,
 							RemovedMapPaths: state.removedMapPaths,
-						}, nil
+						}
+						mapUpdateToPlanPaths(params)
+
+						return params, nil
 					}
 				}
 
@@ -911,6 +929,62 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					}
 				}
 			}(context)
+
+		case shared.ContextGitDiffType:
+			if context.GitDiff == nil {
+				continue
+			}
+
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// only the diff itself is refreshed—files that have changed since it was loaded aren't added to context
+				res, err := getGitDiff(ctx.GitDiff)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to get %s: %v", ctx.Name, err))
+					return
+				}
+				body := res.diff
+
+				size := int64(len(body))
+				if size > shared.MaxContextBodySize {
+					mu.Lock()
+					defer mu.Unlock()
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.Name, Size: size})
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				newSha := hex.EncodeToString(hash[:])
+				if newSha != ctx.Sha {
+					mu.Lock()
+					defer mu.Unlock()
+
+					oldBodySize := int64(len(ctx.Body))
+					if totalSize+size > shared.MaxContextBodySize || totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+						filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+						return
+					}
+
+					totalSize += size
+					totalContextCount++
+					totalBodySize += (size - oldBodySize)
+
+					tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+					numDiffs++
+					updatedContexts = append(updatedContexts, ctx)
+					reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+						return &shared.UpdateContextParams{
+							Body: body,
+						}, nil
+					}
+				}
+			}(context)
 		}
 	}
 
@@ -969,6 +1043,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumDiffs:        numDiffs,
 		NumFilesRemoved: numFilesRemoved,
 		NumTreesRemoved: numTreesRemoved,
 		ReqFn:           reqFn,
@@ -999,6 +1074,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 			NumTrees:    numTrees,
 			NumUrls:     numUrls,
 			NumMaps:     numMaps,
+			NumDiffs:    numDiffs,
 			TokensDiff:  tokensDiff,
 			TotalTokens: newTotal,
 		})
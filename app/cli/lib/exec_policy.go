@@ -0,0 +1,614 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"plandex-cli/fs"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// An exec policy is a project-level file that allows, denies, or requires confirmation for commands in _apply.sh. One rule per line:
+//
+//	# comments and blank lines are ignored
+//	default allow          # action for commands that match no rule: allow (the default), confirm, or deny
+//	deny rm -rf /*
+//	deny curl * | *sh
+//	deny git push*
+//	confirm *sudo *
+//	allow npm *
+//
+// The script is parsed as bash, and patterns are matched against every command in it—including those in if/for/while bodies, functions, subshells,
+// command substitutions, and scripts passed to 'sh -c' or 'eval'—as well as each pipeline as a whole. Env assignments and quotes are removed, and each
+// command is also checked with wrappers like sudo, env, nohup, and xargs stripped. Commands that can't be known ahead of time (a command name from a
+// variable, a shell reading from a pipe) need confirmation, and a script that can't be parsed is denied.
+// '*' matches any run of characters and whitespace is normalized. Deny beats confirm, which beats allow.
+
+const ExecPolicyFileName = "exec-policy"
+
+type ExecPolicyAction string
+
+const (
+	ExecPolicyAllow   ExecPolicyAction = "allow"
+	ExecPolicyConfirm ExecPolicyAction = "confirm"
+	ExecPolicyDeny    ExecPolicyAction = "deny"
+)
+
+type ExecPolicyRule struct {
+	Action  ExecPolicyAction
+	Pattern string
+	Line    int
+	re      *regexp.Regexp
+}
+
+type ExecPolicy struct {
+	Path          string
+	DefaultAction ExecPolicyAction
+	Rules         []*ExecPolicyRule
+}
+
+type ExecPolicyMatch struct {
+	Command string
+	Action  ExecPolicyAction
+	// nil if the command fell through to the default action
+	Rule *ExecPolicyRule
+	// set if the command's code can't be known until it runs, so it needs confirmation
+	Dynamic bool
+	// set if the script couldn't be parsed, which denies the whole script
+	Err error
+}
+
+type ExecPolicyResult struct {
+	Denied    []ExecPolicyMatch
+	ToConfirm []ExecPolicyMatch
+}
+
+func GetExecPolicyPath() string {
+	return filepath.Join(fs.ProjectRoot, ".plandex", ExecPolicyFileName)
+}
+
+// LoadExecPolicy returns nil with no error if the project has no policy file
+func LoadExecPolicy() (*ExecPolicy, error) {
+	path := GetExecPolicyPath()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error opening exec policy: %v", err)
+	}
+	defer f.Close()
+
+	policy, err := ParseExecPolicy(bufio.NewScanner(f))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing exec policy %s: %v", path, err)
+	}
+	policy.Path = path
+
+	return policy, nil
+}
+
+func ParseExecPolicy(scanner *bufio.Scanner) (*ExecPolicy, error) {
+	policy := &ExecPolicy{
+		DefaultAction: ExecPolicyAllow,
+	}
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		if keyword == "default" {
+			action := ExecPolicyAction(rest)
+			if !isValidExecPolicyAction(action) {
+				return nil, fmt.Errorf("line %d: invalid default action '%s'—must be allow, confirm, or deny", lineNum, rest)
+			}
+			policy.DefaultAction = action
+			continue
+		}
+
+		action := ExecPolicyAction(keyword)
+		if !isValidExecPolicyAction(action) {
+			return nil, fmt.Errorf("line %d: unknown action '%s'—must be allow, confirm, deny, or default", lineNum, keyword)
+		}
+		if rest == "" {
+			return nil, fmt.Errorf("line %d: missing command pattern", lineNum)
+		}
+
+		policy.Rules = append(policy.Rules, &ExecPolicyRule{
+			Action:  action,
+			Pattern: rest,
+			Line:    lineNum,
+			re:      execPolicyPatternToRegexp(rest),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (p *ExecPolicy) Check(script string) ExecPolicyResult {
+	var res ExecPolicyResult
+
+	commands, err := parseScriptCommands(script, 0)
+	if err != nil {
+		// fail closed—a script that can't be checked doesn't run
+		res.Denied = append(res.Denied, ExecPolicyMatch{Action: ExecPolicyDeny, Err: err})
+		return res
+	}
+
+	for _, command := range commands {
+		match := p.matchCommand(command)
+		switch match.Action {
+		case ExecPolicyDeny:
+			res.Denied = append(res.Denied, match)
+		case ExecPolicyConfirm:
+			res.ToConfirm = append(res.ToConfirm, match)
+		}
+	}
+
+	return res
+}
+
+// matchCommand checks every form of the command and keeps the strictest result, so 'sudo rm -rf /' is caught by both 'confirm *sudo *' and 'deny rm -rf /*'
+func (p *ExecPolicy) matchCommand(command execPolicyCommand) ExecPolicyMatch {
+	var res ExecPolicyMatch
+	for i, form := range command.forms {
+		match := p.match(form)
+		if i == 0 || execPolicyActionRank(match.Action) > execPolicyActionRank(res.Action) {
+			res = match
+		}
+	}
+	res.Command = command.forms[0]
+
+	if command.dynamic && execPolicyActionRank(res.Action) < execPolicyActionRank(ExecPolicyConfirm) {
+		res.Action = ExecPolicyConfirm
+		res.Rule = nil
+		res.Dynamic = true
+	}
+
+	return res
+}
+
+func (p *ExecPolicy) match(command string) ExecPolicyMatch {
+	var best *ExecPolicyRule
+	for _, rule := range p.Rules {
+		if !rule.re.MatchString(command) {
+			continue
+		}
+		if best == nil || execPolicyActionRank(rule.Action) > execPolicyActionRank(best.Action) {
+			best = rule
+		}
+	}
+
+	if best == nil {
+		return ExecPolicyMatch{Command: command, Action: p.DefaultAction}
+	}
+	return ExecPolicyMatch{Command: command, Action: best.Action, Rule: best}
+}
+
+// Describe formats a violation for the user and for the model in the debug loop
+func (m ExecPolicyMatch) Describe() string {
+	if m.Err != nil {
+		return fmt.Sprintf("the script couldn't be parsed for policy checks, so none of it can run: %v", m.Err)
+	}
+	if m.Dynamic {
+		return fmt.Sprintf("`%s` (runs commands that can't be checked ahead of time)", m.Command)
+	}
+	if m.Rule == nil {
+		return fmt.Sprintf("`%s` (%s by default)", m.Command, m.Action)
+	}
+	return fmt.Sprintf("`%s` (matches '%s %s')", m.Command, m.Rule.Action, m.Rule.Pattern)
+}
+
+// execPolicyCommand is a command found in a script along with the forms that patterns are matched against: the command as written (without env assignments), then with its path and each wrapper (sudo, env, xargs, etc.) stripped
+type execPolicyCommand struct {
+	forms []string
+	// the command runs code that isn't known until it runs, like a command name from a variable or a shell reading from a pipe
+	dynamic bool
+}
+
+const maxExecPolicyNesting = 8
+
+// parseScriptCommands finds every command in a script with a real shell parser, including those in compound commands, functions, subshells, and command substitutions. Pipelines are also returned as a whole, and scripts passed to 'sh -c', 'eval', and the like are parsed too.
+func parseScriptCommands(script string, depth int) ([]execPolicyCommand, error) {
+	if depth > maxExecPolicyNesting {
+		return nil, fmt.Errorf("commands are nested too deeply")
+	}
+
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(script), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []execPolicyCommand
+	var walkErr error
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if walkErr != nil {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *syntax.Stmt:
+			call, ok := node.Cmd.(*syntax.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+
+			args := make([]string, len(call.Args))
+			static := make([]bool, len(call.Args))
+			for i, word := range call.Args {
+				args[i], static[i] = execPolicyWordString(word)
+			}
+
+			res, err := argsToExecPolicyCommands(args, static, node.Redirs, depth)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			commands = append(commands, res...)
+
+		case *syntax.BinaryCmd:
+			if node.Op == syntax.Pipe || node.Op == syntax.PipeAll {
+				commands = append(commands, execPolicyCommand{forms: []string{execPolicyPipelineString(node)}})
+			}
+		}
+
+		return true
+	})
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	return commands, nil
+}
+
+func argsToExecPolicyCommands(args []string, static []bool, redirs []*syntax.Redirect, depth int) ([]execPolicyCommand, error) {
+	command := execPolicyCommand{}
+	var nested []execPolicyCommand
+
+	for len(args) > 0 {
+		command.forms = append(command.forms, normalizeExecPolicyWhitespace(strings.Join(args, " ")))
+
+		if !static[0] {
+			command.dynamic = true
+			break
+		}
+
+		if strings.Contains(args[0], "/") {
+			args = append([]string{path.Base(args[0])}, args[1:]...)
+			command.forms = append(command.forms, normalizeExecPolicyWhitespace(strings.Join(args, " ")))
+		}
+
+		n, dynamic := execPolicyWrapperArgs(args)
+		if dynamic {
+			command.dynamic = true
+		}
+		if n == 0 {
+			break
+		}
+		args = args[n:]
+		static = static[n:]
+	}
+
+	if len(args) > 0 && static[0] {
+		name := args[0]
+
+		var scripts []string
+		var scriptsStatic []bool
+
+		switch {
+		case name == "eval":
+			scripts = append(scripts, strings.Join(args[1:], " "))
+			scriptsStatic = append(scriptsStatic, allExecPolicyArgsStatic(static[1:]))
+
+		case name == "trap":
+			if len(args) > 1 {
+				scripts = append(scripts, args[1])
+				scriptsStatic = append(scriptsStatic, static[1])
+			}
+
+		case execPolicyShells[name]:
+			idx := execPolicyShellScriptArg(args)
+			if idx > 0 {
+				scripts = append(scripts, args[idx])
+				scriptsStatic = append(scriptsStatic, static[idx])
+			} else if idx == 0 {
+				// the shell reads its script from stdin—a heredoc or here-string can be checked, anything else can't
+				found := false
+				for _, redir := range redirs {
+					var word *syntax.Word
+					switch redir.Op {
+					case syntax.Hdoc, syntax.DashHdoc:
+						word = redir.Hdoc
+					case syntax.WordHdoc:
+						word = redir.Word
+					}
+					if word == nil {
+						continue
+					}
+					found = true
+					s, ok := execPolicyWordString(word)
+					scripts = append(scripts, s)
+					scriptsStatic = append(scriptsStatic, ok)
+				}
+				if !found {
+					command.dynamic = true
+				}
+			}
+
+		case name == "find":
+			for i := 1; i < len(args); i++ {
+				switch args[i] {
+				case "-exec", "-execdir", "-ok", "-okdir":
+					end := i + 1
+					for end < len(args) && args[end] != ";" && args[end] != "+" {
+						end++
+					}
+					res, err := argsToExecPolicyCommands(args[i+1:end], static[i+1:end], nil, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					nested = append(nested, res...)
+					i = end
+				}
+			}
+		}
+
+		for i, script := range scripts {
+			if !scriptsStatic[i] {
+				command.dynamic = true
+				continue
+			}
+			res, err := parseScriptCommands(script, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing script passed to %s: %v", name, err)
+			}
+			nested = append(nested, res...)
+		}
+	}
+
+	return append([]execPolicyCommand{command}, nested...), nil
+}
+
+// execPolicyWrappers run the rest of their arguments as a command. Each lists the options that take a separate value.
+var execPolicyWrappers = map[string]map[string]bool{
+	"sudo":     {"-u": true, "-g": true, "-C": true, "-D": true, "-h": true, "-p": true, "-r": true, "-t": true, "-T": true, "-U": true, "--user": true, "--group": true, "--chdir": true},
+	"doas":     {"-u": true, "-C": true},
+	"command":  {},
+	"builtin":  {},
+	"exec":     {"-a": true},
+	"env":      {"-u": true, "-C": true, "--unset": true, "--chdir": true},
+	"nohup":    {},
+	"nice":     {"-n": true, "--adjustment": true},
+	"time":     {"-f": true, "-o": true, "--format": true, "--output": true},
+	"timeout":  {"-s": true, "-k": true, "--signal": true, "--kill-after": true},
+	"xargs":    {"-a": true, "-d": true, "-E": true, "-I": true, "-L": true, "-n": true, "-P": true, "-s": true, "--arg-file": true, "--delimiter": true, "--max-args": true, "--max-procs": true},
+	"stdbuf":   {"-i": true, "-o": true, "-e": true},
+	"setsid":   {},
+	"ionice":   {"-c": true, "-n": true},
+	"unbuffer": {},
+}
+
+// execPolicyWrapperArgs returns how many leading args belong to a wrapper command, or 0 if the command isn't a wrapper. Wrappers that build their command from a string (env -S) make it dynamic.
+func execPolicyWrapperArgs(args []string) (int, bool) {
+	valueOpts, ok := execPolicyWrappers[args[0]]
+	if !ok {
+		return 0, false
+	}
+
+	i := 1
+	for i < len(args) {
+		arg := args[i]
+		if arg == "--" {
+			i++
+			break
+		}
+		if args[0] == "env" && (arg == "-S" || arg == "--split-string" || strings.HasPrefix(arg, "--split-string=") || (strings.HasPrefix(arg, "-S") && !strings.HasPrefix(arg, "--"))) {
+			return len(args), true
+		}
+		if args[0] == "env" && !strings.HasPrefix(arg, "-") && strings.Contains(arg, "=") {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+		i++
+		if valueOpts[arg] {
+			i++
+		}
+	}
+
+	// timeout's first positional arg is the duration
+	if args[0] == "timeout" && i < len(args) {
+		i++
+	}
+
+	if i > len(args) {
+		i = len(args)
+	}
+	return i, false
+}
+
+var execPolicyShells = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "ash": true, "fish": true}
+
+// execPolicyShellScriptArg returns the index of the script passed with -c, 0 if the shell reads its script from stdin, or -1 if it runs a script file
+func execPolicyShellScriptArg(args []string) int {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "+") {
+			if arg == "--" {
+				i++
+			}
+			if i < len(args) {
+				return -1
+			}
+			return 0
+		}
+		// -c can be combined with other flags, as in 'bash -ec'
+		if !strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, "-") && strings.Contains(arg[1:], "c") {
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		}
+		if arg == "-o" || arg == "+o" {
+			i++
+		}
+	}
+	return 0
+}
+
+func allExecPolicyArgsStatic(static []bool) bool {
+	for _, ok := range static {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// execPolicyWordString returns a word's value with quotes and escapes removed. Parts that are only known when the script runs (variables, command substitutions, globs, brace expansions) are kept as written, and the word isn't static.
+func execPolicyWordString(word *syntax.Word) (string, bool) {
+	var sb strings.Builder
+	static := true
+	for _, part := range word.Parts {
+		if !writeExecPolicyWordPart(&sb, part, false) {
+			static = false
+		}
+	}
+	s := sb.String()
+	if s == "[" {
+		static = true
+	}
+	return s, static
+}
+
+func writeExecPolicyWordPart(sb *strings.Builder, part syntax.WordPart, quoted bool) bool {
+	switch part := part.(type) {
+	case *syntax.Lit:
+		value := part.Value
+		static := true
+		if !quoted && strings.ContainsAny(value, "*?[{") {
+			static = false
+		}
+		sb.WriteString(unescapeExecPolicyLit(value, quoted))
+		return static
+
+	case *syntax.SglQuoted:
+		sb.WriteString(part.Value)
+		// $'...' strings can spell out any command with escapes
+		return !part.Dollar || !strings.Contains(part.Value, "\\")
+
+	case *syntax.DblQuoted:
+		static := true
+		for _, p := range part.Parts {
+			if !writeExecPolicyWordPart(sb, p, true) {
+				static = false
+			}
+		}
+		return static
+	}
+
+	syntax.NewPrinter().Print(sb, part)
+	return false
+}
+
+func unescapeExecPolicyLit(value string, quoted bool) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) {
+			next := value[i+1]
+			if next == '\n' {
+				i++
+				continue
+			}
+			if !quoted || strings.IndexByte("$`\"\\", next) >= 0 {
+				sb.WriteByte(next)
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func execPolicyPipelineString(node syntax.Node) string {
+	switch node := node.(type) {
+	case *syntax.BinaryCmd:
+		if node.Op == syntax.Pipe || node.Op == syntax.PipeAll {
+			return execPolicyPipelineString(node.X) + " | " + execPolicyPipelineString(node.Y)
+		}
+	case *syntax.Stmt:
+		if call, ok := node.Cmd.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+			args := make([]string, len(call.Args))
+			for i, word := range call.Args {
+				args[i], _ = execPolicyWordString(word)
+			}
+			return normalizeExecPolicyWhitespace(strings.Join(args, " "))
+		}
+		if node.Cmd != nil {
+			return execPolicyPipelineString(node.Cmd)
+		}
+	}
+
+	var sb strings.Builder
+	syntax.NewPrinter().Print(&sb, node)
+	return normalizeExecPolicyWhitespace(sb.String())
+}
+
+var execPolicyWhitespaceRe = regexp.MustCompile(`\s+`)
+
+var execPolicyPipeRe = regexp.MustCompile(`\s*\|\s*`)
+
+// pipes are spaced out so that 'curl x|sh' and 'curl x | sh' match the same patterns
+func normalizeExecPolicyWhitespace(s string) string {
+	s = execPolicyPipeRe.ReplaceAllString(s, " | ")
+	return strings.TrimSpace(execPolicyWhitespaceRe.ReplaceAllString(s, " "))
+}
+
+func execPolicyPatternToRegexp(pattern string) *regexp.Regexp {
+	pattern = normalizeExecPolicyWhitespace(pattern)
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func isValidExecPolicyAction(action ExecPolicyAction) bool {
+	return action == ExecPolicyAllow || action == ExecPolicyConfirm || action == ExecPolicyDeny
+}
+
+func execPolicyActionRank(action ExecPolicyAction) int {
+	switch action {
+	case ExecPolicyDeny:
+		return 2
+	case ExecPolicyConfirm:
+		return 1
+	}
+	return 0
+}
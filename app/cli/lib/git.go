@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"plandex-cli/fs"
 	"regexp"
 	"strings"
 	"sync"
@@ -56,6 +57,32 @@ func GitAddAndCommitPaths(dir, message string, paths []string, lockMutex bool) e
 	return nil
 }
 
+// GitAddAndCommitRepoRoots commits plan paths to the repo each one belongs to, with the same message in every repo. Paths in roots that aren't git repos are skipped.
+func GitAddAndCommitRepoRoots(message string, paths []string) error {
+	pathsByDir := map[string][]string{}
+	var dirs []string
+	for _, path := range paths {
+		dir, rel := fs.RepoDirForPath(path)
+		if _, ok := pathsByDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		pathsByDir[dir] = append(pathsByDir[dir], rel)
+	}
+
+	for _, dir := range dirs {
+		if !fs.IsGitRepo(dir) {
+			continue
+		}
+
+		err := GitAddAndCommitPaths(dir, message, pathsByDir[dir], true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GitAdd(repoDir, path string, lockMutex bool) error {
 	if lockMutex {
 		gitMutex.Lock()
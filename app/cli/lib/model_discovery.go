@@ -0,0 +1,460 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const DefaultOllamaBaseUrl = "http://localhost:11434"
+
+// used when a server doesn't report a model's context window
+const defaultDiscoveredContextLength = 8192
+
+const (
+	discoveryRequestTimeout = 10 * time.Second
+
+	// the first request to a model loads it into memory, which can take a while for large models
+	discoveryProbeTimeout = 3 * time.Minute
+)
+
+type DiscoveredModel struct {
+	Name string
+
+	ContextLength int
+	// false if the server didn't report a context window and the default was used
+	ContextReported bool
+
+	// in billions, 0 if unknown
+	ParameterSize float64
+
+	IsEmbeddingModel bool
+	HasImageSupport  bool
+
+	// set by ProbeDiscoveredModels
+	Probed   bool
+	ProbeErr error
+}
+
+type DiscoverLocalModelsResult struct {
+	BaseUrl  string
+	IsOllama bool
+	Models   []*DiscoveredModel
+}
+
+// DiscoverLocalModels lists the models on a local Ollama server, or on any OpenAI-compatible server if the Ollama API isn't available
+func DiscoverLocalModels(baseUrl string) (*DiscoverLocalModelsResult, error) {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+
+	models, ollamaErr := discoverOllamaModels(baseUrl)
+	if ollamaErr == nil {
+		return &DiscoverLocalModelsResult{BaseUrl: baseUrl, IsOllama: true, Models: models}, nil
+	}
+
+	// OpenAI-compatible servers are usually served under /v1, but the user may have included it already
+	openAIBaseUrl := baseUrl
+	if !strings.HasSuffix(openAIBaseUrl, "/v1") {
+		openAIBaseUrl += "/v1"
+	}
+
+	models, openAIErr := discoverOpenAICompatibleModels(openAIBaseUrl)
+	if openAIErr != nil {
+		return nil, fmt.Errorf("couldn't list models at %s\nOllama API: %v\nOpenAI-compatible API: %v", baseUrl, ollamaErr, openAIErr)
+	}
+
+	return &DiscoverLocalModelsResult{BaseUrl: openAIBaseUrl, IsOllama: false, Models: models}, nil
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name    string `json:"name"`
+		Details struct {
+			Family        string `json:"family"`
+			ParameterSize string `json:"parameter_size"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+type ollamaShowResponse struct {
+	Parameters   string         `json:"parameters"`
+	ModelInfo    map[string]any `json:"model_info"`
+	Capabilities []string       `json:"capabilities"`
+}
+
+var numCtxRegex = regexp.MustCompile(`(?m)^num_ctx\s+(\d+)`)
+
+func discoverOllamaModels(baseUrl string) ([]*DiscoveredModel, error) {
+	var tags ollamaTagsResponse
+	err := discoveryRequest(http.MethodGet, baseUrl+"/api/tags", nil, &tags, discoveryRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*DiscoveredModel
+	for _, tag := range tags.Models {
+		model := &DiscoveredModel{
+			Name:          tag.Name,
+			ParameterSize: parseParameterSize(tag.Details.ParameterSize),
+		}
+		if model.ParameterSize == 0 {
+			model.ParameterSize = parseParameterSize(tag.Name)
+		}
+
+		var show ollamaShowResponse
+		err := discoveryRequest(http.MethodPost, baseUrl+"/api/show", map[string]string{"model": tag.Name}, &show, discoveryRequestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("error getting details for %s: %v", tag.Name, err)
+		}
+
+		// a num_ctx parameter in the Modelfile is what Ollama actually runs the model with, so it takes precedence over the trained context length
+		if m := numCtxRegex.FindStringSubmatch(show.Parameters); m != nil {
+			model.ContextLength, _ = strconv.Atoi(m[1])
+		}
+		if model.ContextLength == 0 {
+			for key, value := range show.ModelInfo {
+				if strings.HasSuffix(key, ".context_length") {
+					if n, ok := value.(float64); ok {
+						model.ContextLength = int(n)
+					}
+					break
+				}
+			}
+		}
+		model.ContextReported = model.ContextLength > 0
+
+		if len(show.Capabilities) > 0 {
+			for _, capability := range show.Capabilities {
+				switch capability {
+				case "embedding":
+					model.IsEmbeddingModel = true
+				case "vision":
+					model.HasImageSupport = true
+				}
+			}
+		} else {
+			// older Ollama versions don't report capabilities
+			model.IsEmbeddingModel = isEmbeddingModelName(tag.Name) || strings.Contains(tag.Details.Family, "bert")
+		}
+
+		models = append(models, model)
+	}
+
+	return finalizeDiscoveredModels(models), nil
+}
+
+type openAIModelsResponse struct {
+	Data []map[string]any `json:"data"`
+}
+
+// servers that extend the OpenAI models list report the context window under different keys
+var openAIContextLengthKeys = []string{"context_length", "max_model_len", "max_context_length", "context_window"}
+
+func discoverOpenAICompatibleModels(baseUrl string) ([]*DiscoveredModel, error) {
+	var res openAIModelsResponse
+	err := discoveryRequest(http.MethodGet, baseUrl+"/models", nil, &res, discoveryRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*DiscoveredModel
+	for _, data := range res.Data {
+		name, _ := data["id"].(string)
+		if name == "" {
+			continue
+		}
+
+		model := &DiscoveredModel{
+			Name:             name,
+			ParameterSize:    parseParameterSize(name),
+			IsEmbeddingModel: isEmbeddingModelName(name),
+		}
+
+		if modelType, ok := data["type"].(string); ok && (modelType == "embeddings" || modelType == "embedding") {
+			model.IsEmbeddingModel = true
+		}
+
+		for _, key := range openAIContextLengthKeys {
+			if n, ok := data[key].(float64); ok && n > 0 {
+				model.ContextLength = int(n)
+				model.ContextReported = true
+				break
+			}
+		}
+
+		models = append(models, model)
+	}
+
+	return finalizeDiscoveredModels(models), nil
+}
+
+func finalizeDiscoveredModels(models []*DiscoveredModel) []*DiscoveredModel {
+	for _, model := range models {
+		if model.ContextLength == 0 {
+			model.ContextLength = defaultDiscoveredContextLength
+		}
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+
+	return models
+}
+
+func isEmbeddingModelName(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "embed") || strings.Contains(name, "bge-") || strings.Contains(name, "minilm")
+}
+
+var parameterSizeRegex = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*([bm])\b`)
+
+// parseParameterSize parses sizes like '32.8B' or '567M', or a size in a model name like 'qwen3:32b', into billions of parameters
+func parseParameterSize(s string) float64 {
+	m := parameterSizeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0
+	}
+	if strings.EqualFold(m[2], "m") {
+		return n / 1000
+	}
+	return n
+}
+
+// ProbeDiscoveredModels sends a small request to each model to verify that it can actually be used. Models are probed one at a time since each one may need to be loaded into memory.
+func ProbeDiscoveredModels(res *DiscoverLocalModelsResult, onProbe func(model *DiscoveredModel)) {
+	for _, model := range res.Models {
+		model.ProbeErr = probeDiscoveredModel(res, model)
+		model.Probed = true
+		if onProbe != nil {
+			onProbe(model)
+		}
+	}
+}
+
+func probeDiscoveredModel(res *DiscoverLocalModelsResult, model *DiscoveredModel) error {
+	var url string
+	var body any
+	var out map[string]any
+
+	switch {
+	case res.IsOllama && model.IsEmbeddingModel:
+		url = res.BaseUrl + "/api/embed"
+		body = map[string]any{"model": model.Name, "input": "ok"}
+	case res.IsOllama:
+		url = res.BaseUrl + "/api/chat"
+		body = map[string]any{
+			"model":    model.Name,
+			"messages": []map[string]string{{"role": "user", "content": "Reply with 'ok'."}},
+			"stream":   false,
+			"options":  map[string]any{"num_predict": 8},
+		}
+	case model.IsEmbeddingModel:
+		url = res.BaseUrl + "/embeddings"
+		body = map[string]any{"model": model.Name, "input": "ok"}
+	default:
+		url = res.BaseUrl + "/chat/completions"
+		body = map[string]any{
+			"model":      model.Name,
+			"messages":   []map[string]string{{"role": "user", "content": "Reply with 'ok'."}},
+			"max_tokens": 8,
+		}
+	}
+
+	err := discoveryRequest(http.MethodPost, url, body, &out, discoveryProbeTimeout)
+	if err != nil {
+		return err
+	}
+
+	if errMsg, ok := out["error"]; ok && errMsg != nil {
+		return fmt.Errorf("%v", errMsg)
+	}
+
+	return nil
+}
+
+func discoveryRequest(method, url string, body any, out any, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling request: %v", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	err = json.Unmarshal(respBody, out)
+	if err != nil {
+		return fmt.Errorf("error parsing response from %s: %v", url, err)
+	}
+
+	return nil
+}
+
+type DiscoveredModelsInputParams struct {
+	// only used for servers that aren't Ollama, which are added as a custom provider
+	ProviderName string
+	PackName     string
+}
+
+// DiscoveredModelsInput builds custom models for the discovered models that passed their probe (or weren't probed), plus a suggested model pack that uses only those models. Larger models are used for the heavy-lifting roles and the smallest for lighter roles.
+func DiscoveredModelsInput(res *DiscoverLocalModelsResult, params DiscoveredModelsInputParams) (*shared.ModelsInput, error) {
+	input := &shared.ModelsInput{}
+
+	var usesProvider func(model *DiscoveredModel) shared.BaseModelUsesProvider
+	var modelIdPrefix string
+
+	if res.IsOllama {
+		modelIdPrefix = "ollama"
+		usesProvider = func(model *DiscoveredModel) shared.BaseModelUsesProvider {
+			// LiteLLM routes chat models through ollama_chat/ and embedding models through ollama/
+			prefix := "ollama_chat/"
+			if model.IsEmbeddingModel {
+				prefix = "ollama/"
+			}
+			return shared.BaseModelUsesProvider{
+				Provider:  shared.ModelProviderOllama,
+				ModelName: shared.ModelName(prefix + model.Name),
+			}
+		}
+	} else {
+		modelIdPrefix = params.ProviderName
+		input.CustomProviders = append(input.CustomProviders, &shared.CustomProvider{
+			Name:     params.ProviderName,
+			BaseUrl:  res.BaseUrl,
+			SkipAuth: true,
+		})
+		usesProvider = func(model *DiscoveredModel) shared.BaseModelUsesProvider {
+			return shared.BaseModelUsesProvider{
+				Provider:       shared.ModelProviderCustom,
+				CustomProvider: shared.Pointer(params.ProviderName),
+				ModelName:      shared.ModelName(model.Name),
+			}
+		}
+	}
+
+	var chatModels []*DiscoveredModel
+	var embeddingModels []*DiscoveredModel
+	modelIds := map[*DiscoveredModel]shared.ModelId{}
+
+	for _, model := range res.Models {
+		if model.ProbeErr != nil {
+			continue
+		}
+
+		modelId := shared.ModelId(fmt.Sprintf("%s/%s", modelIdPrefix, model.Name))
+		modelIds[model] = modelId
+
+		customModel := &shared.CustomModel{
+			ModelId:     modelId,
+			Description: fmt.Sprintf("%s (local, discovered)", model.Name),
+			Providers:   []shared.BaseModelUsesProvider{usesProvider(model)},
+		}
+
+		if model.IsEmbeddingModel {
+			customModel.BaseModelShared = shared.BaseModelShared{
+				MaxTokens:        model.ContextLength,
+				IsEmbeddingModel: true,
+			}
+			embeddingModels = append(embeddingModels, model)
+		} else {
+			customModel.BaseModelShared = shared.BaseModelShared{
+				MaxTokens:             model.ContextLength,
+				MaxOutputTokens:       model.ContextLength,
+				ReservedOutputTokens:  min(8192, model.ContextLength/4),
+				DefaultMaxConvoTokens: max(2000, min(10000, model.ContextLength/8)),
+				PreferredOutputFormat: shared.ModelOutputFormatXml,
+				ModelCompatibility: shared.ModelCompatibility{
+					HasImageSupport: model.HasImageSupport,
+				},
+			}
+			chatModels = append(chatModels, model)
+		}
+
+		input.CustomModels = append(input.CustomModels, customModel)
+	}
+
+	if len(chatModels) == 0 {
+		return nil, fmt.Errorf("no usable chat models were found, so a model pack can't be created")
+	}
+
+	// strongest first: more parameters, then a larger context window
+	sort.SliceStable(chatModels, func(i, j int) bool {
+		if chatModels[i].ParameterSize != chatModels[j].ParameterSize {
+			return chatModels[i].ParameterSize > chatModels[j].ParameterSize
+		}
+		return chatModels[i].ContextLength > chatModels[j].ContextLength
+	})
+
+	strongest := modelIds[chatModels[0]]
+	smallest := modelIds[chatModels[len(chatModels)-1]]
+
+	roleConfig := func(modelId shared.ModelId) shared.ModelRoleConfigSchema {
+		return shared.ModelRoleConfigSchema{ModelId: modelId}
+	}
+
+	pack := &shared.ModelPackSchema{
+		Name:        params.PackName,
+		Description: fmt.Sprintf("Local models discovered at %s. Uses %s for heavy lifting and %s for lighter tasks.", res.BaseUrl, strongest, smallest),
+		ModelPackSchemaRoles: shared.ModelPackSchemaRoles{
+			Planner:          roleConfig(strongest),
+			PlanSummary:      roleConfig(strongest),
+			Builder:          roleConfig(strongest),
+			WholeFileBuilder: shared.Pointer(roleConfig(strongest)),
+			Namer:            roleConfig(smallest),
+			CommitMsg:        roleConfig(smallest),
+			ExecStatus:       roleConfig(strongest),
+		},
+	}
+
+	if res.IsOllama {
+		pack.LocalProvider = shared.ModelProviderOllama
+	}
+
+	if len(embeddingModels) > 0 {
+		pack.Embeddings = shared.Pointer(roleConfig(modelIds[embeddingModels[0]]))
+	}
+
+	input.CustomModelPacks = append(input.CustomModelPacks, pack)
+
+	return input, nil
+}
@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"sort"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// ExpandContextGlobs returns the project files matching a template's context globs, relative to the current directory. Globs use .gitignore syntax and are relative to the project root—'@<alias>/' globs match files in other repo roots.
+func ExpandContextGlobs(globs []string) ([]string, error) {
+	if len(globs) == 0 {
+		return nil, nil
+	}
+
+	paths, err := fs.GetProjectPathsWithRepoRoots(fs.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error getting project paths: %v", err)
+	}
+
+	matcher := ignore.CompileIgnoreLines(globs...)
+
+	var res []string
+	for path := range paths.ActivePaths {
+		if !matcher.MatchesPath(filepath.ToSlash(fs.ToPlanPath(path))) {
+			continue
+		}
+
+		rel, err := filepath.Rel(fs.Cwd, filepath.Join(fs.ProjectRoot, path))
+		if err != nil {
+			return nil, fmt.Errorf("error getting relative path for %s: %v", path, err)
+		}
+		res = append(res, rel)
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// AddPlandexIgnoreLines appends any lines that aren't already in the project's .plandexignore, creating it if needed. Returns the lines that were added.
+func AddPlandexIgnoreLines(lines []string) ([]string, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	ignorePath := filepath.Join(fs.ProjectRoot, ".plandexignore")
+
+	var existing string
+	bytes, err := os.ReadFile(ignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading .plandexignore: %v", err)
+	}
+	existing = string(bytes)
+
+	existingLines := map[string]bool{}
+	for _, line := range strings.Split(existing, "\n") {
+		existingLines[strings.TrimSpace(line)] = true
+	}
+
+	var added []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || existingLines[line] {
+			continue
+		}
+		existingLines[line] = true
+		added = append(added, line)
+	}
+
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	updated := existing
+	if updated != "" && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	updated += strings.Join(added, "\n") + "\n"
+
+	err = os.WriteFile(ignorePath, []byte(updated), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error writing .plandexignore: %v", err)
+	}
+
+	return added, nil
+}
@@ -172,7 +172,7 @@ func AnalyzeRewind(targetState, currentState *shared.CurrentPlanState) (*RewindA
 			}
 
 			// Get the actual file content from disk
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.ResolvePath(path)
 			diskContent, err := os.ReadFile(dstPath)
 			if err != nil {
 				if os.IsNotExist(err) {
@@ -260,15 +260,15 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 		return nil
 	}
 
-	// Track directories that might need cleanup
-	dirsToCheck := make(map[string]bool)
+	// Track directories that might need cleanup, along with the root dir to stop at
+	dirsToCheck := make(map[string]string)
 	var mu sync.Mutex
 
 	errCh := make(chan error, len(requiredChanges))
 
 	for path, content := range requiredChanges {
 		go func(path, content string) {
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.ResolvePath(path)
 
 			if content == "" {
 				// Remove the file
@@ -279,8 +279,9 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 				}
 				// Mark parent directory for cleanup
 				parentDir := filepath.Dir(dstPath)
+				rootDir, _ := fs.RepoDirForPath(path)
 				mu.Lock()
-				dirsToCheck[parentDir] = true
+				dirsToCheck[parentDir] = rootDir
 				mu.Unlock()
 				errCh <- nil
 				return
@@ -310,8 +311,8 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 	}
 
 	// Clean up empty directories
-	for dir := range dirsToCheck {
-		if err := RemoveEmptyDirs(dir, fs.ProjectRoot); err != nil {
+	for dir, rootDir := range dirsToCheck {
+		if err := RemoveEmptyDirs(dir, rootDir); err != nil {
 			// Log but don't fail the operation for directory cleanup errors
 			fmt.Printf("Warning: failed to clean up directory %s: %v\n", dir, err)
 		}
@@ -25,6 +25,7 @@ func init() {
 
 	auth.SetOpenUnauthenticatedCloudURLFn(ui.OpenUnauthenticatedCloudURL)
 	auth.SetOpenAuthenticatedURLFn(ui.OpenAuthenticatedURL)
+	auth.SetOpenURLFn(ui.OpenURL)
 
 	term.SetOpenAuthenticatedURLFn(ui.OpenAuthenticatedURL)
 	term.SetOpenUnauthenticatedCloudURLFn(ui.OpenUnauthenticatedCloudURL)
@@ -36,11 +36,30 @@ func getOnApplyExecFail(applyFlags types.ApplyFlags, tellFlags types.TellFlags,
 					timesLbl = "time"
 				}
 				color.New(term.ColorHiRed, color.Bold).Printf("Commands failed %d %s.\n", attempt, timesLbl)
+
+				// failing to report shouldn't interrupt the user
+				apiErr := api.Client.ReportPlanEvent(lib.CurrentPlanId, lib.CurrentBranch, shared.ReportPlanEventRequest{
+					Event: shared.WebhookEventAutoDebugExhausted,
+					Data: map[string]interface{}{
+						"attempts":   attempt,
+						"exitStatus": status,
+					},
+				})
+				if apiErr != nil {
+					log.Printf("Error reporting auto-debug event: %v\n", apiErr.Msg)
+				}
 			} else {
 				proceed = true
 			}
 		}
 
+		if !proceed && term.IsJsonOutput() {
+			if toRollback != nil && toRollback.HasChanges() {
+				lib.Rollback(toRollback, false)
+			}
+			onErr("commands failed with exit status %d and changes were rolled back:\n%s", status, output)
+		}
+
 		if !proceed {
 			const (
 				DebugAndRetry          = "Debug and retry once"
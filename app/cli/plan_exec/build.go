@@ -31,7 +31,7 @@ func Build(params ExecParams, flags types.BuildFlags) (bool, error) {
 		term.OutputErrorAndExit("Error getting context: %v", apiErr)
 	}
 
-	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	paths, err := fs.GetProjectPathsWithRepoRoots(fs.GetBaseDirForContexts(contexts))
 
 	if err != nil {
 		return false, fmt.Errorf("error getting project paths: %v", err)
@@ -51,7 +51,7 @@ func Build(params ExecParams, flags types.BuildFlags) (bool, error) {
 
 	apiErr = api.Client.BuildPlan(params.CurrentPlanId, params.CurrentBranch, shared.BuildPlanRequest{
 		ConnectStream: !buildBg,
-		ProjectPaths:  paths.ActivePaths,
+		ProjectPaths:  fs.ToPlanPaths(paths.ActivePaths),
 		AuthVars:      params.AuthVars,
 	}, stream.OnStreamPlan)
 
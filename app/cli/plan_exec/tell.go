@@ -78,7 +78,7 @@ func TellPlan(
 		term.OutputErrorAndExit("Error getting context: %v", apiErr)
 	}
 
-	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	paths, err := fs.GetProjectPathsWithRepoRoots(fs.GetBaseDirForContexts(contexts))
 
 	if err != nil {
 		outputPromptIfTell()
@@ -135,7 +135,8 @@ func TellPlan(
 			Prompt:                 prompt,
 			ConnectStream:          !tellBg,
 			AutoContinue:           !tellStop,
-			ProjectPaths:           paths.ActivePaths,
+			ProjectPaths:           fs.ToPlanPaths(paths.ActivePaths),
+			RepoRoots:              fs.RepoRootDescriptions(),
 			BuildMode:              buildMode,
 			IsUserContinue:         isUserContinue,
 			IsUserDebug:            isDebugCmd,
@@ -148,6 +149,8 @@ func TellPlan(
 			IsImplementationOfChat: isImplementationOfChat,
 			IsGitRepo:              isGitRepo,
 			SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
+			// searches are run by the connected client, so they're only offered when the stream is connected
+			CodeSearchEnabled: !tellBg,
 		}, stream.OnStreamPlan)
 
 		term.StopSpinner()
@@ -195,7 +198,7 @@ func TellPlan(
 					term.OutputErrorAndExit("Error starting stream UI: %v", err)
 				}
 
-				if auth.Current.IsCloud && auth.Current.IntegratedModelsMode && auth.Current.OrgIsTrial {
+				if auth.Current.IsCloud && auth.Current.IntegratedModelsMode && auth.Current.OrgIsTrial && !term.IsJsonOutput() {
 					term.StartSpinner("")
 					balance, apiErr := api.Client.GetBalance()
 					term.StopSpinner()
@@ -230,7 +233,7 @@ func TellPlan(
 				} else if autoApply || isDebugCmd || isApplyDebug {
 					term.StopSpinner()
 					// do nothing, allow auto apply to run
-				} else if skipChangesMenu {
+				} else if skipChangesMenu || term.IsJsonOutput() {
 					term.StopSpinner()
 					// script mode, don't show menu
 				} else {
@@ -276,6 +279,15 @@ func TellPlan(
 	}
 
 	if tellBg {
+		if term.IsJsonOutput() {
+			term.OutputJsonResult("tell", map[string]interface{}{
+				"planId":     params.CurrentPlanId,
+				"branch":     params.CurrentBranch,
+				"background": true,
+			})
+			return
+		}
+
 		outputPromptIfTell()
 		fmt.Println("✅ Plan is active in the background")
 		fmt.Println()
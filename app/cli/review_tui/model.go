@@ -0,0 +1,147 @@
+package reviewtui
+
+import (
+	shared "plandex-shared"
+
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type decision int
+
+const (
+	decisionUndecided decision = iota
+	decisionAccepted
+	decisionRejected
+)
+
+// hunk is a single reviewable change—either one replacement within a file result, or a whole new or removed file
+type hunk struct {
+	path        string
+	resultId    string
+	replacement *shared.Replacement
+	removedFile bool
+	old         string
+	new         string
+	summary     string
+	editable    bool
+}
+
+type reviewUIModel struct {
+	keymap keymap
+	editor string
+
+	hunks     []*hunk
+	idx       int
+	decisions []decision
+	edits     map[int]string
+
+	viewport viewport.Model
+
+	ready  bool
+	width  int
+	height int
+
+	message string
+
+	submitted bool
+	canceled  bool
+}
+
+type keymap = struct {
+	accept,
+	reject,
+	edit,
+	acceptAll,
+	rejectFile,
+	prev,
+	next,
+	scrollUp,
+	scrollDown,
+	pageUp,
+	pageDown,
+	submit,
+	quit bubbleKey.Binding
+}
+
+type editorFinishedMsg struct {
+	idx     int
+	path    string
+	err     error
+	content string
+}
+
+func (m reviewUIModel) Init() tea.Cmd {
+	return nil
+}
+
+func initialModel(hunks []*hunk, editor string) *reviewUIModel {
+	return &reviewUIModel{
+		editor:    editor,
+		hunks:     hunks,
+		decisions: make([]decision, len(hunks)),
+		edits:     map[int]string{},
+		keymap: keymap{
+			accept: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("a", "y"),
+				bubbleKey.WithHelp("a", "accept"),
+			),
+			reject: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("r", "n"),
+				bubbleKey.WithHelp("r", "reject"),
+			),
+			edit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("e"),
+				bubbleKey.WithHelp("e", "edit"),
+			),
+			acceptAll: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("A"),
+				bubbleKey.WithHelp("A", "accept remaining"),
+			),
+			rejectFile: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("R"),
+				bubbleKey.WithHelp("R", "reject rest of file"),
+			),
+			prev: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("left", "h"),
+				bubbleKey.WithHelp("←", "prev"),
+			),
+			next: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("right", "l"),
+				bubbleKey.WithHelp("→", "next"),
+			),
+			scrollDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("j", "down"),
+				bubbleKey.WithHelp("j", "scroll down"),
+			),
+			scrollUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("k", "up"),
+				bubbleKey.WithHelp("k", "scroll up"),
+			),
+			pageDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("d", "pgdown"),
+				bubbleKey.WithHelp("d", "page down"),
+			),
+			pageUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("u", "pgup"),
+				bubbleKey.WithHelp("u", "page up"),
+			),
+			submit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("enter"),
+				bubbleKey.WithHelp("enter", "submit"),
+			),
+			quit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("q", "ctrl+c"),
+				bubbleKey.WithHelp("q", "quit without changes"),
+			),
+		},
+	}
+}
+
+func (m *reviewUIModel) currentNew() string {
+	if edited, ok := m.edits[m.idx]; ok {
+		return edited
+	}
+	return m.hunks[m.idx].new
+}
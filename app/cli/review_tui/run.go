@@ -0,0 +1,136 @@
+package reviewtui
+
+import (
+	"fmt"
+
+	shared "plandex-shared"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Result is what the user decided during review. Changes left undecided are kept.
+type Result struct {
+	Canceled bool
+
+	// individual replacements to reject
+	RejectedReplacementIds []string
+
+	// whole results (new or removed files) to reject
+	RejectedResultIds []string
+
+	Edits []*shared.ReplacementEdit
+
+	NumKept     int
+	NumRejected int
+	NumEdited   int
+}
+
+// StartReviewUI walks every pending change in the given paths (or all pending paths if none are given)
+func StartReviewUI(planState *shared.CurrentPlanState, paths []string, editor string) (*Result, error) {
+	hunks := getHunks(planState.PlanResult, paths)
+
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no pending changes to review")
+	}
+
+	initial := initialModel(hunks, editor)
+
+	p := tea.NewProgram(initial, tea.WithAltScreen())
+	m, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error running review UI: %v", err)
+	}
+
+	var mod *reviewUIModel
+	c, ok := m.(*reviewUIModel)
+	if ok {
+		mod = c
+	} else {
+		c := m.(reviewUIModel)
+		mod = &c
+	}
+
+	if mod.canceled || !mod.submitted {
+		return &Result{Canceled: true}, nil
+	}
+
+	res := &Result{}
+	for i, h := range mod.hunks {
+		if mod.decisions[i] == decisionRejected {
+			res.NumRejected++
+			if h.replacement == nil {
+				res.RejectedResultIds = append(res.RejectedResultIds, h.resultId)
+			} else {
+				res.RejectedReplacementIds = append(res.RejectedReplacementIds, h.replacement.Id)
+			}
+			continue
+		}
+
+		res.NumKept++
+		if edited, ok := mod.edits[i]; ok {
+			res.NumEdited++
+			res.Edits = append(res.Edits, &shared.ReplacementEdit{
+				ReplacementId: h.replacement.Id,
+				New:           edited,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func getHunks(planResult *shared.PlanResult, paths []string) []*hunk {
+	if len(paths) == 0 {
+		paths = planResult.SortedPaths
+	}
+
+	var hunks []*hunk
+	for _, path := range paths {
+		for _, result := range planResult.FileResultsByPath[path] {
+			if !result.IsPending() {
+				continue
+			}
+
+			if result.RemovedFile {
+				hunks = append(hunks, &hunk{
+					path:        path,
+					resultId:    result.Id,
+					removedFile: true,
+				})
+				continue
+			}
+
+			if len(result.Replacements) == 0 {
+				hunks = append(hunks, &hunk{
+					path:     path,
+					resultId: result.Id,
+					new:      result.Content,
+					summary:  "New file",
+				})
+				continue
+			}
+
+			for _, rep := range result.ActiveReplacements() {
+				old := rep.Old
+				new := rep.New
+				if result.ReplaceWithLineNums {
+					old = shared.RemoveLineNums(shared.LineNumberedTextType(old))
+					new = shared.RemoveLineNums(shared.LineNumberedTextType(new))
+				}
+
+				hunks = append(hunks, &hunk{
+					path:        path,
+					resultId:    result.Id,
+					replacement: rep,
+					old:         old,
+					new:         new,
+					summary:     rep.Summary,
+					// legacy results store line numbers in the replacement, so an edit can't be mapped back
+					editable: !result.ReplaceWithLineNums,
+				})
+			}
+		}
+	}
+
+	return hunks
+}
@@ -0,0 +1,193 @@
+package reviewtui
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m reviewUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.windowResized(msg.Width, msg.Height)
+
+	case editorFinishedMsg:
+		os.Remove(msg.path)
+
+		if msg.err != nil {
+			log.Println("Error running editor:", msg.err)
+			m.message = fmt.Sprintf("Error running editor: %v", msg.err)
+		} else if msg.content != m.hunks[msg.idx].new {
+			m.edits[msg.idx] = msg.content
+			m.decisions[msg.idx] = decisionAccepted
+			m.message = "Edited change"
+		} else {
+			delete(m.edits, msg.idx)
+			m.message = "No edits"
+		}
+		m.updateViewport()
+
+	case tea.KeyMsg:
+		m.message = ""
+
+		switch {
+		case bubbleKey.Matches(msg, m.keymap.quit):
+			m.canceled = true
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.submit):
+			m.submitted = true
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.accept):
+			m.decisions[m.idx] = decisionAccepted
+			m.advance()
+
+		case bubbleKey.Matches(msg, m.keymap.reject):
+			m.decisions[m.idx] = decisionRejected
+			delete(m.edits, m.idx)
+			m.advance()
+
+		case bubbleKey.Matches(msg, m.keymap.acceptAll):
+			for i := range m.decisions {
+				if m.decisions[i] == decisionUndecided {
+					m.decisions[i] = decisionAccepted
+				}
+			}
+			m.updateViewport()
+
+		case bubbleKey.Matches(msg, m.keymap.rejectFile):
+			path := m.hunks[m.idx].path
+			for i := m.idx; i < len(m.hunks) && m.hunks[i].path == path; i++ {
+				m.decisions[i] = decisionRejected
+				delete(m.edits, i)
+			}
+			m.advance()
+
+		case bubbleKey.Matches(msg, m.keymap.edit):
+			if !m.hunks[m.idx].editable {
+				m.message = "This change can't be edited—accept or reject it"
+				break
+			}
+			return m, m.openEditor()
+
+		case bubbleKey.Matches(msg, m.keymap.prev):
+			if m.idx > 0 {
+				m.idx--
+				m.updateViewport()
+			}
+
+		case bubbleKey.Matches(msg, m.keymap.next):
+			if m.idx < len(m.hunks)-1 {
+				m.idx++
+				m.updateViewport()
+			}
+
+		case bubbleKey.Matches(msg, m.keymap.scrollDown):
+			m.viewport.LineDown(1)
+
+		case bubbleKey.Matches(msg, m.keymap.scrollUp):
+			m.viewport.LineUp(1)
+
+		case bubbleKey.Matches(msg, m.keymap.pageDown):
+			m.viewport.ViewDown()
+
+		case bubbleKey.Matches(msg, m.keymap.pageUp):
+			m.viewport.ViewUp()
+		}
+	}
+
+	return m, nil
+}
+
+// advance moves to the next undecided hunk, wrapping around. It stays put once every hunk has a decision.
+func (m *reviewUIModel) advance() {
+	for i := 1; i <= len(m.hunks); i++ {
+		next := (m.idx + i) % len(m.hunks)
+		if m.decisions[next] == decisionUndecided {
+			m.idx = next
+			break
+		}
+	}
+	m.updateViewport()
+}
+
+func (m *reviewUIModel) openEditor() tea.Cmd {
+	h := m.hunks[m.idx]
+	idx := m.idx
+
+	f, err := os.CreateTemp("", "plandex-review-*"+filepath.Ext(h.path))
+	if err != nil {
+		m.message = fmt.Sprintf("Error creating temp file: %v", err)
+		return nil
+	}
+	_, err = f.WriteString(m.currentNew())
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		m.message = fmt.Sprintf("Error writing temp file: %v", err)
+		return nil
+	}
+
+	parts := strings.Fields(m.editor)
+	if len(parts) == 0 {
+		parts = []string{"vim"}
+	}
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		res := editorFinishedMsg{idx: idx, path: f.Name(), err: err}
+		if err == nil {
+			bytes, readErr := os.ReadFile(f.Name())
+			if readErr != nil {
+				res.err = readErr
+			} else {
+				res.content = string(bytes)
+				// most editors add a final newline on save
+				if !strings.HasSuffix(h.new, "\n") {
+					res.content = strings.TrimSuffix(res.content, "\n")
+				}
+			}
+		}
+		return res
+	})
+}
+
+func (m *reviewUIModel) windowResized(w, h int) {
+	m.width = w
+	m.height = h
+
+	headerHeight := lipgloss.Height(m.renderHeader())
+	helpHeight := lipgloss.Height(m.renderHelp())
+	viewportHeight := h - headerHeight - helpHeight
+	if viewportHeight < 1 {
+		viewportHeight = 1
+	}
+
+	if !m.ready {
+		m.viewport = viewport.New(w, viewportHeight)
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = viewportHeight
+	}
+
+	m.updateViewport()
+}
+
+func (m *reviewUIModel) updateViewport() {
+	if !m.ready {
+		return
+	}
+	m.viewport.SetContent(m.renderHunk())
+	m.viewport.GotoTop()
+}
@@ -0,0 +1,138 @@
+package reviewtui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+var borderColor = lipgloss.Color("#444")
+var helpTextColor = lipgloss.Color("#ddd")
+
+// unchanged lines shown around each change
+const numContextLines = 3
+
+func (m reviewUIModel) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.renderHeader(), m.viewport.View(), m.renderHelp())
+}
+
+func (m reviewUIModel) renderHeader() string {
+	h := m.hunks[m.idx]
+
+	var status string
+	switch m.decisions[m.idx] {
+	case decisionAccepted:
+		status = color.New(color.Bold, color.FgGreen).Sprint("✅ accepted")
+	case decisionRejected:
+		status = color.New(color.Bold, color.FgRed).Sprint("🚫 rejected")
+	default:
+		status = color.New(color.FgHiBlack).Sprint("undecided")
+	}
+	if _, ok := m.edits[m.idx]; ok {
+		status += color.New(color.FgHiYellow).Sprint(" ✏️  edited")
+	}
+
+	numAccepted, numRejected := m.counts()
+
+	lines := []string{
+		fmt.Sprintf(" %s %s  %s  %s",
+			color.New(color.Bold, color.FgHiCyan).Sprintf("📄 %s", h.path),
+			color.New(color.FgHiBlack).Sprintf("change %d/%d", m.idx+1, len(m.hunks)),
+			status,
+			color.New(color.FgHiBlack).Sprintf("(%d accepted • %d rejected • %d left)", numAccepted, numRejected, len(m.hunks)-numAccepted-numRejected),
+		),
+	}
+	// always two lines so the viewport height doesn't shift between hunks
+	summary, _, _ := strings.Cut(h.summary, "\n")
+	lines = append(lines, " "+color.New(color.Italic).Sprint(summary))
+
+	style := lipgloss.NewStyle().Width(m.width).BorderStyle(lipgloss.NormalBorder()).BorderBottom(true).BorderForeground(borderColor)
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+func (m reviewUIModel) renderHelp() string {
+	style := lipgloss.NewStyle().Width(m.width).Foreground(helpTextColor).BorderStyle(lipgloss.NormalBorder()).BorderTop(true).BorderForeground(borderColor)
+
+	s := " (a)ccept • (r)eject • (e)dit • (A)ccept remaining • (R)eject rest of file • (←/→) prev/next • (j/k) scroll • (enter) submit • (q)uit"
+	return style.Render(" " + m.message + "\n" + s)
+}
+
+func (m reviewUIModel) renderHunk() string {
+	h := m.hunks[m.idx]
+
+	if h.removedFile {
+		return color.New(color.Bold, color.FgRed).Sprint("\n  File will be removed\n")
+	}
+
+	oldLines := splitLines(h.old)
+	newLines := splitLines(m.currentNew())
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix && oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	var b strings.Builder
+	contextColor := color.New(color.FgHiBlack)
+	removedColor := color.New(color.FgRed)
+	addedColor := color.New(color.FgGreen)
+
+	start := prefix - numContextLines
+	if start < 0 {
+		start = 0
+	}
+	if start > 0 {
+		b.WriteString(contextColor.Sprint("  ⋮") + "\n")
+	}
+	for _, line := range oldLines[start:prefix] {
+		b.WriteString(contextColor.Sprint("  "+line) + "\n")
+	}
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		b.WriteString(removedColor.Sprint("- "+line) + "\n")
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		b.WriteString(addedColor.Sprint("+ "+line) + "\n")
+	}
+	end := len(oldLines) - suffix + numContextLines
+	if end > len(oldLines) {
+		end = len(oldLines)
+	}
+	for _, line := range oldLines[len(oldLines)-suffix : end] {
+		b.WriteString(contextColor.Sprint("  "+line) + "\n")
+	}
+	if end < len(oldLines) {
+		b.WriteString(contextColor.Sprint("  ⋮") + "\n")
+	}
+
+	return b.String()
+}
+
+func (m reviewUIModel) counts() (int, int) {
+	numAccepted, numRejected := 0, 0
+	for _, d := range m.decisions {
+		switch d {
+		case decisionAccepted:
+			numAccepted++
+		case decisionRejected:
+			numRejected++
+		}
+	}
+	return numAccepted, numRejected
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
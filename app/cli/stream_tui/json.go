@@ -0,0 +1,112 @@
+package streamtui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	shared "plandex-shared"
+)
+
+// messages are buffered so that anything received before runJsonStream starts isn't lost
+var jsonStreamCh = make(chan shared.StreamMessage, 256)
+
+// runJsonStream is the headless stand-in for the stream UI when --json or --ndjson is set. It writes the raw stream messages to stdout and answers anything the UI would have asked the user with the same choice auto-context makes.
+func runJsonStream() error {
+	var messages []shared.StreamMessage
+
+	emit := func(msg shared.StreamMessage) {
+		if term.IsNdjsonOutput() {
+			term.OutputJson(msg)
+		} else {
+			messages = append(messages, msg)
+		}
+	}
+
+	flush := func() {
+		if !term.IsNdjsonOutput() {
+			term.OutputJson(term.JsonStream{
+				Type:     "stream",
+				Messages: messages,
+			})
+		}
+	}
+
+	onErr := func(apiErr *shared.ApiError) {
+		msg := shared.StreamMessage{Type: shared.StreamMessageError, Error: apiErr}
+		emit(msg)
+		flush()
+		os.Exit(1)
+	}
+
+	for msg := range jsonStreamCh {
+		msgs := []shared.StreamMessage{msg}
+		if msg.Type == shared.StreamMessageMulti {
+			msgs = msg.StreamMessages
+		}
+
+		for _, msg := range msgs {
+			switch msg.Type {
+			case shared.StreamMessageError:
+				onErr(msg.Error)
+
+			case shared.StreamMessagePromptMissingFile:
+				emit(msg)
+				apiErr := respondMissingFileJson(msg.MissingFilePath)
+				if apiErr != nil {
+					onErr(apiErr)
+				}
+
+			case shared.StreamMessageLoadContext:
+				emit(msg)
+				_, err := lib.AutoLoadContextFiles(context.Background(), msg.LoadContextFiles)
+				if err != nil {
+					log.Println("failed to auto load context files:", err)
+					onErr(&shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: err.Error()})
+				}
+
+			case shared.StreamMessageCodeSearch:
+				emit(msg)
+				if msg.CodeSearch != nil {
+					err := lib.RespondCodeSearch(msg.CodeSearch)
+					if err != nil {
+						log.Println("failed to send code search results:", err)
+					}
+				}
+
+			case shared.StreamMessageAborted:
+				emit(msg)
+				flush()
+				os.Exit(0)
+
+			case shared.StreamMessageFinished:
+				emit(msg)
+				flush()
+				return nil
+
+			default:
+				emit(msg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// respondMissingFileJson loads the file into context, which is what happens without prompting when auto-context is on
+func respondMissingFileJson(path string) *shared.ApiError {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("failed to read file: %v", err)}
+	}
+
+	return api.Client.RespondMissingFile(lib.CurrentPlanId, lib.CurrentBranch, shared.RespondMissingFileRequest{
+		Choice:   shared.RespondMissingFileChoiceLoad,
+		FilePath: path,
+		Body:     string(shared.NormalizeEOL(bytes)),
+	})
+}
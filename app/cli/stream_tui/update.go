@@ -71,6 +71,13 @@ func (m streamUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateReplyDisplay()
 		return m, m.Tick()
 
+	case codeSearchDoneMsg:
+		// if the results couldn't be sent, the server times out waiting for them and the model continues without them
+		if msg.err != nil {
+			log.Println("failed to send code search results:", msg.err)
+		}
+		return m, nil
+
 	case delayFileRestartMsg:
 		m.updateState(func() {
 			m.finishedByPath[msg.path] = false
@@ -429,6 +436,34 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			m.updateReplyDisplay()
 		}
 
+	case shared.StreamMessageToolCall:
+		if msg.ToolCall == nil {
+			return m, nil
+		}
+		call := msg.ToolCall
+		name := call.Tool
+		if call.Server != "" {
+			name = call.Server + "/" + call.Tool
+		}
+
+		m.updateState(func() {
+			m.starting = false
+			m.processing = false
+			if call.Finished {
+				if call.IsError {
+					m.reply += fmt.Sprintf("\n❌ `%s` failed\n\n", name)
+				} else {
+					m.reply += fmt.Sprintf("\n✅ `%s` done (%s)\n\n", name, call.FinishedAt.Sub(call.StartedAt).Round(time.Millisecond))
+				}
+			} else {
+				m.reply += fmt.Sprintf("\n\n🔧 Calling `%s`…", name)
+			}
+		})
+
+		if !deferUIUpdate {
+			m.updateReplyDisplay()
+		}
+
 	case shared.StreamMessageBuildInfo:
 		state := m.readState()
 
@@ -507,6 +542,12 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			}),
 		)
 
+	case shared.StreamMessageCodeSearch:
+		if msg.CodeSearch == nil {
+			return m, nil
+		}
+		return m, codeSearchCmd(msg.CodeSearch)
+
 	case shared.StreamMessageError:
 		log.Println("Stream message error:", spew.Sdump(msg))
 
@@ -740,6 +781,17 @@ func (m *streamUIModel) checkMissingFile(msg *shared.StreamMessage) (tea.Model,
 	return m, nil
 }
 
+// codeSearchDoneMsg is sent when a code search requested by the server has finished and its results have been sent
+type codeSearchDoneMsg struct {
+	err error
+}
+
+func codeSearchCmd(req *shared.CodeSearchRequest) tea.Cmd {
+	return func() tea.Msg {
+		return codeSearchDoneMsg{err: lib.RespondCodeSearch(req)}
+	}
+}
+
 // contextLoadDoneMsg is sent when the long-running AutoLoadContextFiles completes
 type contextLoadDoneMsg struct {
 	text string
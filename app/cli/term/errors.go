@@ -35,6 +35,10 @@ func OutputErrorAndExit(msg string, args ...interface{}) {
 
 	msg = fmt.Sprintf(msg, args...)
 
+	if IsJsonOutput() {
+		OutputJsonErrorAndExit(&shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: msg})
+	}
+
 	msg = strings.ReplaceAll(msg, "status code:", "status code")
 	msg = strings.ReplaceAll(msg, ", body:", ":")
 
@@ -106,11 +110,19 @@ func OutputErrorAndExit(msg string, args ...interface{}) {
 
 func OutputUnformattedErrorAndExit(msg string) {
 	StopSpinner()
+
+	if IsJsonOutput() {
+		OutputJsonErrorAndExit(&shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: msg})
+	}
 	fmt.Fprintln(os.Stderr, msg)
 	os.Exit(1)
 }
 
 func OutputNoCurrentPlanErrorAndExit() {
+	if IsJsonOutput() {
+		OutputJsonErrorAndExit(&shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: "no current plan"})
+	}
+
 	fmt.Println("🤷‍♂️ No current plan")
 	fmt.Println()
 	PrintCmds("", "new", "cd")
@@ -118,6 +130,10 @@ func OutputNoCurrentPlanErrorAndExit() {
 }
 
 func HandleApiError(apiError *shared.ApiError) {
+	if IsJsonOutput() {
+		OutputJsonErrorAndExit(apiError)
+	}
+
 	if apiError.Type == shared.ApiErrorTypeCloudSubscriptionPaused {
 		if apiError.BillingError.HasBillingPermission {
 			StopSpinner()
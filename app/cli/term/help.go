@@ -48,6 +48,10 @@ var CliCommands = []CmdConfig{
 	{"new --r1-planner", "", fmt.Sprintf("start a new plan with %s model pack", "'r1-planner'"), true},
 	{"new --perplexity-planner", "", fmt.Sprintf("start a new plan with %s model pack", "'perplexity-planner'"), true},
 	{"new --opus-planner", "", fmt.Sprintf("start a new plan with %s model pack", "'opus-planner'"), true},
+	{"new --template", "", "start a new plan from a plan template", true},
+	{"templates", "", "list your org's plan templates", true},
+	{"templates create", "", "create a reusable plan template with prompt variables", true},
+	{"templates rm", "", "remove a plan template", true},
 
 	{"plans", "pl", "list plans", true},
 	{"cd", "", "set current plan by name or index", true},
@@ -81,10 +85,12 @@ var CliCommands = []CmdConfig{
 
 	{"diff --ui", "", "review pending changes in a browser UI", true},
 	{"diff", "", "review pending changes in 'git diff' format", true},
+	{"review", "rv", "review pending changes one at a time—accept, reject, or edit each", true},
 	{"diff --plain", "", "review pending changes in 'git diff' format with no color formatting", false},
 	{"summary", "", "show the latest summary of the current plan", true},
 
 	{"apply", "ap", "apply pending changes to project files", true},
+	{"apply --recover", "", "roll back or finish an interrupted apply", false},
 	{"reject", "rj", "reject pending changes to one or more project files", true},
 
 	{"log", "", "show log of plan updates", true},
@@ -99,14 +105,23 @@ var CliCommands = []CmdConfig{
 	{"convo 2-5", "", "show a range of messages in the conversation", false},
 	{"convo --plain", "", "show conversation in plain text", false},
 
+	{"roots", "", "list the other repositories in a multi-repo project", true},
+	{"roots add", "", "add another repository to the project", true},
+	{"roots rm", "", "remove a repository from the project", true},
+
 	{"branches", "br", "list plan branches", true},
 	{"checkout", "co", "checkout or create a branch", true},
 	{"delete-branch", "dlb", "delete a branch by name or index", true},
+	{"merge", "", "merge another branch into the current branch", true},
+	{"cherry-pick", "cp", "apply a single update from another branch", true},
 
 	{"plans --archived", "", "list archived plans", true},
 	{"archive", "arc", "archive a plan", true},
 	{"unarchive", "unarc", "unarchive a plan", true},
 
+	{"export", "", "export a plan to a portable archive", true},
+	{"import", "", "import a plan from an archive", true},
+
 	{"models", "", "show current plan model settings", true},
 	{"models default", "", "show the default model settings for new plans", true},
 
@@ -114,6 +129,7 @@ var CliCommands = []CmdConfig{
 	{"models available --custom", "", "show available custom models only", true},
 
 	{"models custom", "", "manage custom models, providers, and model packs", true},
+	{"models discover", "", "add models from a local Ollama or OpenAI-compatible server", true},
 
 	{"providers", "", "show all available model providers", true},
 	{"providers --custom", "", "show available custom model providers only", true},
@@ -137,25 +153,59 @@ var CliCommands = []CmdConfig{
 	{"set-model perplexity-planner", "", fmt.Sprintf("Use %s model pack", "'perplexity-planner'"), true},
 	{"set-model opus-planner", "", fmt.Sprintf("Use %s model pack", "'opus-planner'"), true},
 
+	{"mcp", "", "list MCP servers whose tools plans can call", true},
+	{"mcp add", "", "add an MCP server (stdio command or http url)", true},
+	{"mcp tools", "", "connect to an MCP server and list its tools", true},
+	{"mcp disable", "", "disable an MCP server without removing it", true},
+	{"mcp rm", "", "remove an MCP server", true},
+	{"webhooks", "", "list webhooks for plan lifecycle events", true},
+	{"webhooks add", "", "add a signed webhook for some or all events", true},
+	{"webhooks test", "", "send a test event to a webhook", true},
+	{"webhooks log", "", "show recent webhook deliveries and failures", true},
+	{"webhooks disable", "", "stop sending events to a webhook without removing it", true},
+	{"webhooks rm", "", "remove a webhook", true},
+
 	{"ps", "", "list active and recently finished plan streams", true},
 	{"stop", "", "stop an active plan stream", true},
 	{"connect", "conn", "connect to an active plan stream", true},
+	{"jobs", "", "list server-side jobs for the current plan", true},
+	{"jobs add", "", "queue a prompt to run on the server, once or on a cron schedule", true},
+	{"jobs rm", "", "remove a queued or scheduled job", true},
 
 	{"sign-in", "", "sign in, accept an invite, or create an account", true},
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
+	{"users set-role", "", "change a user's org role", true},
+	{"roles", "", "list org roles and their permissions", true},
+	{"roles create", "", "create a custom org role from permissions", true},
+	{"roles update", "", "update a custom org role", true},
+	{"roles delete", "", "delete a custom org role", true},
+	{"share", "", "share the current plan with a user in your org", true},
+	{"shares", "", "list who the current plan is shared with", true},
+	{"unshare", "", "stop sharing the current plan with a user", true},
+	{"tokens", "", "list API tokens for CI and scripts", true},
+	{"tokens create", "", "create an API token—use it with PLANDEX_TOKEN", true},
+	{"tokens revoke", "", "revoke an API token", true},
+	{"service-accounts", "", "list your org's service accounts", true},
+	{"service-accounts create", "", "create a service account for CI", true},
+	{"service-accounts rm", "", "remove a service account and revoke its tokens", true},
+	{"audit", "", "show the org's audit log of who changed what", true},
+	{"audit export", "", "export the audit log as JSONL", true},
 
 	{"connect-claude", "", "connect your Claude Pro or Max subscription", true},
 	{"disconnect-claude", "", "disconnect your Claude Pro or Max subscription", true},
 	{"claude-status", "", "status of your Claude Pro or Max subscription connection", true},
 
-	{"usage", "", "show Plandex Cloud current balance and usage report", true},
-	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
-	{"usage --month", "", "show Plandex Cloud usage for the current billing month", true},
-	{"usage --plan", "", "show Plandex Cloud usage for the current plan", true},
+	{"usage", "", "show usage report (and current balance on Plandex Cloud)", true},
+	{"usage --today", "", "show usage for the day so far", true},
+	{"usage --month", "", "show usage for the current (billing) month", true},
+	{"usage --plan", "", "show usage for the current plan", true},
 
-	{"usage --log", "", "show Plandex Cloud transaction log", true},
+	{"usage --log", "", "show usage log (transaction log on Plandex Cloud)", true},
+	{"usage prices", "", "show per-model prices used by the self-hosted usage ledger", true},
+	{"usage set-price", "", "override a model's price for the self-hosted usage ledger", true},
+	{"usage reset-price", "", "remove a model price override", true},
 
 	{"billing", "", "show Plandex Cloud billing settings", true},
 }
@@ -301,19 +351,19 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "new --template", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "export", "import", "templates", "templates create")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "apply", "reject")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "review", "apply", "reject")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Context ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear", "roots")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Branches ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch", "merge", "cherry-pick")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " History ")
@@ -325,7 +375,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Streams ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "ps", "connect", "stop")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "ps", "connect", "stop", "jobs", "jobs add")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Config ")
@@ -342,19 +392,19 @@ func PrintHelpAllCommands() {
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Custom Models ")
 	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan},
-		"models custom", "models available", "models available --custom", "providers", "providers --custom", "model-packs", "model-packs --custom", "model-packs show")
+		"models custom", "models discover", "models available", "models available --custom", "providers", "providers --custom", "model-packs", "model-packs --custom", "model-packs show")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "users set-role", "roles", "share", "shares", "tokens", "tokens create", "service-accounts", "audit")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Integrations ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "connect-claude", "disconnect-claude", "claude-status")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "connect-claude", "disconnect-claude", "claude-status", "mcp", "mcp add", "mcp tools", "webhooks", "webhooks add", "webhooks log")
 	fmt.Fprintln(builder)
 
-	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "billing")
+	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Usage ")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "usage prices", "usage set-price", "billing")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " New Plan Shortcuts ")
@@ -0,0 +1,117 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+type OutputFormat string
+
+const (
+	OutputFormatText   OutputFormat = ""
+	OutputFormatJson   OutputFormat = "json"
+	OutputFormatNdjson OutputFormat = "ndjson"
+)
+
+var outputFormat = OutputFormatText
+
+// structured output always goes to the real stdout—everything meant for humans is redirected to stderr
+var jsonOut io.Writer = os.Stdout
+var jsonMu sync.Mutex
+
+// JsonResult wraps the structured output of a command that isn't a stream
+type JsonResult struct {
+	Type    string      `json:"type"`
+	Command string      `json:"command"`
+	Result  interface{} `json:"result"`
+}
+
+// JsonStream is what --json prints for a stream—the full sequence of messages once it ends. --ndjson prints each message as it arrives instead.
+type JsonStream struct {
+	Type     string                 `json:"type"`
+	Messages []shared.StreamMessage `json:"messages"`
+}
+
+// SetOutputFormat switches to structured output. Human-readable output, including colors, tables, and suggested commands, is sent to stderr so that stdout only ever has JSON on it. Must be called before anything is printed.
+func SetOutputFormat(format OutputFormat) {
+	if format == OutputFormatText {
+		return
+	}
+
+	if IsJsonOutput() {
+		outputFormat = format
+		return
+	}
+
+	outputFormat = format
+
+	jsonOut = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	color.NoColor = true
+}
+
+func IsJsonOutput() bool {
+	return outputFormat != OutputFormatText
+}
+
+func IsNdjsonOutput() bool {
+	return outputFormat == OutputFormatNdjson
+}
+
+// OutputJson writes a value to stdout—indented for --json, on a single line for --ndjson
+func OutputJson(v interface{}) {
+	var bytes []byte
+	var err error
+	if outputFormat == OutputFormatNdjson {
+		bytes, err = json.Marshal(v)
+	} else {
+		bytes, err = json.MarshalIndent(v, "", "  ")
+	}
+
+	if err != nil {
+		// can't use OutputErrorAndExit here since it would call back into OutputJson
+		fmt.Fprintf(os.Stderr, "error marshalling json output: %v\n", err)
+		os.Exit(1)
+	}
+
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	jsonOut.Write(append(bytes, '\n'))
+}
+
+func OutputJsonResult(command string, result interface{}) {
+	OutputJson(JsonResult{
+		Type:    "result",
+		Command: command,
+		Result:  result,
+	})
+}
+
+// OutputJsonErrorAndExit writes an error in the same shape as a stream error message so consumers only have to handle one kind of error
+func OutputJsonErrorAndExit(apiErr *shared.ApiError) {
+	OutputJson(shared.StreamMessage{
+		Type:  shared.StreamMessageError,
+		Error: apiErr,
+	})
+	os.Exit(1)
+}
+
+// mustNotPrompt exits with an error in json mode, where there's no one to answer a prompt
+func mustNotPrompt(msg string, args ...interface{}) {
+	if !IsJsonOutput() {
+		return
+	}
+
+	OutputJsonErrorAndExit(&shared.ApiError{
+		Type: shared.ApiErrorTypeOther,
+		Msg:  fmt.Sprintf("input is required but prompts are disabled with --json/--ndjson: "+msg, args...),
+	})
+}
@@ -29,6 +29,8 @@ func GetUserStringInput(msg string) (string, error) {
 }
 
 func GetUserStringInputWithDefault(msg, def string) (string, error) {
+	mustNotPrompt(msg)
+
 	disableBracketedPaste()
 	defer enableBracketedPaste()
 
@@ -56,6 +58,8 @@ func GetRequiredUserStringInputWithDefault(msg, def string) (string, error) {
 }
 
 func GetUserPasswordInput(msg string) (string, error) {
+	mustNotPrompt(msg)
+
 	disableBracketedPaste()
 	defer enableBracketedPaste()
 
@@ -69,6 +73,8 @@ func GetUserPasswordInput(msg string) (string, error) {
 }
 
 func GetUserKeyInput() (rune, keyboard.Key, error) {
+	mustNotPrompt("waiting for a keypress")
+
 	if err := keyboard.Open(); err != nil {
 		return 0, 0, fmt.Errorf("failed to open keyboard: %s", err)
 	}
@@ -85,6 +91,8 @@ func GetUserKeyInput() (rune, keyboard.Key, error) {
 }
 
 func ConfirmYesNo(fmtStr string, fmtArgs ...interface{}) (bool, error) {
+	mustNotPrompt(fmtStr, fmtArgs...)
+
 	color.New(ColorHiMagenta, color.Bold).Printf(fmtStr+" (y)es | (n)o", fmtArgs...)
 	color.New(ColorHiMagenta, color.Bold).Print("> ")
 
@@ -111,6 +119,8 @@ func ConfirmYesNo(fmtStr string, fmtArgs ...interface{}) (bool, error) {
 }
 
 func ConfirmYesNoCancel(fmtStr string, fmtArgs ...interface{}) (bool, bool, error) {
+	mustNotPrompt(fmtStr, fmtArgs...)
+
 	color.New(ColorHiMagenta, color.Bold).Printf(fmtStr+" (y)es | (n)o | (c)ancel", fmtArgs...)
 	color.New(ColorHiMagenta, color.Bold).Print("> ")
 
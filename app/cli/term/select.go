@@ -9,6 +9,8 @@ import (
 )
 
 func SelectFromList(msg string, options []string) (string, error) {
+	mustNotPrompt(msg)
+
 	var selected string
 	prompt := &survey.Select{
 		Message:       color.New(ColorHiMagenta, color.Bold).Sprint(msg),
@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"io"
 	shared "plandex-shared"
 
 	"github.com/shopspring/decimal"
@@ -24,6 +25,8 @@ type ApiClient interface {
 
 	CreateAccount(req shared.CreateAccountRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
 	SignIn(req shared.SignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
+	GetOidcConfig(customHost string) (*shared.OidcConfigResponse, *shared.ApiError)
+	SignInWithOidc(req shared.OidcSignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
 
 	SignOut() *shared.ApiError
 
@@ -38,6 +41,11 @@ type ApiClient interface {
 	DeleteUser(userId string) *shared.ApiError
 
 	ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError)
+	CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	DeleteOrgRole(roleId string) *shared.ApiError
+	SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError
+	ListPermissions() (shared.Permissions, *shared.ApiError)
 
 	InviteUser(req shared.InviteRequest) *shared.ApiError
 	ListPendingInvites() ([]*shared.Invite, *shared.ApiError)
@@ -62,6 +70,7 @@ type ApiClient interface {
 	TellPlan(planId, branch string, req shared.TellPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	BuildPlan(planId, branch string, req shared.BuildPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	RespondMissingFile(planId, branch string, req shared.RespondMissingFileRequest) *shared.ApiError
+	RespondCodeSearch(planId, branch string, req shared.RespondCodeSearchRequest) *shared.ApiError
 
 	DeletePlan(planId string) *shared.ApiError
 	DeleteAllPlans(projectId string) *shared.ApiError
@@ -71,6 +80,8 @@ type ApiClient interface {
 	ArchivePlan(planId string) *shared.ApiError
 	UnarchivePlan(planId string) *shared.ApiError
 	RenamePlan(planId string, name string) *shared.ApiError
+	ExportPlan(planId string, w io.Writer) *shared.ApiError
+	ImportPlan(projectId, name string, archive io.ReadSeeker) (*shared.CreatePlanResponse, *shared.ApiError)
 
 	GetCurrentPlanState(planId, branch string) (*shared.CurrentPlanState, *shared.ApiError)
 	GetCurrentPlanStateAtSha(planId, sha string) (*shared.CurrentPlanState, *shared.ApiError)
@@ -78,6 +89,8 @@ type ApiClient interface {
 	RejectAllChanges(planId, branch string) *shared.ApiError
 	RejectFile(planId, branch, filePath string) *shared.ApiError
 	RejectFiles(planId, branch string, paths []string) *shared.ApiError
+	RejectReplacements(planId, branch string, replacementIds []string) *shared.ApiError
+	EditReplacements(planId, branch string, edits []*shared.ReplacementEdit) *shared.ApiError
 	GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError)
 
 	LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
@@ -94,6 +107,7 @@ type ApiClient interface {
 	ListBranches(planId string) ([]*shared.Branch, *shared.ApiError)
 	DeleteBranch(planId, branch string) *shared.ApiError
 	CreateBranch(planId, branch string, req shared.CreateBranchRequest) *shared.ApiError
+	MergeBranch(planId, branch string, req shared.MergeBranchRequest) (*shared.MergeBranchResponse, *shared.ApiError)
 
 	GetSettings(planId, branch string) (*shared.PlanSettings, *shared.ApiError)
 	UpdateSettings(planId, branch string, req shared.UpdateSettingsRequest) (*shared.UpdateSettingsResponse, *shared.ApiError)
@@ -117,6 +131,48 @@ type ApiClient interface {
 	GetCreditsSummary(req shared.CreditsLogRequest) (*shared.CreditsSummaryResponse, *shared.ApiError)
 	GetBalance() (decimal.Decimal, *shared.ApiError)
 
+	ListModelPrices() ([]*shared.ModelPrice, *shared.ApiError)
+	SetModelPrice(req shared.SetModelPriceRequest) *shared.ApiError
+	DeleteModelPrice(modelId shared.ModelId) *shared.ApiError
+
+	ListMcpServers(projectId string) ([]*shared.McpServer, *shared.ApiError)
+	UpsertMcpServer(projectId string, req shared.UpsertMcpServerRequest) (*shared.McpServer, *shared.ApiError)
+	SetMcpServerEnabled(projectId, name string, enabled bool) *shared.ApiError
+	DeleteMcpServer(projectId, name string) *shared.ApiError
+	ListMcpServerTools(projectId, name string) ([]*shared.McpToolInfo, *shared.ApiError)
+
+	ListWebhooks() ([]*shared.Webhook, *shared.ApiError)
+	CreateWebhook(req shared.CreateWebhookRequest) (*shared.Webhook, *shared.ApiError)
+	SetWebhookEnabled(webhookId string, enabled bool) *shared.ApiError
+	DeleteWebhook(webhookId string) *shared.ApiError
+	TestWebhook(webhookId string) *shared.ApiError
+	ListWebhookDeliveries(webhookId string, limit int) ([]*shared.WebhookDelivery, *shared.ApiError)
+	ReportPlanEvent(planId, branch string, req shared.ReportPlanEventRequest) *shared.ApiError
+
+	ListAuditEvents(req shared.ListAuditEventsRequest) (*shared.ListAuditEventsResponse, *shared.ApiError)
+	ReportExecutedCommands(planId, branch string, req shared.ReportExecutedCommandsRequest) *shared.ApiError
+
+	ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError)
+	GetPlanTemplate(name string) (*shared.PlanTemplate, *shared.ApiError)
+	CreatePlanTemplate(req shared.CreatePlanTemplateRequest) (*shared.PlanTemplate, *shared.ApiError)
+	DeletePlanTemplate(name string) *shared.ApiError
+
+	CreatePlanJob(planId, branch string, req shared.CreatePlanJobRequest) (*shared.PlanJob, *shared.ApiError)
+	ListPlanJobs(planId string) ([]*shared.PlanJob, *shared.ApiError)
+	DeletePlanJob(jobId string) *shared.ApiError
+
+	ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError)
+	SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError)
+	UnsharePlan(planId, userId string) *shared.ApiError
+
+	GetApiTokenSession() (*shared.ApiTokenSessionResponse, *shared.ApiError)
+	ListApiTokens() ([]*shared.ApiToken, *shared.ApiError)
+	CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError)
+	RevokeApiToken(tokenId string) *shared.ApiError
+	ListServiceAccounts() ([]*shared.ServiceAccount, *shared.ApiError)
+	CreateServiceAccount(req shared.CreateServiceAccountRequest) (*shared.ServiceAccount, *shared.ApiError)
+	DeleteServiceAccount(userId string) *shared.ApiError
+
 	GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError)
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
@@ -2,6 +2,7 @@ package types
 
 import (
 	"os"
+	"time"
 )
 
 type ApplyFlags struct {
@@ -13,6 +14,14 @@ type ApplyFlags struct {
 	AutoDebug   int
 }
 
+// ApplyJsonResult is the result of 'apply' with --json/--ndjson
+type ApplyJsonResult struct {
+	UpdatedFiles  []string `json:"updatedFiles"`
+	CommitSummary string   `json:"commitSummary,omitempty"`
+	Committed     bool     `json:"committed"`
+	CommitError   string   `json:"commitError,omitempty"`
+}
+
 type ApplyRollbackOption string
 
 const (
@@ -23,8 +32,8 @@ const (
 type OnApplyExecFailFn func(status int, output string, attempt int, toRollback *ApplyRollbackPlan, onErr OnErrFn, onSuccess func())
 
 type ApplyReversion struct {
-	Content string
-	Mode    os.FileMode
+	Content string      `json:"content"`
+	Mode    os.FileMode `json:"mode"`
 }
 
 type ApplyRollbackPlan struct {
@@ -36,3 +45,42 @@ type ApplyRollbackPlan struct {
 func (r *ApplyRollbackPlan) HasChanges() bool {
 	return len(r.ToRevert) > 0 || len(r.ToRemove) > 0
 }
+
+type ApplyJournalStatus string
+
+const (
+	ApplyJournalStatusWriting   ApplyJournalStatus = "writing"
+	ApplyJournalStatusExecuting ApplyJournalStatus = "executing"
+)
+
+// ApplyJournal is written to the project's plandex dir before an apply touches any files, and removed once the apply finishes or is rolled back. If it's still there, the apply was interrupted.
+type ApplyJournal struct {
+	PlanId    string             `json:"planId"`
+	Branch    string             `json:"branch"`
+	Status    ApplyJournalStatus `json:"status"`
+	StartedAt time.Time          `json:"startedAt"`
+
+	// what the apply writes and removes, by project-relative path, so that it can be completed
+	ToWrite  map[string]string `json:"toWrite"`
+	ToDelete []string          `json:"toDelete"`
+
+	UpdatedFiles []string `json:"updatedFiles"`
+
+	// what's needed to roll it back—same as ApplyRollbackPlan
+	ToRevert      map[string]ApplyReversion `json:"toRevert"`
+	ToRemove      []string                  `json:"toRemove"`
+	PreviousPaths []string                  `json:"previousPaths"`
+}
+
+func (j *ApplyJournal) RollbackPlan() *ApplyRollbackPlan {
+	allPaths := make(map[string]bool, len(j.PreviousPaths))
+	for _, path := range j.PreviousPaths {
+		allPaths[path] = true
+	}
+
+	return &ApplyRollbackPlan{
+		ToRevert:             j.ToRevert,
+		ToRemove:             j.ToRemove,
+		PreviousProjectPaths: &ProjectPaths{AllPaths: allPaths},
+	}
+}
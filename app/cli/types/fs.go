@@ -11,3 +11,16 @@ type ProjectPaths struct {
 	IgnoredPaths   map[string]string
 	GitIgnoredDirs map[string]bool
 }
+
+// RepoRoot is an additional repository that's part of a multi-repo project. Paths inside it are referred to in plans as '@<alias>/<path>'.
+type RepoRoot struct {
+	Alias string `json:"alias"`
+	// relative to the project root, or absolute
+	Path string `json:"path"`
+
+	AbsPath string `json:"-"`
+}
+
+type RepoRootsConfig struct {
+	Roots []*RepoRoot `json:"roots"`
+}
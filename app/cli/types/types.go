@@ -17,6 +17,7 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	GitDiff           *shared.GitDiffSource
 }
 
 type ContextOutdatedResult struct {
@@ -28,6 +29,7 @@ type ContextOutdatedResult struct {
 	NumUrls         int
 	NumTrees        int
 	NumMaps         int
+	NumDiffs        int
 	NumFilesRemoved int
 	NumTreesRemoved int
 	ReqFn           func() (map[string]*shared.UpdateContextParams, error)
@@ -0,0 +1,21 @@
+#!/usr/bin/env bash
+
+# Downloads the BPE tables embedded by shared/tokenizers.go so release builds can count tokens offline
+
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+DATA_DIR="${TOKENIZER_DATA_DIR:-$SCRIPT_DIR/../shared/tokenizer_data}"
+BASE_URL="https://openaipublic.blob.core.windows.net/encodings"
+
+mkdir -p "$DATA_DIR"
+
+for encoding in o200k_base cl100k_base; do
+  if [ -f "$DATA_DIR/$encoding.tiktoken" ]; then
+    echo "$encoding.tiktoken already present"
+    continue
+  fi
+  echo "Downloading $encoding.tiktoken..."
+  curl -sSfL "$BASE_URL/$encoding.tiktoken" -o "$DATA_DIR/$encoding.tiktoken.tmp"
+  mv "$DATA_DIR/$encoding.tiktoken.tmp" "$DATA_DIR/$encoding.tiktoken"
+done
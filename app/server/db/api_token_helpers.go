@@ -0,0 +1,185 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// characters of the token kept so it can be recognized in lists
+const apiTokenDisplayPrefixLen = 12
+
+// last used is only updated this often so tokens used in a loop don't write on every request
+const apiTokenLastUsedInterval = time.Minute
+
+// service accounts get an address on a reserved domain so they can't receive sign-in pins or match an org's email domain
+const serviceAccountEmailDomain = "service-accounts.invalid"
+
+var ErrInvalidApiToken = errors.New("invalid api token")
+
+func hashApiToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
+
+// CreateApiToken generates a token and stores its hash. The token itself is only returned here.
+func CreateApiToken(apiToken *ApiToken) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating api token: %v", err)
+	}
+	token := shared.ApiTokenPrefix + hex.EncodeToString(bytes)
+
+	apiToken.TokenHash = hashApiToken(token)
+	apiToken.TokenPrefix = token[:apiTokenDisplayPrefixLen]
+
+	err = Conn.QueryRow(`INSERT INTO api_tokens (org_id, user_id, creator_id, name, token_prefix, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at`,
+		apiToken.OrgId, apiToken.UserId, apiToken.CreatorId, apiToken.Name, apiToken.TokenPrefix, apiToken.TokenHash, apiToken.Scopes, apiToken.ExpiresAt,
+	).Scan(&apiToken.Id, &apiToken.CreatedAt, &apiToken.UpdatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating api token: %v", err)
+	}
+
+	return token, nil
+}
+
+// ValidateApiToken returns ErrInvalidApiToken if the token doesn't exist, is revoked, or has expired
+func ValidateApiToken(token string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())", hashApiToken(token))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Println("api token error - no rows found")
+			return nil, ErrInvalidApiToken
+		}
+		return nil, fmt.Errorf("error validating api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+func TouchApiToken(apiToken *ApiToken) {
+	if apiToken.LastUsedAt != nil && time.Since(*apiToken.LastUsedAt) < apiTokenLastUsedInterval {
+		return
+	}
+
+	_, err := Conn.Exec("UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1", apiToken.Id)
+	if err != nil {
+		log.Printf("Error updating api token last used: %v\n", err)
+	}
+}
+
+// GetApiToken returns nil with no error if the token doesn't exist
+func GetApiToken(orgId, tokenId string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE org_id = $1 AND id = $2", orgId, tokenId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting api token: %v", err)
+	}
+	return &apiToken, nil
+}
+
+// ListApiTokens lists an org's unrevoked tokens for the given users
+func ListApiTokens(orgId string, userIds []string) ([]*ApiToken, error) {
+	var tokens []*ApiToken
+	err := Conn.Select(&tokens, `SELECT api_tokens.*, users.email AS user_email, users.name AS user_name, users.is_service_account
+FROM api_tokens
+JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.org_id = $1 AND api_tokens.user_id = ANY($2) AND api_tokens.revoked_at IS NULL
+ORDER BY api_tokens.created_at`, orgId, pq.Array(userIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing api tokens: %v", err)
+	}
+
+	return tokens, nil
+}
+
+func RevokeApiToken(orgId, tokenId string) error {
+	_, err := Conn.Exec("UPDATE api_tokens SET revoked_at = NOW() WHERE org_id = $1 AND id = $2 AND revoked_at IS NULL", orgId, tokenId)
+	if err != nil {
+		return fmt.Errorf("error revoking api token: %v", err)
+	}
+	return nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateServiceAccount creates a user that can only authenticate with api tokens and adds it to the org
+func CreateServiceAccount(orgId, name, orgRoleId string, tx *sqlx.Tx) (*User, error) {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		slug = "service-account"
+	}
+	email := fmt.Sprintf("%s-%s@%s", slug, uuid.New().String()[:8], serviceAccountEmailDomain)
+
+	user, err := CreateUser(name, email, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("UPDATE users SET is_service_account = TRUE WHERE id = $1", user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error marking service account: %v", err)
+	}
+	user.IsServiceAccount = true
+
+	err = CreateOrgUser(orgId, user.Id, orgRoleId, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ListServiceAccounts lists the service accounts in an org with their org user records
+func ListServiceAccounts(orgId string) ([]*shared.ServiceAccount, error) {
+	var rows []struct {
+		User
+		OrgRoleId string `db:"org_role_id"`
+	}
+
+	err := Conn.Select(&rows, `SELECT users.*, orgs_users.org_role_id
+FROM users
+JOIN orgs_users ON orgs_users.user_id = users.id
+WHERE orgs_users.org_id = $1 AND users.is_service_account
+ORDER BY users.created_at`, orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing service accounts: %v", err)
+	}
+
+	res := make([]*shared.ServiceAccount, len(rows))
+	for i, row := range rows {
+		res[i] = &shared.ServiceAccount{
+			Id:        row.Id,
+			Name:      row.Name,
+			Email:     row.Email,
+			OrgRoleId: row.OrgRoleId,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AuditEventFilter struct {
+	// a full action like 'plan.apply', or a category like 'plan'
+	Action string
+	// matches either the user's id or their email
+	User   string
+	PlanId string
+	Since  *time.Time
+	Until  *time.Time
+
+	// for paging—only events before this one are returned
+	BeforeCreatedAt *time.Time
+	BeforeId        string
+
+	Limit int
+}
+
+func CreateAuditEvent(event *AuditEvent, data map[string]interface{}) error {
+	if data != nil {
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error marshalling audit event data: %v", err)
+		}
+		s := string(bytes)
+		event.Data = &s
+	}
+
+	err := Conn.QueryRow(`INSERT INTO audit_events (org_id, action, user_id, user_email, api_token_id, ip, plan_id, plan_name, branch, target_id, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at`,
+		event.OrgId, event.Action, event.UserId, event.UserEmail, event.ApiTokenId, event.Ip, event.PlanId, event.PlanName, event.Branch, event.TargetId, event.Data,
+	).Scan(&event.Id, &event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating audit event: %v", err)
+	}
+
+	return nil
+}
+
+// ListAuditEvents returns an org's events newest first
+func ListAuditEvents(orgId string, filter AuditEventFilter) ([]*AuditEvent, error) {
+	conditions := []string{"org_id = $1"}
+	args := []interface{}{orgId}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Action != "" {
+		if strings.Contains(filter.Action, ".") {
+			addCondition("action = ?", filter.Action)
+		} else {
+			addCondition("action LIKE ?", filter.Action+".%")
+		}
+	}
+	if filter.User != "" {
+		addCondition("(user_id::text = ? OR user_email = lower(?))", filter.User)
+	}
+	if filter.PlanId != "" {
+		addCondition("plan_id::text = ?", filter.PlanId)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at < ?", *filter.Until)
+	}
+	if filter.BeforeCreatedAt != nil {
+		args = append(args, *filter.BeforeCreatedAt, filter.BeforeId)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d::uuid)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf("SELECT * FROM audit_events WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d", strings.Join(conditions, " AND "), len(args))
+
+	var events []*AuditEvent
+	err := Conn.Select(&events, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit events: %v", err)
+	}
+
+	return events, nil
+}
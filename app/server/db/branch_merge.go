@@ -0,0 +1,616 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	diff_pkg "plandex-server/diff"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+type MergeBranchParams struct {
+	OrgId  string
+	PlanId string
+
+	// Into is the branch being merged into—it must be checked out
+	Into   string
+	Source string
+
+	// Sha, if set, cherry-picks a single commit instead of merging all of Source
+	Sha string
+
+	DryRun            bool
+	Resolutions       map[string]shared.MergeConflictResolution
+	DefaultResolution shared.MergeConflictResolution
+}
+
+// mergeState is everything a merge will change, worked out before anything is written so that a dry run and a conflicted merge can return without side effects
+type mergeState struct {
+	writes  map[string][]byte
+	deletes map[string]bool
+
+	res *shared.MergeBranchResponse
+}
+
+// MergeBranch brings the changes made on another branch (or in a single commit, for a cherry-pick) into the checked out branch.
+//
+// Plan state is stored as one file per message, context, and result, so most changes merge at the file level: anything the incoming side added or changed is taken unless the target branch changed the same file too, in which case the target's version is kept. On top of that:
+//   - incoming convo messages, results, and descriptions are moved after the target branch's latest activity so that the conversation stays in order and incoming pending changes are applied after the target's
+//   - incoming contexts for a path the target branch already has in context are skipped
+//   - if both branches have new pending changes to the same file, both are kept if the incoming changes still apply on top of the target's. Otherwise it's a conflict that needs a resolution: 'ours' rejects the incoming changes, 'theirs' rejects the target's.
+func MergeBranch(repo *GitRepo, params MergeBranchParams) (*shared.MergeBranchResponse, error) {
+	into := params.Into
+
+	var sourceRef, baseRef string
+	fastForward := false
+
+	if params.Sha != "" {
+		sha, err := repo.GitResolveCommit(params.Sha)
+		if err != nil {
+			return nil, err
+		}
+
+		alreadyIn, err := repo.GitIsAncestor(sha, into)
+		if err != nil {
+			return nil, err
+		}
+		if alreadyIn {
+			return &shared.MergeBranchResponse{UpToDate: true}, nil
+		}
+
+		parent, err := repo.GitResolveCommit(sha + "^")
+		if err != nil {
+			return nil, fmt.Errorf("can't cherry-pick %s because it's the plan's first commit", params.Sha)
+		}
+
+		sourceRef = sha
+		baseRef = parent
+	} else {
+		upToDate, err := repo.GitIsAncestor(params.Source, into)
+		if err != nil {
+			return nil, err
+		}
+		if upToDate {
+			return &shared.MergeBranchResponse{UpToDate: true}, nil
+		}
+
+		base, err := repo.GitMergeBase(into, params.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		intoSha, err := repo.GitResolveCommit(into)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceRef = params.Source
+		baseRef = base
+		fastForward = base == intoSha
+	}
+
+	state, err := getMergeState(repo, params, baseRef, sourceRef)
+	if err != nil {
+		return nil, err
+	}
+
+	res := state.res
+	res.FastForward = fastForward
+
+	if len(res.Conflicts) > 0 || params.DryRun {
+		return res, nil
+	}
+
+	if fastForward {
+		err = repo.GitFastForward(into, params.Source)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// a branch merge is recorded as a git merge so that the next merge from the same branch only picks up what's changed since this one—even if nothing needed to be written
+		isMerge := params.Sha == ""
+
+		if !isMerge && len(state.writes) == 0 && len(state.deletes) == 0 {
+			res.UpToDate = true
+			return res, nil
+		}
+
+		if isMerge {
+			err = repo.GitStartMerge(into, params.Source)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = writeMergeState(repo, params, state, sourceRef)
+		if err != nil {
+			if isMerge {
+				abortErr := repo.GitAbortMerge(into)
+				if abortErr != nil {
+					log.Printf("Error aborting merge: %v\n", abortErr)
+				}
+			}
+			return nil, err
+		}
+	}
+
+	res.Merged = true
+
+	res.LatestSha, res.LatestCommit, err = repo.GetLatestCommit(into)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func writeMergeState(repo *GitRepo, params MergeBranchParams, state *mergeState, sourceRef string) error {
+	into := params.Into
+	res := state.res
+	planDir := getPlanDir(params.OrgId, params.PlanId)
+
+	for path, bytes := range state.writes {
+		fullPath := filepath.Join(planDir, path)
+		err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("error creating dir for %s: %v", path, err)
+		}
+		err = os.WriteFile(fullPath, bytes, 0644)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %v", path, err)
+		}
+	}
+
+	for path := range state.deletes {
+		err := os.Remove(filepath.Join(planDir, path))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %s: %v", path, err)
+		}
+	}
+
+	var msg string
+	if params.Sha != "" {
+		subject, err := repo.GitCommitSubject(sourceRef)
+		if err != nil {
+			return err
+		}
+		msg = fmt.Sprintf("🍒 Cherry-picked %s", params.Sha)
+		if params.Source != "" {
+			msg += fmt.Sprintf(" from branch '%s'", params.Source)
+		}
+		msg += "\n\n" + subject
+	} else {
+		msg = fmt.Sprintf("🔀 Merged branch '%s' into '%s'", params.Source, into)
+	}
+
+	if len(res.PendingPaths) > 0 {
+		msg += "\n\nPending changes:"
+		for _, path := range res.PendingPaths {
+			msg += "\n • " + path
+		}
+	}
+
+	return repo.GitAddAndCommit(into, msg)
+}
+
+func getMergeState(repo *GitRepo, params MergeBranchParams, baseRef, sourceRef string) (*mergeState, error) {
+	orgId := params.OrgId
+	planId := params.PlanId
+
+	sourceChanges, err := repo.GitChangedFiles(baseRef, sourceRef)
+	if err != nil {
+		return nil, err
+	}
+
+	intoChanges, err := repo.GitChangedFiles(baseRef, params.Into)
+	if err != nil {
+		return nil, err
+	}
+
+	intoConvo, err := GetPlanConvo(orgId, planId)
+	if err != nil {
+		return nil, err
+	}
+
+	intoResults, err := GetPlanFileResults(orgId, planId)
+	if err != nil {
+		return nil, err
+	}
+
+	intoDescriptions, err := GetConvoMessageDescriptions(orgId, planId)
+	if err != nil {
+		return nil, err
+	}
+
+	intoContexts, err := GetPlanContexts(orgId, planId, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &mergeState{
+		writes:  map[string][]byte{},
+		deletes: map[string]bool{},
+		res:     &shared.MergeBranchResponse{},
+	}
+
+	// take the incoming version of each file that only the incoming side changed
+	var added []string
+	paths := make([]string, 0, len(sourceChanges))
+	for path := range sourceChanges {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	contents := map[string][]byte{}
+
+	for _, path := range paths {
+		if _, changedOnInto := intoChanges[path]; changedOnInto {
+			log.Printf("MergeBranch - %s changed on both branches, keeping %s's version", path, params.Into)
+			continue
+		}
+
+		if sourceChanges[path] == "D" {
+			state.deletes[path] = true
+			continue
+		}
+
+		bytes, err := repo.GitShowFile(sourceRef, path)
+		if err != nil {
+			return nil, err
+		}
+		contents[path] = bytes
+
+		if sourceChanges[path] == "A" {
+			added = append(added, path)
+		}
+	}
+
+	// skip incoming contexts for paths that are already in context
+	intoContextPaths := map[string]bool{}
+	for _, context := range intoContexts {
+		if context.FilePath != "" {
+			intoContextPaths[context.FilePath] = true
+		}
+	}
+
+	skipContextIds := map[string]bool{}
+	for _, path := range added {
+		if !strings.HasPrefix(path, "context/") || !strings.HasSuffix(path, ".meta") {
+			continue
+		}
+
+		var context Context
+		err := json.Unmarshal(contents[path], &context)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling context %s: %v", path, err)
+		}
+
+		if context.FilePath != "" && intoContextPaths[context.FilePath] {
+			skipContextIds[context.Id] = true
+		} else {
+			state.res.NumContexts++
+		}
+	}
+
+	for path := range contents {
+		if !strings.HasPrefix(path, "context/") {
+			continue
+		}
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if skipContextIds[id] {
+			delete(contents, path)
+		}
+	}
+
+	// decode the incoming messages, results, and descriptions that need to be moved after the target branch's latest activity
+	var newMessages []*ConvoMessage
+	var newResults []*PlanFileResult
+	var newDescriptions []*ConvoMessageDescription
+	newPaths := map[*PlanFileResult]string{}
+	newMessagePaths := map[*ConvoMessage]string{}
+	newDescriptionPaths := map[*ConvoMessageDescription]string{}
+
+	var earliestIncoming time.Time
+	trackEarliest := func(t time.Time) {
+		if earliestIncoming.IsZero() || t.Before(earliestIncoming) {
+			earliestIncoming = t
+		}
+	}
+
+	for _, path := range added {
+		if _, ok := contents[path]; !ok {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(path, "conversation/"):
+			var msg ConvoMessage
+			err := json.Unmarshal(contents[path], &msg)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling convo message %s: %v", path, err)
+			}
+			newMessages = append(newMessages, &msg)
+			newMessagePaths[&msg] = path
+			trackEarliest(msg.CreatedAt)
+
+		case strings.HasPrefix(path, "results/"):
+			var result PlanFileResult
+			err := json.Unmarshal(contents[path], &result)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling result %s: %v", path, err)
+			}
+			newResults = append(newResults, &result)
+			newPaths[&result] = path
+			trackEarliest(result.CreatedAt)
+
+		case strings.HasPrefix(path, "descriptions/"):
+			var desc ConvoMessageDescription
+			err := json.Unmarshal(contents[path], &desc)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling description %s: %v", path, err)
+			}
+			newDescriptions = append(newDescriptions, &desc)
+			newDescriptionPaths[&desc] = path
+			trackEarliest(desc.CreatedAt)
+		}
+	}
+
+	var latestInto time.Time
+	maxNum := 0
+	for _, msg := range intoConvo {
+		if msg.CreatedAt.After(latestInto) {
+			latestInto = msg.CreatedAt
+		}
+		if msg.Num > maxNum {
+			maxNum = msg.Num
+		}
+	}
+	for _, result := range intoResults {
+		if result.CreatedAt.After(latestInto) {
+			latestInto = result.CreatedAt
+		}
+	}
+	for _, desc := range intoDescriptions {
+		if desc.CreatedAt.After(latestInto) {
+			latestInto = desc.CreatedAt
+		}
+	}
+
+	// shifting everything by the same amount keeps the incoming side's own ordering and spacing
+	var shift time.Duration
+	if !earliestIncoming.IsZero() && !earliestIncoming.After(latestInto) {
+		shift = latestInto.Sub(earliestIncoming) + time.Millisecond
+	}
+
+	sort.Slice(newMessages, func(i, j int) bool {
+		return newMessages[i].CreatedAt.Before(newMessages[j].CreatedAt)
+	})
+	for i, msg := range newMessages {
+		msg.CreatedAt = msg.CreatedAt.Add(shift)
+		msg.Num = maxNum + i + 1
+	}
+	state.res.NumConvoMessages = len(newMessages)
+
+	for _, result := range newResults {
+		result.CreatedAt = result.CreatedAt.Add(shift)
+		result.UpdatedAt = result.UpdatedAt.Add(shift)
+	}
+
+	for _, desc := range newDescriptions {
+		desc.CreatedAt = desc.CreatedAt.Add(shift)
+		desc.UpdatedAt = desc.UpdatedAt.Add(shift)
+	}
+
+	// check for conflicting pending changes
+	err = resolveResultConflicts(state, params, intoChanges, intoResults, intoContexts, newResults)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingPaths := map[string]bool{}
+	for _, result := range newResults {
+		if result.AppliedAt == nil && result.RejectedAt == nil {
+			pendingPaths[result.Path] = true
+		}
+	}
+	for path := range pendingPaths {
+		state.res.PendingPaths = append(state.res.PendingPaths, path)
+	}
+	sort.Strings(state.res.PendingPaths)
+
+	// re-encode anything that was changed
+	for _, msg := range newMessages {
+		bytes, err := json.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling convo message: %v", err)
+		}
+		contents[newMessagePaths[msg]] = bytes
+	}
+
+	for _, result := range newResults {
+		bytes, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling result: %v", err)
+		}
+		contents[newPaths[result]] = bytes
+	}
+
+	for _, desc := range newDescriptions {
+		bytes, err := json.Marshal(desc)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling description: %v", err)
+		}
+		contents[newDescriptionPaths[desc]] = bytes
+	}
+
+	for path, bytes := range contents {
+		state.writes[path] = bytes
+	}
+
+	return state, nil
+}
+
+// resolveResultConflicts finds files with new pending changes on both sides, keeps both if the incoming changes apply cleanly after the target's, and otherwise applies the requested resolution or reports a conflict
+func resolveResultConflicts(
+	state *mergeState,
+	params MergeBranchParams,
+	intoChanges map[string]string,
+	intoResults []*PlanFileResult,
+	intoContexts []*Context,
+	newResults []*PlanFileResult,
+) error {
+	isPending := func(result *PlanFileResult) bool {
+		return result.AppliedAt == nil && result.RejectedAt == nil
+	}
+
+	incomingByPath := map[string][]*PlanFileResult{}
+	for _, result := range newResults {
+		if isPending(result) {
+			incomingByPath[result.Path] = append(incomingByPath[result.Path], result)
+		}
+	}
+
+	// pending results on the target branch, and the subset that was added since the two sides diverged
+	intoByPath := map[string][]*PlanFileResult{}
+	intoNewByPath := map[string][]*PlanFileResult{}
+	for _, result := range intoResults {
+		if !isPending(result) {
+			continue
+		}
+		intoByPath[result.Path] = append(intoByPath[result.Path], result)
+		if intoChanges["results/"+result.Id+".json"] == "A" {
+			intoNewByPath[result.Path] = append(intoNewByPath[result.Path], result)
+		}
+	}
+
+	contextsByPath := map[string]*shared.Context{}
+	for _, context := range intoContexts {
+		if context.FilePath != "" {
+			contextsByPath[context.FilePath] = context.ToApi()
+		}
+	}
+
+	paths := make([]string, 0, len(incomingByPath))
+	for path := range incomingByPath {
+		if len(intoNewByPath[path]) > 0 {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	now := time.Now().UTC()
+
+	for _, path := range paths {
+		incoming := incomingByPath[path]
+		ours := intoByPath[path]
+		oursNew := intoNewByPath[path]
+
+		// the target's pending changes that the incoming side also has (from before the branches diverged) plus the incoming side's new ones
+		var common []*PlanFileResult
+		for _, result := range ours {
+			if intoChanges["results/"+result.Id+".json"] != "A" {
+				common = append(common, result)
+			}
+		}
+
+		_, err := getPendingFileForPath(path, append(append([]*PlanFileResult{}, ours...), incoming...), contextsByPath)
+		if err == nil {
+			state.res.AutoResolvedPaths = append(state.res.AutoResolvedPaths, path)
+			continue
+		}
+
+		resolution := params.Resolutions[path]
+		if resolution == "" {
+			resolution = params.DefaultResolution
+		}
+
+		switch resolution {
+		case shared.MergeConflictResolutionOurs:
+			for _, result := range incoming {
+				result.RejectedAt = &now
+			}
+
+		case shared.MergeConflictResolutionTheirs:
+			for _, result := range oursNew {
+				updated := *result
+				updated.RejectedAt = &now
+				bytes, err := json.Marshal(&updated)
+				if err != nil {
+					return fmt.Errorf("error marshalling result: %v", err)
+				}
+				state.writes["results/"+result.Id+".json"] = bytes
+			}
+
+		default:
+			original := ""
+			if context := contextsByPath[path]; context != nil {
+				original = context.Body
+			}
+
+			conflict := &shared.MergeConflict{
+				Path:             path,
+				OursNumChanges:   countReplacements(oursNew),
+				TheirsNumChanges: countReplacements(incoming),
+			}
+
+			if updated, err := getPendingFileForPath(path, ours, contextsByPath); err == nil {
+				conflict.OursDiff, _ = diff_pkg.GetDiffs(original, updated)
+			}
+			if updated, err := getPendingFileForPath(path, append(common, incoming...), contextsByPath); err == nil {
+				conflict.TheirsDiff, _ = diff_pkg.GetDiffs(original, updated)
+			}
+
+			state.res.Conflicts = append(state.res.Conflicts, conflict)
+		}
+	}
+
+	return nil
+}
+
+// getPendingFileForPath applies pending results for a single path in order and returns an error if any of them fail to apply
+func getPendingFileForPath(path string, results []*PlanFileResult, contextsByPath map[string]*shared.Context) (string, error) {
+	sorted := make([]*shared.PlanFileResult, len(results))
+	for i, result := range results {
+		sorted[i] = result.ToApi()
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	planState := &shared.CurrentPlanState{
+		PlanResult:     GetPlanResult(sorted),
+		ContextsByPath: map[string]*shared.Context{},
+	}
+	if context := contextsByPath[path]; context != nil {
+		planState.ContextsByPath[path] = context
+	}
+
+	files, err := planState.GetFiles()
+	if err != nil {
+		return "", err
+	}
+
+	return files.Files[path], nil
+}
+
+func countReplacements(results []*PlanFileResult) int {
+	n := 0
+	for _, result := range results {
+		if len(result.Replacements) == 0 {
+			n++
+			continue
+		}
+		for _, replacement := range result.Replacements {
+			if replacement.RejectedAt == nil {
+				n++
+			}
+		}
+	}
+	return n
+}
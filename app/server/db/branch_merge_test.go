@@ -0,0 +1,379 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const (
+	testMergeOrgId  = "org-1"
+	testMergePlanId = "plan-1"
+)
+
+var testMergeT0 = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestMergeRepo creates a plan repo in a temp dir with one message and main.go in context on 'main', then branches 'feature' off it and leaves 'main' checked out
+func newTestMergeRepo(t *testing.T) *GitRepo {
+	t.Helper()
+
+	prevBaseDir := BaseDir
+	BaseDir = t.TempDir()
+	t.Cleanup(func() { BaseDir = prevBaseDir })
+
+	err := InitPlan(testMergeOrgId, testMergePlanId)
+	if err != nil {
+		t.Fatalf("error initializing plan: %v", err)
+	}
+	repo := getGitRepo(testMergeOrgId, testMergePlanId)
+
+	err = StoreContext(&Context{
+		Id:          "ctx-main",
+		OrgId:       testMergeOrgId,
+		PlanId:      testMergePlanId,
+		ContextType: shared.ContextFileType,
+		FilePath:    "main.go",
+		Body:        "a := \"one\"\nb := \"two\"\n",
+		CreatedAt:   testMergeT0,
+	}, true)
+	if err != nil {
+		t.Fatalf("error storing context: %v", err)
+	}
+	writeTestConvoMessage(t, "msg-0", 1, testMergeT0)
+	commitTestMerge(t, repo, "main", "base")
+
+	err = repo.GitCreateBranch("feature")
+	if err != nil {
+		t.Fatalf("error creating branch: %v", err)
+	}
+	err = repo.GitCheckoutBranch("main")
+	if err != nil {
+		t.Fatalf("error checking out main: %v", err)
+	}
+
+	return repo
+}
+
+func commitTestMerge(t *testing.T, repo *GitRepo, branch, msg string) {
+	t.Helper()
+	err := repo.GitAddAndCommit(branch, msg)
+	if err != nil {
+		t.Fatalf("error committing: %v", err)
+	}
+}
+
+// onTestBranch makes changes with the branch checked out and commits them, then checks out 'main' again
+func onTestBranch(t *testing.T, repo *GitRepo, branch string, fn func()) {
+	t.Helper()
+	err := repo.GitCheckoutBranch(branch)
+	if err != nil {
+		t.Fatalf("error checking out %s: %v", branch, err)
+	}
+	fn()
+	commitTestMerge(t, repo, branch, "changes on "+branch)
+	err = repo.GitCheckoutBranch("main")
+	if err != nil {
+		t.Fatalf("error checking out main: %v", err)
+	}
+}
+
+func writeTestConvoMessage(t *testing.T, id string, num int, createdAt time.Time) {
+	t.Helper()
+	bytes, err := json.Marshal(&ConvoMessage{
+		Id:        id,
+		OrgId:     testMergeOrgId,
+		PlanId:    testMergePlanId,
+		Role:      "user",
+		Num:       num,
+		Message:   id,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("error marshalling message: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(getPlanConversationDir(testMergeOrgId, testMergePlanId), id+".json"), bytes, 0644)
+	if err != nil {
+		t.Fatalf("error writing message: %v", err)
+	}
+}
+
+func writeTestResult(t *testing.T, id string, createdAt time.Time, old, new string) {
+	t.Helper()
+	err := StorePlanResult(&PlanFileResult{
+		Id:           id,
+		OrgId:        testMergeOrgId,
+		PlanId:       testMergePlanId,
+		Path:         "main.go",
+		Replacements: []*shared.Replacement{{Id: id + "-r", Old: old, New: new}},
+		CreatedAt:    createdAt,
+	})
+	if err != nil {
+		t.Fatalf("error storing result: %v", err)
+	}
+}
+
+func writeTestFileContext(t *testing.T, id, path string) {
+	t.Helper()
+	err := StoreContext(&Context{
+		Id:          id,
+		OrgId:       testMergeOrgId,
+		PlanId:      testMergePlanId,
+		ContextType: shared.ContextFileType,
+		FilePath:    path,
+		Body:        "package " + id,
+		CreatedAt:   testMergeT0,
+	}, true)
+	if err != nil {
+		t.Fatalf("error storing context: %v", err)
+	}
+}
+
+func getTestResults(t *testing.T) map[string]*PlanFileResult {
+	t.Helper()
+	results, err := GetPlanFileResults(testMergeOrgId, testMergePlanId)
+	if err != nil {
+		t.Fatalf("error getting results: %v", err)
+	}
+	byId := map[string]*PlanFileResult{}
+	for _, result := range results {
+		byId[result.Id] = result
+	}
+	return byId
+}
+
+func TestMergeBranch(t *testing.T) {
+	// both branches change "one", so the incoming change no longer applies after the target's
+	conflicting := func(t *testing.T, repo *GitRepo) {
+		onTestBranch(t, repo, "feature", func() {
+			writeTestResult(t, "res-theirs", testMergeT0.Add(time.Minute), "\"one\"", "\"eins\"")
+		})
+		writeTestResult(t, "res-ours", testMergeT0.Add(2*time.Minute), "\"one\"", "\"uno\"")
+		commitTestMerge(t, repo, "main", "changes on main")
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, repo *GitRepo)
+		params MergeBranchParams
+		check  func(t *testing.T, res *shared.MergeBranchResponse)
+	}{
+		{
+			name:  "conflicting replacements on the same path",
+			setup: conflicting,
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if res.Merged {
+					t.Errorf("expected nothing to be merged with an unresolved conflict")
+				}
+				if len(res.Conflicts) != 1 || res.Conflicts[0].Path != "main.go" {
+					t.Fatalf("expected a conflict on main.go, got %+v", res.Conflicts)
+				}
+				if res.Conflicts[0].OursNumChanges != 1 || res.Conflicts[0].TheirsNumChanges != 1 {
+					t.Errorf("unexpected change counts: %+v", res.Conflicts[0])
+				}
+				if _, ok := getTestResults(t)["res-theirs"]; ok {
+					t.Errorf("expected the incoming result not to be written")
+				}
+			},
+		},
+		{
+			name:   "ours resolution rejects the incoming changes",
+			setup:  conflicting,
+			params: MergeBranchParams{Resolutions: map[string]shared.MergeConflictResolution{"main.go": shared.MergeConflictResolutionOurs}},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || len(res.Conflicts) > 0 {
+					t.Fatalf("expected the merge to succeed, got %+v", res)
+				}
+				results := getTestResults(t)
+				if results["res-theirs"] == nil || results["res-theirs"].RejectedAt == nil {
+					t.Errorf("expected the incoming result to be rejected")
+				}
+				if results["res-ours"] == nil || results["res-ours"].RejectedAt != nil {
+					t.Errorf("expected the target's result to stay pending")
+				}
+			},
+		},
+		{
+			name:   "theirs resolution rejects the target's changes",
+			setup:  conflicting,
+			params: MergeBranchParams{DefaultResolution: shared.MergeConflictResolutionTheirs},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || len(res.Conflicts) > 0 {
+					t.Fatalf("expected the merge to succeed, got %+v", res)
+				}
+				results := getTestResults(t)
+				if results["res-ours"] == nil || results["res-ours"].RejectedAt == nil {
+					t.Errorf("expected the target's result to be rejected")
+				}
+				if results["res-theirs"] == nil || results["res-theirs"].RejectedAt != nil {
+					t.Errorf("expected the incoming result to stay pending")
+				}
+				if len(res.PendingPaths) != 1 || res.PendingPaths[0] != "main.go" {
+					t.Errorf("expected main.go to be pending, got %v", res.PendingPaths)
+				}
+			},
+		},
+		{
+			name: "changes that apply one after the other are both kept",
+			setup: func(t *testing.T, repo *GitRepo) {
+				onTestBranch(t, repo, "feature", func() {
+					writeTestResult(t, "res-theirs", testMergeT0.Add(time.Minute), "\"two\"", "\"zwei\"")
+				})
+				writeTestResult(t, "res-ours", testMergeT0.Add(2*time.Minute), "\"one\"", "\"uno\"")
+				commitTestMerge(t, repo, "main", "changes on main")
+			},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || len(res.Conflicts) > 0 {
+					t.Fatalf("expected the merge to succeed, got %+v", res)
+				}
+				if len(res.AutoResolvedPaths) != 1 || res.AutoResolvedPaths[0] != "main.go" {
+					t.Errorf("expected main.go to be auto-resolved, got %v", res.AutoResolvedPaths)
+				}
+				results := getTestResults(t)
+				if results["res-ours"] == nil || results["res-ours"].RejectedAt != nil || results["res-theirs"] == nil || results["res-theirs"].RejectedAt != nil {
+					t.Errorf("expected both results to stay pending")
+				}
+				if !results["res-theirs"].CreatedAt.After(results["res-ours"].CreatedAt) {
+					t.Errorf("expected the incoming result to be moved after the target's")
+				}
+			},
+		},
+		{
+			name: "incoming messages are moved after the target's latest activity",
+			setup: func(t *testing.T, repo *GitRepo) {
+				onTestBranch(t, repo, "feature", func() {
+					writeTestConvoMessage(t, "msg-theirs-1", 2, testMergeT0.Add(time.Minute))
+					writeTestConvoMessage(t, "msg-theirs-2", 3, testMergeT0.Add(3*time.Minute))
+				})
+				writeTestConvoMessage(t, "msg-ours", 2, testMergeT0.Add(5*time.Minute))
+				commitTestMerge(t, repo, "main", "changes on main")
+			},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || res.NumConvoMessages != 2 {
+					t.Fatalf("expected 2 messages to be merged, got %+v", res)
+				}
+				convo, err := GetPlanConvo(testMergeOrgId, testMergePlanId)
+				if err != nil {
+					t.Fatalf("error getting convo: %v", err)
+				}
+				var ids []string
+				var nums []int
+				for _, msg := range convo {
+					ids = append(ids, msg.Id)
+					nums = append(nums, msg.Num)
+				}
+				wantIds := []string{"msg-0", "msg-ours", "msg-theirs-1", "msg-theirs-2"}
+				wantNums := []int{1, 2, 3, 4}
+				for i := range wantIds {
+					if i >= len(ids) || ids[i] != wantIds[i] || nums[i] != wantNums[i] {
+						t.Fatalf("got messages %v with nums %v, want %v with nums %v", ids, nums, wantIds, wantNums)
+					}
+				}
+				// the incoming side's spacing is kept
+				if gap := convo[3].CreatedAt.Sub(convo[2].CreatedAt); gap != 2*time.Minute {
+					t.Errorf("expected incoming messages to stay 2 minutes apart, got %v", gap)
+				}
+			},
+		},
+		{
+			name: "contexts already loaded on the target are skipped",
+			setup: func(t *testing.T, repo *GitRepo) {
+				onTestBranch(t, repo, "feature", func() {
+					writeTestFileContext(t, "ctx-main-again", "main.go")
+					writeTestFileContext(t, "ctx-util", "util.go")
+				})
+				writeTestConvoMessage(t, "msg-ours", 2, testMergeT0.Add(time.Minute))
+				commitTestMerge(t, repo, "main", "changes on main")
+			},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || res.NumContexts != 1 {
+					t.Fatalf("expected 1 context to be merged, got %+v", res)
+				}
+				contexts, err := GetPlanContexts(testMergeOrgId, testMergePlanId, false, false)
+				if err != nil {
+					t.Fatalf("error getting contexts: %v", err)
+				}
+				byId := map[string]bool{}
+				for _, context := range contexts {
+					byId[context.Id] = true
+				}
+				if !byId["ctx-main"] || !byId["ctx-util"] || byId["ctx-main-again"] || len(contexts) != 2 {
+					t.Errorf("expected ctx-main and ctx-util, got %v", byId)
+				}
+			},
+		},
+		{
+			name: "fast-forward when the target has no changes",
+			setup: func(t *testing.T, repo *GitRepo) {
+				onTestBranch(t, repo, "feature", func() {
+					writeTestConvoMessage(t, "msg-theirs", 2, testMergeT0.Add(time.Minute))
+				})
+			},
+			check: func(t *testing.T, res *shared.MergeBranchResponse) {
+				if !res.Merged || !res.FastForward {
+					t.Fatalf("expected a fast-forward merge, got %+v", res)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestMergeRepo(t)
+			tt.setup(t, repo)
+
+			params := tt.params
+			params.OrgId = testMergeOrgId
+			params.PlanId = testMergePlanId
+			params.Into = "main"
+			params.Source = "feature"
+
+			res, err := MergeBranch(repo, params)
+			if err != nil {
+				t.Fatalf("MergeBranch() error: %v", err)
+			}
+			tt.check(t, res)
+		})
+	}
+}
+
+func TestMergeBranchCherryPick(t *testing.T) {
+	repo := newTestMergeRepo(t)
+
+	onTestBranch(t, repo, "feature", func() {
+		writeTestConvoMessage(t, "msg-skipped", 2, testMergeT0.Add(time.Minute))
+	})
+	onTestBranch(t, repo, "feature", func() {
+		writeTestConvoMessage(t, "msg-picked", 3, testMergeT0.Add(2*time.Minute))
+	})
+
+	sha, err := repo.GitResolveCommit("feature")
+	if err != nil {
+		t.Fatalf("error resolving commit: %v", err)
+	}
+
+	res, err := MergeBranch(repo, MergeBranchParams{
+		OrgId:  testMergeOrgId,
+		PlanId: testMergePlanId,
+		Into:   "main",
+		Source: "feature",
+		Sha:    sha,
+	})
+	if err != nil {
+		t.Fatalf("MergeBranch() error: %v", err)
+	}
+	if !res.Merged || res.NumConvoMessages != 1 {
+		t.Fatalf("expected 1 message to be cherry-picked, got %+v", res)
+	}
+
+	convo, err := GetPlanConvo(testMergeOrgId, testMergePlanId)
+	if err != nil {
+		t.Fatalf("error getting convo: %v", err)
+	}
+	if len(convo) != 2 || convo[1].Id != "msg-picked" || convo[1].Num != 2 {
+		t.Errorf("expected only msg-picked to be added as message 2, got %+v", convo)
+	}
+}
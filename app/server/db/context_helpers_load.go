@@ -92,6 +92,10 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 	plannerMaxTokens := settings.GetPlannerEffectiveMaxTokens()
 	contextLoaderMaxTokens := settings.GetArchitectEffectiveMaxTokens()
 
+	// count with the tokenizer of the model whose limit the tokens are checked against
+	tokenizer := settings.GetPlannerTokenizer()
+	mapTokenizer := getMapTokenizer(settings, planConfig)
+
 	mapContextsByFilePath := make(map[string]Context)
 
 	existingContexts, err := GetPlanContexts(orgId, planId, false, false)
@@ -198,7 +202,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 			}
 
 			combinedBody := mappedFiles.CombinedMap(mapTokens)
-			numTokens = shared.GetNumTokensEstimate(combinedBody)
+			numTokens = mapTokenizer.CountTokens(combinedBody)
 
 			autoLoaded = autoLoaded || contextParams.AutoLoaded
 
@@ -231,7 +235,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 				return nil, nil, fmt.Errorf("error getting image num tokens: %v", err)
 			}
 		} else {
-			numTokens = shared.GetNumTokensEstimate(contextParams.Body)
+			numTokens = tokenizer.CountTokens(contextParams.Body)
 		}
 
 		paramsByTempId[tempId] = contextParams
@@ -255,7 +259,8 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 
 	// showElapsed("Loaded reqs")
 	if planConfig.AutoLoadContext {
-		if totalMapTokens > contextLoaderMaxTokens {
+		// with semantic retrieval, maps that don't fit are left out of the context phase in favor of retrieved code, so they aren't limited here
+		if totalMapTokens > contextLoaderMaxTokens && !settings.SemanticRetrievalEnabled() {
 			return &shared.LoadContextResponse{
 				TokensAdded:       tokensAdded,
 				TotalTokens:       totalMapTokens,
@@ -323,6 +328,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					Body:            loadParams.Body,
 					ForceSkipIgnore: loadParams.ForceSkipIgnore,
 					ImageDetail:     loadParams.ImageDetail,
+					GitDiff:         loadParams.GitDiff,
 					AutoLoaded:      autoLoaded || loadParams.AutoLoaded,
 				}
 			}
@@ -369,3 +375,11 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 		Msg:         commitMsg,
 	}, dbContexts, nil
 }
+
+// maps are checked against the architect's limit when auto-loading context, otherwise the planner's
+func getMapTokenizer(settings *shared.PlanSettings, planConfig *shared.PlanConfig) shared.Tokenizer {
+	if planConfig.AutoLoadContext {
+		return settings.GetArchitectTokenizer()
+	}
+	return settings.GetPlannerTokenizer()
+}
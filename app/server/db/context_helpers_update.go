@@ -58,6 +58,9 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		return nil, fmt.Errorf("error getting plan config: %v", err)
 	}
 
+	tokenizer := settings.GetPlannerTokenizer()
+	mapTokenizer := getMapTokenizer(settings, planConfig)
+
 	modelPacks, err := ListModelPacks(orgId)
 	if err != nil {
 		return nil, fmt.Errorf("error getting model packs: %v", err)
@@ -133,6 +136,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	numUrls := 0
 	numTrees := 0
 	numMaps := 0
+	numDiffs := 0
 
 	var mu sync.Mutex
 	errCh := make(chan error, len(*req))
@@ -177,7 +181,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 						return
 					}
 				} else {
-					updateNumTokens = shared.GetNumTokensEstimate(params.Body)
+					updateNumTokens = tokenizer.CountTokens(params.Body)
 					// log.Println("len(params.Body)", len(params.Body))
 				}
 
@@ -204,6 +208,8 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				numTrees++
 			case shared.ContextMapType:
 				numMaps++
+			case shared.ContextGitDiffType:
+				numDiffs++
 			}
 
 			errCh <- nil
@@ -310,7 +316,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				}
 
 				context.Body = context.MapParts.CombinedMap(context.MapTokens)
-				newNumTokens := shared.GetNumTokensEstimate(context.Body)
+				newNumTokens := mapTokenizer.CountTokens(context.Body)
 				tokenDiff := newNumTokens - oldNumTokens
 
 				mu.Lock()
@@ -356,7 +362,8 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	}
 
 	if planConfig.AutoLoadContext {
-		if totalMapTokens > contextLoaderMaxTokens {
+		// with semantic retrieval, maps that don't fit are left out of the context phase in favor of retrieved code, so they aren't limited here
+		if totalMapTokens > contextLoaderMaxTokens && !settings.SemanticRetrievalEnabled() {
 			return &shared.UpdateContextResponse{
 				TokensAdded:       aggregateTokensDiff,
 				TotalTokens:       totalTokens,
@@ -375,6 +382,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumDiffs:        numDiffs,
 		MaxTokens:       plannerMaxTokens,
 	}
 
@@ -388,6 +396,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumTrees:    numTrees,
 		NumUrls:     numUrls,
 		NumMaps:     numMaps,
+		NumDiffs:    numDiffs,
 		TokensDiff:  aggregateTokensDiff,
 		TotalTokens: totalTokens,
 	}) + "\n\n" + shared.TableForContextUpdate(updateRes)
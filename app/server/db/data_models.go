@@ -4,11 +4,14 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
 	shared "plandex-shared"
 
+	"github.com/lib/pq"
 	"github.com/sashabaranov/go-openai"
+	"github.com/shopspring/decimal"
 )
 
 // The models below should only be used server-side.
@@ -52,6 +55,7 @@ type User struct {
 	Domain            string             `db:"domain"`
 	NumNonDraftPlans  int                `db:"num_non_draft_plans"`
 	DefaultPlanConfig *shared.PlanConfig `db:"default_plan_config"`
+	IsServiceAccount  bool               `db:"is_service_account"`
 	CreatedAt         time.Time          `db:"created_at"`
 	UpdatedAt         time.Time          `db:"updated_at"`
 }
@@ -64,6 +68,7 @@ func (user *User) ToApi() *shared.User {
 		NumNonDraftPlans:  user.NumNonDraftPlans,
 		IsTrial:           false, // legacy field
 		DefaultPlanConfig: user.DefaultPlanConfig,
+		IsServiceAccount:  user.IsServiceAccount,
 	}
 }
 
@@ -253,6 +258,7 @@ type OrgRole struct {
 func (role *OrgRole) ToApi() *shared.OrgRole {
 	return &shared.OrgRole{
 		Id:          role.Id,
+		Name:        role.Name,
 		IsDefault:   role.OrgId == nil,
 		Label:       role.Label,
 		Description: role.Description,
@@ -314,6 +320,7 @@ type ModelPack struct {
 	CommitMsg        shared.ModelRoleConfig   `db:"commit_msg"`
 	ExecStatus       shared.ModelRoleConfig   `db:"exec_status"`
 	Architect        *shared.ModelRoleConfig  `db:"context_loader"`
+	Embeddings       *shared.ModelRoleConfig  `db:"embeddings"`
 	CreatedAt        time.Time                `db:"created_at"`
 	UpdatedAt        time.Time                `db:"updated_at"`
 }
@@ -331,6 +338,7 @@ func ModelPackFromApi(apiModelPack *shared.ModelPack) *ModelPack {
 		Namer:            apiModelPack.Namer,
 		CommitMsg:        apiModelPack.CommitMsg,
 		ExecStatus:       apiModelPack.ExecStatus,
+		Embeddings:       apiModelPack.Embeddings,
 	}
 }
 
@@ -348,6 +356,7 @@ func (modelPack *ModelPack) ToApi() *shared.ModelPack {
 		Namer:            modelPack.Namer,
 		CommitMsg:        modelPack.CommitMsg,
 		ExecStatus:       modelPack.ExecStatus,
+		Embeddings:       modelPack.Embeddings,
 	}
 }
 
@@ -379,6 +388,13 @@ type CustomModel struct {
 	// for anthropic, token estimate padding percentage
 	TokenEstimatePaddingPct float64 `db:"token_estimate_padding_pct"`
 
+	// list prices for the self-hosted usage ledger
+	InputPricePerMillion       float64 `db:"input_price_per_million"`
+	OutputPricePerMillion      float64 `db:"output_price_per_million"`
+	CachedInputPricePerMillion float64 `db:"cached_input_price_per_million"`
+
+	IsEmbeddingModel bool `db:"is_embedding_model"`
+
 	Providers CustomModelProviders `db:"providers"`
 
 	CreatedAt time.Time `db:"created_at"`
@@ -416,6 +432,10 @@ func CustomModelFromApi(apiModel *shared.CustomModel) *CustomModel {
 		SupportsCacheControl:        apiModel.SupportsCacheControl,
 		SingleMessageNoSystemPrompt: apiModel.SingleMessageNoSystemPrompt,
 		TokenEstimatePaddingPct:     apiModel.TokenEstimatePaddingPct,
+		InputPricePerMillion:        apiModel.InputPricePerMillion,
+		OutputPricePerMillion:       apiModel.OutputPricePerMillion,
+		CachedInputPricePerMillion:  apiModel.CachedInputPricePerMillion,
+		IsEmbeddingModel:            apiModel.IsEmbeddingModel,
 		Providers:                   providers,
 	}
 
@@ -449,6 +469,10 @@ func (model *CustomModel) ToApi() *shared.CustomModel {
 			SupportsCacheControl:        model.SupportsCacheControl,
 			SingleMessageNoSystemPrompt: model.SingleMessageNoSystemPrompt,
 			TokenEstimatePaddingPct:     model.TokenEstimatePaddingPct,
+			InputPricePerMillion:        model.InputPricePerMillion,
+			OutputPricePerMillion:       model.OutputPricePerMillion,
+			CachedInputPricePerMillion:  model.CachedInputPricePerMillion,
+			IsEmbeddingModel:            model.IsEmbeddingModel,
 
 			ModelCompatibility: shared.ModelCompatibility{
 				HasImageSupport: model.HasImageSupport,
@@ -489,8 +513,13 @@ type CustomProvider struct {
 	SkipAuth      bool          `db:"skip_auth"`
 	ApiKeyEnvVar  string        `db:"api_key_env_var"`
 	ExtraAuthVars ExtraAuthVars `db:"extra_auth_vars"`
-	CreatedAt     time.Time     `db:"created_at"`
-	UpdatedAt     time.Time     `db:"updated_at"`
+
+	RequestsPerMinute     int `db:"requests_per_minute"`
+	TokensPerMinute       int `db:"tokens_per_minute"`
+	MaxConcurrentRequests int `db:"max_concurrent_requests"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 func CustomProviderFromApi(apiProvider *shared.CustomProvider) *CustomProvider {
@@ -501,6 +530,10 @@ func CustomProviderFromApi(apiProvider *shared.CustomProvider) *CustomProvider {
 		SkipAuth:      apiProvider.SkipAuth,
 		ApiKeyEnvVar:  apiProvider.ApiKeyEnvVar,
 		ExtraAuthVars: apiProvider.ExtraAuthVars,
+
+		RequestsPerMinute:     apiProvider.RequestsPerMinute,
+		TokensPerMinute:       apiProvider.TokensPerMinute,
+		MaxConcurrentRequests: apiProvider.MaxConcurrentRequests,
 	}
 }
 
@@ -512,6 +545,12 @@ func (provider *CustomProvider) ToApi() *shared.CustomProvider {
 		SkipAuth:      provider.SkipAuth,
 		ApiKeyEnvVar:  provider.ApiKeyEnvVar,
 		ExtraAuthVars: provider.ExtraAuthVars,
+
+		ModelProviderRateLimits: shared.ModelProviderRateLimits{
+			RequestsPerMinute:     provider.RequestsPerMinute,
+			TokensPerMinute:       provider.TokensPerMinute,
+			MaxConcurrentRequests: provider.MaxConcurrentRequests,
+		},
 	}
 }
 
@@ -558,6 +597,316 @@ type DefaultPlanSettings struct {
 	UpdatedAt    time.Time           `db:"updated_at"`
 }
 
+type McpServerArgs []string
+
+func (args *McpServerArgs) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, args)
+	case string:
+		return json.Unmarshal([]byte(s), args)
+	}
+
+	return fmt.Errorf("unsupported data type: %T", src)
+}
+
+func (args McpServerArgs) Value() (driver.Value, error) {
+	if args == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(args)
+}
+
+type McpServerVars map[string]string
+
+func (vars *McpServerVars) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, vars)
+	case string:
+		return json.Unmarshal([]byte(s), vars)
+	}
+
+	return fmt.Errorf("unsupported data type: %T", src)
+}
+
+func (vars McpServerVars) Value() (driver.Value, error) {
+	if vars == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(vars)
+}
+
+type McpServer struct {
+	Id        string              `db:"id"`
+	OrgId     string              `db:"org_id"`
+	ProjectId string              `db:"project_id"`
+	Name      string              `db:"name"`
+	Transport shared.McpTransport `db:"transport"`
+	Command   string              `db:"command"`
+	Args      McpServerArgs       `db:"args"`
+	Env       McpServerVars       `db:"env"`
+	Url       string              `db:"url"`
+	Headers   McpServerVars       `db:"headers"`
+	Enabled   bool                `db:"enabled"`
+	CreatedAt time.Time           `db:"created_at"`
+	UpdatedAt time.Time           `db:"updated_at"`
+}
+
+// ToApi masks env and header values since they often hold credentials
+func (server *McpServer) ToApi() *shared.McpServer {
+	mask := func(vars McpServerVars) map[string]string {
+		if len(vars) == 0 {
+			return nil
+		}
+		res := make(map[string]string, len(vars))
+		for k := range vars {
+			res[k] = shared.McpRedactedValue
+		}
+		return res
+	}
+
+	return &shared.McpServer{
+		Id:        server.Id,
+		ProjectId: server.ProjectId,
+		Name:      server.Name,
+		Transport: server.Transport,
+		Command:   server.Command,
+		Args:      server.Args,
+		Env:       mask(server.Env),
+		Url:       server.Url,
+		Headers:   mask(server.Headers),
+		Enabled:   server.Enabled,
+		CreatedAt: server.CreatedAt,
+		UpdatedAt: server.UpdatedAt,
+	}
+}
+
+type Webhook struct {
+	Id        string         `db:"id"`
+	OrgId     string         `db:"org_id"`
+	ProjectId *string        `db:"project_id"`
+	Url       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	Enabled   bool           `db:"enabled"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+// ToApi leaves out the secret, which is only returned when the webhook is created
+func (webhook *Webhook) ToApi() *shared.Webhook {
+	events := make([]shared.WebhookEvent, len(webhook.Events))
+	for i, event := range webhook.Events {
+		events[i] = shared.WebhookEvent(event)
+	}
+
+	return &shared.Webhook{
+		Id:        webhook.Id,
+		ProjectId: webhook.ProjectId,
+		Url:       webhook.Url,
+		Events:    events,
+		Enabled:   webhook.Enabled,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	Id             string                       `db:"id"`
+	OrgId          string                       `db:"org_id"`
+	WebhookId      string                       `db:"webhook_id"`
+	Event          shared.WebhookEvent          `db:"event"`
+	Payload        string                       `db:"payload"`
+	Status         shared.WebhookDeliveryStatus `db:"status"`
+	Attempts       int                          `db:"attempts"`
+	ResponseStatus *int                         `db:"response_status"`
+	Error          *string                      `db:"error"`
+	NextAttemptAt  time.Time                    `db:"next_attempt_at"`
+	LastAttemptAt  *time.Time                   `db:"last_attempt_at"`
+	CreatedAt      time.Time                    `db:"created_at"`
+	UpdatedAt      time.Time                    `db:"updated_at"`
+}
+
+func (delivery *WebhookDelivery) ToApi() *shared.WebhookDelivery {
+	res := &shared.WebhookDelivery{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		Event:         delivery.Event,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		LastAttemptAt: delivery.LastAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+	if delivery.ResponseStatus != nil {
+		res.ResponseStatus = *delivery.ResponseStatus
+	}
+	if delivery.Error != nil {
+		res.Error = *delivery.Error
+	}
+	if delivery.Status == shared.WebhookDeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		res.NextAttemptAt = &nextAttemptAt
+	}
+	return res
+}
+
+type PlanTemplate struct {
+	Id            string              `db:"id"`
+	OrgId         string              `db:"org_id"`
+	CreatorId     *string             `db:"creator_id"`
+	Name          string              `db:"name"`
+	Description   string              `db:"description"`
+	Prompt        string              `db:"prompt"`
+	ContextGlobs  pq.StringArray      `db:"context_globs"`
+	ModelPackName string              `db:"model_pack_name"`
+	AutoMode      shared.AutoModeType `db:"auto_mode"`
+	PlandexIgnore pq.StringArray      `db:"plandex_ignore"`
+	CreatedAt     time.Time           `db:"created_at"`
+	UpdatedAt     time.Time           `db:"updated_at"`
+}
+
+func (template *PlanTemplate) ToApi() *shared.PlanTemplate {
+	return &shared.PlanTemplate{
+		Id:            template.Id,
+		Name:          template.Name,
+		Description:   template.Description,
+		Prompt:        template.Prompt,
+		ContextGlobs:  template.ContextGlobs,
+		ModelPackName: template.ModelPackName,
+		AutoMode:      template.AutoMode,
+		PlandexIgnore: template.PlandexIgnore,
+		CreatedAt:     template.CreatedAt,
+		UpdatedAt:     template.UpdatedAt,
+	}
+}
+
+type PlanJob struct {
+	Id            string               `db:"id"`
+	OrgId         string               `db:"org_id"`
+	PlanId        string               `db:"plan_id"`
+	CreatorId     string               `db:"creator_id"`
+	Branch        string               `db:"branch"`
+	Prompt        string               `db:"prompt"`
+	AutoMode      shared.AutoModeType  `db:"auto_mode"`
+	Schedule      string               `db:"schedule"`
+	Status        shared.PlanJobStatus `db:"status"`
+	LastRunStatus shared.PlanJobStatus `db:"last_run_status"`
+	LastError     string               `db:"last_error"`
+	NumRuns       int                  `db:"num_runs"`
+	NextRunAt     *time.Time           `db:"next_run_at"`
+	LastRunAt     *time.Time           `db:"last_run_at"`
+	FinishedAt    *time.Time           `db:"finished_at"`
+	HeartbeatAt   *time.Time           `db:"heartbeat_at"`
+	CreatedAt     time.Time            `db:"created_at"`
+	UpdatedAt     time.Time            `db:"updated_at"`
+}
+
+func (job *PlanJob) ToApi() *shared.PlanJob {
+	return &shared.PlanJob{
+		Id:            job.Id,
+		PlanId:        job.PlanId,
+		Branch:        job.Branch,
+		Prompt:        job.Prompt,
+		AutoMode:      job.AutoMode,
+		Schedule:      job.Schedule,
+		Status:        job.Status,
+		LastRunStatus: job.LastRunStatus,
+		LastError:     job.LastError,
+		NumRuns:       job.NumRuns,
+		NextRunAt:     job.NextRunAt,
+		LastRunAt:     job.LastRunAt,
+		FinishedAt:    job.FinishedAt,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     job.UpdatedAt,
+	}
+}
+
+type PlanShare struct {
+	Id        string               `db:"id"`
+	OrgId     string               `db:"org_id"`
+	PlanId    string               `db:"plan_id"`
+	UserId    string               `db:"user_id"`
+	Role      shared.PlanShareRole `db:"role"`
+	CreatedBy string               `db:"created_by"`
+	CreatedAt time.Time            `db:"created_at"`
+	UpdatedAt time.Time            `db:"updated_at"`
+
+	// joined from users when listing shares
+	UserEmail string `db:"user_email"`
+	UserName  string `db:"user_name"`
+}
+
+func (share *PlanShare) ToApi() *shared.PlanShare {
+	return &shared.PlanShare{
+		Id:        share.Id,
+		PlanId:    share.PlanId,
+		UserId:    share.UserId,
+		UserEmail: share.UserEmail,
+		UserName:  share.UserName,
+		Role:      share.Role,
+		CreatedBy: share.CreatedBy,
+		CreatedAt: share.CreatedAt,
+		UpdatedAt: share.UpdatedAt,
+	}
+}
+
+type ApiToken struct {
+	Id          string         `db:"id"`
+	OrgId       string         `db:"org_id"`
+	UserId      string         `db:"user_id"`
+	CreatorId   string         `db:"creator_id"`
+	Name        string         `db:"name"`
+	TokenPrefix string         `db:"token_prefix"`
+	TokenHash   string         `db:"token_hash"`
+	Scopes      pq.StringArray `db:"scopes"`
+	ExpiresAt   *time.Time     `db:"expires_at"`
+	LastUsedAt  *time.Time     `db:"last_used_at"`
+	RevokedAt   *time.Time     `db:"revoked_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+
+	// joined from users when listing tokens
+	UserEmail        string `db:"user_email"`
+	UserName         string `db:"user_name"`
+	IsServiceAccount bool   `db:"is_service_account"`
+}
+
+func (token *ApiToken) ApiScopes() []shared.ApiTokenScope {
+	scopes := make([]shared.ApiTokenScope, len(token.Scopes))
+	for i, scope := range token.Scopes {
+		scopes[i] = shared.ApiTokenScope(scope)
+	}
+	return scopes
+}
+
+// ToApi leaves out the hash—the token itself is only returned when it's created
+func (token *ApiToken) ToApi() *shared.ApiToken {
+	return &shared.ApiToken{
+		Id:               token.Id,
+		Name:             token.Name,
+		Scopes:           token.ApiScopes(),
+		UserId:           token.UserId,
+		UserEmail:        token.UserEmail,
+		UserName:         token.UserName,
+		IsServiceAccount: token.IsServiceAccount,
+		CreatorId:        token.CreatorId,
+		TokenPrefix:      token.TokenPrefix,
+		ExpiresAt:        token.ExpiresAt,
+		LastUsedAt:       token.LastUsedAt,
+		RevokedAt:        token.RevokedAt,
+		CreatedAt:        token.CreatedAt,
+	}
+}
+
 // Models below are stored in files, not in the database.
 // This allows us to store them in a git repo and use git to manage history.
 
@@ -581,6 +930,7 @@ type Context struct {
 	MapShas         map[string]string     `json:"mapShas,omitempty"`
 	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
 	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
+	GitDiff         *shared.GitDiffSource `json:"gitDiff,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	UpdatedAt       time.Time             `json:"updatedAt"`
@@ -607,6 +957,7 @@ func (context *Context) ToMeta() *Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		GitDiff:         context.GitDiff,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -631,6 +982,7 @@ func (context *Context) ToApi() *shared.Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		GitDiff:         context.GitDiff,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -652,6 +1004,7 @@ type ConvoMessage struct {
 	Flags                 shared.ConvoMessageFlags `json:"flags"`
 	ActivatedPaths        map[string]bool          `json:"activatePaths,omitempty"`
 	ActivatedPathsOrdered []string                 `json:"activatePathsOrdered,omitempty"`
+	ToolCalls             []*shared.ConvoToolCall  `json:"toolCalls,omitempty"`
 	CreatedAt             time.Time                `json:"createdAt"`
 }
 
@@ -672,6 +1025,7 @@ func (msg *ConvoMessage) ToApi() *shared.ConvoMessage {
 		Subtask:         msg.Subtask.ToApi(),
 		AddedSubtasks:   addedSubtasks,
 		RemovedSubtasks: msg.RemovedSubtasks,
+		ToolCalls:       msg.ToolCalls,
 		CreatedAt:       msg.CreatedAt,
 	}
 }
@@ -800,3 +1154,157 @@ func (subtask *Subtask) ToApi() *shared.Subtask {
 		IsFinished:  subtask.IsFinished,
 	}
 }
+
+type ModelUsage struct {
+	Id            string               `db:"id"`
+	OrgId         string               `db:"org_id"`
+	UserId        *string              `db:"user_id"`
+	PlanId        *string              `db:"plan_id"`
+	PlanName      *string              `db:"plan_name"`
+	SessionId     *string              `db:"session_id"`
+	ModelId       shared.ModelId       `db:"model_id"`
+	ModelName     shared.ModelName     `db:"model_name"`
+	ModelProvider shared.ModelProvider `db:"model_provider"`
+	ModelRole     shared.ModelRole     `db:"model_role"`
+	ModelPackName string               `db:"model_pack_name"`
+	Purpose       string               `db:"purpose"`
+	GenerationId  *string              `db:"generation_id"`
+
+	InputTokens  int `db:"input_tokens"`
+	OutputTokens int `db:"output_tokens"`
+	CachedTokens int `db:"cached_tokens"`
+
+	InputPricePerMillion       decimal.Decimal `db:"input_price_per_million"`
+	OutputPricePerMillion      decimal.Decimal `db:"output_price_per_million"`
+	CachedInputPricePerMillion decimal.Decimal `db:"cached_input_price_per_million"`
+	Cost                       decimal.Decimal `db:"cost"`
+	CacheSavings               decimal.Decimal `db:"cache_savings"`
+
+	StoppedEarly    bool `db:"stopped_early"`
+	HadError        bool `db:"had_error"`
+	NoReportedUsage bool `db:"no_reported_usage"`
+
+	CreatedAt time.Time `db:"created_at"`
+
+	// joined when listing
+	CurrentPlanName *string `db:"current_plan_name"`
+	UserName        *string `db:"user_name"`
+	UserEmail       *string `db:"user_email"`
+}
+
+// ToApi maps a ledger entry onto the debit transaction shape used by `plandex usage --log`
+func (usage *ModelUsage) ToApi() *shared.CreditsTransaction {
+	million := decimal.NewFromInt(1000000)
+	inputPricePerToken := usage.InputPricePerMillion.Div(million)
+	outputPricePerToken := usage.OutputPricePerMillion.Div(million)
+	surcharge := decimal.Zero
+
+	planName := usage.PlanName
+	if usage.CurrentPlanName != nil {
+		planName = usage.CurrentPlanName
+	}
+
+	var cacheDiscount *decimal.Decimal
+	if !usage.CacheSavings.IsZero() {
+		cacheDiscount = &usage.CacheSavings
+	}
+
+	return &shared.CreditsTransaction{
+		Id:              usage.Id,
+		OrgId:           usage.OrgId,
+		UserId:          usage.UserId,
+		UserEmail:       usage.UserEmail,
+		UserName:        usage.UserName,
+		TransactionType: shared.CreditsTransactionTypeDebit,
+		Amount:          usage.Cost,
+
+		DebitInputTokens:              &usage.InputTokens,
+		DebitOutputTokens:             &usage.OutputTokens,
+		DebitModelInputPricePerToken:  &inputPricePerToken,
+		DebitModelOutputPricePerToken: &outputPricePerToken,
+		DebitBaseAmount:               &usage.Cost,
+		DebitSurcharge:                &surcharge,
+		DebitModelProvider:            &usage.ModelProvider,
+		DebitModelName:                (*string)(&usage.ModelName),
+		DebitModelPackName:            &usage.ModelPackName,
+		DebitModelRole:                &usage.ModelRole,
+		DebitPurpose:                  &usage.Purpose,
+		DebitPlanId:                   usage.PlanId,
+		DebitPlanName:                 planName,
+		DebitId:                       usage.GenerationId,
+		DebitCacheDiscount:            cacheDiscount,
+		DebitSessionId:                usage.SessionId,
+
+		CreatedAt: usage.CreatedAt,
+	}
+}
+
+type ModelPriceOverride struct {
+	Id                         string          `db:"id"`
+	OrgId                      string          `db:"org_id"`
+	ModelId                    shared.ModelId  `db:"model_id"`
+	InputPricePerMillion       decimal.Decimal `db:"input_price_per_million"`
+	OutputPricePerMillion      decimal.Decimal `db:"output_price_per_million"`
+	CachedInputPricePerMillion decimal.Decimal `db:"cached_input_price_per_million"`
+	CreatedAt                  time.Time       `db:"created_at"`
+	UpdatedAt                  time.Time       `db:"updated_at"`
+}
+
+func (override *ModelPriceOverride) ToApi() *shared.ModelPrice {
+	return &shared.ModelPrice{
+		ModelId:                    override.ModelId,
+		InputPricePerMillion:       override.InputPricePerMillion,
+		OutputPricePerMillion:      override.OutputPricePerMillion,
+		CachedInputPricePerMillion: override.CachedInputPricePerMillion,
+		IsOverride:                 true,
+		UpdatedAt:                  &override.UpdatedAt,
+	}
+}
+
+type AuditEvent struct {
+	Id         string             `db:"id"`
+	OrgId      string             `db:"org_id"`
+	Action     shared.AuditAction `db:"action"`
+	UserId     *string            `db:"user_id"`
+	UserEmail  *string            `db:"user_email"`
+	ApiTokenId *string            `db:"api_token_id"`
+	Ip         *string            `db:"ip"`
+	PlanId     *string            `db:"plan_id"`
+	PlanName   *string            `db:"plan_name"`
+	Branch     *string            `db:"branch"`
+	TargetId   *string            `db:"target_id"`
+	Data       *string            `db:"data"`
+	CreatedAt  time.Time          `db:"created_at"`
+}
+
+func (event *AuditEvent) ToApi() *shared.AuditEvent {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	res := &shared.AuditEvent{
+		Id:         event.Id,
+		Action:     event.Action,
+		UserId:     deref(event.UserId),
+		UserEmail:  deref(event.UserEmail),
+		ApiTokenId: deref(event.ApiTokenId),
+		Ip:         deref(event.Ip),
+		PlanId:     deref(event.PlanId),
+		PlanName:   deref(event.PlanName),
+		Branch:     deref(event.Branch),
+		TargetId:   deref(event.TargetId),
+		CreatedAt:  event.CreatedAt,
+	}
+
+	if event.Data != nil {
+		err := json.Unmarshal([]byte(*event.Data), &res.Data)
+		if err != nil {
+			log.Printf("Error unmarshalling audit event data for %s: %v\n", event.Id, err)
+		}
+	}
+
+	return res
+}
@@ -87,6 +87,10 @@ func getProjectMapCacheDir(orgId, projectId string) string {
 	return filepath.Join(getProjectDir(orgId, projectId), "map_cache")
 }
 
+func getProjectSemanticIndexDir(orgId, projectId string) string {
+	return filepath.Join(getProjectDir(orgId, projectId), "semantic_index")
+}
+
 func getPlanDir(orgId, planId string) string {
 	return filepath.Join(getOrgDir(orgId), "plans", planId)
 }
@@ -237,7 +237,7 @@ func (repo *GitRepo) GetLatestCommitShaBeforeTime(branch string, before time.Tim
 
 	// log.Printf("ADMIN - Git formatted time: %s", gitFormattedTime)
 
-	cmd := exec.Command("git", "-C", dir, "log", "-n", "1",
+	cmd := exec.Command("git", "-C", dir, "log", "--first-parent", "-n", "1",
 		"--before="+gitFormattedTime,
 		"--pretty=%h@@|@@%B@>>>@")
 	log.Printf("ADMIN - Executing command: %s", cmd.String())
@@ -468,7 +468,7 @@ func gitRewindToSha(repoDir, sha string) error {
 
 func getLatestCommit(dir string) (sha, body string, err error) {
 	var out bytes.Buffer
-	cmd := exec.Command("git", "log", "--pretty=%h@@|@@%at@@|@@%B@>>>@")
+	cmd := exec.Command("git", "log", "--first-parent", "--pretty=%h@@|@@%at@@|@@%B@>>>@")
 	cmd.Dir = dir
 	cmd.Stdout = &out
 	err = cmd.Run()
@@ -489,7 +489,7 @@ func getLatestCommit(dir string) (sha, body string, err error) {
 
 func getGitCommitHistory(dir string) (body string, shas []string, err error) {
 	var out bytes.Buffer
-	cmd := exec.Command("git", "log", "--pretty=%h@@|@@%at@@|@@%B@>>>@")
+	cmd := exec.Command("git", "log", "--first-parent", "--pretty=%h@@|@@%at@@|@@%B@>>>@")
 	cmd.Dir = dir
 	cmd.Stdout = &out
 	err = cmd.Run()
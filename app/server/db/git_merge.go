@@ -0,0 +1,136 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func (repo *GitRepo) GitResolveCommit(ref string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("commit not found: %s", ref)
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+func (repo *GitRepo) GitMergeBase(a, b string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "merge-base", a, b).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting merge base for %s and %s: %v, output: %s", a, b, err, string(res))
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitIsAncestor returns true if ancestor is reachable from ref
+func (repo *GitRepo) GitIsAncestor(ancestor, ref string) (bool, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", ancestor, ref).CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking whether %s is an ancestor of %s: %v, output: %s", ancestor, ref, err, string(res))
+	}
+
+	return true, nil
+}
+
+// GitChangedFiles returns the status (A, M, or D) of each file that differs between two commits, keyed by path relative to the plan dir
+func (repo *GitRepo) GitChangedFiles(from, to string) (map[string]string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "diff", "--name-status", "--no-renames", "-z", from, to).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting changed files between %s and %s: %v", from, to, err)
+	}
+
+	changed := map[string]string{}
+
+	// with -z, output alternates between status and path, each NUL-terminated
+	parts := strings.Split(strings.TrimSuffix(string(res), "\x00"), "\x00")
+	for i := 0; i+1 < len(parts); i += 2 {
+		status := parts[i]
+		if status == "" {
+			continue
+		}
+		changed[parts[i+1]] = status[:1]
+	}
+
+	return changed, nil
+}
+
+func (repo *GitRepo) GitShowFile(ref, path string) ([]byte, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "show", ref+":"+path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at %s: %v", path, ref, err)
+	}
+
+	return res, nil
+}
+
+func (repo *GitRepo) GitCommitSubject(ref string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "show", "-s", "--format=%s", ref).Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting commit subject for %s: %v", ref, err)
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitFastForward moves the checked out branch up to source. Only succeeds if the branch has no commits that source doesn't.
+func (repo *GitRepo) GitFastForward(branch, source string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "merge", "--ff-only", source).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error fast-forwarding %s to %s for dir: %s, err: %v, output: %s", branch, source, dir, err, string(res))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitFastForward > gitMerge: plan=%s branch=%s source=%s", repo.planId, branch, source))
+
+	return err
+}
+
+// GitStartMerge begins a merge of source into the checked out branch without changing any files, so that the next commit records source as a second parent. The merged files are written separately, then committed with GitAddAndCommit.
+func (repo *GitRepo) GitStartMerge(branch, source string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "merge", "--no-ff", "--no-commit", "-s", "ours", source).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error starting merge of %s into %s for dir: %s, err: %v, output: %s", source, branch, dir, err, string(res))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitStartMerge > gitMerge: plan=%s branch=%s source=%s", repo.planId, branch, source))
+
+	return err
+}
+
+func (repo *GitRepo) GitAbortMerge(branch string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "merge", "--abort").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error aborting merge into %s for dir: %s, err: %v, output: %s", branch, dir, err, string(res))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitAbortMerge > gitMerge: plan=%s branch=%s", repo.planId, branch))
+
+	return err
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func ListMcpServers(orgId, projectId string) ([]*McpServer, error) {
+	var servers []*McpServer
+	err := Conn.Select(&servers, "SELECT * FROM mcp_servers WHERE org_id = $1 AND project_id = $2 ORDER BY name", orgId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing mcp servers: %v", err)
+	}
+	return servers, nil
+}
+
+func ListEnabledMcpServers(orgId, projectId string) ([]*McpServer, error) {
+	var servers []*McpServer
+	err := Conn.Select(&servers, "SELECT * FROM mcp_servers WHERE org_id = $1 AND project_id = $2 AND enabled ORDER BY name", orgId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing enabled mcp servers: %v", err)
+	}
+	return servers, nil
+}
+
+// GetMcpServer returns nil with no error if the server doesn't exist
+func GetMcpServer(orgId, projectId, name string) (*McpServer, error) {
+	var server McpServer
+	err := Conn.Get(&server, "SELECT * FROM mcp_servers WHERE org_id = $1 AND project_id = $2 AND name = $3", orgId, projectId, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting mcp server: %v", err)
+	}
+	return &server, nil
+}
+
+func UpsertMcpServer(orgId, projectId string, req shared.UpsertMcpServerRequest) (*McpServer, error) {
+	server := McpServer{
+		OrgId:     orgId,
+		ProjectId: projectId,
+		Name:      req.Name,
+		Transport: req.Transport,
+		Command:   req.Command,
+		Args:      McpServerArgs(req.Args),
+		Env:       McpServerVars(req.Env),
+		Url:       req.Url,
+		Headers:   McpServerVars(req.Headers),
+		Enabled:   req.Enabled,
+	}
+
+	err := Conn.QueryRow(`INSERT INTO mcp_servers (org_id, project_id, name, transport, command, args, env, url, headers, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (project_id, name) DO UPDATE SET
+  transport = EXCLUDED.transport,
+  command = EXCLUDED.command,
+  args = EXCLUDED.args,
+  env = EXCLUDED.env,
+  url = EXCLUDED.url,
+  headers = EXCLUDED.headers,
+  enabled = EXCLUDED.enabled
+RETURNING id, created_at, updated_at`,
+		server.OrgId, server.ProjectId, server.Name, server.Transport, server.Command, server.Args, server.Env, server.Url, server.Headers, server.Enabled,
+	).Scan(&server.Id, &server.CreatedAt, &server.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error upserting mcp server: %v", err)
+	}
+
+	return &server, nil
+}
+
+func SetMcpServerEnabled(orgId, projectId, name string, enabled bool) (bool, error) {
+	res, err := Conn.Exec("UPDATE mcp_servers SET enabled = $4 WHERE org_id = $1 AND project_id = $2 AND name = $3", orgId, projectId, name, enabled)
+	if err != nil {
+		return false, fmt.Errorf("error updating mcp server: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+func DeleteMcpServer(orgId, projectId, name string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM mcp_servers WHERE org_id = $1 AND project_id = $2 AND name = $3", orgId, projectId, name)
+	if err != nil {
+		return false, fmt.Errorf("error deleting mcp server: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+// MergeMcpServerVars applies an update to stored env or header values so that a client can't wipe secrets by leaving them out or sending back redacted values
+func MergeMcpServerVars(existing McpServerVars, update map[string]string) (McpServerVars, error) {
+	merged := McpServerVars{}
+	for k, v := range existing {
+		merged[k] = v
+	}
+
+	for k, v := range update {
+		switch v {
+		case shared.McpRedactedValue:
+			if _, ok := existing[k]; !ok {
+				return nil, fmt.Errorf("%s has a redacted value but no stored value to keep", k)
+			}
+		case "":
+			delete(merged, k)
+		default:
+			merged[k] = v
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestMergeMcpServerVars(t *testing.T) {
+	existing := McpServerVars{"API_KEY": "secret", "REGION": "us"}
+
+	t.Run("omitted values are kept", func(t *testing.T) {
+		got, err := MergeMcpServerVars(existing, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, existing) {
+			t.Errorf("got %v, want %v", got, existing)
+		}
+	})
+
+	t.Run("redacted values are kept and others updated or removed", func(t *testing.T) {
+		got, err := MergeMcpServerVars(existing, map[string]string{
+			"API_KEY": shared.McpRedactedValue,
+			"REGION":  "",
+			"DEBUG":   "1",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := McpServerVars{"API_KEY": "secret", "DEBUG": "1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("redacted value with nothing stored is an error", func(t *testing.T) {
+		_, err := MergeMcpServerVars(nil, map[string]string{"API_KEY": shared.McpRedactedValue})
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
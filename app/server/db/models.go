@@ -22,7 +22,8 @@ INSERT INTO custom_models (
     predicted_output_enabled, reasoning_effort_enabled, reasoning_effort,
     include_reasoning, reasoning_budget, supports_cache_control,
     single_message_no_system_prompt, token_estimate_padding_pct,
-    providers
+    input_price_per_million, output_price_per_million, cached_input_price_per_million,
+    is_embedding_model, providers
 )
 VALUES (
     $1,$2,
@@ -33,7 +34,8 @@ VALUES (
     $14,$15,$16,
     $17,$18,$19,
     $20,$21,
-    $22
+    $22,$23,$24,
+    $25,$26
 )
 ON CONFLICT (org_id, model_id)
 DO UPDATE SET
@@ -56,6 +58,10 @@ DO UPDATE SET
     supports_cache_control        = EXCLUDED.supports_cache_control,
     single_message_no_system_prompt = EXCLUDED.single_message_no_system_prompt,
     token_estimate_padding_pct    = EXCLUDED.token_estimate_padding_pct,
+    input_price_per_million       = EXCLUDED.input_price_per_million,
+    output_price_per_million      = EXCLUDED.output_price_per_million,
+    cached_input_price_per_million = EXCLUDED.cached_input_price_per_million,
+    is_embedding_model            = EXCLUDED.is_embedding_model,
     providers                     = EXCLUDED.providers
 RETURNING id, created_at, updated_at;
 `
@@ -83,6 +89,10 @@ RETURNING id, created_at, updated_at;
 		model.SupportsCacheControl,
 		model.SingleMessageNoSystemPrompt,
 		model.TokenEstimatePaddingPct,
+		model.InputPricePerMillion,
+		model.OutputPricePerMillion,
+		model.CachedInputPricePerMillion,
+		model.IsEmbeddingModel,
 		model.Providers,
 	).Scan(&model.Id, &model.CreatedAt, &model.UpdatedAt)
 }
@@ -130,18 +140,23 @@ func UpsertCustomProvider(tx *sqlx.Tx, p *CustomProvider) error {
 	const q = `
 INSERT INTO custom_providers (
 	  org_id, name, base_url,
-	  skip_auth, api_key_env_var, extra_auth_vars
+	  skip_auth, api_key_env_var, extra_auth_vars,
+	  requests_per_minute, tokens_per_minute, max_concurrent_requests
 )
 VALUES (
 	  $1,$2,$3,
-	  $4,$5,$6
+	  $4,$5,$6,
+	  $7,$8,$9
 )
 ON CONFLICT (org_id, name)
 DO UPDATE SET
-	  base_url        = EXCLUDED.base_url,
-	  skip_auth       = EXCLUDED.skip_auth,
-	  api_key_env_var = EXCLUDED.api_key_env_var,
-	  extra_auth_vars = EXCLUDED.extra_auth_vars
+	  base_url                = EXCLUDED.base_url,
+	  skip_auth               = EXCLUDED.skip_auth,
+	  api_key_env_var         = EXCLUDED.api_key_env_var,
+	  extra_auth_vars         = EXCLUDED.extra_auth_vars,
+	  requests_per_minute     = EXCLUDED.requests_per_minute,
+	  tokens_per_minute       = EXCLUDED.tokens_per_minute,
+	  max_concurrent_requests = EXCLUDED.max_concurrent_requests
 RETURNING id, created_at, updated_at;
 `
 	return tx.QueryRow(
@@ -152,6 +167,9 @@ RETURNING id, created_at, updated_at;
 		p.SkipAuth,
 		p.ApiKeyEnvVar,
 		p.ExtraAuthVars,
+		p.RequestsPerMinute,
+		p.TokensPerMinute,
+		p.MaxConcurrentRequests,
 	).Scan(&p.Id, &p.CreatedAt, &p.UpdatedAt)
 }
 
@@ -200,13 +218,15 @@ INSERT INTO model_sets (
 	  org_id, name, description,
 	  planner, coder, plan_summary,
 	  builder, whole_file_builder, namer,
-	  commit_msg, exec_status, context_loader
+	  commit_msg, exec_status, context_loader,
+	  embeddings
 )
 VALUES (
 	  $1,$2,$3,
 	  $4,$5,$6,
 	  $7,$8,$9,
-	  $10,$11,$12
+	  $10,$11,$12,
+	  $13
 )
 ON CONFLICT (org_id, name)
 DO UPDATE SET
@@ -219,7 +239,8 @@ DO UPDATE SET
 	  namer              = EXCLUDED.namer,
 	  commit_msg         = EXCLUDED.commit_msg,
 	  exec_status        = EXCLUDED.exec_status,
-	  context_loader     = EXCLUDED.context_loader
+	  context_loader     = EXCLUDED.context_loader,
+	  embeddings         = EXCLUDED.embeddings
 RETURNING id, created_at;
 `
 	return tx.QueryRow(
@@ -236,6 +257,7 @@ RETURNING id, created_at;
 		mp.CommitMsg,
 		mp.ExecStatus,
 		mp.Architect,
+		mp.Embeddings,
 	).Scan(&mp.Id, &mp.CreatedAt)
 }
 
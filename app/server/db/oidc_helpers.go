@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetOidcIdentityUser returns the user linked to an identity provider subject, or nil if there isn't one
+func GetOidcIdentityUser(issuer, subject string) (*User, error) {
+	var user User
+	err := Conn.Get(&user, `SELECT users.* FROM users
+JOIN oidc_identities ON oidc_identities.user_id = users.id
+WHERE oidc_identities.issuer = $1 AND oidc_identities.subject = $2`, issuer, subject)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting oidc identity user: %v", err)
+	}
+
+	return &user, nil
+}
+
+// UpsertOidcIdentity links an identity provider subject to a user and records the sign in
+func UpsertOidcIdentity(userId, issuer, subject string, tx *sqlx.Tx) error {
+	_, err := tx.Exec(`INSERT INTO oidc_identities (user_id, issuer, subject) VALUES ($1, $2, $3)
+ON CONFLICT (issuer, subject) DO UPDATE SET last_sign_in_at = NOW()`, userId, issuer, subject)
+
+	if err != nil {
+		return fmt.Errorf("error upserting oidc identity: %v", err)
+	}
+
+	return nil
+}
+
+// GetOrgRoleByName looks up a built-in role or one of the org's custom roles by name. Returns nil if there's no match.
+func GetOrgRoleByName(orgId, name string) (*OrgRole, error) {
+	var orgRole OrgRole
+	err := Conn.Get(&orgRole, "SELECT * FROM org_roles WHERE (org_id IS NULL OR org_id = $1) AND name = $2 ORDER BY org_id NULLS LAST LIMIT 1", orgId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &orgRole, nil
+}
+
+func UpdateOrgUserRole(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE orgs_users SET org_role_id = $1 WHERE org_id = $2 AND user_id = $3", orgRoleId, orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org user role: %v", err)
+	}
+
+	return nil
+}
+
+// GetSoleOrgId returns the server's org id if there's exactly one org, otherwise an empty string
+func GetSoleOrgId() (string, error) {
+	var orgIds []string
+	err := Conn.Select(&orgIds, "SELECT id FROM orgs LIMIT 2")
+
+	if err != nil {
+		return "", fmt.Errorf("error listing orgs: %v", err)
+	}
+
+	if len(orgIds) != 1 {
+		return "", nil
+	}
+
+	return orgIds[0], nil
+}
@@ -187,6 +187,18 @@ func DeleteOrgUser(orgId, userId string, tx *sqlx.Tx) error {
 		return fmt.Errorf("error deleting org member: %v", err)
 	}
 
+	_, err = tx.Exec("DELETE FROM plan_shares WHERE org_id = $1 AND user_id = $2", orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan shares: %v", err)
+	}
+
+	_, err = tx.Exec("UPDATE api_tokens SET revoked_at = NOW() WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL", orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error revoking api tokens: %v", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,642 @@
+package db
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// A plan archive is a gzipped tarball with two entries:
+//   - manifest.json: the plan's Postgres rows (plan, branches, convo summaries)
+//   - history.fast-export: a `git fast-export --all` stream of the plan's repo (contexts, convo, results, settings, etc. on every branch, with full history)
+// On import, org/plan/project/owner/branch ids are remapped in the history stream's commands, commit messages, and metadata files. Ids are UUIDs, so replacements never change blob lengths.
+
+const PlanArchiveVersion = 1
+
+const (
+	planArchiveManifestName = "manifest.json"
+	planArchiveHistoryName  = "history.fast-export"
+
+	maxPlanArchiveManifestSize = 10 * 1024 * 1024       // 10 MB
+	maxPlanArchiveHistorySize  = 4 * 1024 * 1024 * 1024 // 4 GB uncompressed
+)
+
+type PlanArchiveManifest struct {
+	Version        int                       `json:"version"`
+	ExportedAt     time.Time                 `json:"exportedAt"`
+	Plan           PlanArchivePlan           `json:"plan"`
+	Branches       []PlanArchiveBranch       `json:"branches"`
+	ConvoSummaries []PlanArchiveConvoSummary `json:"convoSummaries"`
+}
+
+type PlanArchivePlan struct {
+	Id           string             `json:"id"`
+	OrgId        string             `json:"orgId"`
+	OwnerId      string             `json:"ownerId"`
+	ProjectId    string             `json:"projectId"`
+	Name         string             `json:"name"`
+	TotalReplies int                `json:"totalReplies"`
+	PlanConfig   *shared.PlanConfig `json:"planConfig"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+type PlanArchiveBranch struct {
+	Id             string            `json:"id"`
+	ParentBranchId *string           `json:"parentBranchId"`
+	Name           string            `json:"name"`
+	Status         shared.PlanStatus `json:"status"`
+	Error          *string           `json:"error"`
+	ContextTokens  int               `json:"contextTokens"`
+	ConvoTokens    int               `json:"convoTokens"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+type PlanArchiveConvoSummary struct {
+	LatestConvoMessageId        string    `json:"latestConvoMessageId"`
+	LatestConvoMessageCreatedAt time.Time `json:"latestConvoMessageCreatedAt"`
+	Summary                     string    `json:"summary"`
+	Tokens                      int       `json:"tokens"`
+	NumMessages                 int       `json:"numMessages"`
+	CreatedAt                   time.Time `json:"createdAt"`
+}
+
+// WritePlanArchive should be called with a root read lock held on the plan (ExecRepoOperation with an empty branch)
+func (repo *GitRepo) WritePlanArchive(plan *Plan, w io.Writer) error {
+	manifest := PlanArchiveManifest{
+		Version:    PlanArchiveVersion,
+		ExportedAt: time.Now(),
+		Plan: PlanArchivePlan{
+			Id:           plan.Id,
+			OrgId:        plan.OrgId,
+			OwnerId:      plan.OwnerId,
+			ProjectId:    plan.ProjectId,
+			Name:         plan.Name,
+			TotalReplies: plan.TotalReplies,
+			PlanConfig:   plan.PlanConfig,
+			CreatedAt:    plan.CreatedAt,
+		},
+		Branches:       []PlanArchiveBranch{},
+		ConvoSummaries: []PlanArchiveConvoSummary{},
+	}
+
+	var branches []*Branch
+	err := Conn.Select(&branches, "SELECT * FROM branches WHERE plan_id = $1 AND archived_at IS NULL AND deleted_at IS NULL ORDER BY created_at", plan.Id)
+	if err != nil {
+		return fmt.Errorf("error getting branches: %v", err)
+	}
+	for _, branch := range branches {
+		manifest.Branches = append(manifest.Branches, PlanArchiveBranch{
+			Id:             branch.Id,
+			ParentBranchId: branch.ParentBranchId,
+			Name:           branch.Name,
+			Status:         branch.Status,
+			Error:          branch.Error,
+			ContextTokens:  branch.ContextTokens,
+			ConvoTokens:    branch.ConvoTokens,
+			CreatedAt:      branch.CreatedAt,
+		})
+	}
+
+	var summaries []*ConvoSummary
+	err = Conn.Select(&summaries, "SELECT * FROM convo_summaries WHERE plan_id = $1 ORDER BY created_at", plan.Id)
+	if err != nil {
+		return fmt.Errorf("error getting convo summaries: %v", err)
+	}
+	for _, summary := range summaries {
+		manifest.ConvoSummaries = append(manifest.ConvoSummaries, PlanArchiveConvoSummary{
+			LatestConvoMessageId:        summary.LatestConvoMessageId,
+			LatestConvoMessageCreatedAt: summary.LatestConvoMessageCreatedAt,
+			Summary:                     summary.Summary,
+			Tokens:                      summary.Tokens,
+			NumMessages:                 summary.NumMessages,
+			CreatedAt:                   summary.CreatedAt,
+		})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling manifest: %v", err)
+	}
+
+	// tar headers need the size up front, so the history stream goes to a temp file first
+	historyFile, err := os.CreateTemp("", "plandex-export-*.fast-export")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %v", err)
+	}
+	defer os.Remove(historyFile.Name())
+	defer historyFile.Close()
+
+	dir := getPlanDir(repo.orgId, repo.planId)
+	cmd := exec.Command("git", "-C", dir, "fast-export", "--all", "--signed-tags=strip")
+	cmd.Stdout = historyFile
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error exporting git history: %v, output: %s", err, stderr.String())
+	}
+
+	historyInfo, err := historyFile.Stat()
+	if err != nil {
+		return fmt.Errorf("error getting history file info: %v", err)
+	}
+	_, err = historyFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("error seeking history file: %v", err)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    planArchiveManifestName,
+		Mode:    0644,
+		Size:    int64(len(manifestBytes)),
+		ModTime: manifest.ExportedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing manifest header: %v", err)
+	}
+	_, err = tw.Write(manifestBytes)
+	if err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    planArchiveHistoryName,
+		Mode:    0644,
+		Size:    historyInfo.Size(),
+		ModTime: manifest.ExportedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing history header: %v", err)
+	}
+	_, err = io.Copy(tw, historyFile)
+	if err != nil {
+		return fmt.Errorf("error writing history: %v", err)
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("error closing tar writer: %v", err)
+	}
+
+	err = gzw.Close()
+	if err != nil {
+		return fmt.Errorf("error closing gzip writer: %v", err)
+	}
+
+	return nil
+}
+
+// ImportPlanArchive creates a new plan in the given project from an archive written by WritePlanArchive
+func ImportPlanArchive(ctx context.Context, orgId, projectId, userId, name string, r io.Reader) (*Plan, error) {
+	historyFile, err := os.CreateTemp("", "plandex-import-*.fast-export")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp file: %v", err)
+	}
+	defer os.Remove(historyFile.Name())
+	defer historyFile.Close()
+
+	manifest, err := readPlanArchive(r, historyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = manifest.Plan.Name
+	}
+
+	plan := &Plan{
+		Id:           uuid.New().String(),
+		OrgId:        orgId,
+		OwnerId:      userId,
+		ProjectId:    projectId,
+		Name:         name,
+		TotalReplies: manifest.Plan.TotalReplies,
+		PlanConfig:   manifest.Plan.PlanConfig,
+	}
+
+	idMap := map[string]string{
+		manifest.Plan.Id:        plan.Id,
+		manifest.Plan.OrgId:     orgId,
+		manifest.Plan.ProjectId: projectId,
+		manifest.Plan.OwnerId:   userId,
+	}
+	for _, branch := range manifest.Branches {
+		idMap[branch.Id] = uuid.New().String()
+	}
+
+	if plan.PlanConfig == nil {
+		plan.PlanConfig, err = GetDefaultPlanConfig(userId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting default plan config: %v", err)
+		}
+	}
+
+	dir := getPlanDir(orgId, plan.Id)
+
+	err = WithTx(ctx, "import plan", func(tx *sqlx.Tx) error {
+		err := tx.QueryRow(
+			`INSERT INTO plans (id, org_id, owner_id, project_id, name, plan_config, total_replies)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING created_at, updated_at`,
+			plan.Id, plan.OrgId, plan.OwnerId, plan.ProjectId, plan.Name, plan.PlanConfig, plan.TotalReplies,
+		).Scan(&plan.CreatedAt, &plan.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("error creating plan: %v", err)
+		}
+
+		_, err = tx.Exec("INSERT INTO lockable_plan_ids (plan_id) VALUES ($1)", plan.Id)
+		if err != nil {
+			return fmt.Errorf("error inserting lockable plan id: %v", err)
+		}
+
+		// insert branches first, then link parents, so order in the manifest doesn't matter
+		for _, branch := range manifest.Branches {
+			status := branch.Status
+			switch status {
+			case shared.PlanStatusReplying, shared.PlanStatusDescribing, shared.PlanStatusBuilding, shared.PlanStatusMissingFile:
+				// a stream can't be running on a plan that was just imported
+				status = shared.PlanStatusStopped
+			}
+
+			_, err = tx.Exec(
+				`INSERT INTO branches (id, org_id, owner_id, plan_id, name, status, error, context_tokens, convo_tokens)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				idMap[branch.Id], orgId, userId, plan.Id, branch.Name, status, branch.Error, branch.ContextTokens, branch.ConvoTokens,
+			)
+			if err != nil {
+				return fmt.Errorf("error creating branch %s: %v", branch.Name, err)
+			}
+		}
+
+		for _, branch := range manifest.Branches {
+			if branch.ParentBranchId == nil {
+				continue
+			}
+			parentId, ok := idMap[*branch.ParentBranchId]
+			if !ok {
+				continue
+			}
+			_, err = tx.Exec("UPDATE branches SET parent_branch_id = $1 WHERE id = $2", parentId, idMap[branch.Id])
+			if err != nil {
+				return fmt.Errorf("error setting parent for branch %s: %v", branch.Name, err)
+			}
+		}
+
+		err = IncActiveBranches(plan.Id, len(manifest.Branches), tx)
+		if err != nil {
+			return fmt.Errorf("error incrementing active branches: %v", err)
+		}
+
+		for _, summary := range manifest.ConvoSummaries {
+			_, err = tx.Exec(
+				`INSERT INTO convo_summaries (org_id, plan_id, latest_convo_message_id, latest_convo_message_created_at, summary, tokens, num_messages, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+				orgId, plan.Id, summary.LatestConvoMessageId, summary.LatestConvoMessageCreatedAt, summary.Summary, summary.Tokens, summary.NumMessages, summary.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error creating convo summary: %v", err)
+			}
+		}
+
+		// the repo goes last so that a failure here rolls back the rows above
+		err = importPlanRepo(dir, historyFile, idMap)
+		if err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Imported plan %s (%s) from archive of plan %s", plan.Id, plan.Name, manifest.Plan.Id)
+
+	return plan, nil
+}
+
+func readPlanArchive(r io.Reader, historyFile *os.File) (*PlanArchiveManifest, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive: %v", err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+
+	var manifest *PlanArchiveManifest
+	hasHistory := false
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive: %v", err)
+		}
+
+		switch header.Name {
+		case planArchiveManifestName:
+			if header.Size > maxPlanArchiveManifestSize {
+				return nil, fmt.Errorf("archive manifest is too large")
+			}
+			bytes, err := io.ReadAll(io.LimitReader(tr, maxPlanArchiveManifestSize))
+			if err != nil {
+				return nil, fmt.Errorf("error reading archive manifest: %v", err)
+			}
+			manifest = &PlanArchiveManifest{}
+			err = json.Unmarshal(bytes, manifest)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing archive manifest: %v", err)
+			}
+
+		case planArchiveHistoryName:
+			if header.Size > maxPlanArchiveHistorySize {
+				return nil, fmt.Errorf("archive history is too large")
+			}
+			_, err := io.Copy(historyFile, io.LimitReader(tr, maxPlanArchiveHistorySize))
+			if err != nil {
+				return nil, fmt.Errorf("error reading archive history: %v", err)
+			}
+			hasHistory = true
+
+		default:
+			log.Printf("readPlanArchive - skipping unknown archive entry: %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("archive is missing %s", planArchiveManifestName)
+	}
+	if !hasHistory {
+		return nil, fmt.Errorf("archive is missing %s", planArchiveHistoryName)
+	}
+	if manifest.Version > PlanArchiveVersion {
+		return nil, fmt.Errorf("archive version %d is newer than this server supports (%d)—upgrade the server to import it", manifest.Version, PlanArchiveVersion)
+	}
+	if manifest.Plan.Id == "" {
+		return nil, fmt.Errorf("archive manifest is missing the plan")
+	}
+
+	hasMain := false
+	for _, branch := range manifest.Branches {
+		if branch.Name == "main" {
+			hasMain = true
+			break
+		}
+	}
+	if !hasMain {
+		return nil, fmt.Errorf("archive manifest is missing the main branch")
+	}
+
+	_, err = historyFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("error seeking history file: %v", err)
+	}
+
+	return manifest, nil
+}
+
+func importPlanRepo(dir string, history io.ReadSeeker, idMap map[string]string) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating plan dir: %v", err)
+	}
+
+	err = initGitRepo(dir)
+	if err != nil {
+		return fmt.Errorf("error initializing git repo: %v", err)
+	}
+
+	cmd := exec.Command("git", "-C", dir, "fast-import", "--quiet")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("error getting fast-import stdin: %v", err)
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("error starting fast-import: %v", err)
+	}
+
+	remapErr := remapPlanArchiveIds(history, stdin, idMap)
+	stdin.Close()
+	waitErr := cmd.Wait()
+
+	if remapErr != nil {
+		return fmt.Errorf("error remapping ids in git history: %v", remapErr)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("error importing git history: %v, output: %s", waitErr, output.String())
+	}
+
+	// fast-import only writes refs—check out main to populate the working tree
+	res, err := exec.Command("git", "-C", dir, "checkout", "-f", "main").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error checking out main after import: %v, output: %s", err, string(res))
+	}
+
+	return nil
+}
+
+// remapPlanArchiveIds rewrites ids in the stream's commands, commit messages, and the blobs of text metadata files. Other blobs (context bodies, file contents, etc.) may be binary and are copied through untouched. Old and new ids are the same length, so 'data' byte counts stay valid.
+// The metadata blobs are only known once a commit references their marks, so the stream is read twice.
+func remapPlanArchiveIds(r io.ReadSeeker, w io.Writer, idMap map[string]string) error {
+	oldIds := [][]byte{}
+	newIds := [][]byte{}
+	for oldId, newId := range idMap {
+		if oldId == "" || oldId == newId {
+			continue
+		}
+		if len(oldId) != len(newId) {
+			return fmt.Errorf("can't remap id %s to %s: ids must be the same length", oldId, newId)
+		}
+		oldIds = append(oldIds, []byte(oldId))
+		newIds = append(newIds, []byte(newId))
+	}
+
+	remap := func(b []byte) []byte {
+		for i, oldId := range oldIds {
+			if bytes.Contains(b, oldId) {
+				b = bytes.ReplaceAll(b, oldId, newIds[i])
+			}
+		}
+		return b
+	}
+
+	metadataMarks := map[string]bool{}
+	err := walkFastExport(r, func(line []byte, state *fastExportState) error {
+		dataRef, path, ok := parseFastExportModify(line)
+		if ok && dataRef != "inline" && isPlanArchiveMetadataPath(path) {
+			metadataMarks[dataRef] = true
+		}
+		return nil
+	}, func(header []byte, data io.Reader, n int64, state *fastExportState) error {
+		_, err := io.CopyN(io.Discard, data, n)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("error seeking history: %v", err)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	err = walkFastExport(r, func(line []byte, state *fastExportState) error {
+		_, err := bw.Write(remap(line))
+		return err
+	}, func(header []byte, data io.Reader, n int64, state *fastExportState) error {
+		_, err := bw.Write(header)
+		if err != nil {
+			return err
+		}
+
+		isText := !state.inBlob && state.inlinePath == "" // commit and tag messages
+		if state.inBlob && metadataMarks[state.blobMark] {
+			isText = true
+		}
+		if state.inlinePath != "" && isPlanArchiveMetadataPath(state.inlinePath) {
+			isText = true
+		}
+
+		if !isText {
+			_, err = io.CopyN(bw, data, n)
+			return err
+		}
+
+		buf := make([]byte, n)
+		_, err = io.ReadFull(data, buf)
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(remap(buf))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// metadata is stored as json (conversation, results, applies, settings, subtasks) or in .meta files (contexts)
+func isPlanArchiveMetadataPath(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".json" || ext == ".meta"
+}
+
+// fastExportState tracks which object the next 'data' payload belongs to
+type fastExportState struct {
+	inBlob     bool
+	blobMark   string
+	inlinePath string
+}
+
+func (state *fastExportState) observe(line []byte) {
+	trimmed := bytes.TrimSuffix(line, []byte("\n"))
+	switch {
+	case bytes.Equal(trimmed, []byte("blob")):
+		state.inBlob = true
+		state.blobMark = ""
+	case bytes.HasPrefix(trimmed, []byte("mark ")):
+		if state.inBlob {
+			state.blobMark = string(bytes.TrimPrefix(trimmed, []byte("mark ")))
+		}
+	case bytes.HasPrefix(trimmed, []byte("commit ")), bytes.HasPrefix(trimmed, []byte("tag ")), bytes.HasPrefix(trimmed, []byte("reset ")):
+		state.inBlob = false
+		state.blobMark = ""
+	}
+
+	if dataRef, path, ok := parseFastExportModify(line); ok && dataRef == "inline" {
+		state.inlinePath = path
+	}
+}
+
+// walkFastExport calls onLine for each command line and onData for each 'data' payload, which must consume exactly n bytes from data
+func walkFastExport(
+	r io.Reader,
+	onLine func(line []byte, state *fastExportState) error,
+	onData func(header []byte, data io.Reader, n int64, state *fastExportState) error,
+) error {
+	br := bufio.NewReader(r)
+	state := &fastExportState{}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if bytes.HasPrefix(line, []byte("data ")) {
+				n, parseErr := strconv.ParseInt(string(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data ")))), 10, 64)
+				if parseErr != nil || n < 0 {
+					return fmt.Errorf("invalid data command in git history: %q", line)
+				}
+				dataErr := onData(line, br, n, state)
+				if dataErr != nil {
+					return dataErr
+				}
+				state.inlinePath = ""
+			} else {
+				state.observe(line)
+				lineErr := onLine(line, state)
+				if lineErr != nil {
+					return lineErr
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFastExportModify parses a 'M <mode> <dataref> <path>' filemodify command
+func parseFastExportModify(line []byte) (string, string, bool) {
+	if !bytes.HasPrefix(line, []byte("M ")) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimSuffix(string(line), "\n"), " ", 4)
+	if len(parts) != 4 {
+		return "", "", false
+	}
+	path := parts[3]
+	if strings.HasPrefix(path, "\"") {
+		unquoted, err := strconv.Unquote(path)
+		if err == nil {
+			path = unquoted
+		}
+	}
+	return parts[2], path, true
+}
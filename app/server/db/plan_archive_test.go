@@ -0,0 +1,43 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRemapPlanArchiveIds(t *testing.T) {
+	oldId := "11111111-1111-1111-1111-111111111111"
+	newId := "22222222-2222-2222-2222-222222222222"
+
+	metaBlob := `{"planId":"` + oldId + `"}`
+	bodyBlob := "binary\x00" + oldId + "\x00data"
+	commitMsg := "update plan " + oldId
+
+	var in strings.Builder
+	fmt.Fprintf(&in, "blob\nmark :1\ndata %d\n%s\n", len(metaBlob), metaBlob)
+	fmt.Fprintf(&in, "blob\nmark :2\ndata %d\n%s\n", len(bodyBlob), bodyBlob)
+	fmt.Fprintf(&in, "reset refs/heads/main\ncommit refs/heads/main\nmark :3\nauthor A <a@b> 0 +0000\ncommitter A <a@b> 0 +0000\ndata %d\n%s\n", len(commitMsg), commitMsg)
+	fmt.Fprintf(&in, "M 100644 :1 context/abc.meta\nM 100644 :2 context/abc.body\n\n")
+
+	var out bytes.Buffer
+	err := remapPlanArchiveIds(strings.NewReader(in.String()), &out, map[string]string{oldId: newId})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := out.String()
+	if len(got) != in.Len() {
+		t.Fatalf("output length %d differs from input length %d", len(got), in.Len())
+	}
+	if !strings.Contains(got, `{"planId":"`+newId+`"}`) {
+		t.Errorf("metadata blob was not remapped:\n%s", got)
+	}
+	if !strings.Contains(got, "update plan "+newId) {
+		t.Errorf("commit message was not remapped:\n%s", got)
+	}
+	if !strings.Contains(got, bodyBlob) {
+		t.Errorf("non-metadata blob was modified:\n%q", got)
+	}
+}
@@ -94,8 +94,9 @@ func CreatePlan(ctx context.Context, orgId, projectId, userId, name string) (*Pl
 	return plan, nil
 }
 
-func ListOwnedPlans(projectIds []string, userId string, archived bool) ([]*Plan, error) {
-	qs := "SELECT * FROM plans WHERE project_id = ANY($1) AND owner_id = $2"
+// ListUserPlans lists the plans the user owns or that are shared with them
+func ListUserPlans(projectIds []string, userId string, archived bool) ([]*Plan, error) {
+	qs := "SELECT * FROM plans WHERE project_id = ANY($1) AND (owner_id = $2 OR id IN (SELECT plan_id FROM plan_shares WHERE user_id = $2))"
 	qargs := []interface{}{pq.Array(projectIds), userId}
 
 	if archived {
@@ -481,6 +482,17 @@ func ValidatePlanAccess(planId, userId, orgId string) (*Plan, error) {
 		return plan, nil
 	}
 
+	// plan is shared with user
+	share, err := GetPlanShare(planId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if share != nil {
+		return plan, nil
+	}
+
 	return nil, nil
 }
 
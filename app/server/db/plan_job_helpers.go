@@ -0,0 +1,157 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+)
+
+// CreatePlanJob queues a job. It runs as soon as a worker is free if NextRunAt is nil.
+func CreatePlanJob(job *PlanJob) error {
+	err := Conn.QueryRow(`INSERT INTO plan_jobs (org_id, plan_id, creator_id, branch, prompt, auto_mode, schedule, status, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, NOW()))
+RETURNING id, next_run_at, created_at, updated_at`,
+		job.OrgId, job.PlanId, job.CreatorId, job.Branch, job.Prompt, job.AutoMode, job.Schedule, job.Status, job.NextRunAt,
+	).Scan(&job.Id, &job.NextRunAt, &job.CreatedAt, &job.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating plan job: %v", err)
+	}
+
+	return nil
+}
+
+// GetPlanJob returns nil with no error if the job doesn't exist
+func GetPlanJob(orgId, jobId string) (*PlanJob, error) {
+	var job PlanJob
+	err := Conn.Get(&job, "SELECT * FROM plan_jobs WHERE org_id = $1 AND id = $2", orgId, jobId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan job: %v", err)
+	}
+	return &job, nil
+}
+
+// ListPlanJobs lists the jobs for the given plans, optionally only those with one of the given statuses
+func ListPlanJobs(planIds []string, statuses []shared.PlanJobStatus) ([]*PlanJob, error) {
+	var jobs []*PlanJob
+
+	if len(planIds) == 0 {
+		return jobs, nil
+	}
+
+	var err error
+	if len(statuses) == 0 {
+		err = Conn.Select(&jobs, "SELECT * FROM plan_jobs WHERE plan_id = ANY($1) ORDER BY created_at", pq.Array(planIds))
+	} else {
+		statusStrs := make([]string, len(statuses))
+		for i, status := range statuses {
+			statusStrs[i] = string(status)
+		}
+		err = Conn.Select(&jobs, "SELECT * FROM plan_jobs WHERE plan_id = ANY($1) AND status = ANY($2) ORDER BY created_at", pq.Array(planIds), pq.Array(statusStrs))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan jobs: %v", err)
+	}
+
+	return jobs, nil
+}
+
+func DeletePlanJob(orgId, jobId string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM plan_jobs WHERE org_id = $1 AND id = $2", orgId, jobId)
+	if err != nil {
+		return false, fmt.Errorf("error deleting plan job: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+// ClaimNextPlanJob marks the next due job as running, skipping orgs that already have maxPerOrg jobs running. Returns nil if there's nothing to run.
+func ClaimNextPlanJob(maxPerOrg int) (*PlanJob, error) {
+	var job PlanJob
+	query := `UPDATE plan_jobs SET status = 'running', last_run_at = NOW(), heartbeat_at = NOW()
+WHERE id = (
+  SELECT j.id FROM plan_jobs j
+  WHERE j.status = 'queued' AND j.next_run_at <= NOW()
+    AND (SELECT COUNT(*) FROM plan_jobs r WHERE r.org_id = j.org_id AND r.status = 'running') < $1
+  ORDER BY j.next_run_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+	err := Conn.Get(&job, query, maxPerOrg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error claiming plan job: %v", err)
+	}
+
+	return &job, nil
+}
+
+func HeartbeatPlanJob(jobId string) error {
+	_, err := Conn.Exec("UPDATE plan_jobs SET heartbeat_at = NOW() WHERE id = $1 AND status = 'running'", jobId)
+	if err != nil {
+		return fmt.Errorf("error updating plan job heartbeat: %v", err)
+	}
+	return nil
+}
+
+// ClaimStalePlanJobs takes running jobs that haven't had a heartbeat within the lease—their server stopped mid-run—so they can be finished as failed
+func ClaimStalePlanJobs(lease time.Duration) ([]*PlanJob, error) {
+	var jobs []*PlanJob
+	query := `UPDATE plan_jobs SET heartbeat_at = NOW()
+WHERE id IN (
+  SELECT id FROM plan_jobs
+  WHERE status = 'running' AND heartbeat_at < NOW() - $1 * INTERVAL '1 second'
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+	err := Conn.Select(&jobs, query, int(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("error claiming stale plan jobs: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// DeferPlanJob puts a claimed job back in the queue without counting it as a run
+func DeferPlanJob(jobId string, delay time.Duration) error {
+	_, err := Conn.Exec("UPDATE plan_jobs SET status = 'queued', next_run_at = NOW() + $2 * INTERVAL '1 second' WHERE id = $1", jobId, int(delay.Seconds()))
+	if err != nil {
+		return fmt.Errorf("error deferring plan job: %v", err)
+	}
+	return nil
+}
+
+// FinishPlanJobRun records the result of a run. A recurring job is queued again for nextRunAt, while a one-off job (nil nextRunAt) takes the run's status.
+func FinishPlanJobRun(jobId string, status shared.PlanJobStatus, errStr string, nextRunAt *time.Time) error {
+	var err error
+	if nextRunAt != nil {
+		_, err = Conn.Exec(`UPDATE plan_jobs SET status = 'queued', last_run_status = $2, last_error = $3, num_runs = num_runs + 1, next_run_at = $4, heartbeat_at = NULL WHERE id = $1`,
+			jobId, status, errStr, *nextRunAt)
+	} else {
+		_, err = Conn.Exec(`UPDATE plan_jobs SET status = $2, last_run_status = $2, last_error = $3, num_runs = num_runs + 1, next_run_at = NULL, finished_at = NOW(), heartbeat_at = NULL WHERE id = $1`,
+			jobId, status, errStr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error finishing plan job run: %v", err)
+	}
+	return nil
+}
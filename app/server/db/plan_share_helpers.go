@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+// UpsertPlanShare shares a plan with a user, or updates their role if it's already shared with them
+func UpsertPlanShare(share *PlanShare) error {
+	err := Conn.QueryRow(`INSERT INTO plan_shares (org_id, plan_id, user_id, role, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (plan_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING id, created_by, created_at, updated_at`,
+		share.OrgId, share.PlanId, share.UserId, share.Role, share.CreatedBy,
+	).Scan(&share.Id, &share.CreatedBy, &share.CreatedAt, &share.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error upserting plan share: %v", err)
+	}
+
+	return nil
+}
+
+// GetPlanShare returns nil with no error if the plan isn't shared with the user
+func GetPlanShare(planId, userId string) (*PlanShare, error) {
+	var share PlanShare
+	err := Conn.Get(&share, "SELECT * FROM plan_shares WHERE plan_id = $1 AND user_id = $2", planId, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan share: %v", err)
+	}
+	return &share, nil
+}
+
+// GetPlanShareRole returns an empty role if the plan isn't shared with the user. Owners and org-level permissions are checked by the caller.
+func GetPlanShareRole(planId, userId string) (shared.PlanShareRole, error) {
+	share, err := GetPlanShare(planId, userId)
+	if err != nil {
+		return "", err
+	}
+	if share == nil {
+		return "", nil
+	}
+	return share.Role, nil
+}
+
+func ListPlanShares(planId string) ([]*PlanShare, error) {
+	var shares []*PlanShare
+	err := Conn.Select(&shares, `SELECT plan_shares.*, users.email AS user_email, users.name AS user_name
+FROM plan_shares
+JOIN users ON users.id = plan_shares.user_id
+WHERE plan_shares.plan_id = $1
+ORDER BY plan_shares.created_at`, planId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan shares: %v", err)
+	}
+
+	return shares, nil
+}
+
+func DeletePlanShare(planId, userId string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM plan_shares WHERE plan_id = $1 AND user_id = $2", planId, userId)
+	if err != nil {
+		return false, fmt.Errorf("error deleting plan share: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
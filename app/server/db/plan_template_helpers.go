@@ -0,0 +1,87 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+)
+
+func ListPlanTemplates(orgId string) ([]*PlanTemplate, error) {
+	var templates []*PlanTemplate
+	err := Conn.Select(&templates, "SELECT * FROM plan_templates WHERE org_id = $1 ORDER BY name", orgId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan templates: %v", err)
+	}
+	return templates, nil
+}
+
+// GetPlanTemplateByName returns nil with no error if the template doesn't exist
+func GetPlanTemplateByName(orgId, name string) (*PlanTemplate, error) {
+	var template PlanTemplate
+	err := Conn.Get(&template, "SELECT * FROM plan_templates WHERE org_id = $1 AND name = $2", orgId, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan template: %v", err)
+	}
+	return &template, nil
+}
+
+// UpsertPlanTemplate creates a template, or replaces the one with the same name
+func UpsertPlanTemplate(orgId, creatorId string, req shared.CreatePlanTemplateRequest) (*PlanTemplate, error) {
+	template := PlanTemplate{
+		OrgId:         orgId,
+		CreatorId:     &creatorId,
+		Name:          req.Name,
+		Description:   req.Description,
+		Prompt:        req.Prompt,
+		ContextGlobs:  pq.StringArray(req.ContextGlobs),
+		ModelPackName: req.ModelPackName,
+		AutoMode:      req.AutoMode,
+		PlandexIgnore: pq.StringArray(req.PlandexIgnore),
+	}
+	if template.ContextGlobs == nil {
+		template.ContextGlobs = pq.StringArray{}
+	}
+	if template.PlandexIgnore == nil {
+		template.PlandexIgnore = pq.StringArray{}
+	}
+
+	err := Conn.QueryRow(`INSERT INTO plan_templates (org_id, creator_id, name, description, prompt, context_globs, model_pack_name, auto_mode, plandex_ignore)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (org_id, name) DO UPDATE SET
+  creator_id = EXCLUDED.creator_id,
+  description = EXCLUDED.description,
+  prompt = EXCLUDED.prompt,
+  context_globs = EXCLUDED.context_globs,
+  model_pack_name = EXCLUDED.model_pack_name,
+  auto_mode = EXCLUDED.auto_mode,
+  plandex_ignore = EXCLUDED.plandex_ignore
+RETURNING id, created_at, updated_at`,
+		template.OrgId, template.CreatorId, template.Name, template.Description, template.Prompt, template.ContextGlobs, template.ModelPackName, template.AutoMode, template.PlandexIgnore,
+	).Scan(&template.Id, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error upserting plan template: %v", err)
+	}
+
+	return &template, nil
+}
+
+func DeletePlanTemplate(orgId, name string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM plan_templates WHERE org_id = $1 AND name = $2", orgId, name)
+	if err != nil {
+		return false, fmt.Errorf("error deleting plan template: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
@@ -1,8 +1,14 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var orgOwnerRoleId string
@@ -97,3 +103,160 @@ func cacheOrgMemberRoleId() error {
 
 	return nil
 }
+
+// GetOrgRole returns a default role or one of the org's custom roles. It returns nil if the role doesn't exist or belongs to another org.
+func GetOrgRole(orgId, roleId string) (*OrgRole, error) {
+	var orgRole OrgRole
+	err := Conn.Get(&orgRole, "SELECT * FROM org_roles WHERE id = $1 AND (org_id IS NULL OR org_id = $2)", roleId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &orgRole, nil
+}
+
+// ListOrgRolePermissions returns each role's permissions that aren't scoped to a resource, keyed by role id
+func ListOrgRolePermissions(roleIds []string) (map[string][]shared.Permission, error) {
+	var rows []struct {
+		OrgRoleId string `db:"org_role_id"`
+		Name      string `db:"name"`
+	}
+
+	err := Conn.Select(&rows, `SELECT DISTINCT orp.org_role_id, p.name
+FROM org_roles_permissions orp
+JOIN permissions p ON p.id = orp.permission_id
+WHERE orp.org_role_id = ANY($1) AND p.resource_id IS NULL
+ORDER BY p.name`, pq.Array(roleIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing org role permissions: %v", err)
+	}
+
+	res := make(map[string][]shared.Permission)
+	for _, row := range rows {
+		res[row.OrgRoleId] = append(res[row.OrgRoleId], shared.Permission(row.Name))
+	}
+
+	return res, nil
+}
+
+// CreateOrgRole adds a custom role to an org. Owners and admins can invite, remove, and set users to the new role, like they can for members.
+func CreateOrgRole(role *OrgRole, permissions []shared.Permission, tx *sqlx.Tx) error {
+	err := tx.QueryRow("INSERT INTO org_roles (org_id, name, label, description) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at",
+		role.OrgId, role.Name, role.Label, role.Description,
+	).Scan(&role.Id, &role.CreatedAt, &role.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating org role: %v", err)
+	}
+
+	err = setOrgRolePermissions(role.Id, permissions, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO permissions (name, description, resource_id) VALUES
+  ('invite_user', $2, $1),
+  ('remove_user', $3, $1),
+  ('set_user_role', $4, $1)`,
+		role.Id,
+		fmt.Sprintf("Invite %s users to an org", role.Label),
+		fmt.Sprintf("Remove %s users from an org", role.Label),
+		fmt.Sprintf("Update a %s user's role in an org", role.Label),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating org role user permissions: %v", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL AND r.name IN ('owner', 'admin') AND p.resource_id = $1`, role.Id)
+
+	if err != nil {
+		return fmt.Errorf("error granting org role user permissions: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateOrgRole updates a custom role's label, description, and permissions
+func UpdateOrgRole(role *OrgRole, permissions []shared.Permission, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE org_roles SET label = $1, description = $2 WHERE id = $3 AND org_id = $4", role.Label, role.Description, role.Id, role.OrgId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles_permissions WHERE org_role_id = $1 AND permission_id IN (SELECT id FROM permissions WHERE resource_id IS NULL)", role.Id)
+
+	if err != nil {
+		return fmt.Errorf("error clearing org role permissions: %v", err)
+	}
+
+	return setOrgRolePermissions(role.Id, permissions, tx)
+}
+
+// DeleteOrgRole deletes a custom role that no users or pending invites have. Accepted invites are moved to the member role since they only record the role a user joined with.
+func DeleteOrgRole(orgId, roleId string, tx *sqlx.Tx) error {
+	memberRoleId, err := GetOrgMemberRoleId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE invites SET org_role_id = $1 WHERE org_id = $2 AND org_role_id = $3 AND accepted_at IS NOT NULL", memberRoleId, orgId, roleId)
+
+	if err != nil {
+		return fmt.Errorf("error updating accepted invites: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM permissions WHERE resource_id = $1", roleId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role user permissions: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles WHERE id = $1 AND org_id = $2", roleId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role: %v", err)
+	}
+
+	return nil
+}
+
+func NumPendingInvitesWithRole(orgId, roleId string) (int, error) {
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM invites WHERE org_id = $1 AND org_role_id = $2 AND accepted_at IS NULL", orgId, roleId)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting pending invites with role: %v", err)
+	}
+
+	return count, nil
+}
+
+func setOrgRolePermissions(roleId string, permissions []shared.Permission, tx *sqlx.Tx) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+
+	_, err := tx.Exec(`INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT $1, id FROM permissions WHERE resource_id IS NULL AND name = ANY($2)`, roleId, pq.Array(names))
+
+	if err != nil {
+		return fmt.Errorf("error setting org role permissions: %v", err)
+	}
+
+	return nil
+}
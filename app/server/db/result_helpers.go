@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -479,7 +480,7 @@ func GetPlanResult(planFileResults []*shared.PlanFileResult) *shared.PlanResult
 
 	for _, results := range resByPath {
 		for _, planRes := range results {
-			replacementsByPath[planRes.Path] = append(replacementsByPath[planRes.Path], planRes.Replacements...)
+			replacementsByPath[planRes.Path] = append(replacementsByPath[planRes.Path], planRes.ActiveReplacements()...)
 		}
 	}
 
@@ -988,42 +989,95 @@ func RejectPlanFile(orgId, planId, filePathOrResultId string, now time.Time) err
 	return nil
 }
 
-func RejectReplacement(orgId, planId, resultId, replacementId string) error {
-	resultsDir := getPlanResultsDir(orgId, planId)
+// ErrReplacementConflict is returned when rejecting or editing a replacement would stop a later pending change to the same file from applying
+var ErrReplacementConflict = errors.New("later pending changes to the file depend on this change")
 
-	bytes, err := os.ReadFile(filepath.Join(resultsDir, resultId+".json"))
+// RejectReplacements rejects individual replacements within pending results. The rest of each result stays pending. Returns the paths that were changed.
+func RejectReplacements(orgId, planId string, replacementIds []string, now time.Time) ([]string, error) {
+	return updatePendingReplacements(orgId, planId, replacementIds, func(rep *shared.Replacement) {
+		rep.RejectedAt = &now
+	})
+}
 
-	if err != nil {
-		return fmt.Errorf("error reading result file: %v", err)
+// EditReplacements replaces the new content of individual pending replacements. Returns the paths that were changed.
+func EditReplacements(orgId, planId string, edits []*shared.ReplacementEdit) ([]string, error) {
+	newById := make(map[string]string, len(edits))
+	ids := make([]string, 0, len(edits))
+	for _, edit := range edits {
+		newById[edit.ReplacementId] = edit.New
+		ids = append(ids, edit.ReplacementId)
 	}
 
-	var result PlanFileResult
-	err = json.Unmarshal(bytes, &result)
+	return updatePendingReplacements(orgId, planId, ids, func(rep *shared.Replacement) {
+		rep.New = newById[rep.Id]
+	})
+}
 
+func updatePendingReplacements(orgId, planId string, replacementIds []string, update func(rep *shared.Replacement)) ([]string, error) {
+	results, err := GetPlanFileResults(orgId, planId)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling result file: %v", err)
+		return nil, fmt.Errorf("error getting plan file results: %v", err)
 	}
 
-	if result.RejectedAt != nil {
-		return nil
+	idSet := make(map[string]bool, len(replacementIds))
+	for _, id := range replacementIds {
+		idSet[id] = true
 	}
 
-	now := time.Now()
+	found := map[string]bool{}
+	pathsSet := map[string]bool{}
+	var updatedResults []*PlanFileResult
+
+	for _, result := range results {
+		if !result.ToApi().IsPending() {
+			continue
+		}
 
-	foundReplacement := false
-	for _, replacement := range result.Replacements {
-		if replacement.Id == replacementId {
-			replacement.RejectedAt = &now
-			foundReplacement = true
-			break
+		didUpdate := false
+		for _, rep := range result.Replacements {
+			if idSet[rep.Id] && rep.RejectedAt == nil {
+				update(rep)
+				found[rep.Id] = true
+				didUpdate = true
+			}
+		}
+
+		if didUpdate {
+			updatedResults = append(updatedResults, result)
+			pathsSet[result.Path] = true
 		}
 	}
 
-	if !foundReplacement {
-		return fmt.Errorf("replacement not found: %s", replacementId)
+	for _, id := range replacementIds {
+		if !found[id] {
+			return nil, fmt.Errorf("pending replacement not found: %s", id)
+		}
 	}
 
-	return nil
+	// make sure every remaining change still applies before storing anything
+	_, err = GetCurrentPlanState(CurrentPlanStateParams{
+		OrgId:           orgId,
+		PlanId:          planId,
+		PlanFileResults: results,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReplacementConflict, err)
+	}
+
+	for _, result := range updatedResults {
+		err = StorePlanResult(result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(pathsSet))
+	for path := range pathsSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths, nil
 }
 
 func GetPlanApplies(orgId, planId string) ([]*PlanApply, error) {
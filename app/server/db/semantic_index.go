@@ -0,0 +1,132 @@
+package db
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// The semantic index is stored per project next to the map cache, with one file per project file. Each holds the file's chunks and their embeddings, along with the sha of the content and the model that embedded it so unchanged files aren't embedded again.
+
+type SemanticIndexFile struct {
+	Path      string                `json:"path"`
+	Sha       string                `json:"sha"`
+	ModelId   shared.ModelId        `json:"modelId"`
+	Chunks    []*SemanticIndexChunk `json:"chunks"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+type SemanticIndexChunk struct {
+	StartLine int       `json:"startLine"`
+	EndLine   int       `json:"endLine"`
+	Text      string    `json:"text"`
+	Embedding Embedding `json:"embedding"`
+}
+
+// Embedding is stored as base64-encoded little-endian float32s, which is several times smaller than a JSON array of numbers
+type Embedding []float32
+
+func (e Embedding) MarshalJSON() ([]byte, error) {
+	bytes := make([]byte, len(e)*4)
+	for i, v := range e {
+		binary.LittleEndian.PutUint32(bytes[i*4:], math.Float32bits(v))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(bytes))
+}
+
+func (e *Embedding) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("error decoding embedding: %v", err)
+	}
+	if len(bytes)%4 != 0 {
+		return fmt.Errorf("invalid embedding length: %d", len(bytes))
+	}
+
+	res := make(Embedding, len(bytes)/4)
+	for i := range res {
+		res[i] = math.Float32frombits(binary.LittleEndian.Uint32(bytes[i*4:]))
+	}
+	*e = res
+	return nil
+}
+
+func getSemanticIndexFilePath(orgId, projectId, path string) string {
+	pathHash := md5.Sum([]byte(path))
+	return filepath.Join(getProjectSemanticIndexDir(orgId, projectId), hex.EncodeToString(pathHash[:])+".json")
+}
+
+// GetSemanticIndexFile returns nil if the file hasn't been indexed
+func GetSemanticIndexFile(orgId, projectId, path string) (*SemanticIndexFile, error) {
+	bytes, err := os.ReadFile(getSemanticIndexFilePath(orgId, projectId, path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading semantic index file: %v", err)
+	}
+
+	var file SemanticIndexFile
+	err = json.Unmarshal(bytes, &file)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling semantic index file: %v", err)
+	}
+
+	return &file, nil
+}
+
+// StoreSemanticIndexFile writes to a temp file and renames it into place so that concurrent readers never see a partial file
+func StoreSemanticIndexFile(orgId, projectId string, file *SemanticIndexFile) error {
+	dir := getProjectSemanticIndexDir(orgId, projectId)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating semantic index dir: %v", err)
+	}
+
+	file.UpdatedAt = time.Now().UTC()
+
+	bytes, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("error marshalling semantic index file: %v", err)
+	}
+
+	// a unique temp file, since the same path can be indexed by concurrent requests
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating semantic index temp file: %v", err)
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(bytes)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing semantic index file: %v", err)
+	}
+
+	err = os.Rename(tmpPath, getSemanticIndexFilePath(orgId, projectId, file.Path))
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing semantic index file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+type ModelUsageFilter struct {
+	UserId    string
+	PlanId    string
+	SessionId string
+	Since     *time.Time
+}
+
+func (f ModelUsageFilter) where(orgId string) (string, []interface{}) {
+	clauses := []string{"mu.org_id = $1"}
+	args := []interface{}{orgId}
+
+	add := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	if f.UserId != "" {
+		add("mu.user_id = $%d", f.UserId)
+	}
+	if f.PlanId != "" {
+		add("mu.plan_id = $%d", f.PlanId)
+	}
+	if f.SessionId != "" {
+		add("mu.session_id = $%d", f.SessionId)
+	}
+	if f.Since != nil {
+		add("mu.created_at >= $%d", *f.Since)
+	}
+
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func StoreModelUsage(usage *ModelUsage) error {
+	query := `INSERT INTO model_usage (
+    org_id, user_id, plan_id, plan_name, session_id,
+    model_id, model_name, model_provider, model_role, model_pack_name, purpose, generation_id,
+    input_tokens, output_tokens, cached_tokens,
+    input_price_per_million, output_price_per_million, cached_input_price_per_million,
+    cost, cache_savings,
+    stopped_early, had_error, no_reported_usage
+  ) VALUES (
+    $1, $2, $3, $4, $5,
+    $6, $7, $8, $9, $10, $11, $12,
+    $13, $14, $15,
+    $16, $17, $18,
+    $19, $20,
+    $21, $22, $23
+  ) RETURNING id, created_at`
+
+	err := Conn.QueryRow(query,
+		usage.OrgId, usage.UserId, usage.PlanId, usage.PlanName, usage.SessionId,
+		usage.ModelId, usage.ModelName, usage.ModelProvider, usage.ModelRole, usage.ModelPackName, usage.Purpose, usage.GenerationId,
+		usage.InputTokens, usage.OutputTokens, usage.CachedTokens,
+		usage.InputPricePerMillion, usage.OutputPricePerMillion, usage.CachedInputPricePerMillion,
+		usage.Cost, usage.CacheSavings,
+		usage.StoppedEarly, usage.HadError, usage.NoReportedUsage,
+	).Scan(&usage.Id, &usage.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error storing model usage: %v", err)
+	}
+
+	return nil
+}
+
+func ListModelUsage(orgId string, filter ModelUsageFilter, limit, offset int) ([]*ModelUsage, int, error) {
+	where, args := filter.where(orgId)
+
+	var total int
+	err := Conn.Get(&total, "SELECT COUNT(*) FROM model_usage mu "+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting model usage: %v", err)
+	}
+
+	query := fmt.Sprintf(`SELECT mu.*, COALESCE(p.name, mu.plan_name) AS current_plan_name, u.name AS user_name, u.email AS user_email
+FROM model_usage mu
+LEFT JOIN plans p ON p.id = mu.plan_id
+LEFT JOIN users u ON u.id = mu.user_id
+%s
+ORDER BY mu.created_at DESC
+LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	var usage []*ModelUsage
+	err = Conn.Select(&usage, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing model usage: %v", err)
+	}
+
+	return usage, total, nil
+}
+
+func GetModelUsageSummary(orgId string, filter ModelUsageFilter) (*shared.CreditsSummaryResponse, error) {
+	where, args := filter.where(orgId)
+
+	var totals struct {
+		Cost         decimal.Decimal `db:"cost"`
+		CacheSavings decimal.Decimal `db:"cache_savings"`
+		InputTokens  int             `db:"input_tokens"`
+		OutputTokens int             `db:"output_tokens"`
+		CachedTokens int             `db:"cached_tokens"`
+	}
+	err := Conn.Get(&totals, `SELECT
+  COALESCE(SUM(mu.cost), 0) AS cost,
+  COALESCE(SUM(mu.cache_savings), 0) AS cache_savings,
+  COALESCE(SUM(mu.input_tokens), 0) AS input_tokens,
+  COALESCE(SUM(mu.output_tokens), 0) AS output_tokens,
+  COALESCE(SUM(mu.cached_tokens), 0) AS cached_tokens
+FROM model_usage mu `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting model usage totals: %v", err)
+	}
+
+	res := &shared.CreditsSummaryResponse{
+		TotalSpend:        totals.Cost,
+		CacheSavings:      totals.CacheSavings,
+		TotalInputTokens:  totals.InputTokens,
+		TotalOutputTokens: totals.OutputTokens,
+		TotalCachedTokens: totals.CachedTokens,
+		PlanNamesById:     map[string]string{},
+		UserNamesById:     map[string]string{},
+	}
+
+	groups := []struct {
+		expr   string
+		join   string
+		target *map[string]decimal.Decimal
+		names  map[string]string
+		label  string
+	}{
+		{expr: "mu.plan_id::text", join: "LEFT JOIN plans x ON x.id = mu.plan_id", label: "COALESCE(x.name, mu.plan_name)", target: &res.ByPlanId, names: res.PlanNamesById},
+		{expr: "mu.user_id::text", join: "LEFT JOIN users x ON x.id = mu.user_id", label: "COALESCE(x.name, x.email)", target: &res.ByUserId, names: res.UserNamesById},
+		{expr: "mu.model_id", target: &res.ByModelName},
+		{expr: "mu.purpose", target: &res.ByPurpose},
+		{expr: "mu.model_role", target: &res.ByModelRole},
+		{expr: "to_char(mu.created_at, 'YYYY-MM-DD')", target: &res.ByDay},
+	}
+
+	for _, group := range groups {
+		label := "NULL"
+		if group.label != "" {
+			label = group.label
+		}
+
+		query := fmt.Sprintf(`SELECT %s AS key, MAX(%s) AS label, SUM(mu.cost) AS cost
+FROM model_usage mu
+%s
+%s AND %s IS NOT NULL
+GROUP BY %s`, group.expr, label, group.join, where, group.expr, group.expr)
+
+		var rows []struct {
+			Key   string          `db:"key"`
+			Label sql.NullString  `db:"label"`
+			Cost  decimal.Decimal `db:"cost"`
+		}
+		err := Conn.Select(&rows, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error summarizing model usage by %s: %v", group.expr, err)
+		}
+
+		byKey := make(map[string]decimal.Decimal, len(rows))
+		for _, row := range rows {
+			byKey[row.Key] = row.Cost
+			if group.names != nil && row.Label.Valid {
+				group.names[row.Key] = row.Label.String
+			}
+		}
+		*group.target = byKey
+	}
+
+	return res, nil
+}
+
+// GetModelPrice resolves the price used for the usage ledger: org override -> built-in list price -> custom model list price -> zero
+func GetModelPrice(orgId string, modelId shared.ModelId) (*shared.ModelPrice, error) {
+	var override ModelPriceOverride
+	err := Conn.Get(&override, "SELECT * FROM model_prices WHERE org_id = $1 AND model_id = $2", orgId, modelId)
+	if err == nil {
+		return override.ToApi(), nil
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error getting model price: %v", err)
+	}
+
+	if builtIn, ok := shared.BuiltInBaseModelsById[modelId]; ok {
+		return shared.ModelPriceFromBaseModelShared(modelId, builtIn.BaseModelShared), nil
+	}
+
+	customModels, err := ListCustomModelsForModelIds(orgId, []string{string(modelId)})
+	if err != nil {
+		return nil, fmt.Errorf("error getting custom model: %v", err)
+	}
+	if len(customModels) > 0 {
+		return shared.ModelPriceFromBaseModelShared(modelId, customModels[0].ToApi().BaseModelShared), nil
+	}
+
+	return &shared.ModelPrice{ModelId: modelId}, nil
+}
+
+// ListModelPrices returns the effective price of every built-in and custom model available to the org, plus any overrides
+func ListModelPrices(orgId string) ([]*shared.ModelPrice, error) {
+	byId := map[shared.ModelId]*shared.ModelPrice{}
+	ids := []shared.ModelId{}
+
+	set := func(price *shared.ModelPrice) {
+		if _, ok := byId[price.ModelId]; !ok {
+			ids = append(ids, price.ModelId)
+		}
+		byId[price.ModelId] = price
+	}
+
+	for _, model := range shared.BuiltInBaseModels {
+		set(shared.ModelPriceFromBaseModelShared(model.ModelId, model.BaseModelShared))
+	}
+
+	customModels, err := ListCustomModels(orgId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing custom models: %v", err)
+	}
+	for _, model := range customModels {
+		set(shared.ModelPriceFromBaseModelShared(model.ModelId, model.ToApi().BaseModelShared))
+	}
+
+	var overrides []*ModelPriceOverride
+	err = Conn.Select(&overrides, "SELECT * FROM model_prices WHERE org_id = $1", orgId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing model prices: %v", err)
+	}
+	for _, override := range overrides {
+		set(override.ToApi())
+	}
+
+	res := make([]*shared.ModelPrice, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, byId[id])
+	}
+	return res, nil
+}
+
+func UpsertModelPrice(orgId string, req shared.SetModelPriceRequest) error {
+	_, err := Conn.Exec(`INSERT INTO model_prices (org_id, model_id, input_price_per_million, output_price_per_million, cached_input_price_per_million)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (org_id, model_id) DO UPDATE SET
+  input_price_per_million = EXCLUDED.input_price_per_million,
+  output_price_per_million = EXCLUDED.output_price_per_million,
+  cached_input_price_per_million = EXCLUDED.cached_input_price_per_million`,
+		orgId, req.ModelId, req.InputPricePerMillion, req.OutputPricePerMillion, req.CachedInputPricePerMillion)
+
+	if err != nil {
+		return fmt.Errorf("error upserting model price: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteModelPrice(orgId string, modelId shared.ModelId) error {
+	_, err := Conn.Exec("DELETE FROM model_prices WHERE org_id = $1 AND model_id = $2", orgId, modelId)
+	if err != nil {
+		return fmt.Errorf("error deleting model price: %v", err)
+	}
+	return nil
+}
@@ -142,7 +142,7 @@ func CreateUser(name, email string, tx *sqlx.Tx) (*User, error) {
 		Domain: domain,
 	}
 
-	err := tx.QueryRow("INSERT INTO users (name, email, domain) VALUES ($1, $2, $3) RETURNING id", user.Name, user.Email, user.Domain).Scan(&user.Id)
+	err := tx.QueryRow("INSERT INTO users (name, email, domain) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at", user.Name, user.Email, user.Domain).Scan(&user.Id, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if IsNonUniqueErr(err) {
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+)
+
+func ListWebhooks(orgId string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	err := Conn.Select(&webhooks, "SELECT * FROM webhooks WHERE org_id = $1 ORDER BY created_at", orgId)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %v", err)
+	}
+	return webhooks, nil
+}
+
+// ListWebhooksForEvent returns the enabled webhooks that should receive an event—org-wide webhooks plus those for the event's project
+func ListWebhooksForEvent(orgId, projectId string, event shared.WebhookEvent) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	query := `SELECT * FROM webhooks
+WHERE org_id = $1
+  AND enabled
+  AND (project_id IS NULL OR project_id::text = $2)
+  AND (cardinality(events) = 0 OR $3 = ANY(events))`
+
+	err := Conn.Select(&webhooks, query, orgId, projectId, string(event))
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks for event: %v", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook returns nil with no error if the webhook doesn't exist
+func GetWebhook(orgId, webhookId string) (*Webhook, error) {
+	var webhook Webhook
+	err := Conn.Get(&webhook, "SELECT * FROM webhooks WHERE org_id = $1 AND id = $2", orgId, webhookId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting webhook: %v", err)
+	}
+	return &webhook, nil
+}
+
+func CreateWebhook(orgId string, projectId *string, url, secret string, events []shared.WebhookEvent) (*Webhook, error) {
+	webhook := Webhook{
+		OrgId:     orgId,
+		ProjectId: projectId,
+		Url:       url,
+		Secret:    secret,
+		Events:    make(pq.StringArray, len(events)),
+		Enabled:   true,
+	}
+	for i, event := range events {
+		webhook.Events[i] = string(event)
+	}
+
+	err := Conn.QueryRow(`INSERT INTO webhooks (org_id, project_id, url, secret, events, enabled)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at`,
+		webhook.OrgId, webhook.ProjectId, webhook.Url, webhook.Secret, webhook.Events, webhook.Enabled,
+	).Scan(&webhook.Id, &webhook.CreatedAt, &webhook.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook: %v", err)
+	}
+
+	return &webhook, nil
+}
+
+func SetWebhookEnabled(orgId, webhookId string, enabled bool) (bool, error) {
+	res, err := Conn.Exec("UPDATE webhooks SET enabled = $3 WHERE org_id = $1 AND id = $2", orgId, webhookId, enabled)
+	if err != nil {
+		return false, fmt.Errorf("error updating webhook: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+func DeleteWebhook(orgId, webhookId string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM webhooks WHERE org_id = $1 AND id = $2", orgId, webhookId)
+	if err != nil {
+		return false, fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+func CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	err := Conn.QueryRow(`INSERT INTO webhook_deliveries (id, org_id, webhook_id, event, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING status, next_attempt_at, created_at, updated_at`,
+		delivery.Id, delivery.OrgId, delivery.WebhookId, delivery.Event, delivery.Payload,
+	).Scan(&delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns the most recent deliveries for the org, optionally limited to one webhook
+func ListWebhookDeliveries(orgId, webhookId string, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	var err error
+
+	if webhookId == "" {
+		err = Conn.Select(&deliveries, "SELECT * FROM webhook_deliveries WHERE org_id = $1 ORDER BY created_at DESC LIMIT $2", orgId, limit)
+	} else {
+		err = Conn.Select(&deliveries, "SELECT * FROM webhook_deliveries WHERE org_id = $1 AND webhook_id = $2 ORDER BY created_at DESC LIMIT $3", orgId, webhookId, limit)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDueWebhookDeliveries takes pending deliveries that are due and pushes their next attempt out by the lease so that other server instances skip them while they're being sent
+func ClaimDueWebhookDeliveries(limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	query := `UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+WHERE id IN (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending' AND next_attempt_at <= NOW()
+  ORDER BY next_attempt_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+	err := Conn.Select(&deliveries, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+type WebhookDeliveryAttempt struct {
+	Status         shared.WebhookDeliveryStatus
+	ResponseStatus *int
+	Error          *string
+	RetryIn        time.Duration
+}
+
+func RecordWebhookDeliveryAttempt(deliveryId string, attempt WebhookDeliveryAttempt) error {
+	_, err := Conn.Exec(`UPDATE webhook_deliveries SET
+  status = $2,
+  attempts = attempts + 1,
+  response_status = $3,
+  error = $4,
+  next_attempt_at = NOW() + $5 * INTERVAL '1 second',
+  last_attempt_at = NOW()
+WHERE id = $1`,
+		deliveryId, attempt.Status, attempt.ResponseStatus, attempt.Error, int(attempt.RetryIn.Seconds()),
+	)
+	if err != nil {
+		return fmt.Errorf("error recording webhook delivery attempt: %v", err)
+	}
+	return nil
+}
+
+func DeleteWebhookDeliveriesBefore(t time.Time) error {
+	_, err := Conn.Exec("DELETE FROM webhook_deliveries WHERE created_at < $1 AND status != 'pending'", t)
+	if err != nil {
+		return fmt.Errorf("error deleting old webhook deliveries: %v", err)
+	}
+	return nil
+}
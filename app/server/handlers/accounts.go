@@ -26,6 +26,10 @@ func CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if rejectIfSsoRequired(w) {
+		return
+	}
+
 	isLocalMode := (os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1")
 
 	// read the request body
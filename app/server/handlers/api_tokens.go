@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+func CreateApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeCredentialManagement(w, auth) {
+		return
+	}
+
+	var req shared.CreateApiTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "A name is required", http.StatusBadRequest)
+		return
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []shared.ApiTokenScope{shared.ApiTokenScopeWrite}
+	}
+	scopeStrs := make(pq.StringArray, len(scopes))
+	for i, scope := range scopes {
+		if !scope.IsValid() {
+			http.Error(w, fmt.Sprintf("Invalid scope: %s", scope), http.StatusBadRequest)
+			return
+		}
+		scopeStrs[i] = string(scope)
+	}
+
+	if req.ExpiresInDays < 0 {
+		http.Error(w, "expiresInDays can't be negative", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	tokenUser := auth.User
+	if req.ServiceAccountId != "" {
+		tokenUser = authorizeServiceAccount(w, auth, req.ServiceAccountId)
+		if tokenUser == nil {
+			return
+		}
+	}
+
+	apiToken := &db.ApiToken{
+		OrgId:            auth.OrgId,
+		UserId:           tokenUser.Id,
+		CreatorId:        auth.User.Id,
+		Name:             name,
+		Scopes:           scopeStrs,
+		ExpiresAt:        expiresAt,
+		UserEmail:        tokenUser.Email,
+		UserName:         tokenUser.Name,
+		IsServiceAccount: tokenUser.IsServiceAccount,
+	}
+
+	token, err := db.CreateApiToken(apiToken)
+	if err != nil {
+		log.Println("Error creating api token: ", err)
+		http.Error(w, "Error creating api token", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionApiTokenCreate,
+		targetId: apiToken.Id,
+		data: map[string]interface{}{
+			"name":           apiToken.Name,
+			"userId":         apiToken.UserId,
+			"serviceAccount": apiToken.IsServiceAccount,
+			"scopes":         scopeStrs,
+			"expiresAt":      apiToken.ExpiresAt,
+		},
+	})
+
+	bytes, err := json.Marshal(shared.CreateApiTokenResponse{
+		Token:    token,
+		ApiToken: apiToken.ToApi(),
+	})
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreateApiTokenHandler processed successfully")
+}
+
+func ListApiTokensHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListApiTokensHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	userIds := []string{auth.User.Id}
+
+	// service account tokens are listed along with the user's own for those who manage them
+	if auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		serviceAccounts, err := db.ListServiceAccounts(auth.OrgId)
+		if err != nil {
+			log.Println("Error listing service accounts: ", err)
+			http.Error(w, "Error listing service accounts", http.StatusInternalServerError)
+			return
+		}
+		for _, sa := range serviceAccounts {
+			if sa.Id != auth.User.Id {
+				userIds = append(userIds, sa.Id)
+			}
+		}
+	}
+
+	tokens, err := db.ListApiTokens(auth.OrgId, userIds)
+	if err != nil {
+		log.Println("Error listing api tokens: ", err)
+		http.Error(w, "Error listing api tokens", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListApiTokensResponse{
+		Tokens: make([]*shared.ApiToken, len(tokens)),
+	}
+	for i, token := range tokens {
+		res.Tokens[i] = token.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListApiTokensHandler processed successfully")
+}
+
+func RevokeApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RevokeApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	tokenId := mux.Vars(r)["tokenId"]
+
+	apiToken, err := db.GetApiToken(auth.OrgId, tokenId)
+	if err != nil {
+		log.Println("Error getting api token: ", err)
+		http.Error(w, "Error getting api token", http.StatusInternalServerError)
+		return
+	}
+
+	if apiToken == nil || apiToken.RevokedAt != nil {
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	// a token can always revoke itself, even with the read scope, so a leaked token can be shut off with only the token
+	isSelf := auth.ApiToken != nil && auth.ApiToken.Id == apiToken.Id
+
+	if !isSelf {
+		if !authorizeCredentialManagement(w, auth) {
+			return
+		}
+
+		if apiToken.UserId != auth.User.Id && authorizeServiceAccount(w, auth, apiToken.UserId) == nil {
+			return
+		}
+	}
+
+	err = db.RevokeApiToken(auth.OrgId, apiToken.Id)
+	if err != nil {
+		log.Println("Error revoking api token: ", err)
+		http.Error(w, "Error revoking api token", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionApiTokenRevoke,
+		targetId: apiToken.Id,
+		data: map[string]interface{}{
+			"name":   apiToken.Name,
+			"userId": apiToken.UserId,
+			"self":   isSelf,
+		},
+	})
+
+	log.Println("RevokeApiTokenHandler processed successfully")
+}
+
+// GetApiTokenSessionHandler returns the token's user and org so clients authenticating with only a token can set up their session
+func GetApiTokenSessionHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetApiTokenSessionHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if auth.ApiToken == nil {
+		http.Error(w, "Not authenticated with an api token", http.StatusBadRequest)
+		return
+	}
+
+	org, apiErr := getApiOrg(auth.OrgId)
+	if apiErr != nil {
+		log.Printf("Error converting org to api: %v\n", apiErr)
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	apiToken := *auth.ApiToken
+	apiToken.UserEmail = auth.User.Email
+	apiToken.UserName = auth.User.Name
+	apiToken.IsServiceAccount = auth.User.IsServiceAccount
+
+	bytes, err := json.Marshal(shared.ApiTokenSessionResponse{
+		ApiToken: apiToken.ToApi(),
+		Org:      org,
+	})
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("GetApiTokenSessionHandler processed successfully")
+}
+
+// authorizeCredentialManagement requires the admin scope to create or revoke credentials with an api token, so a leaked CI token can't be used to mint others
+func authorizeCredentialManagement(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if auth.ApiToken != nil && !shared.HasApiTokenScope(auth.ApiToken.ApiScopes(), shared.ApiTokenScopeAdmin) {
+		log.Println("API token without admin scope used to manage credentials")
+		http.Error(w, "API tokens need the admin scope to manage tokens and service accounts", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorizeServiceAccount checks that the user can manage the given service account in their org
+func authorizeServiceAccount(w http.ResponseWriter, auth *types.ServerAuth, userId string) *db.User {
+	if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		log.Println("User does not have permission to manage service accounts")
+		http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+		return nil
+	}
+
+	user, err := db.GetUser(userId)
+	if err != nil {
+		log.Println("Error getting user: ", err)
+		http.Error(w, "Error getting user", http.StatusInternalServerError)
+		return nil
+	}
+
+	isMember := false
+	if user != nil && user.IsServiceAccount {
+		isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+		if err != nil {
+			log.Println("Error validating org membership: ", err)
+			http.Error(w, "Error validating org membership", http.StatusInternalServerError)
+			return nil
+		}
+	}
+
+	if !isMember {
+		http.Error(w, "Service account not found", http.StatusNotFound)
+		return nil
+	}
+
+	return user
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"plandex-server/types"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const defaultAuditEventsLimit = 100
+const maxAuditEventsLimit = 1000
+
+// auditEvent is what a handler knows about an action—the actor and request details are filled in by recordAuditEvent
+type auditEvent struct {
+	action   shared.AuditAction
+	plan     *db.Plan
+	branch   string
+	targetId string
+	data     map[string]interface{}
+}
+
+// recordAuditEvent appends an action to the org's audit log. It's called after the action succeeds, so a failure to record is logged and reported rather than failing the request.
+func recordAuditEvent(r *http.Request, auth *types.ServerAuth, ev auditEvent) {
+	orgId := auth.OrgId
+	if orgId == "" && ev.plan != nil {
+		orgId = ev.plan.OrgId
+	}
+
+	event := &db.AuditEvent{
+		OrgId:  orgId,
+		Action: ev.action,
+	}
+
+	optional := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+
+	if auth.User != nil {
+		event.UserId = &auth.User.Id
+		event.UserEmail = &auth.User.Email
+	}
+	if auth.ApiToken != nil {
+		event.ApiTokenId = &auth.ApiToken.Id
+	}
+	event.Ip = optional(requestIp(r))
+	if ev.plan != nil {
+		event.PlanId = &ev.plan.Id
+		event.PlanName = &ev.plan.Name
+	}
+	event.Branch = optional(ev.branch)
+	event.TargetId = optional(ev.targetId)
+
+	err := db.CreateAuditEvent(event, ev.data)
+	if err != nil {
+		log.Printf("Error recording %s audit event: %v\n", ev.action, err)
+		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error recording %s audit event: %v", ev.action, err))
+	}
+}
+
+// requestIp is the connection's address unless it's a proxy listed in TRUSTED_PROXIES (comma-separated IPs and CIDRs), in which case the client address is taken from the forwarded headers—the right-most X-Forwarded-For hop that isn't itself a trusted proxy, since hops to the left of it can be set by the client
+func requestIp(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+
+	trusted := getTrustedProxies()
+	if !trusted.contains(peer) {
+		return peer
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		ip := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			ip = hop
+			if !trusted.contains(hop) {
+				break
+			}
+		}
+		return ip
+	}
+
+	if realIp := strings.TrimSpace(r.Header.Get("X-Real-Ip")); net.ParseIP(realIp) != nil {
+		return realIp
+	}
+
+	return peer
+}
+
+type trustedProxies []*net.IPNet
+
+func getTrustedProxies() trustedProxies {
+	var res trustedProxies
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			res = append(res, ipNet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else {
+			log.Printf("Ignoring invalid TRUSTED_PROXIES entry: %s\n", entry)
+		}
+	}
+	return res
+}
+
+func (t trustedProxies) contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range t {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func ListAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListAuditEventsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionViewAuditLog) {
+		log.Println("User does not have permission to view the audit log")
+		http.Error(w, "User does not have permission to view the audit log", http.StatusForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+
+	filter := db.AuditEventFilter{
+		Action: q.Get("action"),
+		User:   q.Get("user"),
+		PlanId: q.Get("planId"),
+		Limit:  defaultAuditEventsLimit,
+	}
+
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = min(n, maxAuditEventsLimit)
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if s := q.Get(param.name); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				http.Error(w, param.name+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			*param.dst = &t
+		}
+	}
+
+	if s := q.Get("before"); s != "" {
+		createdAt, id, err := parseAuditCursor(s)
+		if err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		filter.BeforeCreatedAt = &createdAt
+		filter.BeforeId = id
+	}
+
+	// fetch one extra to know whether there's another page
+	limit := filter.Limit
+	filter.Limit++
+
+	events, err := db.ListAuditEvents(auth.OrgId, filter)
+	if err != nil {
+		log.Println("Error listing audit events: ", err)
+		http.Error(w, "Error listing audit events", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListAuditEventsResponse{}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		res.NextCursor = last.CreatedAt.Format(time.RFC3339Nano) + "_" + last.Id
+	}
+
+	res.Events = make([]*shared.AuditEvent, len(events))
+	for i, event := range events {
+		res.Events[i] = event.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListAuditEventsHandler processed successfully")
+}
+
+// ReportExecutedCommandsHandler records commands from a plan's _apply.sh that the client ran—the server can't see them otherwise
+func ReportExecutedCommandsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ReportExecutedCommandsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionExecCommands) {
+		log.Println("User does not have permission to execute commands")
+		http.Error(w, "User does not have permission to execute commands", http.StatusForbidden)
+		return
+	}
+
+	var req shared.ReportExecutedCommandsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Commands) == "" {
+		http.Error(w, "commands are required", http.StatusBadRequest)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionCommandsExecute,
+		plan:   plan,
+		branch: branch,
+		data: map[string]interface{}{
+			"commands":   req.Commands,
+			"exitStatus": req.ExitStatus,
+			"attempt":    req.Attempt,
+		},
+	})
+
+	log.Println("ReportExecutedCommandsHandler processed successfully")
+}
+
+func parseAuditCursor(cursor string) (time.Time, string, error) {
+	i := strings.LastIndex(cursor, "_")
+	if i == -1 {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor[:i])
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	id := cursor[i+1:]
+	if _, err := uuid.Parse(id); err != nil {
+		return time.Time{}, "", err
+	}
+
+	return createdAt, id, nil
+}
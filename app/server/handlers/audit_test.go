@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIp(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    string
+		remoteAddr string
+		forwarded  string
+		realIp     string
+		want       string
+	}{
+		{"no proxy", "", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"forwarded headers ignored without trusted proxies", "", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"forwarded headers ignored from untrusted peer", "10.0.0.0/8", "203.0.113.7:5000", "198.51.100.1", "", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.0/8", "10.0.0.5:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed left-most hop", "10.0.0.0/8", "10.0.0.5:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.0/8,192.0.2.1", "10.0.0.5:5000", "1.2.3.4, 198.51.100.1, 192.0.2.1, 10.0.0.9", "", "198.51.100.1"},
+		{"invalid hop", "10.0.0.0/8", "10.0.0.5:5000", "198.51.100.1, not-an-ip", "", "10.0.0.5"},
+		{"real ip from trusted proxy", "10.0.0.5", "10.0.0.5:5000", "", "198.51.100.1", "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.trusted)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIp != "" {
+				r.Header.Set("X-Real-Ip", tt.realIp)
+			}
+
+			if got := requestIp(r); got != tt.want {
+				t.Errorf("requestIp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -59,6 +59,11 @@ func GetAuthHeader(r *http.Request) (*shared.AuthHeader, error) {
 	// strip off the "Bearer " prefix
 	encoded := strings.TrimPrefix(authHeader, "Bearer ")
 
+	// api tokens are sent as-is—the org comes from the token
+	if shared.IsApiToken(encoded) {
+		return &shared.AuthHeader{Token: encoded}, nil
+	}
+
 	// decode the base64-encoded credentials
 	bytes, err := base64.URLEncoding.DecodeString(encoded)
 
@@ -348,6 +353,11 @@ func ValidateAndSignIn(w http.ResponseWriter, r *http.Request, req shared.SignIn
 		}
 	}
 
+	if user.IsServiceAccount {
+		log.Printf("Service account sign in attempted: %v\n", user.Email)
+		return nil, fmt.Errorf("service accounts can only authenticate with api tokens")
+	}
+
 	var token string
 	var authTokenId string
 
@@ -482,7 +492,22 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	}
 
 	// validate the token
-	authToken, err := db.ValidateAuthToken(parsed.Token)
+	var authToken *db.AuthToken
+	var apiToken *db.ApiToken
+	var userId string
+
+	if shared.IsApiToken(parsed.Token) {
+		apiToken, err = db.ValidateApiToken(parsed.Token)
+		if err == nil {
+			userId = apiToken.UserId
+			parsed.OrgId = apiToken.OrgId
+		}
+	} else {
+		authToken, err = db.ValidateAuthToken(parsed.Token)
+		if err == nil {
+			userId = authToken.UserId
+		}
+	}
 
 	if err != nil {
 		log.Printf("error validating auth token: %v\n", err)
@@ -495,7 +520,19 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	user, err := db.GetUser(authToken.UserId)
+	if apiToken != nil {
+		go db.TouchApiToken(apiToken)
+
+		if !shared.HasApiTokenScope(apiToken.ApiScopes(), shared.ApiTokenScopeWrite) && !isReadOnlyRequest(r) && !isSelfRevokeRequest(r, apiToken.Id) {
+			log.Println("read-only api token used for a write request")
+			if raiseErr {
+				http.Error(w, "API token is read-only", http.StatusForbidden)
+			}
+			return nil
+		}
+	}
+
+	user, err := db.GetUser(userId)
 
 	if err != nil {
 		log.Printf("error getting user: %v\n", err)
@@ -508,6 +545,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	if !requireOrg {
 		return &types.ServerAuth{
 			AuthToken: authToken,
+			ApiToken:  apiToken,
 			User:      user,
 		}
 	}
@@ -521,7 +559,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	}
 
 	// validate the org membership
-	isMember, err := db.ValidateOrgMembership(authToken.UserId, parsed.OrgId)
+	isMember, err := db.ValidateOrgMembership(userId, parsed.OrgId)
 
 	if err != nil {
 		log.Printf("error validating org membership: %v\n", err)
@@ -531,6 +569,14 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
+	if !isMember && apiToken != nil {
+		log.Println("api token user is not a member of the org")
+		if raiseErr {
+			http.Error(w, "not a member of org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
 	if !isMember {
 		// check if there's an invite for this user and accept it if so (adds the user to the org)
 		invite, err := db.GetActiveInviteByEmail(parsed.OrgId, user.Email)
@@ -546,7 +592,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		if invite != nil {
 			log.Println("accepting invite")
 
-			err := db.AcceptInvite(r.Context(), invite, authToken.UserId)
+			err := db.AcceptInvite(r.Context(), invite, userId)
 
 			if err != nil {
 				log.Printf("error accepting invite: %v\n", err)
@@ -566,7 +612,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	}
 
 	// get user permissions
-	permissions, err := db.GetUserPermissions(authToken.UserId, parsed.OrgId)
+	permissions, err := db.GetUserPermissions(userId, parsed.OrgId)
 
 	if err != nil {
 		log.Printf("error getting user permissions: %v\n", err)
@@ -582,8 +628,13 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		permissionsMap[permission] = true
 	}
 
+	if apiToken != nil {
+		permissionsMap = shared.ScopePermissions(permissionsMap, apiToken.ApiScopes())
+	}
+
 	auth := &types.ServerAuth{
 		AuthToken:   authToken,
+		ApiToken:    apiToken,
 		User:        user,
 		OrgId:       parsed.OrgId,
 		Permissions: permissionsMap,
@@ -608,12 +659,41 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", authToken.UserId, user.Email, parsed.OrgId)
+	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", userId, user.Email, parsed.OrgId)
 
 	return auth
 
 }
 
+// read-only endpoints that take a request body
+var readOnlyPostPaths = []string{
+	"/plans/current_branches",
+	"/file_map",
+	"/billing/credits_summary",
+	"/billing/credits_transactions",
+}
+
+// isSelfRevokeRequest is true for a token revoking itself, which any scope can do
+func isSelfRevokeRequest(r *http.Request, apiTokenId string) bool {
+	return r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/api_tokens/"+apiTokenId)
+}
+
+func isReadOnlyRequest(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+
+	if r.Method == http.MethodPost {
+		for _, path := range readOnlyPostPaths {
+			if strings.HasSuffix(r.URL.Path, path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func authorizeProject(w http.ResponseWriter, projectId string, auth *types.ServerAuth) bool {
 	return authorizeProjectOptional(w, projectId, auth, true)
 }
@@ -687,15 +767,61 @@ func authorizePlan(w http.ResponseWriter, planId string, auth *types.ServerAuth)
 }
 
 func authorizePlanUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	return authorizePlanRole(w, planId, auth, shared.PlanShareRoleEditor)
+}
+
+// authorizePlanRole checks that the user has at least the given share role on the plan. The owner, and users who can update any plan, have every role. Access through a plan being shared with the whole org is view-only.
+func authorizePlanRole(w http.ResponseWriter, planId string, auth *types.ServerAuth, role shared.PlanShareRole) *db.Plan {
+	plan := authorizePlan(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if role == shared.PlanShareRoleViewer {
+		return plan
+	}
+
+	shareRole, err := getPlanShareRole(plan, auth)
+
+	if err != nil {
+		log.Printf("error getting plan share role: %v\n", err)
+		http.Error(w, "error getting plan share role", http.StatusInternalServerError)
+		return nil
+	}
+
+	if !shareRole.Includes(role) {
+		msg := "User does not have permission to update plan"
+		if role == shared.PlanShareRoleCommenter {
+			msg = "User does not have permission to chat with plan"
+		}
+		log.Println(msg)
+		http.Error(w, msg, http.StatusForbidden)
+		return nil
+	}
+
+	return plan
+}
+
+// getPlanShareRole returns the user's effective role on a plan they have access to—empty if their access is view-only through the plan being shared with the org
+func getPlanShareRole(plan *db.Plan, auth *types.ServerAuth) (shared.PlanShareRole, error) {
+	if plan.OwnerId == auth.User.Id || auth.HasPermission(shared.PermissionUpdateAnyPlan) {
+		return shared.PlanShareRoleEditor, nil
+	}
+
+	return db.GetPlanShareRole(plan.Id, auth.User.Id)
+}
+
+func authorizePlanManageShares(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
 	plan := authorizePlan(w, planId, auth)
 
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
-		log.Println("User does not have permission to update plan")
-		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
+	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionManageAnyPlanShares) {
+		log.Println("User does not have permission to manage plan shares")
+		http.Error(w, "User does not have permission to manage plan shares", http.StatusForbidden)
 		return nil
 	}
 
@@ -91,7 +91,7 @@ func CreateBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExecUpdate(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -171,7 +171,7 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
 		return
 	}
 
@@ -205,3 +205,147 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully deleted branch")
 }
+
+func MergeBranchHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for MergeBranchHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.MergeBranchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Source == "" && req.Sha == "" {
+		log.Println("No source branch or sha")
+		http.Error(w, "A source branch or sha is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Sha == "" && req.Source == branch {
+		log.Println("Cannot merge a branch into itself")
+		http.Error(w, "Cannot merge a branch into itself", http.StatusBadRequest)
+		return
+	}
+
+	if req.Source != "" {
+		sourceBranch, err := db.GetDbBranch(planId, req.Source)
+		if err != nil {
+			log.Printf("Error getting source branch: %v\n", err)
+			http.Error(w, "Error getting source branch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if sourceBranch == nil {
+			log.Printf("Source branch %s not found\n", req.Source)
+			http.Error(w, "Branch not found: "+req.Source, http.StatusNotFound)
+			return
+		}
+	}
+
+	// a model stream writes to its branch as it goes, so wait for it to finish before merging to or from it
+	for _, b := range []string{branch, req.Source} {
+		if b == "" {
+			continue
+		}
+		modelStream, err := db.GetActiveModelStream(planId, b)
+		if err != nil {
+			log.Printf("Error getting active model stream: %v\n", err)
+			http.Error(w, "Error getting active model stream", http.StatusInternalServerError)
+			return
+		}
+		if modelStream != nil {
+			log.Printf("Branch %s has an active model stream\n", b)
+			http.Error(w, fmt.Sprintf("Branch '%s' has an active stream—stop it or wait for it to finish before merging", b), http.StatusConflict)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	var res *shared.MergeBranchResponse
+
+	reason := "merge branch"
+	if req.Sha != "" {
+		reason = "cherry-pick"
+	}
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Reason:         reason,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		var err error
+		res, err = db.MergeBranch(repo, db.MergeBranchParams{
+			OrgId:             auth.OrgId,
+			PlanId:            planId,
+			Into:              branch,
+			Source:            req.Source,
+			Sha:               req.Sha,
+			DryRun:            req.DryRun,
+			Resolutions:       req.Resolutions,
+			DefaultResolution: req.DefaultResolution,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.Merged {
+			err = db.SyncPlanTokens(auth.OrgId, planId, branch)
+			if err != nil {
+				return fmt.Errorf("error syncing plan tokens: %v", err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error merging branch: %v\n", err)
+		http.Error(w, "Error merging branch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if res.Merged {
+		log.Println("Successfully merged branch")
+	} else {
+		log.Printf("Merge not applied - dry run: %v, conflicts: %d\n", req.DryRun, len(res.Conflicts))
+	}
+
+	w.Write(bytes)
+}
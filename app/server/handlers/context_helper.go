@@ -40,6 +40,14 @@ func loadContexts(
 
 	log.Printf("[ContextHelper] Starting loadContexts with %d contexts, cachedMapsByPath: %v, autoLoaded: %v", len(*loadReq), cachedMapsByPath != nil, autoLoaded)
 
+	for _, context := range *loadReq {
+		if context.ForceSkipIgnore && !auth.HasPermission(shared.PermissionForceLoadIgnored) {
+			log.Printf("User does not have permission to load ignored file %s\n", context.FilePath)
+			http.Error(w, fmt.Sprintf("User does not have permission to load %s since it's excluded by .plandexignore", context.FilePath), http.StatusForbidden)
+			return nil, nil
+		}
+	}
+
 	// check file count and size limits
 	// this is all a sanity check - we should have already checked these limits in the client
 	totalFiles := 0
@@ -299,5 +307,21 @@ func loadContexts(
 		return nil, nil
 	}
 
+	// loading a file that .plandexignore excludes is recorded, since it can send otherwise excluded content like secrets to model providers
+	var ignoredPaths []string
+	for _, context := range *loadReq {
+		if context.ForceSkipIgnore {
+			ignoredPaths = append(ignoredPaths, context.FilePath)
+		}
+	}
+	if len(ignoredPaths) > 0 {
+		recordAuditEvent(r, auth, auditEvent{
+			action: shared.AuditActionContextLoadIgnored,
+			plan:   plan,
+			branch: branchName,
+			data:   map[string]interface{}{"paths": ignoredPaths},
+		})
+	}
+
 	return loadRes, dbContexts
 }
@@ -1,20 +1,26 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/model"
 	"runtime"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	shared "plandex-shared"
 
 	"github.com/gorilla/mux"
 )
 
+// indexing embeds every changed chunk in the batch, so it gets more time than a single model request
+const semanticIndexTimeout = 3 * time.Minute
+
 func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for GetFileMapHandler")
 
@@ -64,6 +70,51 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var indexParams *model.SemanticIndexParams
+	if req.PlanId != "" {
+		plan := authorizePlan(w, req.PlanId, auth)
+		if plan == nil {
+			return
+		}
+
+		settings, err := db.GetPlanSettings(plan)
+		if err != nil {
+			log.Printf("GetFileMapHandler: error getting plan settings: %v\n", err)
+			http.Error(w, "Error getting plan settings: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if settings.SemanticRetrievalEnabled() {
+			orgUserConfig, err := db.GetOrgUserConfig(auth.User.Id, auth.OrgId)
+			if err != nil {
+				log.Printf("GetFileMapHandler: error getting org user config: %v\n", err)
+				http.Error(w, "Error getting org user config", http.StatusInternalServerError)
+				return
+			}
+
+			res := initClients(initClientsParams{
+				w:             w,
+				auth:          auth,
+				authVars:      req.AuthVars,
+				plan:          plan,
+				settings:      settings,
+				orgUserConfig: orgUserConfig,
+			})
+			if res.clients == nil {
+				return
+			}
+
+			indexParams = &model.SemanticIndexParams{
+				Clients:       res.clients,
+				AuthVars:      res.authVars,
+				Auth:          auth,
+				Plan:          plan,
+				Settings:      settings,
+				OrgUserConfig: orgUserConfig,
+			}
+		}
+	}
+
 	results := make(chan shared.FileMapBodies, 1)
 
 	err := queueProjectMapJob(projectMapJob{
@@ -90,6 +141,17 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 		resp := shared.GetFileMapResponse{
 			MapBodies: maps,
 		}
+
+		if indexParams != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), semanticIndexTimeout)
+			err := model.UpdateSemanticIndex(ctx, *indexParams, req.MapInputs)
+			cancel()
+			if err != nil {
+				log.Printf("GetFileMapHandler: error updating semantic index: %v", err)
+				resp.SemanticIndexError = err.Error()
+			}
+		}
+
 		respBytes, err := json.Marshal(resp)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
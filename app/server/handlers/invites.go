@@ -71,6 +71,18 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// role-scoped permissions for custom roles are granted to the default roles in every org, so make sure this one is the org's
+	orgRole, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if orgRole == nil {
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusNotFound)
+		return
+	}
+
 	// ensure user doesn't already have access to org via domain
 	split := strings.Split(req.Email, "@")
 	if len(split) != 2 {
@@ -125,15 +137,17 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	newInvite := &db.Invite{
+		OrgId:     auth.OrgId,
+		OrgRoleId: req.OrgRoleId,
+		Email:     req.Email,
+		Name:      req.Name,
+		InviterId: currentUserId,
+	}
+
 	err = db.WithTx(r.Context(), "invite user", func(tx *sqlx.Tx) error {
 
-		err = db.CreateInvite(&db.Invite{
-			OrgId:     auth.OrgId,
-			OrgRoleId: req.OrgRoleId,
-			Email:     req.Email,
-			Name:      req.Name,
-			InviterId: currentUserId,
-		}, tx)
+		err = db.CreateInvite(newInvite, tx)
 
 		if err != nil {
 			log.Printf("Error creating invite: %v\n", err)
@@ -156,6 +170,15 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionInviteCreate,
+		targetId: newInvite.Id,
+		data: map[string]interface{}{
+			"email":     req.Email,
+			"orgRoleId": req.OrgRoleId,
+		},
+	})
+
 	log.Println("Successfully created invite")
 }
 
@@ -403,5 +426,11 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionInviteDelete,
+		targetId: invite.Id,
+		data:     map[string]interface{}{"email": invite.Email},
+	})
+
 	log.Println("Successfully deleted invite")
 }
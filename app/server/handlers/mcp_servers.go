@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"plandex-server/db"
+	"plandex-server/mcp"
+	"regexp"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+var mcpServerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+func ListMcpServersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListMcpServersHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	projectId := mux.Vars(r)["projectId"]
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	servers, err := db.ListMcpServers(auth.OrgId, projectId)
+	if err != nil {
+		log.Println("Error listing mcp servers: ", err)
+		http.Error(w, "Error listing mcp servers", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListMcpServersResponse{
+		Servers: make([]*shared.McpServer, len(servers)),
+	}
+	for i, server := range servers {
+		res.Servers[i] = server.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListMcpServersHandler processed successfully")
+}
+
+func UpsertMcpServerHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpsertMcpServerHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageMcpServers) {
+		log.Println("User does not have permission to manage mcp servers")
+		http.Error(w, "User does not have permission to manage mcp servers", http.StatusForbidden)
+		return
+	}
+
+	projectId := mux.Vars(r)["projectId"]
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	var req shared.UpsertMcpServerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	err = validateMcpServerRequest(req)
+	if err != nil {
+		log.Println("Invalid mcp server: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := db.GetMcpServer(auth.OrgId, projectId, req.Name)
+	if err != nil {
+		log.Println("Error getting mcp server: ", err)
+		http.Error(w, "Error getting mcp server", http.StatusInternalServerError)
+		return
+	}
+
+	// secrets are only kept when the transport stays the same—stdio env and http headers aren't interchangeable
+	var existingEnv, existingHeaders db.McpServerVars
+	if existing != nil && existing.Transport == req.Transport {
+		existingEnv = existing.Env
+		existingHeaders = existing.Headers
+	}
+
+	req.Env, err = db.MergeMcpServerVars(existingEnv, req.Env)
+	if err != nil {
+		http.Error(w, "Invalid env: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Headers, err = db.MergeMcpServerVars(existingHeaders, req.Headers)
+	if err != nil {
+		http.Error(w, "Invalid headers: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	server, err := db.UpsertMcpServer(auth.OrgId, projectId, req)
+	if err != nil {
+		log.Println("Error upserting mcp server: ", err)
+		http.Error(w, "Error saving mcp server", http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(server.ToApi())
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("UpsertMcpServerHandler processed successfully")
+}
+
+func SetMcpServerEnabledHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetMcpServerEnabledHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageMcpServers) {
+		log.Println("User does not have permission to manage mcp servers")
+		http.Error(w, "User does not have permission to manage mcp servers", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+	name := vars["name"]
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	found, err := db.SetMcpServerEnabled(auth.OrgId, projectId, name, req.Enabled)
+	if err != nil {
+		log.Println("Error updating mcp server: ", err)
+		http.Error(w, "Error updating mcp server", http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+
+	log.Println("SetMcpServerEnabledHandler processed successfully")
+}
+
+func DeleteMcpServerHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteMcpServerHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageMcpServers) {
+		log.Println("User does not have permission to manage mcp servers")
+		http.Error(w, "User does not have permission to manage mcp servers", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+	name := vars["name"]
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	found, err := db.DeleteMcpServer(auth.OrgId, projectId, name)
+	if err != nil {
+		log.Println("Error deleting mcp server: ", err)
+		http.Error(w, "Error deleting mcp server", http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+
+	log.Println("DeleteMcpServerHandler processed successfully")
+}
+
+// ListMcpServerToolsHandler connects to a server and lists its tools—useful for checking that a newly added server works
+func ListMcpServerToolsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListMcpServerToolsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// connecting starts the server's process or makes requests to it, so it's limited to the same users who can configure it
+	if !auth.HasPermission(shared.PermissionManageMcpServers) {
+		log.Println("User does not have permission to manage mcp servers")
+		http.Error(w, "User does not have permission to manage mcp servers", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+	name := vars["name"]
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	server, err := db.GetMcpServer(auth.OrgId, projectId, name)
+	if err != nil {
+		log.Println("Error getting mcp server: ", err)
+		http.Error(w, "Error getting mcp server", http.StatusInternalServerError)
+		return
+	}
+
+	if server == nil {
+		http.Error(w, "MCP server not found", http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), mcp.ConnectTimeout)
+	defer cancel()
+
+	// use a fresh connection rather than the pool so that config problems show up immediately
+	client, err := mcp.Connect(ctx, server)
+	if err != nil {
+		log.Println("Error connecting to mcp server: ", err)
+		http.Error(w, fmt.Sprintf("Error connecting to MCP server: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		log.Println("Error listing mcp server tools: ", err)
+		http.Error(w, fmt.Sprintf("Error listing MCP server tools: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	res := shared.McpServerToolsResponse{
+		Tools: make([]*shared.McpToolInfo, len(tools)),
+	}
+	for i, tool := range tools {
+		res.Tools[i] = &shared.McpToolInfo{
+			Name:        tool.Name,
+			Description: tool.Description,
+		}
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListMcpServerToolsHandler processed successfully")
+}
+
+func validateMcpServerRequest(req shared.UpsertMcpServerRequest) error {
+	if !mcpServerNameRegex.MatchString(req.Name) {
+		return fmt.Errorf("name must be 1-32 letters, numbers, dashes, or underscores")
+	}
+
+	switch req.Transport {
+	case shared.McpTransportStdio:
+		if req.Command == "" {
+			return fmt.Errorf("command is required for stdio MCP servers")
+		}
+		if err := mcp.CheckStdioCommand(req.Command); err != nil {
+			return err
+		}
+		if req.Url != "" || len(req.Headers) > 0 {
+			return fmt.Errorf("url and headers are only used by http MCP servers")
+		}
+	case shared.McpTransportHttp:
+		u, err := url.Parse(req.Url)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("a valid http or https url is required for http MCP servers")
+		}
+		if err := mcp.CheckHttpUrl(req.Url); err != nil {
+			return err
+		}
+		if req.Command != "" || len(req.Args) > 0 || len(req.Env) > 0 {
+			return fmt.Errorf("command, args, and env are only used by stdio MCP servers")
+		}
+	default:
+		return fmt.Errorf("transport must be '%s' or '%s'", shared.McpTransportStdio, shared.McpTransportHttp)
+	}
+
+	return nil
+}
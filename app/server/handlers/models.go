@@ -28,6 +28,12 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !auth.HasPermission(shared.PermissionManageCustomProviders) {
+		log.Println("User does not have permission to manage custom models and providers")
+		http.Error(w, "User does not have permission to manage custom models and providers", http.StatusForbidden)
+		return
+	}
+
 	var modelsInput shared.ModelsInput
 	if err := json.NewDecoder(r.Body).Decode(&modelsInput); err != nil {
 		log.Printf("Error decoding request body: %v\n", err)
@@ -250,6 +256,30 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		err = modelPack.CheckModelKinds(func(id shared.ModelId) bool {
+			if bm, builtIn := shared.BuiltInBaseModelsById[id]; builtIn {
+				return bm.IsEmbeddingModel
+			}
+			// models being imported take precedence over existing ones with the same id
+			for _, model := range modelsInput.CustomModels {
+				if model.ModelId == id {
+					return model.IsEmbeddingModel
+				}
+			}
+			for _, model := range customModels {
+				if model.ModelId == id {
+					return model.IsEmbeddingModel
+				}
+			}
+			return false
+		})
+		if err != nil {
+			msg := fmt.Sprintf("Model pack '%s': %v", modelPack.Name, err)
+			log.Println(msg)
+			http.Error(w, msg, http.StatusUnprocessableEntity)
+			return
+		}
+
 		mp := modelPack.ToModelPack()
 		dbMp := db.ModelPackFromApi(&mp)
 		dbMp.OrgId = auth.OrgId
@@ -262,21 +292,27 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 	toDeleteCustomProviderIds := []string{}
 	toDeleteModelPackIds := []string{}
 
+	// names of what's removed, for the audit log
+	deletedNames := []string{}
+
 	for _, model := range customModels {
 		if _, exists := inputModelIds[string(model.ModelId)]; !exists {
 			toDeleteCustomModelIds = append(toDeleteCustomModelIds, model.Id)
+			deletedNames = append(deletedNames, "model:"+string(model.ModelId))
 		}
 	}
 
 	for _, provider := range customProviders {
 		if _, exists := inputProviderNames[provider.Name]; !exists {
 			toDeleteCustomProviderIds = append(toDeleteCustomProviderIds, provider.Id)
+			deletedNames = append(deletedNames, "provider:"+provider.Name)
 		}
 	}
 
 	for _, modelPack := range customModelPacks {
 		if _, exists := inputModelPackNames[modelPack.Name]; !exists {
 			toDeleteModelPackIds = append(toDeleteModelPackIds, modelPack.Id)
+			deletedNames = append(deletedNames, "modelPack:"+modelPack.Name)
 		}
 	}
 
@@ -333,6 +369,25 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	upsertedNames := []string{}
+	for _, model := range toUpsertCustomModels {
+		upsertedNames = append(upsertedNames, "model:"+string(model.ModelId))
+	}
+	for _, provider := range toUpsertCustomProviders {
+		upsertedNames = append(upsertedNames, "provider:"+provider.Name)
+	}
+	for _, modelPack := range toUpsertModelPacks {
+		upsertedNames = append(upsertedNames, "modelPack:"+modelPack.Name)
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionCustomModelsUpdate,
+		data: map[string]interface{}{
+			"upserted": upsertedNames,
+			"deleted":  deletedNames,
+		},
+	})
+
 	w.WriteHeader(http.StatusOK)
 
 	log.Println("Successfully imported custom models/providers/model packs")
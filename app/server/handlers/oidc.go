@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"plandex-server/db"
+	"plandex-server/oidc"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func GetOidcConfigHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOidcConfigHandler")
+
+	res := shared.OidcConfigResponse{}
+
+	provider := oidc.GetProvider()
+	if provider != nil {
+		discovery, err := provider.Discover(r.Context())
+		if err != nil {
+			log.Printf("Error discovering oidc provider: %v\n", err)
+			http.Error(w, "Error discovering identity provider", http.StatusBadGateway)
+			return
+		}
+
+		config := oidc.GetConfig()
+		res = shared.OidcConfigResponse{
+			Enabled:               true,
+			Required:              config.Required,
+			AuthorizationEndpoint: discovery.AuthorizationEndpoint,
+			ClientId:              config.ClientId,
+			Scopes:                config.Scopes,
+			RedirectPort:          config.RedirectPort,
+		}
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("GetOidcConfigHandler processed successfully")
+}
+
+func OidcSignInHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for OidcSignInHandler")
+
+	provider := oidc.GetProvider()
+	if provider == nil {
+		http.Error(w, "Single sign-on isn't configured on this server", http.StatusNotFound)
+		return
+	}
+	config := oidc.GetConfig()
+
+	var req shared.OidcSignInRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Code == "" || req.CodeVerifier == "" || req.Nonce == "" {
+		http.Error(w, "code, codeVerifier, and nonce are required", http.StatusBadRequest)
+		return
+	}
+
+	if !isLoopbackRedirect(req.RedirectUri) {
+		http.Error(w, "redirectUri must be a loopback address", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := provider.Exchange(r.Context(), req.Code, req.CodeVerifier, req.RedirectUri, req.Nonce)
+	if err != nil {
+		log.Printf("Error verifying oidc sign in: %v\n", err)
+		http.Error(w, "Error verifying identity: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if claims.Email == "" {
+		http.Error(w, "The identity provider didn't return an email address—make sure the 'email' scope is allowed", http.StatusForbidden)
+		return
+	}
+
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		http.Error(w, "Your email address isn't verified with the identity provider", http.StatusForbidden)
+		return
+	}
+
+	// resolve the org users are added to and the role their groups map to
+	var orgId string
+	var orgRole *db.OrgRole
+	if len(config.RoleMappings) > 0 || config.DefaultRole != "" {
+		orgId = config.OrgId
+		if orgId == "" {
+			orgId, err = db.GetSoleOrgId()
+			if err != nil {
+				log.Printf("Error getting org: %v\n", err)
+				http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if roleName := config.ResolveRole(claims.Groups); orgId != "" && roleName != "" {
+			orgRole, err = db.GetOrgRoleByName(orgId, roleName)
+			if err != nil {
+				log.Printf("Error getting org role: %v\n", err)
+				http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if orgRole == nil {
+				log.Printf("OIDC role mapping references unknown org role: %s\n", roleName)
+				http.Error(w, "Single sign-on is misconfigured: org role "+roleName+" not found", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	var user *db.User
+	var token string
+	var previousOrgRoleId string
+	var roleChanged bool
+
+	err = db.WithTx(r.Context(), "oidc sign in", func(tx *sqlx.Tx) error {
+		var err error
+
+		user, err = db.GetOidcIdentityUser(claims.Issuer, claims.Subject)
+		if err != nil {
+			return err
+		}
+
+		// first sign in with this identity—link it to an existing account with the same email, or create one
+		if user == nil {
+			user, err = db.GetUserByEmail(claims.Email)
+			if err != nil {
+				return err
+			}
+
+			// only link when the provider vouches for the email, otherwise anyone who can set an unverified email could take over the account
+			if user != nil && !claims.IsEmailVerified() {
+				return errOidcEmailUnverified
+			}
+		}
+
+		if user == nil {
+			name := claims.Name
+			if name == "" {
+				name = strings.Split(claims.Email, "@")[0]
+			}
+
+			user, err = db.CreateUser(name, claims.Email, tx)
+			if err != nil {
+				return err
+			}
+
+			// joining an org by email domain relies on the email, so it's skipped unless the provider verified it
+			if orgId == "" && claims.IsEmailVerified() {
+				_, err = db.AddToOrgForDomain(user.Id, user.Domain, tx)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if user.IsServiceAccount {
+			return errServiceAccountSignIn
+		}
+
+		err = db.UpsertOidcIdentity(user.Id, claims.Issuer, claims.Subject, tx)
+		if err != nil {
+			return err
+		}
+
+		if orgId != "" {
+			previousOrgRoleId, roleChanged, err = syncOidcOrgRole(orgId, user.Id, orgRole, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		token, _, err = db.CreateAuthToken(user.Id, tx)
+		if err != nil {
+			return fmt.Errorf("error creating auth token: %v", err)
+		}
+
+		return nil
+	})
+
+	if err == errServiceAccountSignIn {
+		http.Error(w, "Service accounts can only authenticate with api tokens", http.StatusForbidden)
+		return
+	} else if err == errOidcEmailUnverified {
+		http.Error(w, "An account with this email already exists—your identity provider must mark the email as verified to sign in to it", http.StatusForbidden)
+		return
+	} else if err == errOidcNoOrgAccess {
+		http.Error(w, "Your identity provider groups don't give you access to this server's org", http.StatusForbidden)
+		return
+	} else if err != nil {
+		log.Printf("Error signing in with oidc: %v\n", err)
+		http.Error(w, "Error signing in: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if roleChanged {
+		recordAuditEvent(r, &types.ServerAuth{User: user, OrgId: orgId}, auditEvent{
+			action:   shared.AuditActionUserRoleChange,
+			targetId: user.Id,
+			data: map[string]interface{}{
+				"previousOrgRoleId": previousOrgRoleId,
+				"orgRoleId":         orgRole.Id,
+				"source":            "oidc",
+			},
+		})
+	}
+
+	orgs, err := db.GetAccessibleOrgsForUser(user)
+	if err != nil {
+		log.Printf("Error getting orgs for user: %v\n", err)
+		http.Error(w, "Error getting orgs for user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiOrgs, apiErr := toApiOrgs(orgs)
+	if apiErr != nil {
+		log.Printf("Error converting orgs to api orgs: %v\n", apiErr)
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.SessionResponse{
+		UserId:   user.Id,
+		Token:    token,
+		Email:    user.Email,
+		UserName: user.Name,
+		Orgs:     apiOrgs,
+		IsSso:    true,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Signed in with oidc: %s\n", user.Email)
+
+	w.Write(bytes)
+}
+
+var errServiceAccountSignIn = fmt.Errorf("service accounts can only authenticate with api tokens")
+var errOidcNoOrgAccess = fmt.Errorf("no org access for oidc groups")
+var errOidcEmailUnverified = fmt.Errorf("oidc email not verified")
+
+// syncOidcOrgRole keeps a user's org membership in line with their identity provider groups. A nil role leaves existing members as they are. Returns the previous role and whether it changed—the previous role is empty when the user is added to the org.
+func syncOidcOrgRole(orgId, userId string, orgRole *db.OrgRole, tx *sqlx.Tx) (string, bool, error) {
+	orgUser, err := db.GetOrgUser(userId, orgId)
+	if err != nil {
+		return "", false, err
+	}
+
+	if orgUser == nil {
+		if orgRole == nil {
+			return "", false, errOidcNoOrgAccess
+		}
+		err = db.CreateOrgUser(orgId, userId, orgRole.Id, tx)
+		if err != nil {
+			return "", false, err
+		}
+		return "", true, nil
+	}
+
+	if orgRole == nil || orgUser.OrgRoleId == orgRole.Id {
+		return orgUser.OrgRoleId, false, nil
+	}
+
+	ownerRoleId, err := db.GetOrgOwnerRoleId()
+	if err != nil {
+		return "", false, err
+	}
+
+	// never demote the last owner, so the org can't be left without one
+	if orgUser.OrgRoleId == ownerRoleId {
+		numOwners, err := db.NumUsersWithRole(orgId, ownerRoleId)
+		if err != nil {
+			return "", false, err
+		}
+		if numOwners <= 1 {
+			log.Printf("Not changing role of last org owner %s from oidc groups\n", userId)
+			return orgUser.OrgRoleId, false, nil
+		}
+	}
+
+	err = db.UpdateOrgUserRole(orgId, userId, orgRole.Id, tx)
+	if err != nil {
+		return "", false, err
+	}
+
+	return orgUser.OrgRoleId, true, nil
+}
+
+// the CLI receives the authorization code on a local port, per RFC 8252
+func isLoopbackRedirect(redirectUri string) bool {
+	u, err := url.Parse(redirectUri)
+	if err != nil || u.Scheme != "http" {
+		return false
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// rejectIfSsoRequired blocks email pin sign in and account creation when the server requires single sign-on
+func rejectIfSsoRequired(w http.ResponseWriter) bool {
+	if !oidc.Required() || (os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1") {
+		return false
+	}
+
+	log.Println("Email pin auth attempted while single sign-on is required")
+	writeApiError(w, shared.ApiError{
+		Type:   shared.ApiErrorTypeSsoRequired,
+		Status: http.StatusForbidden,
+		Msg:    "This server requires single sign-on—run 'plandex sign-in' and choose to sign in with SSO",
+	})
+	return true
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"regexp"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+var orgRoleNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ListPermissionsHandler returns the current user's permissions in the org, so the client can check them up front—like exec_commands before applying a plan with commands
+func ListPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPermissionsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	perms := auth.Permissions
+	if perms == nil {
+		perms = shared.Permissions{}
+	}
+
+	bytes, err := json.Marshal(perms)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListPermissionsHandler processed successfully")
+}
+
+func CreateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateOrgRoleHandler")
+
+	auth := authorizeManageOrgRoles(w, r)
+	if auth == nil {
+		return
+	}
+
+	var req shared.CreateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !orgRoleNameRegex.MatchString(name) {
+		http.Error(w, "Role name must start with a letter or number and contain only lowercase letters, numbers, dashes, and underscores", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := db.GetOrgRoleByName(auth.OrgId, name)
+	if err != nil {
+		log.Println("Error getting org role: ", err)
+		http.Error(w, "Error getting org role", http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, fmt.Sprintf("A role named '%s' already exists", name), http.StatusConflict)
+		return
+	}
+
+	permissions, ok := validateOrgRolePermissions(w, auth, req.Permissions, nil)
+	if !ok {
+		return
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = name
+	}
+
+	role := &db.OrgRole{
+		OrgId:       &auth.OrgId,
+		Name:        name,
+		Label:       label,
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	err = db.WithTx(r.Context(), "create org role", func(tx *sqlx.Tx) error {
+		return db.CreateOrgRole(role, permissions, tx)
+	})
+
+	if err != nil {
+		log.Println("Error creating org role: ", err)
+		http.Error(w, "Error creating org role", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionRoleCreate,
+		targetId: role.Id,
+		data: map[string]interface{}{
+			"name":        role.Name,
+			"permissions": permissions,
+		},
+	})
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreateOrgRoleHandler processed successfully")
+}
+
+func UpdateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgRoleHandler")
+
+	auth := authorizeManageOrgRoles(w, r)
+	if auth == nil {
+		return
+	}
+
+	role := getCustomOrgRole(w, r, auth)
+	if role == nil {
+		return
+	}
+
+	var req shared.UpdateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	currentPermissions, err := db.ListOrgRolePermissions([]string{role.Id})
+	if err != nil {
+		log.Println("Error listing org role permissions: ", err)
+		http.Error(w, "Error listing org role permissions", http.StatusInternalServerError)
+		return
+	}
+	previousPermissions := currentPermissions[role.Id]
+
+	permissions, ok := validateOrgRolePermissions(w, auth, req.Permissions, previousPermissions)
+	if !ok {
+		return
+	}
+
+	if label := strings.TrimSpace(req.Label); label != "" {
+		role.Label = label
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		role.Description = description
+	}
+
+	err = db.WithTx(r.Context(), "update org role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgRole(role, permissions, tx)
+	})
+
+	if err != nil {
+		log.Println("Error updating org role: ", err)
+		http.Error(w, "Error updating org role", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionRoleUpdate,
+		targetId: role.Id,
+		data: map[string]interface{}{
+			"name":                role.Name,
+			"permissions":         permissions,
+			"previousPermissions": previousPermissions,
+		},
+	})
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("UpdateOrgRoleHandler processed successfully")
+}
+
+func DeleteOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgRoleHandler")
+
+	auth := authorizeManageOrgRoles(w, r)
+	if auth == nil {
+		return
+	}
+
+	role := getCustomOrgRole(w, r, auth)
+	if role == nil {
+		return
+	}
+
+	numUsers, err := db.NumUsersWithRole(auth.OrgId, role.Id)
+	if err != nil {
+		log.Println("Error counting users with role: ", err)
+		http.Error(w, "Error counting users with role", http.StatusInternalServerError)
+		return
+	}
+	if numUsers > 0 {
+		http.Error(w, fmt.Sprintf("%d users have the '%s' role—give them another role before deleting it", numUsers, role.Name), http.StatusBadRequest)
+		return
+	}
+
+	numInvites, err := db.NumPendingInvitesWithRole(auth.OrgId, role.Id)
+	if err != nil {
+		log.Println("Error counting pending invites with role: ", err)
+		http.Error(w, "Error counting pending invites with role", http.StatusInternalServerError)
+		return
+	}
+	if numInvites > 0 {
+		http.Error(w, fmt.Sprintf("%d pending invites have the '%s' role—revoke them before deleting it", numInvites, role.Name), http.StatusBadRequest)
+		return
+	}
+
+	err = db.WithTx(r.Context(), "delete org role", func(tx *sqlx.Tx) error {
+		return db.DeleteOrgRole(auth.OrgId, role.Id, tx)
+	})
+
+	if err != nil {
+		log.Println("Error deleting org role: ", err)
+		http.Error(w, "Error deleting org role", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionRoleDelete,
+		targetId: role.Id,
+		data:     map[string]interface{}{"name": role.Name},
+	})
+
+	log.Println("DeleteOrgRoleHandler processed successfully")
+}
+
+func authorizeManageOrgRoles(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for user management",
+		})
+		return nil
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't manage org roles",
+		})
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		log.Println("User does not have permission to manage org roles")
+		http.Error(w, "User does not have permission to manage org roles", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
+
+// getCustomOrgRole loads the role in the request path—default roles can't be changed
+func getCustomOrgRole(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth) *db.OrgRole {
+	roleId := mux.Vars(r)["roleId"]
+
+	if _, err := uuid.Parse(roleId); err != nil {
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Println("Error getting org role: ", err)
+		http.Error(w, "Error getting org role", http.StatusInternalServerError)
+		return nil
+	}
+
+	if role == nil {
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	if role.OrgId == nil {
+		http.Error(w, fmt.Sprintf("The default '%s' role can't be changed", role.Name), http.StatusBadRequest)
+		return nil
+	}
+
+	return role
+}
+
+// validateOrgRolePermissions dedupes a custom role's permissions and checks that the user can grant each one they're adding—nobody can create a role with more access than their own
+func validateOrgRolePermissions(w http.ResponseWriter, auth *types.ServerAuth, permissions, current []shared.Permission) ([]shared.Permission, bool) {
+	currentSet := make(map[shared.Permission]bool, len(current))
+	for _, p := range current {
+		currentSet[p] = true
+	}
+
+	seen := make(map[shared.Permission]bool, len(permissions))
+	res := []shared.Permission{}
+
+	for _, p := range permissions {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		if !p.IsCustomOrgRolePermission() {
+			http.Error(w, fmt.Sprintf("'%s' can't be included in a custom role", p), http.StatusBadRequest)
+			return nil, false
+		}
+
+		if !currentSet[p] && !auth.HasPermission(p) {
+			log.Printf("User can't grant permission they don't have: %s\n", p)
+			http.Error(w, fmt.Sprintf("You can't grant '%s' since your role doesn't have it", p), http.StatusForbidden)
+			return nil, false
+		}
+
+		res = append(res, p)
+	}
+
+	return res, true
+}
@@ -100,7 +100,7 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// create a new org
-		org, err = db.CreateOrg(&req, auth.AuthToken.UserId, domain, tx)
+		org, err = db.CreateOrg(&req, auth.User.Id, domain, tx)
 
 		if err != nil {
 			log.Printf("Error creating org: %v\n", err)
@@ -238,9 +238,24 @@ func ListOrgRolesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	roleIds := make([]string, len(roles))
+	for i, role := range roles {
+		roleIds[i] = role.Id
+	}
+
+	permissionsByRoleId, err := db.ListOrgRolePermissions(roleIds)
+
+	if err != nil {
+		log.Printf("Error listing org role permissions: %v\n", err)
+		http.Error(w, "Error listing org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	var apiRoles []*shared.OrgRole
 	for _, role := range roles {
-		apiRoles = append(apiRoles, role.ToApi())
+		apiRole := role.ToApi()
+		apiRole.Permissions = permissionsByRoleId[role.Id]
+		apiRoles = append(apiRoles, apiRole)
 	}
 
 	bytes, err := json.Marshal(apiRoles)
@@ -66,7 +66,7 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExecUpdate(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -86,6 +86,12 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionConfigUpdate,
+		plan:   plan,
+		data:   map[string]interface{}{"config": req.Config},
+	})
+
 	log.Println("UpdatePlanConfigHandler processed successfully")
 }
 
@@ -152,5 +158,10 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionDefaultConfigUpdate,
+		data:   map[string]interface{}{"config": req.Config},
+	})
+
 	log.Println("UpdateDefaultPlanConfigHandler processed successfully")
 }
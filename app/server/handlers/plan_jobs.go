@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/jobs"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func CreatePlanJobHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreatePlanJobHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branchName := vars["branch"]
+
+	plan := authorizePlanExecUpdate(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var req shared.CreatePlanJobRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Prompt) == "" {
+		http.Error(w, "A prompt is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.AutoMode != "" && !isValidAutoMode(req.AutoMode) {
+		http.Error(w, "Invalid auto mode: "+string(req.AutoMode), http.StatusBadRequest)
+		return
+	}
+
+	var nextRunAt *time.Time
+	if req.Schedule != "" {
+		schedule, err := shared.ParseCronSchedule(req.Schedule)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		next := schedule.Next(time.Now().UTC())
+		if next.IsZero() {
+			http.Error(w, "Schedule never runs", http.StatusBadRequest)
+			return
+		}
+		nextRunAt = &next
+	}
+
+	branch, err := db.GetDbBranch(planId, branchName)
+	if err != nil {
+		log.Println("Error getting branch: ", err)
+		http.Error(w, "Error getting branch", http.StatusInternalServerError)
+		return
+	}
+
+	if branch == nil {
+		http.Error(w, "Branch not found", http.StatusNotFound)
+		return
+	}
+
+	job := &db.PlanJob{
+		OrgId:     auth.OrgId,
+		PlanId:    plan.Id,
+		CreatorId: auth.User.Id,
+		Branch:    branchName,
+		Prompt:    req.Prompt,
+		AutoMode:  req.AutoMode,
+		Schedule:  strings.TrimSpace(req.Schedule),
+		Status:    shared.PlanJobStatusQueued,
+		NextRunAt: nextRunAt,
+	}
+
+	err = db.CreatePlanJob(job)
+	if err != nil {
+		log.Println("Error creating plan job: ", err)
+		http.Error(w, "Error creating plan job", http.StatusInternalServerError)
+		return
+	}
+
+	if nextRunAt == nil {
+		jobs.Wake()
+	}
+
+	bytes, err := json.Marshal(job.ToApi())
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreatePlanJobHandler processed successfully")
+}
+
+func ListPlanJobsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanJobsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	planId := mux.Vars(r)["planId"]
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	planJobs, err := db.ListPlanJobs([]string{plan.Id}, nil)
+	if err != nil {
+		log.Println("Error listing plan jobs: ", err)
+		http.Error(w, "Error listing plan jobs", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListPlanJobsResponse{
+		Jobs: make([]*shared.PlanJob, len(planJobs)),
+	}
+	for i, job := range planJobs {
+		res.Jobs[i] = job.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListPlanJobsHandler processed successfully")
+}
+
+func DeletePlanJobHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeletePlanJobHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	jobId := mux.Vars(r)["jobId"]
+
+	job, err := db.GetPlanJob(auth.OrgId, jobId)
+	if err != nil {
+		log.Println("Error getting plan job: ", err)
+		http.Error(w, "Error getting plan job", http.StatusInternalServerError)
+		return
+	}
+
+	if job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	if authorizePlanExecUpdate(w, job.PlanId, auth) == nil {
+		return
+	}
+
+	// a running job's current stream keeps going—it can be stopped like any other stream
+	_, err = db.DeletePlanJob(auth.OrgId, jobId)
+	if err != nil {
+		log.Println("Error deleting plan job: ", err)
+		http.Error(w, "Error deleting plan job", http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("DeletePlanJobHandler processed successfully")
+}
+
+func isValidAutoMode(mode shared.AutoModeType) bool {
+	if mode == shared.AutoModeCustom {
+		return false
+	}
+	for _, option := range shared.AutoModeOptions {
+		if string(mode) == option[0] {
+			return true
+		}
+	}
+	return false
+}
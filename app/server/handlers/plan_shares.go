@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanSharesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanSharesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	planId := mux.Vars(r)["planId"]
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	shares, err := db.ListPlanShares(plan.Id)
+	if err != nil {
+		log.Println("Error listing plan shares: ", err)
+		http.Error(w, "Error listing plan shares", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListPlanSharesResponse{
+		Shares: make([]*shared.PlanShare, len(shares)),
+	}
+	for i, share := range shares {
+		res.Shares[i] = share.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListPlanSharesHandler processed successfully")
+}
+
+func SharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	planId := mux.Vars(r)["planId"]
+
+	plan := authorizePlanManageShares(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var req shared.SharePlanRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if !req.Role.IsValid() {
+		http.Error(w, fmt.Sprintf("Invalid role: %s", req.Role), http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		log.Println("Error getting user: ", err)
+		http.Error(w, "Error getting user", http.StatusInternalServerError)
+		return
+	}
+
+	isMember := false
+	if user != nil {
+		isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+		if err != nil {
+			log.Println("Error validating org membership: ", err)
+			http.Error(w, "Error validating org membership", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if !isMember {
+		http.Error(w, fmt.Sprintf("No user with email %s in the org", email), http.StatusNotFound)
+		return
+	}
+
+	if user.Id == plan.OwnerId {
+		http.Error(w, "The plan's owner already has full access", http.StatusBadRequest)
+		return
+	}
+
+	share := &db.PlanShare{
+		OrgId:     auth.OrgId,
+		PlanId:    plan.Id,
+		UserId:    user.Id,
+		Role:      req.Role,
+		CreatedBy: auth.User.Id,
+		UserEmail: user.Email,
+		UserName:  user.Name,
+	}
+
+	err = db.UpsertPlanShare(share)
+	if err != nil {
+		log.Println("Error sharing plan: ", err)
+		http.Error(w, "Error sharing plan", http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(share.ToApi())
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("SharePlanHandler processed successfully")
+}
+
+func UnsharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UnsharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	userId := vars["userId"]
+
+	var plan *db.Plan
+	if userId == auth.User.Id {
+		// anyone can leave a plan that's shared with them
+		plan = authorizePlan(w, planId, auth)
+	} else {
+		plan = authorizePlanManageShares(w, planId, auth)
+	}
+	if plan == nil {
+		return
+	}
+
+	deleted, err := db.DeletePlanShare(plan.Id, userId)
+	if err != nil {
+		log.Println("Error deleting plan share: ", err)
+		http.Error(w, "Error deleting plan share", http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		http.Error(w, "Plan isn't shared with that user", http.StatusNotFound)
+		return
+	}
+
+	log.Println("UnsharePlanHandler processed successfully")
+}
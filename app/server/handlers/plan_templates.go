@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanTemplatesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	templates, err := db.ListPlanTemplates(auth.OrgId)
+	if err != nil {
+		log.Println("Error listing plan templates: ", err)
+		http.Error(w, "Error listing plan templates", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListPlanTemplatesResponse{
+		Templates: make([]*shared.PlanTemplate, len(templates)),
+	}
+	for i, template := range templates {
+		res.Templates[i] = template.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListPlanTemplatesHandler processed successfully")
+}
+
+func GetPlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetPlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+
+	template, err := db.GetPlanTemplateByName(auth.OrgId, name)
+	if err != nil {
+		log.Println("Error getting plan template: ", err)
+		http.Error(w, "Error getting plan template", http.StatusInternalServerError)
+		return
+	}
+
+	if template == nil {
+		http.Error(w, fmt.Sprintf("Template '%s' not found", name), http.StatusNotFound)
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("GetPlanTemplateHandler processed successfully")
+}
+
+func CreatePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreatePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManagePlanTemplates) {
+		log.Println("User does not have permission to manage plan templates")
+		http.Error(w, "User does not have permission to manage plan templates", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreatePlanTemplateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	err = validateCreatePlanTemplateRequest(req)
+	if err != nil {
+		log.Println("Invalid plan template: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !req.Replace {
+		existing, err := db.GetPlanTemplateByName(auth.OrgId, req.Name)
+		if err != nil {
+			log.Println("Error getting plan template: ", err)
+			http.Error(w, "Error getting plan template", http.StatusInternalServerError)
+			return
+		}
+
+		if existing != nil {
+			http.Error(w, fmt.Sprintf("Template '%s' already exists", req.Name), http.StatusConflict)
+			return
+		}
+	}
+
+	template, err := db.UpsertPlanTemplate(auth.OrgId, auth.User.Id, req)
+	if err != nil {
+		log.Println("Error creating plan template: ", err)
+		http.Error(w, "Error creating plan template", http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreatePlanTemplateHandler processed successfully")
+}
+
+func DeletePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeletePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManagePlanTemplates) {
+		log.Println("User does not have permission to manage plan templates")
+		http.Error(w, "User does not have permission to manage plan templates", http.StatusForbidden)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+
+	found, err := db.DeletePlanTemplate(auth.OrgId, name)
+	if err != nil {
+		log.Println("Error deleting plan template: ", err)
+		http.Error(w, "Error deleting plan template", http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, fmt.Sprintf("Template '%s' not found", name), http.StatusNotFound)
+		return
+	}
+
+	log.Println("DeletePlanTemplateHandler processed successfully")
+}
+
+func validateCreatePlanTemplateRequest(req shared.CreatePlanTemplateRequest) error {
+	err := shared.ValidatePlanTemplateName(req.Name)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(req.Prompt) == "" {
+		return fmt.Errorf("a prompt is required")
+	}
+
+	if req.AutoMode != "" && !isValidAutoMode(req.AutoMode) {
+		return fmt.Errorf("invalid auto mode: %s", req.AutoMode)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ExportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ExportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	// the archive goes to a temp file before responding so that an error partway through doesn't leave the client with a truncated file, without holding a multi-GB history in memory
+	archiveFile, err := os.CreateTemp("", "plandex-export-*.tar.gz")
+	if err != nil {
+		log.Println("Error creating temp file: ", err)
+		http.Error(w, "Error creating temp file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Reason:   "export plan",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		return repo.WritePlanArchive(plan, archiveFile)
+	})
+
+	if err != nil {
+		log.Println("Error exporting plan: ", err)
+		http.Error(w, "Error exporting plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	archiveInfo, err := archiveFile.Stat()
+	if err != nil {
+		log.Println("Error getting archive file info: ", err)
+		http.Error(w, "Error getting archive file info: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, err = archiveFile.Seek(0, io.SeekStart)
+	if err != nil {
+		log.Println("Error seeking archive file: ", err)
+		http.Error(w, "Error seeking archive file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Length", strconv.FormatInt(archiveInfo.Size(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", plan.Name+".tar.gz"))
+	_, err = io.Copy(w, archiveFile)
+	if err != nil {
+		log.Println("Error writing archive: ", err)
+		return
+	}
+
+	log.Println("ExportPlanHandler processed successfully")
+}
+
+func ImportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ImportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionCreatePlan) {
+		log.Println("User does not have permission to create a plan")
+		http.Error(w, "User does not have permission to create a plan", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillCreatePlan, hooks.HookParams{Auth: auth})
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	// the name is resolved from the manifest if not given, so uniqueness is checked after import
+	name := r.URL.Query().Get("name")
+
+	// request body size is already capped by the server's max bytes middleware
+	defer r.Body.Close()
+
+	plan, err := db.ImportPlanArchive(r.Context(), auth.OrgId, projectId, auth.User.Id, name, r.Body)
+	if err != nil {
+		log.Printf("Error importing plan: %v\n", err)
+		http.Error(w, "Error importing plan: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	uniqueName, err := getUniquePlanName(projectId, auth.User.Id, plan.Id, plan.Name)
+	if err != nil {
+		log.Printf("Error getting unique plan name: %v\n", err)
+		http.Error(w, "Error getting unique plan name: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if uniqueName != plan.Name {
+		err = db.RenamePlan(plan.Id, uniqueName, nil)
+		if err != nil {
+			log.Printf("Error renaming plan: %v\n", err)
+			http.Error(w, "Error renaming plan: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		plan.Name = uniqueName
+	}
+
+	resp := shared.CreatePlanResponse{
+		Id:   plan.Id,
+		Name: plan.Name,
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully imported plan: %v\n", plan)
+}
+
+func getUniquePlanName(projectId, ownerId, planId, name string) (string, error) {
+	i := 2
+	originalName := name
+	for {
+		var count int
+		err := db.Conn.Get(&count, "SELECT COUNT(*) FROM plans WHERE project_id = $1 AND owner_id = $2 AND name = $3 AND id != $4", projectId, ownerId, name, planId)
+		if err != nil {
+			return "", fmt.Errorf("error checking if plan exists: %v", err)
+		}
+
+		if count == 0 {
+			return name, nil
+		}
+
+		name = originalName + "." + fmt.Sprint(i)
+		i++
+	}
+}
@@ -3,12 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/webhooks"
 	"time"
 
 	shared "plandex-shared"
@@ -116,7 +118,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExecUpdate(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -184,6 +186,12 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("ApplyPlanHandler: Got current plan state:", currentPlan != nil)
 
+	if currentPlan.CurrentPlanFiles != nil && currentPlan.CurrentPlanFiles.Files["_apply.sh"] != "" && !auth.HasPermission(shared.PermissionExecCommands) {
+		log.Println("User does not have permission to execute commands")
+		http.Error(w, "User does not have permission to execute commands—reject _apply.sh to apply the plan's file changes without them", http.StatusForbidden)
+		return
+	}
+
 	res := initClients(
 		initClientsParams{
 			w:           w,
@@ -243,6 +251,33 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	webhooks.Dispatch(webhooks.Event{
+		Type:   shared.WebhookEventApplySucceeded,
+		OrgId:  auth.OrgId,
+		Branch: branch,
+		Plan:   plan,
+		Data:   map[string]interface{}{"commitMsg": commitMsg},
+	})
+
+	var appliedPaths []string
+	if currentPlan.PlanResult != nil {
+		for _, path := range currentPlan.PlanResult.SortedPaths {
+			if currentPlan.PlanResult.NumPendingForPath(path) > 0 {
+				appliedPaths = append(appliedPaths, path)
+			}
+		}
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionPlanApply,
+		plan:   plan,
+		branch: branch,
+		data: map[string]interface{}{
+			"commitMsg": commitMsg,
+			"paths":     appliedPaths,
+		},
+	})
+
 	w.Write([]byte(commitMsg))
 
 	log.Println("Successfully applied plan", planId)
@@ -261,7 +296,7 @@ func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
 		return
 	}
 
@@ -313,7 +348,7 @@ func RejectFileHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
 		return
 	}
 
@@ -373,7 +408,7 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
 		return
 	}
 
@@ -429,6 +464,162 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
+func RejectReplacementsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RejectReplacementsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.RejectReplacementsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.ReplacementIds) == 0 {
+		http.Error(w, "No replacements to reject", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		paths, err := db.RejectReplacements(auth.OrgId, planId, req.ReplacementIds, time.Now())
+		if err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf("🚫 Rejected %d pending change", len(req.ReplacementIds))
+		if len(req.ReplacementIds) > 1 {
+			msg += "s"
+		}
+		msg += " to:"
+		for _, path := range paths {
+			msg += fmt.Sprintf("\n • %s", path)
+		}
+
+		err = repo.GitAddAndCommit(branch, msg)
+		if err != nil {
+			return fmt.Errorf("error committing rejected changes: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error rejecting replacements: %v\n", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrReplacementConflict) {
+			status = http.StatusConflict
+		}
+		http.Error(w, "Error rejecting changes: "+err.Error(), status)
+		return
+	}
+
+	log.Println("Successfully rejected replacements", req.ReplacementIds)
+}
+
+func EditReplacementsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for EditReplacementsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanExecUpdate(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.EditReplacementsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Edits) == 0 {
+		http.Error(w, "No replacements to edit", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		paths, err := db.EditReplacements(auth.OrgId, planId, req.Edits)
+		if err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf("✏️ Edited %d pending change", len(req.Edits))
+		if len(req.Edits) > 1 {
+			msg += "s"
+		}
+		msg += " to:"
+		for _, path := range paths {
+			msg += fmt.Sprintf("\n • %s", path)
+		}
+
+		err = repo.GitAddAndCommit(branch, msg)
+		if err != nil {
+			return fmt.Errorf("error committing edited changes: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error editing replacements: %v\n", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrReplacementConflict) {
+			status = http.StatusConflict
+		}
+		http.Error(w, "Error editing changes: "+err.Error(), status)
+		return
+	}
+
+	log.Println("Successfully edited replacements")
+}
+
 func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 	auth := Authenticate(w, r, true)
 	if auth == nil {
@@ -474,6 +665,8 @@ func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{action: shared.AuditActionPlanArchive, plan: plan})
+
 	log.Println("Successfully archived plan", planId)
 }
 
@@ -522,6 +715,8 @@ func UnarchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{action: shared.AuditActionPlanUnarchive, plan: plan})
+
 	log.Println("Successfully unarchived plan", planId)
 }
 
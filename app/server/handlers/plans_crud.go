@@ -123,6 +123,8 @@ func CreatePlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 
+	recordAuditEvent(r, auth, auditEvent{action: shared.AuditActionPlanCreate, plan: plan})
+
 	log.Printf("Successfully created plan: %v\n", plan)
 }
 
@@ -202,6 +204,12 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionPlanRename,
+		plan:   plan,
+		data:   map[string]interface{}{"newName": requestBody.Name},
+	})
+
 	log.Println("Successfully renamed plan")
 }
 
@@ -259,6 +267,8 @@ func DeletePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{action: shared.AuditActionPlanDelete, plan: plan})
+
 	log.Println("Successfully deleted plan", planId)
 }
 
@@ -287,6 +297,8 @@ func DeleteAllPlansHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{action: shared.AuditActionPlanDeleteAll, targetId: projectId})
+
 	log.Println("Successfully deleted all plans")
 }
 
@@ -332,7 +344,7 @@ func ListPlansHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plans, err := db.ListOwnedPlans(authorizedProjectIds, auth.User.Id, false)
+	plans, err := db.ListUserPlans(authorizedProjectIds, auth.User.Id, false)
 
 	if err != nil {
 		log.Printf("Error listing plans: %v\n", err)
@@ -385,7 +397,7 @@ func ListArchivedPlansHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	plans, err := db.ListOwnedPlans(authorizedProjectIds, auth.User.Id, true)
+	plans, err := db.ListUserPlans(authorizedProjectIds, auth.User.Id, true)
 
 	if err != nil {
 		log.Printf("Error listing plans: %v\n", err)
@@ -424,7 +436,7 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	plans, err := db.ListOwnedPlans(projectIds, auth.User.Id, false)
+	plans, err := db.ListUserPlans(projectIds, auth.User.Id, false)
 
 	if err != nil {
 		log.Printf("Error listing plans: %v\n", err)
@@ -437,9 +449,10 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		planIds = append(planIds, plan.Id)
 	}
 
-	errCh := make(chan error, 2)
+	errCh := make(chan error, 3)
 	var streams []*db.ModelStream
 	var branches []*db.Branch
+	var planJobs []*db.PlanJob
 
 	go func() {
 		defer func() {
@@ -480,7 +493,24 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		errCh <- nil
 	}()
 
-	for i := 0; i < 2; i++ {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in ListPlansRunningHandler: %v\n%s", r, debug.Stack())
+				errCh <- fmt.Errorf("panic in ListPlansRunningHandler: %v\n%s", r, debug.Stack())
+				runtime.Goexit() // don't allow outer function to continue and double-send to channel
+			}
+		}()
+		var err error
+		planJobs, err = db.ListPlanJobs(planIds, []shared.PlanJobStatus{shared.PlanJobStatusQueued, shared.PlanJobStatusRunning})
+		if err != nil {
+			errCh <- fmt.Errorf("error getting plan jobs: %v", err)
+			return
+		}
+		errCh <- nil
+	}()
+
+	for i := 0; i < 3; i++ {
 		err := <-errCh
 		if err != nil {
 			log.Println(err)
@@ -495,6 +525,7 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		StreamFinishedAtByBranchId: map[string]time.Time{},
 		PlansById:                  map[string]*shared.Plan{},
 		StreamIdByBranchId:         map[string]string{},
+		Jobs:                       []*shared.PlanJob{},
 	}
 
 	var apiPlansById = make(map[string]*shared.Plan)
@@ -540,6 +571,13 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		res.PlansById[stream.PlanId] = apiPlan
 	}
 
+	for _, job := range planJobs {
+		res.Jobs = append(res.Jobs, job.ToApi())
+		if apiPlan, ok := apiPlansById[job.PlanId]; ok {
+			res.PlansById[job.PlanId] = apiPlan
+		}
+	}
+
 	sort.Slice(res.Branches, func(i, j int) bool {
 		iComposite := res.Branches[i].PlanId + "|" + res.Branches[i].Name
 		jComposite := res.Branches[j].PlanId + "|" + res.Branches[j].Name
@@ -596,7 +634,7 @@ func GetCurrentBranchByPlanIdHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plans, err := db.ListOwnedPlans([]string{projectId}, auth.User.Id, false)
+	plans, err := db.ListUserPlans([]string{projectId}, auth.User.Id, false)
 
 	if err != nil {
 		log.Printf("Error listing plans: %v\n", err)
@@ -34,7 +34,8 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlanExecUpdate(w, planId, auth)
+	// commenters can chat—checked below once the request is parsed
+	plan := authorizePlanRole(w, planId, auth, shared.PlanShareRoleCommenter)
 	if plan == nil {
 		return
 	}
@@ -66,6 +67,29 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	shareRole, err := getPlanShareRole(plan, auth)
+	if err != nil {
+		log.Printf("Error getting plan share role: %v\n", err)
+		http.Error(w, "Error getting plan share role", http.StatusInternalServerError)
+		return
+	}
+
+	if shareRole != shared.PlanShareRoleEditor {
+		if !requestBody.IsChatOnly {
+			log.Println("Commenter tried to make changes to plan")
+			http.Error(w, "Commenters can only chat with a plan", http.StatusForbidden)
+			return
+		}
+
+		// auto-loading context would change the plan
+		requestBody.AutoContext = false
+	}
+
+	// the model only writes commands to _apply.sh if the user's org role allows executing them
+	if !auth.HasPermission(shared.PermissionExecCommands) {
+		requestBody.ExecEnabled = false
+	}
+
 	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
@@ -281,7 +305,7 @@ func StopPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanRole(w, planId, auth, shared.PlanShareRoleCommenter) == nil {
 		return
 	}
 
@@ -358,7 +382,7 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanRole(w, planId, auth, shared.PlanShareRoleCommenter)
 	if plan == nil {
 		return
 	}
@@ -425,6 +449,65 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully processed request for RespondMissingFileHandler")
 }
 
+func RespondCodeSearchHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RespondCodeSearchHandler", "ip:", host.Ip)
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId)
+	log.Println("branch: ", branch)
+	isProxy := r.URL.Query().Get("proxy") == "true"
+
+	active := modelPlan.GetActivePlan(planId, branch)
+	if active == nil {
+		if isProxy {
+			log.Println("No active plan on proxied request")
+			http.Error(w, "No active plan", http.StatusNotFound)
+			return
+		}
+
+		proxyActivePlanMethod(w, r, planId, branch, "respond_code_search")
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	plan := authorizePlanRole(w, planId, auth, shared.PlanShareRoleCommenter)
+	if plan == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var requestBody shared.RespondCodeSearchRequest
+	if err := json.Unmarshal(body, &requestBody); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("code search %s: %d matches, %d files\n", requestBody.Id, len(requestBody.Matches), len(requestBody.Files))
+
+	// the stream only waits for one search at a time, so a response for a search that already timed out is either dropped here or skipped by id
+	select {
+	case active.CodeSearchResponseCh <- &requestBody:
+	default:
+		log.Printf("Dropping code search response %s - no search is waiting\n", requestBody.Id)
+	}
+
+	log.Println("Successfully processed request for RespondCodeSearchHandler")
+}
+
 func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for AutoLoadContextHandler", "ip:", host.Ip)
 
@@ -453,7 +536,7 @@ func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExecUpdate(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -617,16 +700,5 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func authorizePlanExecUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
-	if plan == nil {
-		return nil
-	}
-
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
-		log.Println("User does not have permission to update plan")
-		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
-		return nil
-	}
-
-	return plan
+	return authorizePlanRole(w, planId, auth, shared.PlanShareRoleEditor)
 }
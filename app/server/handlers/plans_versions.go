@@ -93,7 +93,8 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	plan := authorizePlanExecUpdate(w, planId, auth)
+	if plan == nil {
 		return
 	}
 
@@ -180,5 +181,12 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionPlanRewind,
+		plan:   plan,
+		branch: branch,
+		data:   map[string]interface{}{"sha": requestBody.Sha},
+	})
+
 	log.Println("Successfully processed request for RewindPlanHandler")
 }
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+func CreateServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateServiceAccountHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeCredentialManagement(w, auth) {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		log.Println("User does not have permission to manage service accounts")
+		http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreateServiceAccountRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "A name is required", http.StatusBadRequest)
+		return
+	}
+
+	if !canAssignServiceAccountRole(auth, req.OrgRoleId) {
+		log.Printf("User does not have permission to create service account with role: %v\n", req.OrgRoleId)
+		http.Error(w, "User does not have permission to create service account with role: "+req.OrgRoleId, http.StatusForbidden)
+		return
+	}
+
+	orgRole, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if orgRole == nil {
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusNotFound)
+		return
+	}
+
+	var user *db.User
+	err = db.WithTx(r.Context(), "create service account", func(tx *sqlx.Tx) error {
+		var err error
+		user, err = db.CreateServiceAccount(auth.OrgId, name, req.OrgRoleId, tx)
+		if err != nil {
+			log.Println("Error creating service account: ", err)
+			return fmt.Errorf("error creating service account: %v", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Println("Error creating service account: ", err)
+		http.Error(w, "Error creating service account", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionServiceAccountCreate,
+		targetId: user.Id,
+		data: map[string]interface{}{
+			"name":      user.Name,
+			"email":     user.Email,
+			"orgRoleId": req.OrgRoleId,
+		},
+	})
+
+	bytes, err := json.Marshal(shared.ServiceAccount{
+		Id:        user.Id,
+		Name:      user.Name,
+		Email:     user.Email,
+		OrgRoleId: req.OrgRoleId,
+		CreatedAt: user.CreatedAt,
+	})
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreateServiceAccountHandler processed successfully")
+}
+
+func ListServiceAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListServiceAccountsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	serviceAccounts, err := db.ListServiceAccounts(auth.OrgId)
+	if err != nil {
+		log.Println("Error listing service accounts: ", err)
+		http.Error(w, "Error listing service accounts", http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.ListServiceAccountsResponse{
+		ServiceAccounts: serviceAccounts,
+	})
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListServiceAccountsHandler processed successfully")
+}
+
+func DeleteServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteServiceAccountHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeCredentialManagement(w, auth) {
+		return
+	}
+
+	userId := mux.Vars(r)["userId"]
+
+	user := authorizeServiceAccount(w, auth, userId)
+	if user == nil {
+		return
+	}
+
+	// the user row is kept so plans it owns aren't deleted—removing it from the org also revokes its tokens
+	err := db.WithTx(r.Context(), "delete service account", func(tx *sqlx.Tx) error {
+		err := db.DeleteOrgUser(auth.OrgId, user.Id, tx)
+		if err != nil {
+			log.Println("Error deleting org user: ", err)
+			return fmt.Errorf("error deleting org user: %v", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Println("Error deleting service account: ", err)
+		http.Error(w, "Error deleting service account", http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionUserRemove,
+		targetId: user.Id,
+		data: map[string]interface{}{
+			"email":          user.Email,
+			"serviceAccount": true,
+		},
+	})
+
+	log.Println("DeleteServiceAccountHandler processed successfully")
+}
+
+// canAssignServiceAccountRole applies the same rule as invites—a service account can't be given a role the user couldn't invite someone with
+func canAssignServiceAccountRole(auth *types.ServerAuth, orgRoleId string) bool {
+	return auth.HasPermissionForResource(shared.PermissionInviteUser, orgRoleId)
+}
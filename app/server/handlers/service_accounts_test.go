@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"plandex-server/types"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestCanAssignServiceAccountRole(t *testing.T) {
+	auth := &types.ServerAuth{
+		Permissions: shared.Permissions{
+			string(shared.PermissionManageServiceAccounts):            true,
+			string(shared.PermissionInviteUser) + "|member-role-id":   true,
+			string(shared.PermissionInviteUser) + "|reviewer-role-id": true,
+		},
+	}
+
+	if !canAssignServiceAccountRole(auth, "member-role-id") {
+		t.Error("expected a role the user can invite with to be assignable")
+	}
+	if canAssignServiceAccountRole(auth, "owner-role-id") {
+		t.Error("expected a role the user can't invite with not to be assignable")
+	}
+	if canAssignServiceAccountRole(&types.ServerAuth{Permissions: shared.Permissions{}}, "member-role-id") {
+		t.Error("expected no roles to be assignable without invite permissions")
+	}
+}
+
+func TestIsSelfRevokeRequest(t *testing.T) {
+	if !isSelfRevokeRequest(httptest.NewRequest("DELETE", "/api_tokens/token-1", nil), "token-1") {
+		t.Error("expected a token deleting itself to be a self-revoke")
+	}
+	if isSelfRevokeRequest(httptest.NewRequest("DELETE", "/api_tokens/token-2", nil), "token-1") {
+		t.Error("expected deleting another token not to be a self-revoke")
+	}
+	if isSelfRevokeRequest(httptest.NewRequest("DELETE", "/plans/token-1", nil), "token-1") {
+		t.Error("expected another route not to be a self-revoke")
+	}
+}
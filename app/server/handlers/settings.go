@@ -91,7 +91,7 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExecUpdate(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -176,6 +176,10 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("no model pack name or model pack provided")
 		}
 
+		if modelPackChanged(originalSettings, settings) && !auth.HasPermission(shared.PermissionChangeModelPack) {
+			return errChangeModelPackPermission
+		}
+
 		// log.Println("Original settings:")
 		// spew.Dump(originalSettings)
 
@@ -199,7 +203,11 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 
-	if err != nil {
+	if err == errChangeModelPackPermission {
+		log.Println("User does not have permission to change the model pack")
+		http.Error(w, "User does not have permission to change the model pack", http.StatusForbidden)
+		return
+	} else if err != nil {
 		log.Println("Error updating settings: ", err)
 		http.Error(w, "Error updating settings", http.StatusInternalServerError)
 		return
@@ -218,6 +226,13 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionSettingsUpdate,
+		plan:   plan,
+		branch: branch,
+		data:   map[string]interface{}{"changes": commitMsg},
+	})
+
 	log.Println("UpdateSettingsHandler processed successfully")
 
 }
@@ -301,6 +316,10 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("no model pack name or model pack provided")
 		}
 
+		if modelPackChanged(originalSettings, settings) && !auth.HasPermission(shared.PermissionChangeModelPack) {
+			return errChangeModelPackPermission
+		}
+
 		// log.Println("Original settings:")
 		// spew.Dump(originalSettings)
 
@@ -317,7 +336,11 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 
-	if err != nil {
+	if err == errChangeModelPackPermission {
+		log.Println("User does not have permission to change the model pack")
+		http.Error(w, "User does not have permission to change the model pack", http.StatusForbidden)
+		return
+	} else if err != nil {
 		log.Println("Error updating default settings: ", err)
 		http.Error(w, "Error updating default settings", http.StatusInternalServerError)
 		return
@@ -338,6 +361,11 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 
+	recordAuditEvent(r, auth, auditEvent{
+		action: shared.AuditActionDefaultSettingsUpdate,
+		data:   map[string]interface{}{"changes": commitMsg},
+	})
+
 	log.Println("UpdateDefaultSettingsHandler processed successfully")
 }
 
@@ -370,6 +398,17 @@ func getUpdateCommitMsg(settings *shared.PlanSettings, originalSettings *shared.
 	return s
 }
 
+var errChangeModelPackPermission = fmt.Errorf("user does not have permission to change the model pack")
+
+// modelPackChanged is true if the updated settings use a different model pack, or different models in a custom pack
+func modelPackChanged(original, updated *shared.PlanSettings) bool {
+	if original.ModelPackName != "" || updated.ModelPackName != "" {
+		return original.ModelPackName != updated.ModelPackName
+	}
+	changes := compareAny(original.GetModelPack().ToModelPackSchema().ModelPackSchemaRoles, updated.GetModelPack().ToModelPackSchema().ModelPackSchemaRoles, "", nil)
+	return len(changes) > 0
+}
+
 func compareSettings(original, updated *shared.PlanSettings, changes []string) []string {
 	if updated.ModelPackName != "" {
 		originalName := "custom"
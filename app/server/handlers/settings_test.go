@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestModelPackChanged(t *testing.T) {
+	packA := shared.BuiltInModelPacks[0]
+	packB := shared.BuiltInModelPacks[1]
+
+	named := func(name string) *shared.PlanSettings {
+		return &shared.PlanSettings{ModelPackName: name, Configured: true}
+	}
+	custom := func(mp *shared.ModelPack) *shared.PlanSettings {
+		return &shared.PlanSettings{ModelPack: mp, Configured: true}
+	}
+
+	tests := []struct {
+		name              string
+		original, updated *shared.PlanSettings
+		want              bool
+	}{
+		{"same named pack", named(packA.Name), named(packA.Name), false},
+		{"different named pack", named(packA.Name), named(packB.Name), true},
+		{"named to custom", named(packA.Name), custom(packA), true},
+		{"same custom pack", custom(packA), custom(packA), false},
+		{"different custom pack", custom(packA), custom(packB), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modelPackChanged(tt.original, tt.updated); got != tt.want {
+				t.Errorf("modelPackChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
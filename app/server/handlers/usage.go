@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const maxUsageLogPageSize = 500
+
+// self-hosted usage ledger—these serve the same routes and response types as Plandex Cloud billing so that `plandex usage` works against either
+
+func CreditsSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreditsSummaryHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.CreditsLogRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, monthStart, ok := getModelUsageFilter(w, auth, req)
+	if !ok {
+		return
+	}
+
+	res, err := db.GetModelUsageSummary(auth.OrgId, filter)
+	if err != nil {
+		log.Println("Error getting usage summary: ", err)
+		http.Error(w, "Error getting usage summary", http.StatusInternalServerError)
+		return
+	}
+	res.MonthStart = monthStart
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreditsSummaryHandler processed successfully")
+}
+
+func CreditsTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreditsTransactionsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 100
+	}
+	if pageSize > maxUsageLogPageSize {
+		pageSize = maxUsageLogPageSize
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	var req shared.CreditsLogRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, monthStart, ok := getModelUsageFilter(w, auth, req)
+	if !ok {
+		return
+	}
+
+	res := shared.CreditsLogResponse{
+		Transactions:  []*shared.CreditsTransaction{},
+		MonthStart:    monthStart,
+		PlanNamesById: map[string]string{},
+	}
+
+	// the ledger only records debits
+	if req.TransactionType != shared.CreditsTransactionTypeCredit {
+		usage, total, err := db.ListModelUsage(auth.OrgId, filter, pageSize, (page-1)*pageSize)
+		if err != nil {
+			log.Println("Error listing usage: ", err)
+			http.Error(w, "Error listing usage", http.StatusInternalServerError)
+			return
+		}
+
+		for _, u := range usage {
+			transaction := u.ToApi()
+			res.Transactions = append(res.Transactions, transaction)
+			if transaction.DebitPlanId != nil && transaction.DebitPlanName != nil {
+				res.PlanNamesById[*transaction.DebitPlanId] = *transaction.DebitPlanName
+			}
+		}
+
+		res.NumPages = int(math.Ceil(float64(total) / float64(pageSize)))
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreditsTransactionsHandler processed successfully")
+}
+
+func ListModelPricesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListModelPricesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	prices, err := db.ListModelPrices(auth.OrgId)
+	if err != nil {
+		log.Println("Error listing model prices: ", err)
+		http.Error(w, "Error listing model prices", http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.ListModelPricesResponse{Prices: prices})
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListModelPricesHandler processed successfully")
+}
+
+func SetModelPriceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetModelPriceHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageBilling) {
+		log.Println("User does not have permission to set model prices")
+		http.Error(w, "User does not have permission to set model prices", http.StatusForbidden)
+		return
+	}
+
+	var req shared.SetModelPriceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.ModelId == "" {
+		http.Error(w, "Model id is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.InputPricePerMillion.IsNegative() || req.OutputPricePerMillion.IsNegative() || req.CachedInputPricePerMillion.IsNegative() {
+		http.Error(w, "Prices can't be negative", http.StatusBadRequest)
+		return
+	}
+
+	err = db.UpsertModelPrice(auth.OrgId, req)
+	if err != nil {
+		log.Println("Error setting model price: ", err)
+		http.Error(w, "Error setting model price", http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("SetModelPriceHandler processed successfully")
+}
+
+func DeleteModelPriceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteModelPriceHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageBilling) {
+		log.Println("User does not have permission to reset model prices")
+		http.Error(w, "User does not have permission to reset model prices", http.StatusForbidden)
+		return
+	}
+
+	modelId := shared.ModelId(mux.Vars(r)["modelId"])
+
+	err := db.DeleteModelPrice(auth.OrgId, modelId)
+	if err != nil {
+		log.Println("Error deleting model price: ", err)
+		http.Error(w, "Error deleting model price", http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("DeleteModelPriceHandler processed successfully")
+}
+
+// getModelUsageFilter limits users without billing permission to their own usage
+func getModelUsageFilter(w http.ResponseWriter, auth *types.ServerAuth, req shared.CreditsLogRequest) (db.ModelUsageFilter, time.Time, bool) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	filter := db.ModelUsageFilter{
+		PlanId:    req.PlanId,
+		SessionId: req.SessionId,
+		Since:     req.DayStart,
+	}
+
+	if req.Month {
+		filter.Since = &monthStart
+	}
+
+	if req.PlanId != "" {
+		plan := authorizePlan(w, req.PlanId, auth)
+		if plan == nil {
+			return filter, monthStart, false
+		}
+	}
+
+	if !auth.HasPermission(shared.PermissionManageBilling) {
+		filter.UserId = auth.User.Id
+	}
+
+	return filter, monthStart, true
+}
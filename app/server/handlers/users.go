@@ -219,5 +219,143 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionUserRemove,
+		targetId: userId,
+		data:     map[string]interface{}{"orgRoleId": orgUser.OrgRoleId},
+	})
+
 	log.Println("Successfully processed request for DeleteOrgUserHandler")
 }
+
+func SetOrgUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for SetOrgUserRoleHandler")
+
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for user management",
+		})
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't change user roles",
+		})
+		return
+	}
+
+	vars := mux.Vars(r)
+	userId := vars["userId"]
+
+	log.Println("userId: ", userId)
+
+	var req shared.SetOrgUserRoleRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	orgUser, err := db.GetOrgUser(userId, auth.OrgId)
+
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser == nil {
+		http.Error(w, "User "+userId+" is not a member of org "+auth.OrgId, http.StatusNotFound)
+		return
+	}
+
+	// ensure current user can change the target user's role from their current role and to the new one
+	if !auth.HasPermissionForResource(shared.PermissionSetUserRole, orgUser.OrgRoleId) || !auth.HasPermissionForResource(shared.PermissionSetUserRole, req.OrgRoleId) {
+		log.Printf("User does not have permission to change role from %v to %v\n", orgUser.OrgRoleId, req.OrgRoleId)
+		http.Error(w, "User does not have permission to change role from "+orgUser.OrgRoleId+" to "+req.OrgRoleId, http.StatusForbidden)
+		return
+	}
+
+	orgRole, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgRole == nil {
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusNotFound)
+		return
+	}
+
+	if orgUser.OrgRoleId == orgRole.Id {
+		log.Println("User already has role, nothing to change")
+		return
+	}
+
+	orgOwnerRoleId, err := db.GetOrgOwnerRoleId()
+
+	if err != nil {
+		log.Printf("Error getting org owner role id: %v\n", err)
+		http.Error(w, "Error getting org owner role id: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// verify user isn't the only org owner
+	if orgUser.OrgRoleId == orgOwnerRoleId {
+		numOwners, err := db.NumUsersWithRole(auth.OrgId, orgOwnerRoleId)
+
+		if err != nil {
+			log.Printf("Error getting number of org owners: %v\n", err)
+			http.Error(w, "Error getting number of org owners: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if numOwners == 1 {
+			log.Println("Cannot change the role of the only org owner")
+			http.Error(w, "Cannot change the role of the only org owner", http.StatusForbidden)
+			return
+		}
+	}
+
+	err = db.WithTx(r.Context(), "set org user role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgUserRole(auth.OrgId, userId, orgRole.Id, tx)
+	})
+
+	if err != nil {
+		log.Println("Error setting org user role: ", err)
+		http.Error(w, "Error setting org user role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAuditEvent(r, auth, auditEvent{
+		action:   shared.AuditActionUserRoleChange,
+		targetId: userId,
+		data: map[string]interface{}{
+			"previousOrgRoleId": orgUser.OrgRoleId,
+			"orgRoleId":         orgRole.Id,
+			"orgRole":           orgRole.Name,
+		},
+	})
+
+	log.Println("Successfully processed request for SetOrgUserRoleHandler")
+}
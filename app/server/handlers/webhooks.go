@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"plandex-server/db"
+	"plandex-server/webhooks"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+)
+
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhooksHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	hooks, err := db.ListWebhooks(auth.OrgId)
+	if err != nil {
+		log.Println("Error listing webhooks: ", err)
+		http.Error(w, "Error listing webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListWebhooksResponse{
+		Webhooks: make([]*shared.Webhook, len(hooks)),
+	}
+	for i, hook := range hooks {
+		res.Webhooks[i] = hook.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListWebhooksHandler processed successfully")
+}
+
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	err = validateCreateWebhookRequest(req)
+	if err != nil {
+		log.Println("Invalid webhook: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var projectId *string
+	if req.ProjectId != "" {
+		if !authorizeProject(w, req.ProjectId, auth) {
+			return
+		}
+		projectId = &req.ProjectId
+	}
+
+	secret, err := genWebhookSecret()
+	if err != nil {
+		log.Println("Error generating webhook secret: ", err)
+		http.Error(w, "Error generating webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	hook, err := db.CreateWebhook(auth.OrgId, projectId, req.Url, secret, req.Events)
+	if err != nil {
+		log.Println("Error creating webhook: ", err)
+		http.Error(w, "Error creating webhook", http.StatusInternalServerError)
+		return
+	}
+
+	// the secret is only ever returned here
+	res := hook.ToApi()
+	res.Secret = hook.Secret
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("CreateWebhookHandler processed successfully")
+}
+
+func SetWebhookEnabledHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetWebhookEnabledHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	found, err := db.SetWebhookEnabled(auth.OrgId, webhookId, req.Enabled)
+	if err != nil {
+		log.Println("Error updating webhook: ", err)
+		http.Error(w, "Error updating webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	log.Println("SetWebhookEnabledHandler processed successfully")
+}
+
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	found, err := db.DeleteWebhook(auth.OrgId, webhookId)
+	if err != nil {
+		log.Println("Error deleting webhook: ", err)
+		http.Error(w, "Error deleting webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	log.Println("DeleteWebhookHandler processed successfully")
+}
+
+func TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for TestWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	hook, err := db.GetWebhook(auth.OrgId, webhookId)
+	if err != nil {
+		log.Println("Error getting webhook: ", err)
+		http.Error(w, "Error getting webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if hook == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	err = webhooks.Ping(auth.OrgId, webhookId)
+	if err != nil {
+		log.Println("Error queuing test delivery: ", err)
+		http.Error(w, "Error queuing test delivery", http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("TestWebhookHandler processed successfully")
+}
+
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhookDeliveriesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	webhookId := r.URL.Query().Get("webhookId")
+
+	limit := defaultWebhookDeliveriesLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxWebhookDeliveriesLimit)
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(auth.OrgId, webhookId, limit)
+	if err != nil {
+		log.Println("Error listing webhook deliveries: ", err)
+		http.Error(w, "Error listing webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListWebhookDeliveriesResponse{
+		Deliveries: make([]*shared.WebhookDelivery, len(deliveries)),
+	}
+	for i, delivery := range deliveries {
+		res.Deliveries[i] = delivery.ToApi()
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error marshalling response: ", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+	log.Println("ListWebhookDeliveriesHandler processed successfully")
+}
+
+// ReportPlanEventHandler accepts lifecycle events that happen on the client side
+func ReportPlanEventHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ReportPlanEventHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	// the same access needed to apply and debug the plan, since the event is sent on to the org's webhooks
+	plan := authorizePlanExecUpdate(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var req shared.ReportPlanEventRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println("Error decoding request body: ", err)
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	// everything else is reported by the server itself
+	if req.Event != shared.WebhookEventAutoDebugExhausted {
+		http.Error(w, fmt.Sprintf("Event %s can't be reported by the client", req.Event), http.StatusBadRequest)
+		return
+	}
+
+	webhooks.Dispatch(webhooks.Event{
+		Type:   req.Event,
+		OrgId:  auth.OrgId,
+		Branch: branch,
+		Plan:   plan,
+		Data:   req.Data,
+	})
+
+	log.Println("ReportPlanEventHandler processed successfully")
+}
+
+func validateCreateWebhookRequest(req shared.CreateWebhookRequest) error {
+	u, err := url.Parse(req.Url)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("a valid http or https url is required")
+	}
+
+	if os.Getenv("IS_CLOUD") != "" && u.Scheme != "https" {
+		return fmt.Errorf("webhook urls must use https on Plandex Cloud")
+	}
+
+	if err := webhooks.CheckUrl(req.Url); err != nil {
+		return err
+	}
+
+	for _, event := range req.Events {
+		valid := false
+		for _, e := range shared.WebhookEvents {
+			if event == e {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown event: %s", event)
+		}
+	}
+
+	return nil
+}
+
+func genWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"plandex-server/model"
+	modelPlan "plandex-server/model/plan"
+	"plandex-server/types"
+	"time"
+
+	shared "plandex-shared"
+)
+
+type execResult struct {
+	status   shared.PlanJobStatus
+	err      string
+	deferred bool
+}
+
+func failed(format string, args ...interface{}) execResult {
+	return execResult{status: shared.PlanJobStatusFailed, err: fmt.Sprintf(format, args...)}
+}
+
+// execJob sends the job's prompt the same way as a 'tell' from the CLI and waits for the stream to finish. There's no client to send credentials, so models use the provider keys in the server's environment (or integrated models).
+func execJob(ctx context.Context, job *db.PlanJob) execResult {
+	plan, err := db.GetPlan(job.PlanId)
+	if err != nil {
+		return failed("error getting plan: %v", err)
+	}
+	if plan == nil {
+		return failed("plan not found")
+	}
+
+	modelStream, err := db.GetActiveModelStream(plan.Id, job.Branch)
+	if err != nil {
+		return failed("error checking for an active stream: %v", err)
+	}
+	if modelStream != nil {
+		err = db.DeferPlanJob(job.Id, busyRetryDelay)
+		if err != nil {
+			return failed("error deferring job: %v", err)
+		}
+		return execResult{deferred: true}
+	}
+
+	auth, err := getJobAuth(job)
+	if err != nil {
+		return failed("%v", err)
+	}
+
+	// the same checks as a 'tell' from the job's creator
+	accessible, err := db.ValidatePlanAccess(plan.Id, auth.User.Id, auth.OrgId)
+	if err != nil {
+		return failed("error validating plan access: %v", err)
+	}
+	canEdit := accessible != nil && (plan.OwnerId == auth.User.Id || auth.HasPermission(shared.PermissionUpdateAnyPlan))
+	if accessible != nil && !canEdit {
+		shareRole, err := db.GetPlanShareRole(plan.Id, auth.User.Id)
+		if err != nil {
+			return failed("error getting plan share role: %v", err)
+		}
+		canEdit = shareRole == shared.PlanShareRoleEditor
+	}
+	if !canEdit {
+		return failed("%s no longer has permission to update the plan", auth.User.Email)
+	}
+
+	settings, err := db.GetPlanSettings(plan)
+	if err != nil {
+		return failed("error getting plan settings: %v", err)
+	}
+
+	config, err := db.GetPlanConfig(plan.Id)
+	if err != nil {
+		return failed("error getting plan config: %v", err)
+	}
+	if job.AutoMode != "" {
+		// only applies to this run—the plan's config isn't updated
+		config.SetAutoMode(job.AutoMode)
+	}
+
+	orgUserConfig, err := db.GetOrgUserConfig(auth.User.Id, auth.OrgId)
+	if err != nil {
+		return failed("error getting org user config: %v", err)
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
+		Auth: auth,
+		Plan: plan,
+	})
+	if apiErr != nil {
+		return failed("%s", apiErr.Msg)
+	}
+
+	authVars := model.AuthVarsFromEnv(settings)
+
+	hookResult, apiErr := hooks.ExecHook(hooks.GetIntegratedModels, hooks.HookParams{
+		Auth: auth,
+		Plan: plan,
+	})
+	if apiErr != nil {
+		return failed("error getting integrated models: %s", apiErr.Msg)
+	}
+	if hookResult.GetIntegratedModelsResult != nil && hookResult.GetIntegratedModelsResult.IntegratedModelsMode {
+		authVars = hookResult.GetIntegratedModelsResult.AuthVars
+	}
+
+	if len(authVars) == 0 {
+		return failed("no model provider credentials are set in the server's environment")
+	}
+
+	buildMode := shared.BuildModeNone
+	if config.AutoBuild {
+		buildMode = shared.BuildModeAuto
+	}
+
+	// auto-loading context needs a connected client to read files, so jobs work with the context that's already loaded in the plan
+	req := &shared.TellPlanRequest{
+		Prompt:       job.Prompt,
+		BuildMode:    buildMode,
+		AutoContinue: config.AutoContinue,
+		SmartContext: config.SmartContext,
+		ExecEnabled:  config.CanExec && auth.HasPermission(shared.PermissionExecCommands),
+		AuthVars:     authVars,
+	}
+
+	err = modelPlan.Tell(modelPlan.TellParams{
+		Clients:  model.InitClients(authVars, settings, orgUserConfig),
+		Plan:     plan,
+		Branch:   job.Branch,
+		Auth:     auth,
+		Req:      req,
+		AuthVars: authVars,
+	})
+	if err != nil {
+		return failed("error telling plan: %v", err)
+	}
+
+	active := modelPlan.GetActivePlan(plan.Id, job.Branch)
+	if active != nil {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+	wait:
+		for {
+			select {
+			case <-active.Ctx.Done():
+				break wait
+			case <-ctx.Done():
+				return failed("the server stopped while the job was running")
+			case <-ticker.C:
+				err := db.HeartbeatPlanJob(job.Id)
+				if err != nil {
+					// not fatal—the job is only considered stale after several missed heartbeats
+					log.Printf("Error updating heartbeat for plan job %s: %v\n", job.Id, err)
+				}
+			}
+		}
+	}
+
+	branch, err := db.GetDbBranch(plan.Id, job.Branch)
+	if err != nil {
+		return failed("error getting branch: %v", err)
+	}
+	if branch == nil {
+		return failed("branch %s not found", job.Branch)
+	}
+
+	switch branch.Status {
+	case shared.PlanStatusFinished:
+		return execResult{status: shared.PlanJobStatusFinished}
+	case shared.PlanStatusError:
+		errStr := "the plan stream failed"
+		if branch.Error != nil && *branch.Error != "" {
+			errStr = *branch.Error
+		}
+		return failed("%s", errStr)
+	default:
+		// stopped with 'plandex stop'
+		return execResult{status: shared.PlanJobStatusCancelled}
+	}
+}
+
+func getJobAuth(job *db.PlanJob) (*types.ServerAuth, error) {
+	user, err := db.GetUser(job.CreatorId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting job creator: %v", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("the user who created the job no longer exists")
+	}
+
+	permissions, err := db.GetUserPermissions(user.Id, job.OrgId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user permissions: %v", err)
+	}
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("%s is no longer a member of the org", user.Email)
+	}
+
+	permissionsMap := make(shared.Permissions)
+	for _, permission := range permissions {
+		permissionsMap[permission] = true
+	}
+
+	return &types.ServerAuth{
+		User:        user,
+		OrgId:       job.OrgId,
+		Permissions: permissionsMap,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"plandex-server/shutdown"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const (
+	pollInterval      = 30 * time.Second
+	heartbeatInterval = time.Minute
+
+	// a running job without a heartbeat for this long was on a server that stopped
+	staleLease = 5 * time.Minute
+
+	// how long to wait before trying again when the job's branch is already streaming
+	busyRetryDelay = 2 * time.Minute
+
+	defaultMaxWorkers   = 4
+	defaultMaxPerOrg    = 2
+	maxWorkersEnvVar    = "PLANDEX_JOB_WORKERS"
+	maxPerOrgJobsEnvVar = "PLANDEX_JOB_MAX_PER_ORG"
+)
+
+var (
+	wakeCh      = make(chan struct{}, 1)
+	workerStart sync.Once
+
+	// one slot per job running on this server
+	slots chan struct{}
+
+	maxPerOrg int
+)
+
+// StartWorker starts the background job loop. Jobs are stored in the db, so any that came due while the server was down run on the first poll.
+func StartWorker() {
+	workerStart.Do(func() {
+		slots = make(chan struct{}, envInt(maxWorkersEnvVar, defaultMaxWorkers))
+		maxPerOrg = envInt(maxPerOrgJobsEnvVar, defaultMaxPerOrg)
+		go runWorker(shutdown.ShutdownCtx)
+	})
+}
+
+// Wake checks for due jobs right away instead of waiting for the next poll
+func Wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func runWorker(ctx context.Context) {
+	log.Printf("Starting plan job worker with %d slots, max %d running jobs per org\n", cap(slots), maxPerOrg)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		finishStale()
+		claimDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("Plan job worker stopped")
+			return
+		case <-ticker.C:
+		case <-wakeCh:
+		}
+	}
+}
+
+func claimDue(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic in jobs claimDue: %v\n%s", r, debug.Stack())
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in jobs claimDue: %v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	for ctx.Err() == nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			// all slots are busy—a finishing job wakes the worker
+			return
+		}
+
+		job, err := db.ClaimNextPlanJob(maxPerOrg)
+		if err != nil || job == nil {
+			<-slots
+			if err != nil {
+				log.Printf("Error claiming plan job: %v\n", err)
+			}
+			return
+		}
+
+		go func(job *db.PlanJob) {
+			defer func() {
+				<-slots
+				Wake()
+			}()
+			runJob(ctx, job)
+		}(job)
+	}
+}
+
+func finishStale() {
+	jobs, err := db.ClaimStalePlanJobs(staleLease)
+	if err != nil {
+		log.Printf("Error claiming stale plan jobs: %v\n", err)
+		return
+	}
+
+	for _, job := range jobs {
+		log.Printf("Plan job %s was running on a server that stopped\n", job.Id)
+		finishRun(job, shared.PlanJobStatusFailed, "the server stopped while the job was running")
+	}
+}
+
+func runJob(ctx context.Context, job *db.PlanJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic running plan job %s: %v\n%s", job.Id, r, debug.Stack())
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic running plan job %s: %v\n%s", job.Id, r, debug.Stack()))
+			finishRun(job, shared.PlanJobStatusFailed, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	log.Printf("Running plan job %s for plan %s on branch %s\n", job.Id, job.PlanId, job.Branch)
+
+	res := execJob(ctx, job)
+	if res.deferred {
+		log.Printf("Plan job %s deferred—branch %s is busy\n", job.Id, job.Branch)
+		return
+	}
+
+	if res.err != "" {
+		log.Printf("Plan job %s %s: %s\n", job.Id, res.status, res.err)
+	} else {
+		log.Printf("Plan job %s %s\n", job.Id, res.status)
+	}
+
+	finishRun(job, res.status, res.err)
+}
+
+// finishRun records a run's result and, for a recurring job, schedules the next run
+func finishRun(job *db.PlanJob, status shared.PlanJobStatus, errStr string) {
+	var nextRunAt *time.Time
+	if job.Schedule != "" {
+		schedule, err := shared.ParseCronSchedule(job.Schedule)
+		if err != nil {
+			// validated when the job was created, so this shouldn't happen
+			log.Printf("Error parsing schedule for plan job %s: %v\n", job.Id, err)
+		} else if next := schedule.Next(time.Now().UTC()); !next.IsZero() {
+			nextRunAt = &next
+		}
+	}
+
+	err := db.FinishPlanJobRun(job.Id, status, errStr, nextRunAt)
+	if err != nil {
+		log.Printf("Error finishing plan job %s: %v\n", job.Id, err)
+	}
+}
+
+func envInt(name string, def int) int {
+	if s := os.Getenv(name); s != "" {
+		n, err := strconv.Atoi(s)
+		if err == nil && n > 0 {
+			return n
+		}
+		log.Printf("Invalid %s '%s'—using %d\n", name, s, def)
+	}
+	return def
+}
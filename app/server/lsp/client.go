@@ -0,0 +1,353 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// A minimal Language Server Protocol client—just enough to open a document and collect the diagnostics the server publishes for it
+
+const maxMessageBytes = 20 * 1024 * 1024
+
+type rpcMessage struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// incomingMessage is rpcMessage with raw params and an id of any type—servers are free to use strings for the requests they send us
+type incomingMessage struct {
+	Id     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+type publishDiagnosticsParams struct {
+	Uri         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type client struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	nextId  atomic.Int64
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	pending     map[int64]chan *incomingMessage
+	diagnostics map[string]publishDiagnosticsParams
+	doneCh      chan struct{}
+	doneErr     error
+
+	// receives the uri of each publishDiagnostics notification
+	publishedCh chan string
+}
+
+func startClient(config *ServerConfig, dir string) (*client, error) {
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), config.Env...)
+	cmd.Stderr = &stderrLogger{prefix: "[lsp " + config.Name + "] "}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdin pipe: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdout pipe: %v", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting language server %s: %v", config.Name, err)
+	}
+
+	c := &client{
+		name:        config.Name,
+		cmd:         cmd,
+		stdin:       stdin,
+		pending:     map[int64]chan *incomingMessage{},
+		diagnostics: map[string]publishDiagnosticsParams{},
+		doneCh:      make(chan struct{}),
+		publishedCh: make(chan string, 64),
+	}
+
+	go c.readLoop(stdout)
+
+	return c, nil
+}
+
+// readLoop reads Content-Length framed messages until the server exits
+func (c *client) readLoop(stdout io.Reader) {
+	reader := textproto.NewReader(bufio.NewReader(stdout))
+
+	var err error
+	for {
+		var body []byte
+		body, err = readMessage(reader)
+		if err != nil {
+			break
+		}
+
+		var msg incomingMessage
+		err = json.Unmarshal(body, &msg)
+		if err != nil {
+			log.Printf("LSP %s: ignoring invalid message: %v\n", c.name, err)
+			continue
+		}
+
+		if msg.Method != "" {
+			c.handleServerMessage(&msg)
+			continue
+		}
+
+		var id int64
+		if json.Unmarshal(msg.Id, &id) != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		if ok {
+			ch <- &msg
+		}
+	}
+
+	if err == nil || errors.Is(err, io.EOF) {
+		err = errors.New("language server closed its output")
+	}
+
+	c.mu.Lock()
+	c.doneErr = err
+	c.mu.Unlock()
+	close(c.doneCh)
+}
+
+func readMessage(reader *textproto.Reader) ([]byte, error) {
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", header.Get("Content-Length"))
+	}
+	if length > maxMessageBytes {
+		return nil, fmt.Errorf("message too large: %d bytes", length)
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader.R, body)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// handleServerMessage stores published diagnostics and gives empty answers to the requests servers commonly send during startup
+func (c *client) handleServerMessage(msg *incomingMessage) {
+	if msg.Method == "textDocument/publishDiagnostics" {
+		var params publishDiagnosticsParams
+		err := json.Unmarshal(msg.Params, &params)
+		if err != nil {
+			log.Printf("LSP %s: invalid publishDiagnostics params: %v\n", c.name, err)
+			return
+		}
+
+		c.mu.Lock()
+		c.diagnostics[params.Uri] = params
+		c.mu.Unlock()
+
+		select {
+		case c.publishedCh <- params.Uri:
+		default:
+		}
+		return
+	}
+
+	if len(msg.Id) == 0 {
+		return
+	}
+
+	res := map[string]any{"jsonrpc": "2.0", "id": msg.Id}
+
+	switch msg.Method {
+	case "workspace/configuration":
+		// a null for each requested item means 'use your defaults'
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		res["result"] = make([]any, len(params.Items))
+	case "window/workDoneProgress/create", "client/registerCapability", "client/unregisterCapability", "window/showMessageRequest":
+		res["result"] = nil
+	default:
+		res["error"] = &rpcError{Code: -32601, Message: "method not found"}
+	}
+
+	err := c.write(res)
+	if err != nil {
+		log.Printf("LSP %s: error responding to %s: %v\n", c.name, msg.Method, err)
+	}
+}
+
+func (c *client) write(msg any) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling message: %v", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err = fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(bytes), bytes)
+	if err != nil {
+		return fmt.Errorf("error writing to language server: %v", err)
+	}
+	return nil
+}
+
+func (c *client) call(ctx context.Context, method string, params any, result any) error {
+	id := c.nextId.Add(1)
+
+	ch := make(chan *incomingMessage, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	err := c.write(&rpcMessage{JsonRpc: "2.0", Id: &id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+
+	var res *incomingMessage
+	select {
+	case res = <-ch:
+	case <-c.doneCh:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return fmt.Errorf("%s: %v", method, c.doneErr)
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %v", method, ctx.Err())
+	}
+
+	if res.Error != nil {
+		return fmt.Errorf("%s: %v", method, res.Error)
+	}
+	if result != nil && len(res.Result) > 0 {
+		err = json.Unmarshal(res.Result, result)
+		if err != nil {
+			return fmt.Errorf("%s: error unmarshalling result: %v", method, err)
+		}
+	}
+	return nil
+}
+
+func (c *client) notify(method string, params any) error {
+	err := c.write(&rpcMessage{JsonRpc: "2.0", Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
+
+// published returns the last diagnostics the server published for a uri, if any
+func (c *client) published(uri string) (publishDiagnosticsParams, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	params, ok := c.diagnostics[uri]
+	return params, ok
+}
+
+func (c *client) forget(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.diagnostics, uri)
+}
+
+// close follows the protocol's shutdown/exit sequence, then escalates to SIGTERM and SIGKILL if the process doesn't exit
+func (c *client) close() {
+	select {
+	case <-c.doneCh:
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := c.call(ctx, "shutdown", nil, nil)
+		cancel()
+		if err == nil {
+			c.notify("exit", nil)
+		}
+	}
+
+	c.stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- c.cmd.Wait()
+	}()
+
+	select {
+	case <-exited:
+		return
+	case <-time.After(2 * time.Second):
+	}
+
+	c.cmd.Process.Signal(syscall.SIGTERM)
+
+	select {
+	case <-exited:
+		return
+	case <-time.After(2 * time.Second):
+	}
+
+	c.cmd.Process.Kill()
+}
+
+type stderrLogger struct {
+	prefix string
+}
+
+func (l *stderrLogger) Write(p []byte) (int, error) {
+	log.Printf("%s%s", l.prefix, p)
+	return len(p), nil
+}
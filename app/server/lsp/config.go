@@ -0,0 +1,131 @@
+package lsp
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const defaultValidationTimeout = 30 * time.Second
+
+type ServerConfig struct {
+	// Name is how the server is referred to in LSP_VALIDATION and in the LSP_<NAME>_COMMAND override
+	Name    string
+	Command string
+	Args    []string
+
+	// InitializationOptions are sent with the initialize request
+	InitializationOptions map[string]any
+
+	// Env is added to the server's environment
+	Env []string
+}
+
+var defaultServers = []ServerConfig{
+	{
+		Name:    "gopls",
+		Command: "gopls",
+		// the overlay has no module cache of its own—don't let gopls go to the network or switch toolchains to fill it
+		Env: []string{"GOPROXY=off", "GOTOOLCHAIN=local"},
+	},
+	{
+		Name:    "tsserver",
+		Command: "typescript-language-server",
+		Args:    []string{"--stdio"},
+	},
+	{
+		Name:    "pyright",
+		Command: "pyright-langserver",
+		Args:    []string{"--stdio"},
+	},
+	{
+		Name:    "rust-analyzer",
+		Command: "rust-analyzer",
+		// build scripts and proc macros execute code from the project, so they stay off
+		InitializationOptions: map[string]any{
+			"cargo": map[string]any{
+				"buildScripts": map[string]any{"enable": false},
+			},
+			"procMacro":   map[string]any{"enable": false},
+			"checkOnSave": false,
+		},
+	},
+}
+
+var serverNameByLanguage = map[shared.Language]string{
+	shared.LanguageGo:         "gopls",
+	shared.LanguageTypescript: "tsserver",
+	shared.LanguageTsx:        "tsserver",
+	shared.LanguageJavascript: "tsserver",
+	shared.LanguageJsx:        "tsserver",
+	shared.LanguagePython:     "pyright",
+	shared.LanguageRust:       "rust-analyzer",
+}
+
+var languageIdByLanguage = map[shared.Language]string{
+	shared.LanguageGo:         "go",
+	shared.LanguageTypescript: "typescript",
+	shared.LanguageTsx:        "typescriptreact",
+	shared.LanguageJavascript: "javascript",
+	shared.LanguageJsx:        "javascriptreact",
+	shared.LanguagePython:     "python",
+	shared.LanguageRust:       "rust",
+}
+
+// GetServerConfig returns the language server to validate builds of the given language with, or nil if there isn't one or it hasn't been enabled with LSP_VALIDATION
+func GetServerConfig(lang shared.Language) *ServerConfig {
+	name, ok := serverNameByLanguage[lang]
+	if !ok {
+		return nil
+	}
+
+	if !isEnabled(name) {
+		return nil
+	}
+
+	for _, server := range defaultServers {
+		if server.Name != name {
+			continue
+		}
+
+		// LSP_GOPLS_COMMAND, LSP_RUST_ANALYZER_COMMAND, etc. replace the default command and args
+		envVar := "LSP_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_COMMAND"
+		if override := strings.Fields(os.Getenv(envVar)); len(override) > 0 {
+			server.Command = override[0]
+			server.Args = override[1:]
+		}
+
+		return &server
+	}
+
+	return nil
+}
+
+// LSP_VALIDATION is a comma-separated list of server names, or 'all'
+func isEnabled(name string) bool {
+	// language servers run on the host—never on cloud
+	if os.Getenv("IS_CLOUD") != "" {
+		return false
+	}
+
+	for _, s := range strings.Split(os.Getenv("LSP_VALIDATION"), ",") {
+		s = strings.TrimSpace(strings.ToLower(s))
+		if s == "all" || s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validationTimeout() time.Duration {
+	if s := os.Getenv("LSP_VALIDATION_TIMEOUT"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultValidationTimeout
+}
@@ -0,0 +1,322 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const SeverityError = 1
+
+// once a server has published diagnostics for a document, it has this long to publish again (e.g. after a slower semantic pass) before we take what we have
+const diagnosticsSettleTime = 750 * time.Millisecond
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity,omitempty"`
+	Code     any    `json:"code,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("line %d: %s", d.Range.Start.Line+1, d.Message)
+	if d.Source != "" {
+		s += fmt.Sprintf(" (%s)", d.Source)
+	}
+	return s
+}
+
+// severity is optional—servers that leave it out are treated as reporting errors
+func (d Diagnostic) isError() bool {
+	return d.Severity == SeverityError || d.Severity == 0
+}
+
+// key identifies a diagnostic regardless of where it is in the file, since edits shift line numbers
+func (d Diagnostic) key() string {
+	return fmt.Sprintf("%s|%v|%s", d.Source, d.Code, d.Message)
+}
+
+type ValidateParams struct {
+	Language shared.Language
+	Path     string
+
+	// Files are written to a scratch overlay alongside the file being validated so that the server can resolve imports—typically the plan's context and its current files, keyed by project-relative path
+	Files map[string]string
+
+	Original string
+	Updated  string
+
+	// Baseline, if non-nil, is used as the original file's errors instead of diagnosing Original again
+	Baseline []Diagnostic
+}
+
+type ValidateResult struct {
+	ServerName string
+
+	// Errors that Updated has and Original didn't
+	Errors []Diagnostic
+
+	// Baseline is the original file's errors—pass it back in on the next call for the same file
+	Baseline []Diagnostic
+
+	TimedOut bool
+}
+
+// ValidateFile runs the language server configured for the file's language on the updated file and returns any errors that the change introduced. Errors already present in the original file aren't reported—the overlay only has the files in the plan's context, so some errors, like unresolved imports, are expected either way. Returns nil if no server is enabled for the language.
+func ValidateFile(ctx context.Context, params ValidateParams) (*ValidateResult, error) {
+	config := GetServerConfig(params.Language)
+	if config == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, validationTimeout())
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "plandex-lsp-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating overlay dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	absPath, err := writeOverlay(dir, params)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := startClient(config, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer client.close()
+
+	res := &ValidateResult{ServerName: config.Name}
+
+	err = initialize(ctx, client, config, dir)
+	if err != nil {
+		if ctx.Err() != nil {
+			res.TimedOut = true
+			return res, nil
+		}
+		return nil, fmt.Errorf("error initializing language server %s: %v", config.Name, err)
+	}
+
+	uri := fileUri(absPath)
+	languageId := languageIdByLanguage[params.Language]
+
+	baseline := params.Baseline
+	version := 1
+
+	if baseline == nil && params.Original != "" {
+		err = client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{
+				"uri":        uri,
+				"languageId": languageId,
+				"version":    version,
+				"text":       params.Original,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		baseline, ok = waitForDiagnostics(ctx, client, uri, version)
+		if !ok {
+			res.TimedOut = true
+			return res, nil
+		}
+
+		version++
+		client.forget(uri)
+
+		err = client.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": version},
+			"contentChanges": []map[string]any{{"text": params.Updated}},
+		})
+	} else {
+		err = client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{
+				"uri":        uri,
+				"languageId": languageId,
+				"version":    version,
+				"text":       params.Updated,
+			},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated, ok := waitForDiagnostics(ctx, client, uri, version)
+	if !ok {
+		res.TimedOut = true
+		return res, nil
+	}
+
+	if baseline == nil {
+		baseline = []Diagnostic{}
+	}
+
+	res.Baseline = baseline
+	res.Errors = newErrors(baseline, updated)
+
+	return res, nil
+}
+
+func initialize(ctx context.Context, client *client, config *ServerConfig, dir string) error {
+	rootUri := fileUri(dir)
+
+	params := map[string]any{
+		"processId": os.Getpid(),
+		"clientInfo": map[string]any{
+			"name": "plandex",
+		},
+		"rootUri": rootUri,
+		"workspaceFolders": []map[string]any{
+			{"uri": rootUri, "name": filepath.Base(dir)},
+		},
+		"capabilities": map[string]any{
+			"workspace": map[string]any{
+				"configuration":    true,
+				"workspaceFolders": true,
+			},
+			"textDocument": map[string]any{
+				"synchronization": map[string]any{},
+				"publishDiagnostics": map[string]any{
+					"versionSupport": true,
+				},
+			},
+		},
+	}
+	if config.InitializationOptions != nil {
+		params["initializationOptions"] = config.InitializationOptions
+	}
+
+	err := client.call(ctx, "initialize", params, nil)
+	if err != nil {
+		return err
+	}
+
+	return client.notify("initialized", map[string]any{})
+}
+
+// waitForDiagnostics waits for the server to publish diagnostics for a document version, then for them to settle. Returns false if the server didn't publish anything before the context ended.
+func waitForDiagnostics(ctx context.Context, client *client, uri string, version int) ([]Diagnostic, bool) {
+	current := func() ([]Diagnostic, bool) {
+		params, ok := client.published(uri)
+		if !ok || (params.Version != nil && *params.Version != version) {
+			return nil, false
+		}
+		return params.Diagnostics, true
+	}
+
+	var diagnostics []Diagnostic
+	received := false
+	var settle <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return diagnostics, received
+		case <-client.doneCh:
+			return diagnostics, received
+		case <-settle:
+			return diagnostics, true
+		case published := <-client.publishedCh:
+			if published != uri {
+				continue
+			}
+			if d, ok := current(); ok {
+				diagnostics = d
+				received = true
+				settle = time.After(diagnosticsSettleTime)
+			}
+		}
+	}
+}
+
+// newErrors returns the errors in updated that aren't in baseline. Matching is by message rather than position, and counts duplicates, so an error that's repeated by a change is still reported.
+func newErrors(baseline, updated []Diagnostic) []Diagnostic {
+	counts := map[string]int{}
+	for _, d := range baseline {
+		if d.isError() {
+			counts[d.key()]++
+		}
+	}
+
+	var res []Diagnostic
+	for _, d := range updated {
+		if !d.isError() {
+			continue
+		}
+
+		k := d.key()
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+// writeOverlay writes the plan's files and the original file to the scratch dir and returns the absolute path of the file being validated
+func writeOverlay(dir string, params ValidateParams) (string, error) {
+	write := func(path, content string) (string, error) {
+		path = filepath.Clean(path)
+		if filepath.IsAbs(path) || path == "." || strings.HasPrefix(path, ".."+string(filepath.Separator)) || path == ".." {
+			return "", fmt.Errorf("path is outside the project: %s", path)
+		}
+
+		abs := filepath.Join(dir, path)
+		err := os.MkdirAll(filepath.Dir(abs), 0755)
+		if err != nil {
+			return "", err
+		}
+		return abs, os.WriteFile(abs, []byte(content), 0644)
+	}
+
+	for path, content := range params.Files {
+		if path == params.Path {
+			continue
+		}
+		_, err := write(path, content)
+		if err != nil {
+			log.Printf("LSP overlay: skipping %s: %v\n", path, err)
+		}
+	}
+
+	// the original goes on disk so the rest of the workspace sees the file as it is now—the server gets the updated content over the protocol
+	content := params.Original
+	if content == "" {
+		content = params.Updated
+	}
+
+	abs, err := write(params.Path, content)
+	if err != nil {
+		return "", fmt.Errorf("error writing %s to overlay: %v", params.Path, err)
+	}
+	return abs, nil
+}
+
+func fileUri(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,39 @@
+package lsp
+
+import "testing"
+
+func TestNewErrors(t *testing.T) {
+	undefinedFoo := Diagnostic{Severity: SeverityError, Source: "compiler", Message: "undefined: foo"}
+	unresolvedImport := Diagnostic{Severity: SeverityError, Source: "compiler", Message: `could not import "example.com/pkg"`}
+	unusedVar := Diagnostic{Severity: 2, Source: "compiler", Message: "x declared and not used"}
+
+	at := func(d Diagnostic, line int) Diagnostic {
+		d.Range.Start.Line = line
+		return d
+	}
+
+	baseline := []Diagnostic{at(unresolvedImport, 2), at(undefinedFoo, 10)}
+
+	updated := []Diagnostic{
+		// already present, just moved by the change
+		at(unresolvedImport, 4),
+		at(undefinedFoo, 14),
+		// introduced by the change
+		at(undefinedFoo, 30),
+		// warnings are ignored
+		at(unusedVar, 20),
+	}
+
+	got := newErrors(baseline, updated)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 new error, got %d: %v", len(got), got)
+	}
+
+	if got[0].String() != "line 31: undefined: foo (compiler)" {
+		t.Errorf("unexpected error: %s", got[0].String())
+	}
+
+	if len(newErrors(baseline, baseline)) != 0 {
+		t.Errorf("expected no new errors when nothing changed")
+	}
+}
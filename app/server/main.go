@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"plandex-server/hooks"
+	"plandex-server/jobs"
+	"plandex-server/mcp"
 	"plandex-server/model"
+	"plandex-server/oidc"
 	"plandex-server/routes"
 	"plandex-server/setup"
+	"plandex-server/webhooks"
 
 	"github.com/gorilla/mux"
 )
@@ -26,13 +31,21 @@ func main() {
 	setup.RegisterShutdownHook(func() {
 		model.ShutdownLiteLLMServer()
 	})
+	setup.RegisterShutdownHook(mcp.CloseAll)
+
+	hooks.RegisterHook(hooks.DidSendModelRequest, model.RecordModelUsageHook)
 
 	r := mux.NewRouter()
 	routes.AddHealthRoutes(r)
 	routes.AddApiRoutes(r)
 	routes.AddProxyableApiRoutes(r)
+	routes.AddUsageRoutes(r)
 	setup.MustLoadIp()
+	oidc.MustLoadConfig()
 	setup.MustInitDb()
-	setup.StartServer(r, nil, nil)
+	setup.StartServer(r, nil, func() {
+		webhooks.StartWorker()
+		jobs.StartWorker()
+	})
 	os.Exit(0)
 }
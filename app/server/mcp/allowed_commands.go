@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stdio MCP servers run as child processes of the server, so they're off unless the operator enables them with MCP_STDIO_ENABLED=true.
+// MCP_STDIO_ALLOWED_COMMANDS can further limit them to a comma-separated list of commands ('npx', '/usr/local/bin/mcp-server-git'), matched exactly.
+// On Plandex Cloud, they're never allowed.
+
+// the only variables from the server's environment that a stdio MCP server gets—everything else comes from its own configured env
+var stdioEnvAllowList = []string{"PATH", "HOME"}
+
+// CheckStdioCommand returns an error if a stdio MCP server with the command isn't allowed by the server's config
+func CheckStdioCommand(command string) error {
+	if os.Getenv("IS_CLOUD") != "" {
+		return fmt.Errorf("stdio MCP servers aren't supported on Plandex Cloud—use an http server instead")
+	}
+	if os.Getenv("MCP_STDIO_ENABLED") != "true" {
+		return fmt.Errorf("stdio MCP servers aren't enabled on this server—the server's operator can enable them with MCP_STDIO_ENABLED=true")
+	}
+
+	allowList := os.Getenv("MCP_STDIO_ALLOWED_COMMANDS")
+	if allowList == "" {
+		return nil
+	}
+	for _, allowed := range strings.Split(allowList, ",") {
+		if strings.TrimSpace(allowed) == command {
+			return nil
+		}
+	}
+	return fmt.Errorf("MCP server command %s isn't allowed—the server's operator must add it to MCP_STDIO_ALLOWED_COMMANDS", command)
+}
+
+// stdioEnv builds a stdio MCP server's environment, so the server's own secrets (database url, provider keys, etc.) aren't passed to it
+func stdioEnv(env map[string]string) []string {
+	var res []string
+	for _, key := range stdioEnvAllowList {
+		if v, ok := os.LookupEnv(key); ok {
+			res = append(res, key+"="+v)
+		}
+	}
+	for k, v := range env {
+		res = append(res, k+"="+v)
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckStdioCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		cloud     string
+		enabled   string
+		allowList string
+		command   string
+		wantErr   bool
+	}{
+		{"disabled by default", "", "", "", "npx", true},
+		{"enabled", "", "true", "", "npx", false},
+		{"allowed command", "", "true", "npx, /usr/local/bin/mcp-server-git", "/usr/local/bin/mcp-server-git", false},
+		{"command not in allow list", "", "true", "npx", "/tmp/npx", true},
+		{"cloud", "1", "true", "", "npx", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IS_CLOUD", tt.cloud)
+			t.Setenv("MCP_STDIO_ENABLED", tt.enabled)
+			t.Setenv("MCP_STDIO_ALLOWED_COMMANDS", tt.allowList)
+
+			err := CheckStdioCommand(tt.command)
+			if tt.wantErr && err == nil {
+				t.Error("expected an error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStdioEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("DATABASE_URL", "postgres://secret")
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+
+	env := strings.Join(stdioEnv(map[string]string{"GITHUB_TOKEN": "gh-token"}), "\n")
+
+	for _, want := range []string{"PATH=/usr/bin", "GITHUB_TOKEN=gh-token"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("expected env to contain %s, got:\n%s", want, env)
+		}
+	}
+	for _, secret := range []string{"DATABASE_URL", "OPENAI_API_KEY"} {
+		if strings.Contains(env, secret) {
+			t.Errorf("expected env not to contain %s, got:\n%s", secret, env)
+		}
+	}
+}
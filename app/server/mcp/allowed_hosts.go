@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// By default, http MCP servers must be on a loopback address so that the server can't be used to reach other hosts on its network.
+// Operators can allow more with MCP_HTTP_ALLOWED_HOSTS, a comma-separated list of hostnames ('mcp.internal', '*.example.com') and CIDRs ('10.1.0.0/16').
+// On Plandex Cloud, loopback isn't allowed—only the configured hosts are.
+
+type allowedHosts struct {
+	names     map[string]bool
+	wildcards []string
+	nets      []*net.IPNet
+	loopback  bool
+}
+
+func getAllowedHosts() *allowedHosts {
+	res := &allowedHosts{
+		names:    map[string]bool{},
+		loopback: os.Getenv("IS_CLOUD") == "",
+	}
+
+	for _, entry := range strings.Split(os.Getenv("MCP_HTTP_ALLOWED_HOSTS"), ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			res.nets = append(res.nets, ipNet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			res.nets = append(res.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else if strings.HasPrefix(entry, "*.") {
+			res.wildcards = append(res.wildcards, strings.TrimPrefix(entry, "*"))
+		} else {
+			res.names[entry] = true
+		}
+	}
+
+	return res
+}
+
+// allowsName is true if the operator allowed the hostname itself, in which case any address it resolves to is trusted
+func (a *allowedHosts) allowsName(host string) bool {
+	host = strings.ToLower(host)
+	if a.names[host] {
+		return true
+	}
+	for _, suffix := range a.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *allowedHosts) allowsIP(ip net.IP) bool {
+	if a.loopback && ip.IsLoopback() {
+		return true
+	}
+	for _, ipNet := range a.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHttpUrl returns an error if an http MCP server at the url isn't allowed by the server's host policy
+func CheckHttpUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid MCP server url: %s", rawUrl)
+	}
+	return checkHttpHost(getAllowedHosts(), u.Hostname())
+}
+
+func checkHttpHost(allowed *allowedHosts, host string) error {
+	if allowed.allowsName(host) {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && allowed.allowsIP(ip) {
+		return nil
+	}
+	// checked again against the resolved address when connecting
+	if strings.EqualFold(host, "localhost") && allowed.loopback {
+		return nil
+	}
+
+	if allowed.loopback {
+		return fmt.Errorf("MCP server host %s isn't allowed—http MCP servers must be on localhost unless the server's operator adds the host to MCP_HTTP_ALLOWED_HOSTS", host)
+	}
+	return fmt.Errorf("MCP server host %s isn't allowed—the server's operator must add it to MCP_HTTP_ALLOWED_HOSTS", host)
+}
+
+// newRestrictedHttpClient re-checks every address it connects to, so a hostname can't resolve or redirect to a host that isn't allowed
+func newRestrictedHttpClient(host string) *http.Client {
+	allowed := getAllowedHosts()
+	trustName := allowed.allowsName(host)
+
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if trustName {
+				return nil
+			}
+			ipStr, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(ipStr)
+			if ip == nil || !allowed.allowsIP(ip) {
+				return fmt.Errorf("MCP server address %s isn't allowed", address)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			// proxies from the environment would make the dialer check the proxy rather than the MCP server
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			if !strings.EqualFold(req.URL.Hostname(), host) {
+				return fmt.Errorf("MCP server redirected to another host: %s", req.URL.Host)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCheckHttpUrl(t *testing.T) {
+	t.Setenv("IS_CLOUD", "")
+	t.Setenv("MCP_HTTP_ALLOWED_HOSTS", "mcp.internal, *.tools.example.com, 10.1.0.0/16")
+
+	allowed := []string{
+		"http://localhost:8931/mcp",
+		"http://127.0.0.1:8931/mcp",
+		"http://[::1]:8931/mcp",
+		"https://mcp.internal/mcp",
+		"https://a.tools.example.com/mcp",
+		"http://10.1.2.3/mcp",
+	}
+	for _, u := range allowed {
+		if err := CheckHttpUrl(u); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", u, err)
+		}
+	}
+
+	denied := []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.2.0.1/mcp",
+		"https://example.com/mcp",
+		"https://tools.example.com.evil.com/mcp",
+		"http://metadata.google.internal/",
+	}
+	for _, u := range denied {
+		if err := CheckHttpUrl(u); err == nil {
+			t.Errorf("expected %s to be denied", u)
+		}
+	}
+}
+
+func TestCheckHttpUrlCloud(t *testing.T) {
+	t.Setenv("IS_CLOUD", "1")
+	t.Setenv("MCP_HTTP_ALLOWED_HOSTS", "")
+
+	if err := CheckHttpUrl("http://localhost:8931/mcp"); err == nil {
+		t.Error("expected loopback to be denied on cloud")
+	}
+	if getAllowedHosts().allowsIP(net.ParseIP("127.0.0.1")) {
+		t.Error("expected loopback addresses to be denied on cloud")
+	}
+}
@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"plandex-server/db"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// A minimal Model Context Protocol client—just enough of the spec to list a server's tools and call them. Resources, prompts, and sampling aren't supported.
+
+const ProtocolVersion = "2025-03-26"
+
+const (
+	ConnectTimeout  = 30 * time.Second
+	ToolCallTimeout = 2 * time.Minute
+)
+
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+type ContentItem struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Resource *struct {
+		Uri  string `json:"uri"`
+		Text string `json:"text,omitempty"`
+	} `json:"resource,omitempty"`
+}
+
+type CallToolResult struct {
+	Content []ContentItem `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Text flattens the result into plain text for the model. Binary content is noted but not included.
+func (res *CallToolResult) Text() string {
+	var parts []string
+	for _, item := range res.Content {
+		switch item.Type {
+		case "text":
+			parts = append(parts, item.Text)
+		case "resource":
+			if item.Resource != nil && item.Resource.Text != "" {
+				parts = append(parts, item.Resource.Text)
+			} else if item.Resource != nil {
+				parts = append(parts, fmt.Sprintf("[resource %s omitted]", item.Resource.Uri))
+			}
+		default:
+			mimeType := item.MimeType
+			if mimeType == "" {
+				mimeType = item.Type
+			}
+			parts = append(parts, fmt.Sprintf("[%s content omitted]", mimeType))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+type rpcMessage struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+type transport interface {
+	// send writes a request and waits for its response, or just writes a notification (no id) and returns nil
+	send(ctx context.Context, msg *rpcMessage) (*rpcMessage, error)
+	alive() bool
+	close() error
+}
+
+type Client struct {
+	Name string
+
+	transport transport
+	nextId    atomic.Int64
+	closed    atomic.Bool
+}
+
+func Connect(ctx context.Context, server *db.McpServer) (*Client, error) {
+	var t transport
+	var err error
+
+	switch server.Transport {
+	case shared.McpTransportStdio:
+		if os.Getenv("IS_CLOUD") != "" {
+			return nil, fmt.Errorf("stdio MCP servers aren't supported on Plandex Cloud")
+		}
+		t, err = newStdioTransport(server.Command, server.Args, server.Env)
+	case shared.McpTransportHttp:
+		t, err = newHttpTransport(server.Url, server.Headers)
+	default:
+		return nil, fmt.Errorf("unknown MCP transport: %s", server.Transport)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{Name: server.Name, transport: t}
+
+	ctx, cancel := context.WithTimeout(ctx, ConnectTimeout)
+	defer cancel()
+
+	err = client.initialize(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error initializing MCP server %s: %v", server.Name, err)
+	}
+
+	return client, nil
+}
+
+func (c *Client) initialize(ctx context.Context) error {
+	var res struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo": map[string]any{
+			"name":    "plandex",
+			"version": "2",
+		},
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("MCP server %s initialized: %s %s (protocol %s)\n", c.Name, res.ServerInfo.Name, res.ServerInfo.Version, res.ProtocolVersion)
+
+	return c.notify(ctx, "notifications/initialized")
+}
+
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var res struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor,omitempty"`
+		}
+		err := c.call(ctx, "tools/list", params, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		tools = append(tools, res.Tools...)
+
+		if res.NextCursor == "" || res.NextCursor == cursor {
+			break
+		}
+		cursor = res.NextCursor
+	}
+	return tools, nil
+}
+
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (*CallToolResult, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	var res CallToolResult
+	err := c.call(ctx, "tools/call", map[string]any{
+		"name":      name,
+		"arguments": args,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) Close() {
+	if c.closed.Swap(true) {
+		return
+	}
+	err := c.transport.close()
+	if err != nil {
+		log.Printf("Error closing MCP server %s: %v\n", c.Name, err)
+	}
+}
+
+func (c *Client) IsAlive() bool {
+	return !c.closed.Load() && c.transport.alive()
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	id := c.nextId.Add(1)
+	res, err := c.transport.send(ctx, &rpcMessage{
+		JsonRpc: "2.0",
+		Id:      &id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s: %v", method, res.Error)
+	}
+	if result != nil && len(res.Result) > 0 {
+		err = json.Unmarshal(res.Result, result)
+		if err != nil {
+			return fmt.Errorf("%s: error unmarshalling result: %v", method, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) notify(ctx context.Context, method string) error {
+	_, err := c.transport.send(ctx, &rpcMessage{
+		JsonRpc: "2.0",
+		Method:  method,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"plandex-server/db"
+	"sync"
+	"time"
+)
+
+// Connections are kept open between tells so that stdio servers aren't restarted on every model request. A connection is replaced when the server's config changes and closed after sitting idle.
+
+const poolIdleTimeout = 10 * time.Minute
+
+type pooledClient struct {
+	client    *Client
+	updatedAt time.Time
+	lastUsed  time.Time
+}
+
+var (
+	poolMu      sync.Mutex
+	pool        = map[string]*pooledClient{}
+	reaperStart sync.Once
+)
+
+func getClient(ctx context.Context, server *db.McpServer) (*Client, error) {
+	reaperStart.Do(func() {
+		go reapIdleClients()
+	})
+
+	poolMu.Lock()
+	pc, ok := pool[server.Id]
+	if ok && pc.client.IsAlive() && pc.updatedAt.Equal(server.UpdatedAt) {
+		pc.lastUsed = time.Now()
+		poolMu.Unlock()
+		return pc.client, nil
+	}
+	if ok {
+		delete(pool, server.Id)
+		go pc.client.Close()
+	}
+	poolMu.Unlock()
+
+	client, err := Connect(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+
+	// another tell may have connected while we were initializing—keep theirs
+	if existing, ok := pool[server.Id]; ok && existing.client.IsAlive() && existing.updatedAt.Equal(server.UpdatedAt) {
+		go client.Close()
+		existing.lastUsed = time.Now()
+		return existing.client, nil
+	}
+
+	pool[server.Id] = &pooledClient{
+		client:    client,
+		updatedAt: server.UpdatedAt,
+		lastUsed:  time.Now(),
+	}
+
+	return client, nil
+}
+
+// dropClient closes a connection after a transport error so the next tell reconnects
+func dropClient(serverId string, client *Client) {
+	poolMu.Lock()
+	if pc, ok := pool[serverId]; ok && pc.client == client {
+		delete(pool, serverId)
+	}
+	poolMu.Unlock()
+
+	client.Close()
+}
+
+func reapIdleClients() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		var idle []*Client
+
+		poolMu.Lock()
+		for id, pc := range pool {
+			if time.Since(pc.lastUsed) > poolIdleTimeout || !pc.client.IsAlive() {
+				idle = append(idle, pc.client)
+				delete(pool, id)
+			}
+		}
+		poolMu.Unlock()
+
+		for _, client := range idle {
+			log.Printf("Closing idle MCP connection to %s\n", client.Name)
+			client.Close()
+		}
+	}
+}
+
+// CloseAll closes every pooled connection—called on server shutdown so stdio servers don't outlive us
+func CloseAll() {
+	poolMu.Lock()
+	clients := make([]*Client, 0, len(pool))
+	for id, pc := range pool {
+		clients = append(clients, pc.client)
+		delete(pool, id)
+	}
+	poolMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Close()
+		}(client)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	ToolNamePrefix     = "mcp__"
+	MaxToolResultChars = 20000
+
+	// function names are limited to 64 chars of [a-zA-Z0-9_-] by most providers
+	maxToolNameLength = 64
+)
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// ToolName namespaces a tool by its server so that tools with the same name on different servers don't collide
+func ToolName(serverName, toolName string) string {
+	name := ToolNamePrefix + invalidToolNameChars.ReplaceAllString(serverName, "_") + "__" + invalidToolNameChars.ReplaceAllString(toolName, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+type toolRef struct {
+	server *db.McpServer
+	client *Client
+	tool   string
+}
+
+// ToolSet is the combined set of tools from a project's enabled MCP servers, ready to pass to the model
+type ToolSet struct {
+	Tools []openai.Tool
+
+	// servers that couldn't be reached—their tools are left out rather than failing the tell
+	Errors []string
+
+	refs map[string]toolRef
+}
+
+type CallResult struct {
+	Server  string
+	Tool    string
+	Text    string
+	IsError bool
+}
+
+// LoadToolSet returns nil if the project has no enabled MCP servers or none of them have tools
+func LoadToolSet(ctx context.Context, orgId, projectId string) (*ToolSet, error) {
+	servers, err := db.ListEnabledMcpServers(orgId, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(servers) == 0 {
+		return nil, nil
+	}
+
+	type serverTools struct {
+		server *db.McpServer
+		client *Client
+		tools  []Tool
+		err    error
+	}
+
+	results := make([]serverTools, len(servers))
+
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server *db.McpServer) {
+			defer wg.Done()
+			res := serverTools{server: server}
+			defer func() {
+				results[i] = res
+			}()
+
+			client, err := getClient(ctx, server)
+			if err != nil {
+				res.err = err
+				return
+			}
+			res.client = client
+
+			listCtx, cancel := context.WithTimeout(ctx, ConnectTimeout)
+			defer cancel()
+
+			res.tools, res.err = client.ListTools(listCtx)
+			if res.err != nil && !client.IsAlive() {
+				dropClient(server.Id, client)
+			}
+		}(i, server)
+	}
+	wg.Wait()
+
+	ts := &ToolSet{refs: map[string]toolRef{}}
+
+	for _, res := range results {
+		if res.err != nil {
+			log.Printf("Error loading tools from MCP server %s: %v\n", res.server.Name, res.err)
+			ts.Errors = append(ts.Errors, fmt.Sprintf("%s: %v", res.server.Name, res.err))
+			continue
+		}
+
+		for _, tool := range res.tools {
+			name := ToolName(res.server.Name, tool.Name)
+			if _, exists := ts.refs[name]; exists {
+				log.Printf("Skipping duplicate MCP tool name %s\n", name)
+				continue
+			}
+
+			var params any = json.RawMessage(`{"type":"object","properties":{}}`)
+			if len(tool.InputSchema) > 0 {
+				params = tool.InputSchema
+			}
+
+			description := fmt.Sprintf("[MCP server: %s] %s", res.server.Name, tool.Description)
+
+			ts.refs[name] = toolRef{server: res.server, client: res.client, tool: tool.Name}
+			ts.Tools = append(ts.Tools, openai.Tool{
+				Type: openai.ToolTypeFunction,
+				Function: &openai.FunctionDefinition{
+					Name:        name,
+					Description: description,
+					Parameters:  params,
+				},
+			})
+		}
+	}
+
+	if len(ts.Tools) == 0 {
+		return nil, nil
+	}
+
+	// stable order keeps the tool definitions cacheable across requests
+	sort.Slice(ts.Tools, func(i, j int) bool {
+		return ts.Tools[i].Function.Name < ts.Tools[j].Function.Name
+	})
+
+	return ts, nil
+}
+
+// Resolve returns the server and tool names for a namespaced tool name
+func (ts *ToolSet) Resolve(name string) (string, string, bool) {
+	ref, ok := ts.refs[name]
+	if !ok {
+		return "", "", false
+	}
+	return ref.server.Name, ref.tool, true
+}
+
+// Call runs a tool. Failures are returned as an error result rather than an error so they can be passed back to the model.
+func (ts *ToolSet) Call(ctx context.Context, name, args string) CallResult {
+	ref, ok := ts.refs[name]
+	if !ok {
+		return CallResult{Tool: name, Text: fmt.Sprintf("Unknown tool: %s", name), IsError: true}
+	}
+
+	res := CallResult{Server: ref.server.Name, Tool: ref.tool}
+
+	if args == "" {
+		args = "{}"
+	}
+	if !json.Valid([]byte(args)) {
+		res.Text = "Tool arguments must be a valid JSON object"
+		res.IsError = true
+		return res
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ToolCallTimeout)
+	defer cancel()
+
+	callRes, err := ref.client.CallTool(ctx, ref.tool, json.RawMessage(args))
+	if err != nil {
+		log.Printf("Error calling MCP tool %s on %s: %v\n", ref.tool, ref.server.Name, err)
+		if !ref.client.IsAlive() {
+			dropClient(ref.server.Id, ref.client)
+		}
+		res.Text = fmt.Sprintf("Error calling tool: %v", err)
+		res.IsError = true
+		return res
+	}
+
+	res.Text = callRes.Text()
+	res.IsError = callRes.IsError
+
+	if len(res.Text) > MaxToolResultChars {
+		res.Text = res.Text[:MaxToolResultChars] + fmt.Sprintf("\n\n[truncated—result was %d characters]", len(res.Text))
+	}
+
+	return res
+}
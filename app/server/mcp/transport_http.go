@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxHttpResponseBytes = 10 * 1024 * 1024
+
+// httpTransport implements the 'streamable HTTP' transport: each message is POSTed to the server's endpoint, which responds with either a JSON body or an SSE stream that ends with the response
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu        sync.Mutex
+	sessionId string
+}
+
+func newHttpTransport(endpoint string, headers map[string]string) (*httpTransport, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid MCP server url: %s", endpoint)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("MCP server url must be http or https: %s", endpoint)
+	}
+
+	if os.Getenv("IS_CLOUD") != "" && u.Scheme != "https" {
+		return nil, fmt.Errorf("MCP server url must use https on Plandex Cloud")
+	}
+
+	err = CheckHttpUrl(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpTransport{
+		url:     endpoint,
+		headers: headers,
+		client:  newRestrictedHttpClient(u.Hostname()),
+	}, nil
+}
+
+func (t *httpTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	t.mu.Lock()
+	if t.sessionId != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionId)
+	}
+	t.mu.Unlock()
+
+	return req, nil
+}
+
+func (t *httpTransport) send(ctx context.Context, msg *rpcMessage) (*rpcMessage, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling message: %v", err)
+	}
+
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request to MCP server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if sessionId := resp.Header.Get("Mcp-Session-Id"); sessionId != "" {
+		t.mu.Lock()
+		t.sessionId = sessionId
+		t.mu.Unlock()
+	}
+
+	if resp.StatusCode >= 400 {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("MCP server returned %s: %s", resp.Status, strings.TrimSpace(string(errBody)))
+	}
+
+	if msg.Id == nil {
+		return nil, nil
+	}
+
+	limitedBody := io.LimitReader(resp.Body, maxHttpResponseBytes)
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readSSEResponse(limitedBody, *msg.Id)
+	}
+
+	var res rpcMessage
+	err = json.NewDecoder(limitedBody).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding MCP server response: %v", err)
+	}
+	return &res, nil
+}
+
+// readSSEResponse reads events until the response to the request with the given id arrives. Server-initiated requests and notifications on the stream are skipped.
+func readSSEResponse(r io.Reader, id int64) (*rpcMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxHttpResponseBytes)
+
+	var data strings.Builder
+
+	dispatch := func() *rpcMessage {
+		defer data.Reset()
+		if data.Len() == 0 {
+			return nil
+		}
+		var msg rpcMessage
+		if json.Unmarshal([]byte(data.String()), &msg) != nil {
+			return nil
+		}
+		if msg.Method == "" && msg.Id != nil && *msg.Id == id {
+			return &msg
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if msg := dispatch(); msg != nil {
+				return msg, nil
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "data:") {
+			if data.Len() > 0 {
+				data.WriteString("\n")
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if msg := dispatch(); msg != nil {
+		return msg, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading MCP server event stream: %v", err)
+	}
+
+	return nil, fmt.Errorf("MCP server event stream ended without a response")
+}
+
+func (t *httpTransport) alive() bool {
+	return true
+}
+
+// close ends the session on the server if it gave us one
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionId := t.sessionId
+	t.mu.Unlock()
+
+	if sessionId == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := t.newRequest(ctx, http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error ending MCP session: %v", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
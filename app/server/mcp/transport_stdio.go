@@ -0,0 +1,233 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const maxStdioMessageBytes = 10 * 1024 * 1024
+
+// stdioTransport runs the server as a child process and exchanges newline-delimited JSON-RPC messages over its stdin/stdout
+type stdioTransport struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan *rpcMessage
+	doneCh  chan struct{}
+	doneErr error
+}
+
+func newStdioTransport(command string, args []string, env map[string]string) (*stdioTransport, error) {
+	if command == "" {
+		return nil, fmt.Errorf("command is required for stdio MCP servers")
+	}
+
+	// checked again here since the operator's config can change after a server is added
+	err := CheckStdioCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = stdioEnv(env)
+	cmd.Stderr = &stderrLogger{prefix: "[mcp " + command + "] "}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdin pipe: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error getting stdout pipe: %v", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting MCP server: %v", err)
+	}
+
+	t := &stdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[int64]chan *rpcMessage{},
+		doneCh:  make(chan struct{}),
+	}
+
+	go t.readLoop(stdout)
+
+	return t, nil
+}
+
+func (t *stdioTransport) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxStdioMessageBytes)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg rpcMessage
+		err := json.Unmarshal(line, &msg)
+		if err != nil {
+			log.Printf("MCP stdio: ignoring invalid message: %v\n", err)
+			continue
+		}
+
+		if msg.Method != "" {
+			t.handleServerMessage(&msg)
+			continue
+		}
+
+		if msg.Id == nil {
+			continue
+		}
+
+		t.mu.Lock()
+		ch, ok := t.pending[*msg.Id]
+		delete(t.pending, *msg.Id)
+		t.mu.Unlock()
+
+		if ok {
+			ch <- &msg
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("MCP server closed its output")
+	}
+
+	t.mu.Lock()
+	t.doneErr = err
+	t.mu.Unlock()
+	close(t.doneCh)
+}
+
+// handleServerMessage answers requests from the server. We don't offer any client capabilities, so pings are the only thing we respond to successfully.
+func (t *stdioTransport) handleServerMessage(msg *rpcMessage) {
+	if msg.Id == nil {
+		return
+	}
+
+	res := &rpcMessage{JsonRpc: "2.0", Id: msg.Id}
+	if msg.Method == "ping" {
+		res.Result = json.RawMessage("{}")
+	} else {
+		res.Error = &rpcError{Code: -32601, Message: "method not found"}
+	}
+
+	err := t.write(res)
+	if err != nil {
+		log.Printf("MCP stdio: error responding to %s: %v\n", msg.Method, err)
+	}
+}
+
+func (t *stdioTransport) write(msg *rpcMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling message: %v", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	_, err = t.stdin.Write(append(bytes, '\n'))
+	if err != nil {
+		return fmt.Errorf("error writing to MCP server: %v", err)
+	}
+	return nil
+}
+
+func (t *stdioTransport) send(ctx context.Context, msg *rpcMessage) (*rpcMessage, error) {
+	var ch chan *rpcMessage
+	if msg.Id != nil {
+		ch = make(chan *rpcMessage, 1)
+		t.mu.Lock()
+		t.pending[*msg.Id] = ch
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			delete(t.pending, *msg.Id)
+			t.mu.Unlock()
+		}()
+	}
+
+	err := t.write(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if ch == nil {
+		return nil, nil
+	}
+
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-t.doneCh:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return nil, t.doneErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *stdioTransport) alive() bool {
+	select {
+	case <-t.doneCh:
+		return false
+	default:
+		return true
+	}
+}
+
+// close follows the spec's shutdown sequence: close stdin, then escalate to SIGTERM and SIGKILL if the process doesn't exit
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- t.cmd.Wait()
+	}()
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(2 * time.Second):
+	}
+
+	t.cmd.Process.Signal(syscall.SIGTERM)
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(2 * time.Second):
+	}
+
+	return t.cmd.Process.Kill()
+}
+
+type stderrLogger struct {
+	prefix string
+}
+
+func (l *stderrLogger) Write(p []byte) (int, error) {
+	log.Printf("%s%s", l.prefix, p)
+	return len(p), nil
+}
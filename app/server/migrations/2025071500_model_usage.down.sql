@@ -0,0 +1,6 @@
+ALTER TABLE custom_models DROP COLUMN IF EXISTS cached_input_price_per_million;
+ALTER TABLE custom_models DROP COLUMN IF EXISTS output_price_per_million;
+ALTER TABLE custom_models DROP COLUMN IF EXISTS input_price_per_million;
+
+DROP TABLE IF EXISTS model_prices;
+DROP TABLE IF EXISTS model_usage;
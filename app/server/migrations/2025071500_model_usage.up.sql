@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS model_usage (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+
+  -- no foreign key so usage is kept after a plan is deleted
+  plan_id UUID,
+  plan_name VARCHAR(255),
+  session_id VARCHAR(255),
+
+  model_id VARCHAR(255) NOT NULL,
+  model_name VARCHAR(255) NOT NULL,
+  model_provider VARCHAR(255) NOT NULL,
+  model_role VARCHAR(64) NOT NULL,
+  model_pack_name VARCHAR(255) NOT NULL DEFAULT '',
+  purpose VARCHAR(255) NOT NULL DEFAULT '',
+  generation_id VARCHAR(255),
+
+  input_tokens INTEGER NOT NULL DEFAULT 0,
+  output_tokens INTEGER NOT NULL DEFAULT 0,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+
+  input_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  output_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  cached_input_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  cost NUMERIC(18, 10) NOT NULL DEFAULT 0,
+  cache_savings NUMERIC(18, 10) NOT NULL DEFAULT 0,
+
+  stopped_early BOOLEAN NOT NULL DEFAULT FALSE,
+  had_error BOOLEAN NOT NULL DEFAULT FALSE,
+  no_reported_usage BOOLEAN NOT NULL DEFAULT FALSE,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX model_usage_org_created_idx ON model_usage(org_id, created_at DESC);
+CREATE INDEX model_usage_plan_idx ON model_usage(org_id, plan_id, created_at DESC);
+CREATE INDEX model_usage_session_idx ON model_usage(org_id, session_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS model_prices (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  model_id VARCHAR(255) NOT NULL,
+
+  input_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  output_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  cached_input_price_per_million NUMERIC(14, 6) NOT NULL DEFAULT 0,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_model_prices_modtime BEFORE UPDATE ON model_prices FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX model_prices_org_model_idx ON model_prices(org_id, model_id);
+
+ALTER TABLE custom_models ADD COLUMN input_price_per_million FLOAT NOT NULL DEFAULT 0.0;
+ALTER TABLE custom_models ADD COLUMN output_price_per_million FLOAT NOT NULL DEFAULT 0.0;
+ALTER TABLE custom_models ADD COLUMN cached_input_price_per_million FLOAT NOT NULL DEFAULT 0.0;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_mcp_servers';
+
+DROP TABLE IF EXISTS mcp_servers;
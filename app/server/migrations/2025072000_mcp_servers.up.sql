@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS mcp_servers (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  transport VARCHAR(32) NOT NULL,
+
+  command TEXT NOT NULL DEFAULT '',
+  args JSON NOT NULL DEFAULT '[]',
+  env JSON NOT NULL DEFAULT '{}',
+
+  url TEXT NOT NULL DEFAULT '',
+  headers JSON NOT NULL DEFAULT '{}',
+
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_mcp_servers_modtime BEFORE UPDATE ON mcp_servers FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX mcp_servers_project_name_idx ON mcp_servers(project_id, name);
+
+INSERT INTO permissions (name, description) VALUES
+  ('manage_mcp_servers', 'Add, update, and remove a project''s MCP servers');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_mcp_servers';
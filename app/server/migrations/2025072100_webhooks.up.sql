@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  events TEXT[] NOT NULL DEFAULT '{}',
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_webhooks_modtime BEFORE UPDATE ON webhooks FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX webhooks_org_idx ON webhooks(org_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  event VARCHAR(64) NOT NULL,
+  payload JSON NOT NULL,
+  status VARCHAR(32) NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  response_status INTEGER,
+  error TEXT,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_attempt_at TIMESTAMP,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_webhook_deliveries_modtime BEFORE UPDATE ON webhook_deliveries FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX webhook_deliveries_org_created_idx ON webhook_deliveries(org_id, created_at DESC);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+INSERT INTO permissions (name, description) VALUES
+  ('manage_webhooks', 'Add, update, remove, and view the delivery log of org webhooks');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_webhooks';
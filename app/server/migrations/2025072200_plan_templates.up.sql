@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS plan_templates (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  creator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  name VARCHAR(64) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  prompt TEXT NOT NULL,
+  context_globs TEXT[] NOT NULL DEFAULT '{}',
+  model_pack_name VARCHAR(255) NOT NULL DEFAULT '',
+  auto_mode VARCHAR(32) NOT NULL DEFAULT '',
+  plandex_ignore TEXT[] NOT NULL DEFAULT '{}',
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_plan_templates_modtime BEFORE UPDATE ON plan_templates FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX plan_templates_org_name_idx ON plan_templates(org_id, name);
+
+INSERT INTO permissions (name, description) VALUES
+  ('manage_plan_templates', 'Create, update, and remove org plan templates');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin', 'member')
+    AND p.name = 'manage_plan_templates';
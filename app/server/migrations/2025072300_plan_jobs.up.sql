@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS plan_jobs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  branch VARCHAR(255) NOT NULL,
+  prompt TEXT NOT NULL,
+  auto_mode VARCHAR(32) NOT NULL DEFAULT '',
+  schedule VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT 'queued',
+  last_run_status VARCHAR(32) NOT NULL DEFAULT '',
+  last_error TEXT NOT NULL DEFAULT '',
+  num_runs INTEGER NOT NULL DEFAULT 0,
+  next_run_at TIMESTAMP,
+  last_run_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  heartbeat_at TIMESTAMP,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_plan_jobs_modtime BEFORE UPDATE ON plan_jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX plan_jobs_plan_idx ON plan_jobs(plan_id);
+CREATE INDEX plan_jobs_due_idx ON plan_jobs(next_run_at) WHERE status = 'queued';
+CREATE INDEX plan_jobs_running_idx ON plan_jobs(org_id) WHERE status = 'running';
@@ -0,0 +1,2 @@
+ALTER TABLE custom_models DROP COLUMN IF EXISTS is_embedding_model;
+ALTER TABLE model_sets DROP COLUMN IF EXISTS embeddings;
@@ -0,0 +1,2 @@
+ALTER TABLE model_sets ADD COLUMN embeddings JSON;
+ALTER TABLE custom_models ADD COLUMN is_embedding_model BOOLEAN NOT NULL DEFAULT FALSE;
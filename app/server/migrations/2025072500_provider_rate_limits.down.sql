@@ -0,0 +1,3 @@
+ALTER TABLE custom_providers DROP COLUMN IF EXISTS max_concurrent_requests;
+ALTER TABLE custom_providers DROP COLUMN IF EXISTS tokens_per_minute;
+ALTER TABLE custom_providers DROP COLUMN IF EXISTS requests_per_minute;
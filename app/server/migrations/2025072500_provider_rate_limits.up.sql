@@ -0,0 +1,3 @@
+ALTER TABLE custom_providers ADD COLUMN requests_per_minute INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE custom_providers ADD COLUMN tokens_per_minute INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE custom_providers ADD COLUMN max_concurrent_requests INTEGER NOT NULL DEFAULT 0;
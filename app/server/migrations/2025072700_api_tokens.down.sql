@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'manage_service_accounts';
+
+DROP TABLE IF EXISTS api_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_service_account;
@@ -0,0 +1,36 @@
+ALTER TABLE users ADD COLUMN is_service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  token_prefix VARCHAR(16) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL,
+  scopes TEXT[] NOT NULL,
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  revoked_at TIMESTAMP,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_api_tokens_modtime BEFORE UPDATE ON api_tokens FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX api_tokens_hash_idx ON api_tokens(token_hash);
+CREATE INDEX api_tokens_org_user_idx ON api_tokens(org_id, user_id);
+
+INSERT INTO permissions (name, description) VALUES
+  ('manage_service_accounts', 'Create and remove org service accounts and manage their API tokens');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_service_accounts';
@@ -0,0 +1,44 @@
+-- no foreign keys so that events outlive the users and plans they refer to
+CREATE TABLE IF NOT EXISTS audit_events (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL,
+  action VARCHAR(64) NOT NULL,
+  user_id UUID,
+  user_email VARCHAR(255),
+  api_token_id UUID,
+  ip VARCHAR(64),
+  plan_id UUID,
+  plan_name VARCHAR(255),
+  branch VARCHAR(255),
+  target_id VARCHAR(255),
+  data JSON,
+
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_events_org_created_idx ON audit_events(org_id, created_at DESC, id DESC);
+CREATE INDEX audit_events_org_user_idx ON audit_events(org_id, user_id);
+CREATE INDEX audit_events_org_plan_idx ON audit_events(org_id, plan_id);
+
+CREATE OR REPLACE FUNCTION prevent_audit_event_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events FOR EACH ROW EXECUTE FUNCTION prevent_audit_event_changes();
+
+INSERT INTO permissions (name, description) VALUES
+  ('view_audit_log', 'View and export the org audit log');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name = 'owner'
+    AND p.name = 'view_audit_log';
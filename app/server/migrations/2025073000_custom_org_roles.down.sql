@@ -0,0 +1,13 @@
+-- move anyone with a custom role back to member so the roles can be dropped
+UPDATE orgs_users SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+DELETE FROM permissions WHERE resource_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+DELETE FROM org_roles WHERE org_id IS NOT NULL;
+
+DELETE FROM permissions WHERE name IN ('exec_commands', 'change_model_pack', 'manage_custom_providers', 'force_load_ignored', 'manage_org_roles');
+
+DROP INDEX IF EXISTS permissions_resource_idx;
@@ -0,0 +1,31 @@
+CREATE INDEX permissions_resource_idx ON permissions(resource_id);
+
+INSERT INTO permissions (name, description) VALUES
+  ('exec_commands', 'Execute commands from a plan''s _apply.sh'),
+  ('change_model_pack', 'Change the model pack of a plan or the org''s default model pack'),
+  ('manage_custom_providers', 'Add, update, and remove the org''s custom models, providers, and model packs'),
+  ('force_load_ignored', 'Load files excluded by .plandexignore into context'),
+  ('manage_org_roles', 'Create, update, and delete custom org roles');
+
+-- default roles keep what they could already do before these were permissions
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin', 'member')
+    AND p.name IN ('exec_commands', 'change_model_pack', 'manage_custom_providers', 'force_load_ignored');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_org_roles';
@@ -54,6 +54,38 @@ func InitClients(authVars map[string]string, settings *shared.PlanSettings, orgU
 	return clients
 }
 
+// AuthVarsFromEnv reads model provider credentials from the server's environment, for requests that don't come with credentials from a client
+func AuthVarsFromEnv(settings *shared.PlanSettings) map[string]string {
+	authVars := map[string]string{}
+
+	// Get all provider configs to know which env vars to check
+	allProviders := []shared.ModelProviderConfigSchema{}
+	for _, providerConfig := range shared.BuiltInModelProviderConfigs {
+		allProviders = append(allProviders, providerConfig)
+	}
+	if settings != nil {
+		for _, customProvider := range settings.CustomProviders {
+			allProviders = append(allProviders, customProvider.ToModelProviderConfigSchema())
+		}
+	}
+
+	// Check environment for each provider's required vars
+	for _, providerConfig := range allProviders {
+		if providerConfig.ApiKeyEnvVar != "" {
+			if val := os.Getenv(providerConfig.ApiKeyEnvVar); val != "" {
+				authVars[providerConfig.ApiKeyEnvVar] = val
+			}
+		}
+		for _, extraAuthVar := range providerConfig.ExtraAuthVars {
+			if val := os.Getenv(extraAuthVar.Var); val != "" {
+				authVars[extraAuthVar.Var] = val
+			}
+		}
+	}
+
+	return authVars
+}
+
 func newClient(providerConfig shared.ModelProviderConfigSchema, authVars map[string]string) ClientInfo {
 	var apiKey string
 	if providerConfig.ApiKeyEnvVar != "" {
@@ -84,6 +116,10 @@ type ExtendedChatCompletionStream struct {
 	openaiStream *openai.ChatCompletionStream
 	customReader *StreamReader[types.ExtendedChatCompletionStreamResponse]
 	ctx          context.Context
+
+	// holds a concurrency slot with the provider's rate limiter until the stream is closed
+	limiter     *providerLimiter
+	releaseOnce sync.Once
 }
 
 // StreamReader handles the SSE stream reading
@@ -359,18 +395,32 @@ func createChatCompletionStreamExtended(
 
 	addOpenRouterHeaders(req)
 
+	limiter, err := waitForRateLimit(ctx, waitForRateLimitParams{
+		client:      client,
+		role:        modelConfig.Role,
+		inputTokens: getChatRequestTokensEstimate(extendedReq),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for rate limit: %w", err)
+	}
+
 	// Send the request
 	resp, err := httpClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
+		limiter.release()
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		defer limiter.release()
 		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return nil, fmt.Errorf("error reading error response: %w", err)
 		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			limiter.pause(rateLimitPauseDuration(resp.Header, string(body)))
+		}
 		return nil, &HTTPError{
 			StatusCode: resp.StatusCode,
 			Body:       string(body),
@@ -395,6 +445,7 @@ func createChatCompletionStreamExtended(
 	return &ExtendedChatCompletionStream{
 		customReader: reader,
 		ctx:          ctx,
+		limiter:      limiter,
 	}, nil
 }
 
@@ -495,6 +546,8 @@ func (stream *ExtendedChatCompletionStream) Recv() (*types.ExtendedChatCompletio
 
 // Close the response body
 func (stream *ExtendedChatCompletionStream) Close() error {
+	stream.releaseOnce.Do(stream.limiter.release)
+
 	if stream.openaiStream != nil {
 		return stream.openaiStream.Close()
 	}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"plandex-server/notify"
+	"plandex-server/types"
+	"runtime/debug"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// inputs are sent in batches to stay well under providers' per-request input limits
+const embeddingsBatchSize = 64
+
+type EmbeddingsParams struct {
+	Clients       map[string]ClientInfo
+	AuthVars      map[string]string
+	Auth          *types.ServerAuth
+	Plan          *db.Plan
+	Settings      *shared.PlanSettings
+	OrgUserConfig *shared.OrgUserConfig
+	Purpose       string
+	SessionId     string
+
+	Inputs []string
+}
+
+// CreateEmbeddings embeds each input with the model pack's embeddings role. The result has one embedding per input, in the same order.
+func CreateEmbeddings(ctx context.Context, params EmbeddingsParams) ([][]float32, error) {
+	settings := params.Settings
+	authVars := params.AuthVars
+	orgUserConfig := params.OrgUserConfig
+
+	modelConfig := settings.GetModelPack().Embeddings
+	if modelConfig == nil {
+		return nil, fmt.Errorf("model pack has no embeddings role")
+	}
+
+	if params.Purpose == "" {
+		return nil, fmt.Errorf("purpose is required")
+	}
+
+	baseModelConfig := modelConfig.GetBaseModelConfig(authVars, settings, orgUserConfig)
+	if !baseModelConfig.IsEmbeddingModel {
+		return nil, fmt.Errorf("%s isn't an embedding model", baseModelConfig.ModelId)
+	}
+
+	providerComposite := modelConfig.GetProviderComposite(authVars, settings, orgUserConfig)
+	client, ok := params.Clients[providerComposite]
+	if !ok {
+		return nil, fmt.Errorf("client not found for provider composite: %s", providerComposite)
+	}
+
+	res := make([][]float32, 0, len(params.Inputs))
+
+	for start := 0; start < len(params.Inputs); start += embeddingsBatchSize {
+		end := min(start+embeddingsBatchSize, len(params.Inputs))
+		batch := params.Inputs[start:end]
+
+		inputTokensEstimate := 0
+		for _, input := range batch {
+			inputTokensEstimate += shared.GetNumTokensEstimate(input)
+		}
+
+		_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+			Auth: params.Auth,
+			Plan: params.Plan,
+			WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
+				InputTokens: inputTokensEstimate,
+				ModelName:   baseModelConfig.ModelName,
+				ModelId:     baseModelConfig.ModelId,
+				ModelTag:    baseModelConfig.ModelTag,
+			},
+		})
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		limiter, err := waitForRateLimit(ctx, waitForRateLimitParams{
+			client:      client,
+			role:        shared.ModelRoleEmbeddings,
+			inputTokens: inputTokensEstimate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for rate limit: %w", err)
+		}
+
+		reqStarted := time.Now()
+
+		resp, err := client.Client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: batch,
+			Model: openai.EmbeddingModel(baseModelConfig.ModelName),
+		})
+		limiter.release()
+		if err != nil {
+			var openaiErr *openai.APIError
+			if errors.As(err, &openaiErr) && openaiErr.HTTPStatusCode == http.StatusTooManyRequests {
+				limiter.pause(rateLimitDefaultPause)
+			}
+			return nil, fmt.Errorf("error creating embeddings with %s: %v", baseModelConfig.ModelName, err)
+		}
+
+		if len(resp.Data) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings from %s, got %d", len(batch), baseModelConfig.ModelName, len(resp.Data))
+		}
+
+		embeddings := make([][]float32, len(batch))
+		for _, data := range resp.Data {
+			if data.Index < 0 || data.Index >= len(batch) {
+				return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+			}
+			embeddings[data.Index] = data.Embedding
+		}
+		res = append(res, embeddings...)
+
+		inputTokens := resp.Usage.PromptTokens
+		noReportedUsage := inputTokens == 0
+		if noReportedUsage {
+			inputTokens = inputTokensEstimate
+		}
+
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("panic in DidSendModelRequest hook: %v\n%s", r, debug.Stack())
+					go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in DidSendModelRequest hook: %v\n%s", r, debug.Stack()))
+				}
+			}()
+
+			var planId string
+			if params.Plan != nil {
+				planId = params.Plan.Id
+			}
+
+			_, apiErr := hooks.ExecHook(hooks.DidSendModelRequest, hooks.HookParams{
+				Auth: params.Auth,
+				Plan: params.Plan,
+				DidSendModelRequestParams: &hooks.DidSendModelRequestParams{
+					InputTokens:      inputTokens,
+					ModelId:          baseModelConfig.ModelId,
+					ModelTag:         baseModelConfig.ModelTag,
+					ModelName:        baseModelConfig.ModelName,
+					ModelProvider:    baseModelConfig.Provider,
+					ModelPackName:    settings.GetModelPack().Name,
+					ModelRole:        shared.ModelRoleEmbeddings,
+					Purpose:          params.Purpose,
+					PlanId:           planId,
+					SessionId:        params.SessionId,
+					RequestStartedAt: reqStarted,
+					ModelConfig:      modelConfig,
+					NoReportedUsage:  noReportedUsage,
+				},
+			})
+			if apiErr != nil {
+				log.Printf("CreateEmbeddings - error executing DidSendModelRequest hook: %v", apiErr)
+				go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error executing DidSendModelRequest hook: %v", apiErr))
+			}
+		}()
+	}
+
+	return res, nil
+}
@@ -84,7 +84,9 @@ func ModelRequest(
 	messages = CheckSingleSystemMessage(modelConfig, baseModelConfig, messages)
 	inputTokensEstimate := GetMessagesTokenEstimate(messages...) + TokensPerRequest
 
-	config := modelConfig.GetRoleForInputTokens(inputTokensEstimate, settings)
+	config := modelConfig.GetRoleForInputTokenCounter(func(tokenizer shared.Tokenizer) int {
+		return GetMessagesTokenCount(tokenizer, messages...) + TokensPerRequest
+	}, settings)
 	modelConfig = &config
 
 	if params.EstimatedOutputTokens != 0 {
@@ -271,10 +273,12 @@ func FilterEmptyMessages(messages []types.ExtendedChatMessage) []types.ExtendedC
 				content = append(content, part)
 			}
 		}
-		if len(content) > 0 {
+		if len(content) > 0 || len(message.ToolCalls) > 0 {
 			filteredMessages = append(filteredMessages, types.ExtendedChatMessage{
-				Role:    message.Role,
-				Content: content,
+				Role:       message.Role,
+				Content:    content,
+				ToolCalls:  message.ToolCalls,
+				ToolCallID: message.ToolCallID,
 			})
 		}
 	}
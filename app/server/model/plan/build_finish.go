@@ -9,6 +9,7 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/notify"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -294,6 +295,17 @@ func (fileState *activeBuildStreamFileState) onBuildFileError(err error) {
 
 	go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error for file %s: %v", filePath, err))
 
+	webhooks.Dispatch(webhooks.Event{
+		Type:   shared.WebhookEventBuildFailed,
+		OrgId:  activePlan.OrgId,
+		Branch: branch,
+		Plan:   fileState.plan,
+		Data: map[string]interface{}{
+			"path":  filePath,
+			"error": err.Error(),
+		},
+	})
+
 	activePlan.StreamDoneCh <- &shared.ApiError{
 		Type:   shared.ApiErrorTypeOther,
 		Status: http.StatusInternalServerError,
@@ -0,0 +1,86 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"log"
+	"plandex-server/lsp"
+
+	shared "plandex-shared"
+)
+
+// validateLsp is the optional post-build validation stage: if a language server is enabled for the file's language, it checks the updated file in a scratch overlay of the plan's files and returns any errors the build introduced. Errors that were already in the file before the build aren't returned.
+func (fileState *activeBuildStreamFileState) validateLsp(ctx context.Context, updated string) []string {
+	if fileState.language == "" || lsp.GetServerConfig(fileState.language) == nil {
+		return nil
+	}
+
+	fileState.lspMu.Lock()
+	defer fileState.lspMu.Unlock()
+
+	if fileState.lspUnavailable {
+		return nil
+	}
+
+	res, err := lsp.ValidateFile(ctx, lsp.ValidateParams{
+		Language: fileState.language,
+		Path:     fileState.filePath,
+		Files:    fileState.lspOverlayFiles(),
+		Original: fileState.preBuildState,
+		Updated:  updated,
+		Baseline: fileState.lspBaseline,
+	})
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		// a server that can't start won't do better on the next attempt, so skip the stage for the rest of this file's build
+		log.Printf("validateLsp - %s - error running language server, skipping lsp validation: %v\n", fileState.filePath, err)
+		fileState.lspUnavailable = true
+		return nil
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	if res.TimedOut {
+		log.Printf("validateLsp - %s - %s timed out, skipping lsp validation\n", fileState.filePath, res.ServerName)
+		fileState.lspUnavailable = true
+		return nil
+	}
+
+	fileState.lspBaseline = res.Baseline
+
+	log.Printf("validateLsp - %s - %s found %d new errors\n", fileState.filePath, res.ServerName, len(res.Errors))
+
+	var errs []string
+	for _, d := range res.Errors {
+		errs = append(errs, d.String())
+	}
+	return errs
+}
+
+// lspOverlayFiles is everything the server can see besides the file being built: the plan's file context, with any pending changes from the plan applied
+func (fileState *activeBuildStreamFileState) lspOverlayFiles() map[string]string {
+	files := map[string]string{}
+
+	for _, context := range fileState.modelContext {
+		if context.ContextType == shared.ContextFileType && context.FilePath != "" {
+			files[context.FilePath] = context.Body
+		}
+	}
+
+	if fileState.currentPlanState != nil && fileState.currentPlanState.CurrentPlanFiles != nil {
+		for path, content := range fileState.currentPlanState.CurrentPlanFiles.Files {
+			if fileState.currentPlanState.CurrentPlanFiles.Removed[path] {
+				delete(files, path)
+				continue
+			}
+			files[path] = content
+		}
+	}
+
+	return files
+}
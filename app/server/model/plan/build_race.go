@@ -24,6 +24,7 @@ type buildRaceParams struct {
 	desc            string
 	reasons         []syntax.NeedsVerifyReason
 	syntaxErrors    []string
+	lspErrors       []string
 
 	didCallFastApply bool
 	fastApplyCh      chan string
@@ -241,6 +242,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			desc:                 desc,
 			reasons:              reasons,
 			syntaxErrors:         syntaxErrors,
+			lspErrors:            params.lspErrors,
 			initialPhaseOnStream: onInitialStream,
 			isInitial:            true,
 			sessionId:            sessionId,
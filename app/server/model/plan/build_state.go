@@ -3,8 +3,10 @@ package plan
 import (
 	"plandex-server/db"
 	"plandex-server/hooks"
+	"plandex-server/lsp"
 	"plandex-server/model"
 	"plandex-server/types"
+	"sync"
 
 	shared "plandex-shared"
 
@@ -45,5 +47,10 @@ type activeBuildStreamFileState struct {
 	isNewFile                  bool
 	contextPart                *db.Context
 
+	// the validation loop and fast apply validation can run lsp validation concurrently
+	lspMu          sync.Mutex
+	lspBaseline    []lsp.Diagnostic
+	lspUnavailable bool
+
 	builderRun hooks.DidFinishBuilderRunParams
 }
@@ -126,12 +126,19 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 	autoApplyHasSyntaxErrors := len(autoApplySyntaxErrors) > 0
 	autoApplyIsValid := !autoApplyHasSyntaxErrors && !hasNeedsVerifyReasons
 
+	// a result that passes the syntax check can still fail the optional language server stage
+	var autoApplyLspErrors []string
+	if autoApplyIsValid {
+		autoApplyLspErrors = fileState.validateLsp(buildCtx, autoApplyRes.NewFile)
+		autoApplyIsValid = len(autoApplyLspErrors) == 0
+	}
+
 	if !autoApplyIsValid && !calledFastApply {
 		callFastApply()
 	}
 
-	log.Printf("buildStructuredEdits - %s - autoApplyHasSyntaxErrors: %t, hasNeedsVerifyReasons: %t, autoApplyIsValid: %t\n",
-		filePath, autoApplyHasSyntaxErrors, hasNeedsVerifyReasons, autoApplyIsValid)
+	log.Printf("buildStructuredEdits - %s - autoApplyHasSyntaxErrors: %t, hasNeedsVerifyReasons: %t, autoApplyLspErrors: %d, autoApplyIsValid: %t\n",
+		filePath, autoApplyHasSyntaxErrors, hasNeedsVerifyReasons, len(autoApplyLspErrors), autoApplyIsValid)
 
 	updated := autoApplyRes.NewFile
 
@@ -140,7 +147,7 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 		log.Printf("buildStructuredEdits - %s - changes are valid, using ApplyChanges result\n", filePath)
 		fileState.builderRun.AutoApplySuccess = true
 	} else {
-		log.Printf("buildStructuredEdits - %s - auto apply has syntax errors or NeedsVerifyReasons", filePath)
+		log.Printf("buildStructuredEdits - %s - auto apply has syntax errors, language server errors, or NeedsVerifyReasons", filePath)
 		fileState.builderRun.AutoApplyValidationReasons = make([]string, len(autoApplyRes.NeedsVerifyReasons))
 		for i, reason := range autoApplyRes.NeedsVerifyReasons {
 			fileState.builderRun.AutoApplyValidationReasons[i] = string(reason)
@@ -154,6 +161,7 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 			desc:            desc,
 			reasons:         autoApplyRes.NeedsVerifyReasons,
 			syntaxErrors:    autoApplySyntaxErrors,
+			lspErrors:       autoApplyLspErrors,
 
 			didCallFastApply: calledFastApply,
 			fastApplyCh:      fastApplyCh,
@@ -27,6 +27,7 @@ type buildValidateLoopParams struct {
 	proposedContent            string
 	desc                       string
 	syntaxErrors               []string
+	lspErrors                  []string
 	reasons                    []syntax.NeedsVerifyReason
 	initialPhaseOnStream       func(chunk string, buffer string) bool
 	validateOnlyOnFinalAttempt bool
@@ -53,6 +54,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 	desc := params.desc
 
 	syntaxErrors := params.syntaxErrors
+	lspErrors := params.lspErrors
 	numAttempts := 0
 
 	problems := []string{}
@@ -111,6 +113,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 			desc:            desc,
 			onStream:        onStream,
 			syntaxErrors:    syntaxErrors,
+			lspErrors:       lspErrors,
 			reasons:         reasons,
 			modelConfig:     &modelConfig,
 			validateOnly:    isLastAttempt && params.validateOnlyOnFinalAttempt,
@@ -136,14 +139,24 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 		log.Printf("Found %d syntax errors after attempt %d", len(syntaxErrors), currentAttempt)
 
 		if res.valid && len(syntaxErrors) == 0 {
-			log.Printf("Validation succeeded in attempt %d", currentAttempt)
-			return buildValidateLoopResult{
-				valid:   res.valid,
-				updated: res.updated,
-			}, nil
-		}
+			// the optional language server stage only runs once everything else passes
+			lspErrors = fileState.validateLsp(ctx, updated)
+			log.Printf("Found %d language server errors after attempt %d", len(lspErrors), currentAttempt)
+
+			if len(lspErrors) == 0 {
+				log.Printf("Validation succeeded in attempt %d", currentAttempt)
+				return buildValidateLoopResult{
+					valid:   res.valid,
+					updated: res.updated,
+				}, nil
+			}
 
-		problems = append(problems, res.problem)
+			problems = append(problems, "Language server errors:\n"+strings.Join(lspErrors, "\n"))
+		} else {
+			// language server errors from a previous attempt no longer apply to the updated file
+			lspErrors = nil
+			problems = append(problems, res.problem)
+		}
 
 		log.Printf("Validation failed in attempt %d, preparing for next attempt", currentAttempt)
 
@@ -164,6 +177,7 @@ type buildValidateParams struct {
 	proposedContent string
 	desc            string
 	syntaxErrors    []string
+	lspErrors       []string
 	reasons         []syntax.NeedsVerifyReason
 	onStream        func(chunk string, buffer string) bool
 	phase           int
@@ -224,6 +238,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 		ProposedWithLineNums: proposedWithLineNums,
 		Diff:                 diff,
 		SyntaxErrors:         syntaxErrors,
+		LspErrors:            params.lspErrors,
 		Reasons:              reasons,
 	})
 
@@ -54,10 +54,11 @@ func (fileState *activeBuildStreamFileState) buildWholeFileFallback(buildCtx con
 		},
 	}
 
-	inputTokens := model.GetMessagesTokenEstimate(messages...) + model.TokensPerRequest
 	maxExpectedOutputTokens := shared.GetNumTokensEstimate(originalFile + proposedContent)
 
-	modelConfig := config.GetRoleForInputTokens(inputTokens, fileState.settings)
+	modelConfig := config.GetRoleForInputTokenCounter(func(tokenizer shared.Tokenizer) int {
+		return model.GetMessagesTokenCount(tokenizer, messages...) + model.TokensPerRequest
+	}, fileState.settings)
 	modelConfig = modelConfig.GetRoleForOutputTokens(maxExpectedOutputTokens, fileState.settings)
 
 	log.Println("buildWholeFile - calling model for whole file write")
@@ -8,6 +8,7 @@ import (
 	"plandex-server/notify"
 	"plandex-server/shutdown"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -54,6 +55,13 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
 					}
 
+					webhooks.Dispatch(webhooks.Event{
+						Type:   shared.WebhookEventPlanFinished,
+						OrgId:  orgId,
+						PlanId: planId,
+						Branch: branch,
+					})
+
 					// cancel *after* the DeleteActivePlan call
 					// allows queued operations to complete
 					DeleteActivePlan(orgId, userId, planId, branch)
@@ -69,6 +77,14 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to error: %v\n", planId, err)
 					}
 
+					webhooks.Dispatch(webhooks.Event{
+						Type:   shared.WebhookEventPlanStreamError,
+						OrgId:  orgId,
+						PlanId: planId,
+						Branch: branch,
+						Data:   map[string]interface{}{"error": apiErr.Msg},
+					})
+
 					log.Println("Sending error message to client")
 					activePlan.Stream(shared.StreamMessage{
 						Type:  shared.StreamMessageError,
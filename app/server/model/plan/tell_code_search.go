@@ -0,0 +1,228 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/mcp"
+	"plandex-server/syntax/file_map"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+const codeSearchToolName = "search_code"
+
+// how long to wait for the client to run a search
+const codeSearchTimeout = 60 * time.Second
+
+// max length of each matching line in the results passed to the model
+const maxCodeSearchLineChars = 300
+
+var codeSearchTool = openai.Tool{
+	Type: openai.ToolTypeFunction,
+	Function: &openai.FunctionDefinition{
+		Name:        codeSearchToolName,
+		Description: "Search the project's files, including files that aren't in context. Returns matching lines with their paths and line numbers.",
+		Parameters: jsonschema.Definition{
+			Type: jsonschema.Object,
+			Properties: map[string]jsonschema.Definition{
+				"kind": {
+					Type:        jsonschema.String,
+					Enum:        []string{string(shared.CodeSearchKindText), string(shared.CodeSearchKindSymbol)},
+					Description: "'text' finds lines matching the query. 'symbol' finds where the named function, method, type, class, or variable is defined, and the lines that reference it.",
+				},
+				"query": {
+					Type:        jsonschema.String,
+					Description: "The text or regex to search for, or the symbol name for 'symbol' searches",
+				},
+				"isRegex": {
+					Type:        jsonschema.Boolean,
+					Description: "Whether the query is a regular expression (RE2 syntax). Only for 'text' searches.",
+				},
+				"caseSensitive": {
+					Type:        jsonschema.Boolean,
+					Description: "Whether the search is case sensitive. 'symbol' searches are always case sensitive.",
+				},
+				"paths": {
+					Type:        jsonschema.Array,
+					Items:       &jsonschema.Definition{Type: jsonschema.String},
+					Description: "Files or directories to limit the search to. Searches the whole project if empty.",
+				},
+				"maxResults": {
+					Type:        jsonschema.Integer,
+					Description: fmt.Sprintf("Maximum number of matching lines to return. Defaults to %d, max %d.", shared.CodeSearchDefaultMaxResults, shared.CodeSearchMaxResults),
+				},
+			},
+			Required: []string{"kind", "query"},
+		},
+	},
+}
+
+// tools returns the built-in tools followed by the project's MCP tools
+func (state *activeTellStreamState) tools() []openai.Tool {
+	var tools []openai.Tool
+	if state.codeSearchEnabled {
+		tools = append(tools, codeSearchTool)
+	}
+	if state.mcpTools != nil {
+		tools = append(tools, state.mcpTools.Tools...)
+	}
+	return tools
+}
+
+// execCodeSearch streams a search request to the client, waits for the results, and formats them for the model. Failures are returned as an error result so the model can continue without them.
+func (state *activeTellStreamState) execCodeSearch(active *types.ActivePlan, args string) mcp.CallResult {
+	res := mcp.CallResult{Tool: codeSearchToolName}
+
+	var req shared.CodeSearchRequest
+	if err := json.Unmarshal([]byte(args), &req); err != nil {
+		res.Text = fmt.Sprintf("Invalid arguments: %v", err)
+		res.IsError = true
+		return res
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		res.Text = "query is required"
+		res.IsError = true
+		return res
+	}
+	if req.Kind != shared.CodeSearchKindText && req.Kind != shared.CodeSearchKindSymbol {
+		res.Text = fmt.Sprintf("kind must be '%s' or '%s'", shared.CodeSearchKindText, shared.CodeSearchKindSymbol)
+		res.IsError = true
+		return res
+	}
+	if req.Kind == shared.CodeSearchKindSymbol {
+		req.IsRegex = false
+		req.CaseSensitive = true
+	}
+	req.Id = uuid.New().String()
+
+	active.Stream(shared.StreamMessage{
+		Type:       shared.StreamMessageCodeSearch,
+		CodeSearch: &req,
+	})
+	active.FlushStreamBuffer()
+
+	searchRes, err := waitForCodeSearch(active, req.Id)
+	if err != nil {
+		res.Text = err.Error()
+		res.IsError = true
+		return res
+	}
+
+	if searchRes.Error != "" {
+		res.Text = searchRes.Error
+		res.IsError = true
+		return res
+	}
+
+	var defs map[string][]file_map.SymbolDefinition
+	if req.Kind == shared.CodeSearchKindSymbol {
+		defs = findCodeSearchDefinitions(active.Ctx, req.Query, searchRes.Files)
+	}
+
+	res.Text = formatCodeSearchResult(&req, searchRes, defs)
+	return res
+}
+
+func waitForCodeSearch(active *types.ActivePlan, id string) (*shared.RespondCodeSearchRequest, error) {
+	timer := time.NewTimer(codeSearchTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-active.Ctx.Done():
+			return nil, active.Ctx.Err()
+		case <-timer.C:
+			log.Printf("Timeout waiting for code search %s\n", id)
+			return nil, fmt.Errorf("timed out waiting for search results")
+		case res := <-active.CodeSearchResponseCh:
+			if res.Id != id {
+				log.Printf("Skipping stale code search response %s\n", res.Id)
+				continue
+			}
+			return res, nil
+		}
+	}
+}
+
+// tree-sitter parsing happens on the server like it does for project maps, so the client only needs to find candidate files
+func findCodeSearchDefinitions(ctx context.Context, name string, files []shared.CodeSearchFile) map[string][]file_map.SymbolDefinition {
+	res := map[string][]file_map.SymbolDefinition{}
+	for _, file := range files {
+		fileDefs, err := file_map.FindSymbolDefinitions(ctx, file.Path, []byte(file.Body), name)
+		if err != nil {
+			log.Printf("Error finding definitions of %s in %s: %v\n", name, file.Path, err)
+			continue
+		}
+		if len(fileDefs) > 0 {
+			res[file.Path] = fileDefs
+		}
+	}
+	return res
+}
+
+func formatCodeSearchResult(req *shared.CodeSearchRequest, res *shared.RespondCodeSearchRequest, defs map[string][]file_map.SymbolDefinition) string {
+	var sb strings.Builder
+
+	isDefLine := map[string]bool{}
+	if req.Kind == shared.CodeSearchKindSymbol {
+		var numDefs int
+		for _, fileDefs := range defs {
+			numDefs += len(fileDefs)
+		}
+
+		if numDefs == 0 {
+			sb.WriteString(fmt.Sprintf("No definitions of %s found.\n", req.Query))
+		} else {
+			sb.WriteString(fmt.Sprintf("Definitions of %s:\n", req.Query))
+			// follow the order of the matches so output is stable
+			seen := map[string]bool{}
+			for _, match := range res.Matches {
+				if seen[match.Path] {
+					continue
+				}
+				seen[match.Path] = true
+				for _, def := range defs[match.Path] {
+					isDefLine[fmt.Sprintf("%s:%d", match.Path, def.Line)] = true
+					sb.WriteString(fmt.Sprintf("%s:%d: %s (%s)\n", match.Path, def.Line, def.Signature, def.Type))
+				}
+			}
+		}
+		sb.WriteString("\nReferences:\n")
+	}
+
+	var numMatches int
+	for _, match := range res.Matches {
+		if isDefLine[fmt.Sprintf("%s:%d", match.Path, match.Line)] {
+			continue
+		}
+		numMatches++
+		text := strings.TrimSpace(match.Text)
+		if len(text) > maxCodeSearchLineChars {
+			text = text[:maxCodeSearchLineChars] + "…"
+		}
+		sb.WriteString(fmt.Sprintf("%s:%d: %s\n", match.Path, match.Line, text))
+	}
+
+	if numMatches == 0 {
+		sb.WriteString("No matches found.\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\n(%d files searched", res.NumFilesSearched))
+	if res.Truncated {
+		sb.WriteString(fmt.Sprintf("; stopped after %d matches—narrow the search with 'paths' or a more specific query to see more", len(res.Matches)))
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
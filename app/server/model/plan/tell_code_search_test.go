@@ -0,0 +1,63 @@
+package plan
+
+import (
+	"plandex-server/syntax/file_map"
+	shared "plandex-shared"
+	"testing"
+)
+
+func TestFormatCodeSearchResult(t *testing.T) {
+	t.Run("text search", func(t *testing.T) {
+		req := &shared.CodeSearchRequest{Kind: shared.CodeSearchKindText, Query: "fetchUser"}
+		res := &shared.RespondCodeSearchRequest{
+			Matches: []shared.CodeSearchMatch{
+				{Path: "src/api.ts", Line: 4, Text: "  export function fetchUser(id: string) {"},
+				{Path: "src/app.ts", Line: 12, Text: "const user = await fetchUser(id);"},
+			},
+			NumFilesSearched: 30,
+			Truncated:        true,
+		}
+
+		want := "src/api.ts:4: export function fetchUser(id: string) {\n" +
+			"src/app.ts:12: const user = await fetchUser(id);\n" +
+			"\n(30 files searched; stopped after 2 matches—narrow the search with 'paths' or a more specific query to see more)"
+
+		if got := formatCodeSearchResult(req, res, nil); got != want {
+			t.Errorf("got:\n%s\n\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("symbol search lists definitions separately from references", func(t *testing.T) {
+		req := &shared.CodeSearchRequest{Kind: shared.CodeSearchKindSymbol, Query: "Start"}
+		res := &shared.RespondCodeSearchRequest{
+			Matches: []shared.CodeSearchMatch{
+				{Path: "main.go", Line: 3, Text: "func Start() {"},
+				{Path: "main.go", Line: 8, Text: "\tStart()"},
+			},
+			NumFilesSearched: 2,
+		}
+		defs := map[string][]file_map.SymbolDefinition{
+			"main.go": {{Type: "function_declaration", Line: 3, Signature: "func Start() {"}},
+		}
+
+		want := "Definitions of Start:\n" +
+			"main.go:3: func Start() { (function_declaration)\n" +
+			"\nReferences:\n" +
+			"main.go:8: Start()\n" +
+			"\n(2 files searched)"
+
+		if got := formatCodeSearchResult(req, res, defs); got != want {
+			t.Errorf("got:\n%s\n\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		req := &shared.CodeSearchRequest{Kind: shared.CodeSearchKindText, Query: "missing"}
+		res := &shared.RespondCodeSearchRequest{NumFilesSearched: 5}
+
+		want := "No matches found.\n\n(5 files searched)"
+		if got := formatCodeSearchResult(req, res, nil); got != want {
+			t.Errorf("got:\n%s\n\nwant:\n%s", got, want)
+		}
+	})
+}
@@ -262,6 +262,9 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		} else if part.ContextType == shared.ContextMapType {
 			fmtStr = "\n\n- %s | map:\n\n```\n%s\n```"
 			args = append(args, part.FilePath, part.Body)
+		} else if part.ContextType == shared.ContextGitDiffType {
+			fmtStr = "\n\n- %s | git diff:\n\n```diff\n%s\n```"
+			args = append(args, part.Name, part.Body)
 		} else if part.Url != "" {
 			fmtStr = "\n\n- %s:\n\n```\n%s\n```"
 			args = append(args, part.Url, part.Body)
@@ -178,6 +178,15 @@ func execTellPlan(params execTellPlanParams) {
 
 	activatePaths, activatePathsOrdered := state.resolveCurrentStage()
 
+	// tools are offered to the planner and coder, but not during the context loading phase
+	isContextPhase := state.currentStage.TellStage == shared.TellStagePlanning && state.currentStage.PlanningPhase == shared.PlanningPhaseContext
+	if !isContextPhase {
+		state.loadMcpTools()
+	}
+
+	// code search is offered to the planner in both phases so that it can find callers and implementations while choosing context
+	state.codeSearchEnabled = req.CodeSearchEnabled && state.currentStage.TellStage == shared.TellStagePlanning
+
 	var tentativeModelConfig shared.ModelRoleConfig
 	var tentativeMaxTokens int
 	if state.currentStage.TellStage == shared.TellStagePlanning {
@@ -224,15 +233,27 @@ func execTellPlan(params execTellPlanParams) {
 		// add the shared context between planning and context phases first so it can be cached
 		// for chat-only requests, do NOT include the heavy project map to keep the request small/cost-effective
 		// auto contexts will be added later if enabled
+		omitMaps := state.shouldOmitMaps()
 		planStageSharedMsgs = state.formatModelContext(formatModelContextParams{
-			includeMaps:         !req.IsChatOnly,
+			includeMaps:         !req.IsChatOnly && !omitMaps,
 			smartContextEnabled: req.SmartContext,
 			includeApplyScript:  req.ExecEnabled,
 			baseOnly:            true,
 			cacheControl:        true,
 		})
 
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext && !req.IsChatOnly {
+			// with semantic retrieval enabled, code relevant to the prompt is added after the auto context instructions, along with the project's paths if the map was left out
+			msg := types.ExtendedChatMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: []types.ExtendedChatMessagePart{},
+			}
+			for _, part := range planStageSharedMsgs {
+				msg.Content = append(msg.Content, *part)
+			}
+			tokensRemaining := tentativeMaxTokens - (model.GetMessagesTokenEstimate(msg) + tokensWithoutContext)
+			planningPhaseOnlyMsgs = state.formatSemanticContext(omitMaps, int(float64(tokensRemaining)*0.95))
+		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
 			if req.AutoContext {
 				msg := types.ExtendedChatMessage{
 					Role:    openai.ChatMessageRoleSystem,
@@ -318,9 +339,10 @@ func execTellPlan(params execTellPlanParams) {
 
 	// log.Println("promptMessage:", spew.Sdump(promptMessage))
 
+	tokenizer := state.stageTokenizer()
 	state.tokensBeforeConvo =
-		model.GetMessagesTokenEstimate(state.messages...) +
-			model.GetMessagesTokenEstimate(*promptMessage) +
+		model.GetMessagesTokenCount(tokenizer, state.messages...) +
+			model.GetMessagesTokenCount(tokenizer, *promptMessage) +
 			state.latestSummaryTokens +
 			model.TokensPerRequest
 
@@ -387,21 +409,25 @@ func execTellPlan(params execTellPlanParams) {
 	// 	log.Printf("%s: %v\n", message.Role, message.Content)
 	// }
 
-	requestTokens := model.GetMessagesTokenEstimate(state.messages...) + model.TokensPerRequest
-	state.totalRequestTokens = requestTokens
+	countRequestTokens := func(tokenizer shared.Tokenizer) int {
+		return model.GetMessagesTokenCount(tokenizer, state.messages...) + state.toolsTokens(tokenizer) + model.TokensPerRequest
+	}
 
 	modelConfig := tentativeModelConfig
 
 	if state.currentStage.TellStage == shared.TellStagePlanning {
 		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
-			modelConfig = state.settings.GetModelPack().GetArchitect().GetRoleForInputTokens(requestTokens, state.settings)
+			modelConfig = state.settings.GetModelPack().GetArchitect().GetRoleForInputTokenCounter(countRequestTokens, state.settings)
 		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
-			modelConfig = state.settings.GetModelPack().Planner.GetRoleForInputTokens(requestTokens, state.settings)
+			modelConfig = state.settings.GetModelPack().Planner.GetRoleForInputTokenCounter(countRequestTokens, state.settings)
 		}
 	} else if state.currentStage.TellStage == shared.TellStageImplementation {
-		modelConfig = state.settings.GetModelPack().GetCoder().GetRoleForInputTokens(requestTokens, state.settings)
+		modelConfig = state.settings.GetModelPack().GetCoder().GetRoleForInputTokenCounter(countRequestTokens, state.settings)
 	}
 
+	requestTokens := countRequestTokens(modelConfig.GetTokenizer(state.settings))
+	state.totalRequestTokens = requestTokens
+
 	state.modelConfig = &modelConfig
 
 	baseModelConfig := modelConfig.GetBaseModelConfig(authVars, state.settings, state.orgUserConfig)
@@ -529,6 +555,13 @@ func (state *activeTellStreamState) doTellRequest() {
 		TopP:        modelConfig.TopP,
 	}
 
+	if tools := state.tools(); len(tools) > 0 {
+		modelReq.Tools = tools
+		if state.numToolRounds >= MaxToolRounds {
+			modelReq.ToolChoice = "none"
+		}
+	}
+
 	if baseModelConfig.StopDisabled {
 		state.manualStop = stop
 	} else {
@@ -636,9 +669,10 @@ func (state *activeTellStreamState) dryRunCalculateTokensWithoutContext(tentativ
 		return false, 0
 	}
 
+	tokenizer := clone.stageTokenizer()
 	clone.tokensBeforeConvo =
-		model.GetMessagesTokenEstimate(clone.messages...) +
-			model.GetMessagesTokenEstimate(*promptMessage) +
+		model.GetMessagesTokenCount(tokenizer, clone.messages...) +
+			model.GetMessagesTokenCount(tokenizer, *promptMessage) +
 			clone.latestSummaryTokens +
 			model.TokensPerRequest
 
@@ -671,5 +705,15 @@ func (state *activeTellStreamState) dryRunCalculateTokensWithoutContext(tentativ
 
 	clone.messages = append(clone.messages, *promptMessage)
 
-	return true, model.GetMessagesTokenEstimate(clone.messages...) + model.TokensPerRequest
+	return true, model.GetMessagesTokenCount(tokenizer, clone.messages...) + model.TokensPerRequest
+}
+
+// stageTokenizer returns the tokenizer for the model whose limit applies to the current stage—see the effectiveMaxTokens checks above
+func (state *activeTellStreamState) stageTokenizer() shared.Tokenizer {
+	if state.currentStage.TellStage == shared.TellStagePlanning && state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
+		return state.settings.GetArchitectTokenizer()
+	} else if state.currentStage.TellStage == shared.TellStageImplementation {
+		return state.settings.GetCoderTokenizer()
+	}
+	return state.settings.GetPlannerTokenizer()
 }
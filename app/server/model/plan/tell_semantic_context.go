@@ -0,0 +1,127 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plandex-server/model"
+	"plandex-server/model/prompts"
+	"plandex-server/types"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// when the project map takes up more than this share of the context loading budget, it's left out and the architect relies on retrieved code and the list of project paths instead
+	maxMapShareWithSemanticContext = 0.5
+
+	semanticContextMaxTokens = 20000
+	semanticContextTopK      = 40
+	semanticSearchTimeout    = 30 * time.Second
+)
+
+// shouldOmitMaps is true when semantic retrieval is enabled and the project map is too large to leave room for retrieved code in the context loading phase
+func (state *activeTellStreamState) shouldOmitMaps() bool {
+	if !state.settings.SemanticRetrievalEnabled() || !state.req.AutoContext || state.req.IsChatOnly {
+		return false
+	}
+
+	mapTokens := 0
+	for _, context := range state.modelContext {
+		if context.ContextType == shared.ContextMapType {
+			mapTokens += context.NumTokens
+		}
+	}
+
+	return float64(mapTokens) > float64(state.settings.GetArchitectEffectiveMaxTokens())*maxMapShareWithSemanticContext
+}
+
+// formatSemanticContext searches the project's semantic index with the user's prompt and formats the most relevant chunks for the context loading phase. If the project map was omitted, the mapped paths are listed too so the architect can still choose files outside the retrieved chunks. Errors are logged and result in no semantic context rather than failing the request.
+func (state *activeTellStreamState) formatSemanticContext(mapsOmitted bool, maxTokens int) []*types.ExtendedChatMessagePart {
+	if !state.settings.SemanticRetrievalEnabled() || state.req.Prompt == "" {
+		return nil
+	}
+
+	pathTokens := map[string]int{}
+	for _, context := range state.modelContext {
+		if context.ContextType != shared.ContextMapType {
+			continue
+		}
+		for path := range context.MapShas {
+			pathTokens[path] = context.MapTokens[path]
+		}
+	}
+	if len(pathTokens) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(pathTokens))
+	for path := range pathTokens {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var pathsList string
+	if mapsOmitted {
+		var b strings.Builder
+		b.WriteString(prompts.SemanticContextPathsPrompt)
+		for _, path := range paths {
+			fmt.Fprintf(&b, "- %s (%d 🪙)\n", path, pathTokens[path])
+		}
+		pathsList = b.String()
+	}
+
+	searchMaxTokens := min(semanticContextMaxTokens, maxTokens-shared.GetNumTokensEstimate(pathsList))
+	if searchMaxTokens <= 0 {
+		log.Println("formatSemanticContext - no room for semantic context")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(state.activePlan.Ctx, semanticSearchTimeout)
+	defer cancel()
+
+	results, err := model.SearchSemanticIndex(ctx, model.SemanticSearchParams{
+		SemanticIndexParams: model.SemanticIndexParams{
+			Clients:       state.clients,
+			AuthVars:      state.authVars,
+			Auth:          state.auth,
+			Plan:          state.plan,
+			Settings:      state.settings,
+			OrgUserConfig: state.orgUserConfig,
+			SessionId:     state.req.SessionId,
+		},
+		Query:     state.req.Prompt,
+		Paths:     paths,
+		TopK:      semanticContextTopK,
+		MaxTokens: searchMaxTokens,
+	})
+	if err != nil {
+		log.Printf("formatSemanticContext - error searching semantic index: %v\n", err)
+		return nil
+	}
+
+	log.Printf("formatSemanticContext - %d results, maps omitted: %t\n", len(results), mapsOmitted)
+
+	if len(results) == 0 && pathsList == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(prompts.SemanticContextPrompt)
+	for _, result := range results {
+		fmt.Fprintf(&b, "- `%s` (lines %d-%d):\n\n```\n%s\n```\n\n", result.Path, result.StartLine, result.EndLine, result.Text)
+	}
+	b.WriteString(pathsList)
+
+	return []*types.ExtendedChatMessagePart{
+		{
+			Type: openai.ChatMessagePartTypeText,
+			Text: b.String(),
+		},
+	}
+}
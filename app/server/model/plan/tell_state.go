@@ -2,6 +2,7 @@ package plan
 
 import (
 	"plandex-server/db"
+	"plandex-server/mcp"
 	"plandex-server/model"
 	"plandex-server/types"
 	"time"
@@ -61,6 +62,14 @@ type activeTellStreamState struct {
 
 	manualStop []string
 
+	mcpTools               *mcp.ToolSet
+	codeSearchEnabled      bool
+	pendingToolCalls       []openai.ToolCall
+	toolCalls              []*shared.ConvoToolCall
+	numToolRounds          int
+	toolRoundReplyOffset   int
+	resumingAfterToolCalls bool
+
 	numErrorRetry       int
 	numFallbackRetry    int
 	modelErr            *shared.ModelError
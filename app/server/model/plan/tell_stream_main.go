@@ -45,15 +45,11 @@ func (state *activeTellStreamState) listenStream(stream *model.ExtendedChatCompl
 		}
 	}()
 
-	state.chunkProcessor = &chunkProcessor{
-		replyOperations:                 []*shared.Operation{},
-		chunksReceived:                  0,
-		maybeRedundantOpeningTagContent: "",
-		fileOpen:                        false,
-		contentBuffer:                   "",
-		awaitingBlockOpeningTag:         false,
-		awaitingBlockClosingTag:         false,
-		awaitingBackticks:               false,
+	// after tool calls, the model continues the same reply, so keep the processor's state
+	if state.resumingAfterToolCalls && state.chunkProcessor != nil {
+		state.resumingAfterToolCalls = false
+	} else {
+		state.initChunkProcessor()
 	}
 
 	// Create a timer that will trigger if no chunk is received within the specified duration
@@ -62,6 +58,7 @@ func (state *activeTellStreamState) listenStream(stream *model.ExtendedChatCompl
 	timer := time.NewTimer(firstTokenTimeout)
 	defer timer.Stop()
 	streamFinished := false
+	awaitingToolCalls := false
 
 	baseModelConfig := state.modelConfig.GetBaseModelConfig(state.authVars, state.settings, state.orgUserConfig)
 
@@ -97,6 +94,9 @@ mainLoop:
 			if streamFinished {
 				log.Println("Tell stream finished—timed out waiting for usage chunk")
 				state.execHookOnStop(false)
+				if awaitingToolCalls {
+					state.execToolCalls()
+				}
 				return
 			} else {
 				res := state.onError(onErrorParams{
@@ -182,6 +182,9 @@ mainLoop:
 			if len(response.Choices) == 0 {
 				if response.Usage != nil {
 					state.handleUsageChunk(response.Usage)
+					if awaitingToolCalls {
+						state.execToolCalls()
+					}
 					return
 				}
 
@@ -206,6 +209,10 @@ mainLoop:
 
 			choice := response.Choices[0]
 
+			if len(choice.Delta.ToolCalls) > 0 && len(state.tools()) > 0 {
+				state.accumulateToolCallDeltas(choice.Delta.ToolCalls)
+			}
+
 			processChunkRes := state.processChunk(choice)
 			if processChunkRes.shouldReturn {
 				return
@@ -269,6 +276,25 @@ mainLoop:
 					}
 				}
 
+				if len(state.pendingToolCalls) > 0 {
+					log.Printf("Model stream stopped to call %d tools\n", len(state.pendingToolCalls))
+
+					if response.Usage != nil {
+						state.handleUsageChunk(response.Usage)
+						state.execToolCalls()
+						return
+					}
+
+					// wait for the usage chunk before calling tools
+					if !timer.Stop() {
+						<-timer.C
+					}
+					timer.Reset(model.USAGE_CHUNK_TIMEOUT)
+					streamFinished = true
+					awaitingToolCalls = true
+					continue
+				}
+
 				res := handleFinished()
 				if res.shouldReturn {
 					return
@@ -305,3 +331,16 @@ func firstTokenTimeout(tok int, isLocalModel bool) time.Duration {
 	}
 	return base + extra
 }
+
+func (state *activeTellStreamState) initChunkProcessor() {
+	state.chunkProcessor = &chunkProcessor{
+		replyOperations:                 []*shared.Operation{},
+		chunksReceived:                  0,
+		maybeRedundantOpeningTagContent: "",
+		fileOpen:                        false,
+		contentBuffer:                   "",
+		awaitingBlockOpeningTag:         false,
+		awaitingBlockClosingTag:         false,
+		awaitingBackticks:               false,
+	}
+}
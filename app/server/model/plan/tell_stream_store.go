@@ -269,6 +269,11 @@ func (state *activeTellStreamState) storeAssistantReply(repo *db.GitRepo, params
 
 	// fmt.Println("raw message: ", activePlan.CurrentReplyContent)
 
+	if len(state.toolCalls) > 0 {
+		flags.DidCallTools = true
+		replyNumTokens += shared.GetNumTokensEstimate(formatConvoToolCalls(state.toolCalls))
+	}
+
 	assistantMsg := db.ConvoMessage{
 		Id:                    replyId,
 		OrgId:                 currentOrgId,
@@ -284,6 +289,7 @@ func (state *activeTellStreamState) storeAssistantReply(repo *db.GitRepo, params
 		ActivatedPaths:        activatePaths,
 		ActivatedPathsOrdered: activatePathsOrdered,
 		RemovedSubtasks:       removedSubtasks,
+		ToolCalls:             state.toolCalls,
 	}
 
 	commitMsg, err := db.StoreConvoMessage(repo, &assistantMsg, auth.User.Id, branch, false)
@@ -11,6 +11,7 @@ import (
 	"plandex-server/model/prompts"
 	"plandex-server/notify"
 	"plandex-server/types"
+	"sync"
 	"time"
 
 	shared "plandex-shared"
@@ -37,11 +38,13 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 		return false
 	}
 
+	tokenizer := state.settings.GetPlannerTokenizer()
+
 	conversationTokens := 0
 	tokensUpToTimestamp := make(map[int64]int)
 	convoMessagesById := make(map[string]*db.ConvoMessage)
 	for _, convoMessage := range convo {
-		conversationTokens += convoMessage.Tokens + model.TokensPerMessage + model.TokensPerName
+		conversationTokens += convoMessageTokens(tokenizer, convoMessage) + model.TokensPerMessage + model.TokensPerName
 		timestamp := convoMessage.CreatedAt.UnixNano() / int64(time.Millisecond)
 		tokensUpToTimestamp[timestamp] = conversationTokens
 		convoMessagesById[convoMessage.Id] = convoMessage
@@ -106,7 +109,7 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 				}
 			}
 
-			updatedConversationTokens := (conversationTokens - tokens) + s.Tokens
+			updatedConversationTokens := (conversationTokens - tokens) + convoSummaryTokens(tokenizer, s)
 			savedTokens := conversationTokens - updatedConversationTokens
 
 			log.Printf("Conversation summary tokens: %d\n", tokens)
@@ -153,7 +156,7 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 				Content: []types.ExtendedChatMessagePart{
 					{
 						Type: openai.ChatMessagePartTypeText,
-						Text: convoMessage.Message,
+						Text: convoMessage.Message + formatConvoToolCalls(convoMessage.ToolCalls),
 					},
 				},
 			})
@@ -206,7 +209,7 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 					Content: []types.ExtendedChatMessagePart{
 						{
 							Type: openai.ChatMessagePartTypeText,
-							Text: convoMessage.Message,
+							Text: convoMessage.Message + formatConvoToolCalls(convoMessage.ToolCalls),
 						},
 					},
 				})
@@ -304,7 +307,7 @@ func summarizeConvo(clients map[string]model.ClientInfo, authVars map[string]str
 				Content: []types.ExtendedChatMessagePart{
 					{
 						Type: openai.ChatMessagePartTypeText,
-						Text: convoMessage.Message,
+						Text: convoMessage.Message + formatConvoToolCalls(convoMessage.ToolCalls),
 					},
 				},
 			})
@@ -338,7 +341,7 @@ func summarizeConvo(clients map[string]model.ClientInfo, authVars map[string]str
 					Content: []types.ExtendedChatMessagePart{
 						{
 							Type: openai.ChatMessagePartTypeText,
-							Text: convoMessage.Message,
+							Text: convoMessage.Message + formatConvoToolCalls(convoMessage.ToolCalls),
 						},
 					},
 				})
@@ -367,7 +370,7 @@ func summarizeConvo(clients map[string]model.ClientInfo, authVars map[string]str
 				},
 			})
 
-			tokens := shared.GetNumTokensEstimate(userPrompt)
+			tokens := settings.GetPlannerTokenizer().CountTokens(userPrompt)
 			numTokens += tokens + model.TokensPerMessage + model.TokensPerName
 		}
 	}
@@ -432,3 +435,54 @@ func summarizeConvo(clients map[string]model.ClientInfo, authVars map[string]str
 
 	return nil
 }
+
+// convo messages don't change once stored, so counts for non-default tokenizers are cached per message to avoid re-tokenizing the whole history on every tell
+const maxConvoTokenCacheEntries = 50000
+
+type convoTokenCacheKey struct {
+	tokenizer shared.TokenizerName
+	messageId string
+	numBytes  int
+}
+
+var convoTokenCache = map[convoTokenCacheKey]int{}
+var convoTokenCacheMu sync.Mutex
+
+// stored token counts use the default tokenizer (and already include tool call text), so they're only recounted when the planner's tokenizer differs
+func convoMessageTokens(tokenizer shared.Tokenizer, convoMessage *db.ConvoMessage) int {
+	if tokenizer.Name() == shared.DefaultTokenizerName {
+		return convoMessage.Tokens
+	}
+
+	text := convoMessage.Message + formatConvoToolCalls(convoMessage.ToolCalls)
+	key := convoTokenCacheKey{
+		tokenizer: tokenizer.Name(),
+		messageId: convoMessage.Id,
+		numBytes:  len(text),
+	}
+
+	convoTokenCacheMu.Lock()
+	numTokens, ok := convoTokenCache[key]
+	convoTokenCacheMu.Unlock()
+	if ok {
+		return numTokens
+	}
+
+	numTokens = tokenizer.CountTokens(text)
+
+	convoTokenCacheMu.Lock()
+	if len(convoTokenCache) >= maxConvoTokenCacheEntries {
+		convoTokenCache = map[convoTokenCacheKey]int{}
+	}
+	convoTokenCache[key] = numTokens
+	convoTokenCacheMu.Unlock()
+
+	return numTokens
+}
+
+func convoSummaryTokens(tokenizer shared.Tokenizer, summary *db.ConvoSummary) int {
+	if tokenizer.Name() == shared.DefaultTokenizerName {
+		return summary.Tokens
+	}
+	return tokenizer.CountTokens(summary.Summary)
+}
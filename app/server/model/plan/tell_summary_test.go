@@ -0,0 +1,50 @@
+package plan
+
+import (
+	"plandex-server/db"
+	shared "plandex-shared"
+	"testing"
+)
+
+type countingTokenizer struct {
+	calls int
+}
+
+func (t *countingTokenizer) Name() shared.TokenizerName {
+	return shared.TokenizerCl100kBase
+}
+
+func (t *countingTokenizer) CountTokens(text string) int {
+	t.calls++
+	return len(text)
+}
+
+func TestConvoMessageTokens(t *testing.T) {
+	t.Run("non-default tokenizer counts each message once and includes tool calls", func(t *testing.T) {
+		tokenizer := &countingTokenizer{}
+		msg := &db.ConvoMessage{
+			Id:      "convo-message-tokens-test",
+			Message: "hello",
+			ToolCalls: []*shared.ConvoToolCall{
+				{Server: "docs", Tool: "search", Arguments: `{"q":"x"}`, Result: "found"},
+			},
+		}
+
+		want := len(msg.Message + formatConvoToolCalls(msg.ToolCalls))
+		for i := 0; i < 3; i++ {
+			if got := convoMessageTokens(tokenizer, msg); got != want {
+				t.Fatalf("got %d tokens, want %d", got, want)
+			}
+		}
+		if tokenizer.calls != 1 {
+			t.Errorf("tokenizer called %d times, want 1", tokenizer.calls)
+		}
+	})
+
+	t.Run("default tokenizer uses the stored count", func(t *testing.T) {
+		msg := &db.ConvoMessage{Id: "convo-message-tokens-default", Message: "hello", Tokens: 42}
+		if got := convoMessageTokens(shared.GetDefaultTokenizer(), msg); got != 42 {
+			t.Errorf("got %d tokens, want 42", got)
+		}
+	})
+}
@@ -179,5 +179,26 @@ func (state *activeTellStreamState) getTellSysPrompt(params getTellSysPromptPara
 		}
 	}
 
+	if len(req.RepoRoots) > 0 {
+		sysParts = append(sysParts, types.ExtendedChatMessagePart{
+			Type: openai.ChatMessagePartTypeText,
+			Text: prompts.GetRepoRootsPrompt(req.RepoRoots, req.ExecEnabled),
+		})
+	}
+
+	if state.codeSearchEnabled {
+		sysParts = append(sysParts, types.ExtendedChatMessagePart{
+			Type: openai.ChatMessagePartTypeText,
+			Text: prompts.CodeSearchPrompt,
+		})
+	}
+
+	if state.mcpTools != nil {
+		sysParts = append(sysParts, types.ExtendedChatMessagePart{
+			Type: openai.ChatMessagePartTypeText,
+			Text: prompts.McpToolsPrompt,
+		})
+	}
+
 	return sysParts, nil
 }
@@ -0,0 +1,252 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/mcp"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/sashabaranov/go-openai"
+)
+
+// MaxToolRounds caps how many times a single reply can stop to call tools. After the cap, tools stay defined (some providers require it when the messages include tool calls) but the model can't call them.
+const MaxToolRounds = 10
+
+// max length of each tool result when it's included with a convo message in later requests
+const maxConvoToolResultChars = 2000
+
+func (state *activeTellStreamState) loadMcpTools() {
+	active := state.activePlan
+
+	toolSet, err := mcp.LoadToolSet(active.Ctx, state.currentOrgId, state.plan.ProjectId)
+	if err != nil {
+		log.Printf("Error loading MCP tools: %v\n", err)
+		return
+	}
+
+	if toolSet == nil {
+		return
+	}
+
+	log.Printf("Loaded %d MCP tools\n", len(toolSet.Tools))
+	state.mcpTools = toolSet
+}
+
+func (state *activeTellStreamState) toolsTokens(tokenizer shared.Tokenizer) int {
+	tools := state.tools()
+	if len(tools) == 0 {
+		return 0
+	}
+	bytes, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return tokenizer.CountTokens(string(bytes))
+}
+
+// accumulateToolCallDeltas merges streamed tool call fragments. The first fragment of each call has its id and name, later ones add to the arguments.
+func (state *activeTellStreamState) accumulateToolCallDeltas(deltas []openai.ToolCall) {
+	for _, delta := range deltas {
+		idx := -1
+		if delta.Index != nil {
+			idx = *delta.Index
+		} else if delta.ID != "" {
+			for i, call := range state.pendingToolCalls {
+				if call.ID == delta.ID {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				idx = len(state.pendingToolCalls)
+			}
+		} else if len(state.pendingToolCalls) > 0 {
+			idx = len(state.pendingToolCalls) - 1
+		} else {
+			idx = 0
+		}
+
+		for len(state.pendingToolCalls) <= idx {
+			state.pendingToolCalls = append(state.pendingToolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		}
+
+		call := &state.pendingToolCalls[idx]
+		if delta.ID != "" {
+			call.ID = delta.ID
+		}
+		if delta.Function.Name != "" {
+			call.Function.Name = delta.Function.Name
+		}
+		call.Function.Arguments += delta.Function.Arguments
+	}
+}
+
+// execToolCalls runs the tool calls from the model's last response, streams them to the client, and continues the reply with the results
+func (state *activeTellStreamState) execToolCalls() {
+	active := GetActivePlan(state.plan.Id, state.branch)
+	if active == nil {
+		state.onActivePlanMissingError()
+		return
+	}
+
+	calls := state.pendingToolCalls
+	state.pendingToolCalls = nil
+	state.numToolRounds++
+
+	// streamed indexes aren't valid in requests and some providers don't set ids
+	for i := range calls {
+		calls[i].Index = nil
+		if calls[i].ID == "" {
+			calls[i].ID = "call_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		}
+	}
+
+	log.Printf("execToolCalls - round %d, %d calls\n", state.numToolRounds, len(calls))
+
+	assistantMsg := types.ExtendedChatMessage{
+		Role:      openai.ChatMessageRoleAssistant,
+		ToolCalls: calls,
+	}
+	if len(active.CurrentReplyContent) > state.toolRoundReplyOffset {
+		roundReply := active.CurrentReplyContent[state.toolRoundReplyOffset:]
+		if strings.TrimSpace(roundReply) != "" {
+			assistantMsg.Content = []types.ExtendedChatMessagePart{
+				{
+					Type: openai.ChatMessagePartTypeText,
+					Text: roundReply,
+				},
+			}
+		}
+	}
+	newMessages := []types.ExtendedChatMessage{assistantMsg}
+
+	for _, call := range calls {
+		isCodeSearch := state.codeSearchEnabled && call.Function.Name == codeSearchToolName
+
+		var serverName string
+		toolName := call.Function.Name
+		if !isCodeSearch && state.mcpTools != nil {
+			if s, t, ok := state.mcpTools.Resolve(call.Function.Name); ok {
+				serverName, toolName = s, t
+			}
+		}
+
+		record := &shared.ConvoToolCall{
+			Id:        call.ID,
+			Server:    serverName,
+			Tool:      toolName,
+			Arguments: call.Function.Arguments,
+			StartedAt: time.Now(),
+		}
+
+		started := *record
+		active.Stream(shared.StreamMessage{
+			Type:     shared.StreamMessageToolCall,
+			ToolCall: &started,
+		})
+
+		var res mcp.CallResult
+		if isCodeSearch {
+			res = state.execCodeSearch(active, call.Function.Arguments)
+		} else if state.mcpTools != nil {
+			res = state.mcpTools.Call(active.Ctx, call.Function.Name, call.Function.Arguments)
+		} else {
+			res = mcp.CallResult{Tool: toolName, Text: fmt.Sprintf("Unknown tool: %s", toolName), IsError: true}
+		}
+
+		if active.Ctx.Err() != nil {
+			log.Println("execToolCalls - context canceled while calling tools")
+			return
+		}
+
+		record.Result = res.Text
+		record.IsError = res.IsError
+		record.Finished = true
+		record.FinishedAt = time.Now()
+
+		finished := *record
+		active.Stream(shared.StreamMessage{
+			Type:     shared.StreamMessageToolCall,
+			ToolCall: &finished,
+		})
+
+		state.toolCalls = append(state.toolCalls, record)
+
+		text := res.Text
+		if text == "" {
+			text = "(no output)"
+		}
+		if res.IsError {
+			text = "Error: " + text
+		}
+
+		newMessages = append(newMessages, types.ExtendedChatMessage{
+			Role:       openai.ChatMessageRoleTool,
+			ToolCallID: call.ID,
+			Content: []types.ExtendedChatMessagePart{
+				{
+					Type: openai.ChatMessagePartTypeText,
+					Text: text,
+				},
+			},
+		})
+	}
+
+	active.FlushStreamBuffer()
+
+	state.messages = append(state.messages, newMessages...)
+	state.totalRequestTokens += state.modelConfig.GetTokenizer(state.settings).CountTokens(formatToolMessagesForCount(newMessages))
+
+	state.toolRoundReplyOffset = len(active.CurrentReplyContent)
+	state.resumingAfterToolCalls = true
+	state.generationId = ""
+	state.firstTokenAt = time.Time{}
+
+	state.doTellRequest()
+}
+
+func formatToolMessagesForCount(messages []types.ExtendedChatMessage) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			sb.WriteString(part.Text)
+		}
+		for _, call := range msg.ToolCalls {
+			sb.WriteString(call.Function.Name)
+			sb.WriteString(call.Function.Arguments)
+		}
+	}
+	return sb.String()
+}
+
+// formatConvoToolCalls renders a stored reply's tool calls so they stay in context for later requests, with results shortened
+func formatConvoToolCalls(toolCalls []*shared.ConvoToolCall) string {
+	if len(toolCalls) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n[Tool calls made during this response]\n")
+	for _, call := range toolCalls {
+		result := call.Result
+		if len(result) > maxConvoToolResultChars {
+			result = result[:maxConvoToolResultChars] + "\n[truncated]"
+		}
+		status := "result"
+		if call.IsError {
+			status = "error"
+		}
+		name := call.Tool
+		if call.Server != "" {
+			name = call.Server + "/" + call.Tool
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s(%s)\n  %s:\n%s\n", name, call.Arguments, status, result))
+	}
+	return sb.String()
+}
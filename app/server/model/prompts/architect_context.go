@@ -325,3 +325,15 @@ Important: your response should address the user! Don't say things like "The use
 
 	return s
 }
+
+const SemanticContextPrompt = `
+[RETRIEVED CODE]
+The following code snippets were retrieved from the project because they are semantically similar to the user's latest prompt. They are excerpts, not complete files, and are ordered from most to least relevant. Use them alongside the codebase map to decide which files to load. Every file that a snippet comes from is part of the project, so you can include it in the '### Files' section even though it isn't listed in the codebase map.
+
+`
+
+const SemanticContextPathsPrompt = `
+[PROJECT FILES]
+The project is too large to include the full codebase map, so only the paths of the files in the project are listed below, along with the number of tokens in each file. These paths count as files in the codebase map, so you can include any of them in the '### Files' section. Judge the relevance of files without retrieved snippets based on their paths and names.
+
+`
@@ -16,12 +16,14 @@ type ValidationPromptParams struct {
 	Diff                 string
 	Reasons              []syntax.NeedsVerifyReason
 	SyntaxErrors         []string
+	LspErrors            []string
 }
 
 // GetValidationReplacementsXmlPrompt constructs the complete prompt string for XML responses.
 func GetValidationReplacementsXmlPrompt(params ValidationPromptParams) (string, int) {
 	reasons := params.Reasons
 	syntaxErrs := params.SyntaxErrors
+	lspErrs := params.LspErrors
 	path := params.Path
 	originalWithLineNums := params.OriginalWithLineNums
 	desc := params.Desc
@@ -64,6 +66,13 @@ Diff of applied changes:
 		))
 	}
 
+	if len(lspErrs) > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"The language server reported errors in the file after the changes were applied:\n%s\n\nThese errors were NOT present in the original file, so they were introduced by the changes. The changes are NOT correct until these errors are resolved. Include an assessment of what caused these errors.",
+			strings.Join(lspErrs, "\n"),
+		))
+	}
+
 	s += strings.Join(parts, "\n\n")
 
 	s += `
@@ -71,7 +80,7 @@ Diff of applied changes:
 
 Your first task is to examine whether the changes were applied as described in the proposed changes explanation. Do NOT evaluate:
 - Code quality
-- Missing imports
+- Missing imports (unless language server errors have been previously specified)
 - Unused variables
 - Best practices
 - Potential bugs
@@ -83,6 +92,7 @@ b. Whether the changes included *all* the specified additions/modifications
 c. Whether *any* unintended changes were made to surrounding code
 d. Whether *any* specified code was accidentally removed or duplicated
 e. Any syntax errors that have been previously specified
+f. Any language server errors that have been previously specified
 
 --
 
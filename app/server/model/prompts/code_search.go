@@ -0,0 +1,14 @@
+package prompts
+
+const CodeSearchPrompt = `
+[CODE SEARCH]
+
+You can search the user's project with the search_code tool. It searches every file in the project that isn't ignored, including files that aren't loaded into context.
+
+- Use kind 'symbol' to find where a function, method, type, class, or variable is defined and where it's referenced. Use kind 'text' to find lines matching a string or a regex (set isRegex), like ripgrep.
+- Search when you need to know where something is defined or used—the callers of a function, the implementations of an interface, the places a config value is read—rather than guessing or loading whole directories to look for it.
+- Limit a search with 'paths' when you know which files or directories are relevant.
+- You can search before or in the middle of your response. Results are returned to you and you then continue your response from where you left off. Don't repeat what you've already written.
+- Results only include matching lines, not whole files. If you need a file's full contents to plan or make changes, load it into context as described in your instructions.
+- Don't run the same search more than once.
+`
@@ -0,0 +1,13 @@
+package prompts
+
+const McpToolsPrompt = `
+[EXTERNAL TOOLS]
+
+You have access to tools from the MCP servers the user has configured for this project. Each tool's description says which server it comes from.
+
+- Call a tool when it can give you information you need—a database schema, a ticket's details, internal docs—rather than guessing or asking the user for something you could look up yourself.
+- You can call tools before or in the middle of your response. Tool results are returned to you and you then continue your response from where you left off. Don't repeat what you've already written.
+- Only call tools that are relevant to the user's request. Don't call the same tool with the same arguments more than once.
+- If a tool call fails, decide whether you can continue without it. Don't retry a failing call more than once.
+- Tools can only provide information. Any changes to the project must still be made with code blocks and _apply.sh as described in your instructions.
+`
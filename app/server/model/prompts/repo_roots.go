@@ -0,0 +1,36 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+const repoRootsPrompt = `
+[MULTI-REPO PROJECT]
+
+This project spans multiple repositories. Files in the main project root have plain paths. Files in the project's other repositories have paths that start with '@<alias>/', where the alias names the repository. The project's other repositories are:
+
+%s
+
+- Use the full '@<alias>/' path for every file in another repository—in code block file paths, when loading context, and when referring to files in your response.
+- Only create files under '@<alias>/' paths for the repositories listed above. Never add a '@' prefix to a path in the main project root.
+- A task will often need changes in more than one repository. Make all of them as part of the same plan.
+`
+
+const repoRootsExecPrompt = `
+- _apply.sh runs from the main project root. The absolute path of each other repository is in the environment variable shown next to its alias above. To run commands in another repository, change into it in a subshell, e.g. '(cd "$PLANDEX_ROOT_FRONTEND" && npm install)'. Do NOT use '@<alias>/' paths in _apply.sh—they only exist in Plandex, not on the file system.
+`
+
+// GetRepoRootsPrompt explains '@<alias>/' paths for a multi-repo project. Each root is described as '@<alias> ($PLANDEX_ROOT_<ALIAS>)'.
+func GetRepoRootsPrompt(roots []string, execMode bool) string {
+	var list []string
+	for _, root := range roots {
+		list = append(list, "- "+root)
+	}
+
+	prompt := fmt.Sprintf(repoRootsPrompt, strings.Join(list, "\n"))
+	if execMode {
+		prompt += repoRootsExecPrompt
+	}
+	return prompt
+}
@@ -0,0 +1,378 @@
+package model
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/types"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// Requests to the same provider with the same api key share a limiter, so that builds fanning out across many files don't run into the provider's rate limits. Requests over the limit wait in a queue, and requests the user is waiting on directly (planning, implementation) are sent before builds and other background requests.
+
+type rateLimitPriority int
+
+const (
+	rateLimitPriorityHigh rateLimitPriority = iota
+	rateLimitPriorityNormal
+	rateLimitPriorityLow
+)
+
+func rateLimitPriorityForRole(role shared.ModelRole) rateLimitPriority {
+	switch role {
+	case shared.ModelRolePlanner, shared.ModelRoleArchitect, shared.ModelRoleCoder:
+		return rateLimitPriorityHigh
+	case shared.ModelRoleBuilder, shared.ModelRoleWholeFileBuilder, shared.ModelRoleEmbeddings:
+		return rateLimitPriorityLow
+	default:
+		return rateLimitPriorityNormal
+	}
+}
+
+// rate limits for built-in providers can be set on the server with a JSON object keyed by provider, e.g. {"openai": {"requestsPerMinute": 500, "tokensPerMinute": 200000}}
+// custom providers are keyed by "custom|<name>", though limits for them are usually set in the custom provider config instead
+const providerRateLimitsEnvVar = "PROVIDER_RATE_LIMITS"
+
+var envRateLimitsOnce sync.Once
+var envRateLimits map[string]shared.ModelProviderRateLimits
+
+func getEnvRateLimits() map[string]shared.ModelProviderRateLimits {
+	envRateLimitsOnce.Do(func() {
+		v := os.Getenv(providerRateLimitsEnvVar)
+		if v == "" {
+			return
+		}
+		err := json.Unmarshal([]byte(v), &envRateLimits)
+		if err != nil {
+			log.Printf("Error parsing %s - provider rate limits from the environment will be ignored: %v\n", providerRateLimitsEnvVar, err)
+			envRateLimits = nil
+		}
+	})
+	return envRateLimits
+}
+
+// limits from the environment take precedence over the provider config
+func resolveRateLimits(providerConfig shared.ModelProviderConfigSchema) shared.ModelProviderRateLimits {
+	if limits, ok := getEnvRateLimits()[providerConfig.ToComposite()]; ok {
+		return limits
+	}
+	return providerConfig.ModelProviderRateLimits
+}
+
+var providerLimitersMu sync.Mutex
+var providerLimiters = map[string]*providerLimiter{}
+
+type waitForRateLimitParams struct {
+	client      ClientInfo
+	role        shared.ModelRole
+	inputTokens int
+}
+
+// waitForRateLimit blocks until the request can be sent under the provider's rate limits, or until the context is done. The returned limiter is nil if the provider has no limits. Otherwise, release must be called once the request is finished.
+func waitForRateLimit(ctx context.Context, params waitForRateLimitParams) (*providerLimiter, error) {
+	limits := resolveRateLimits(params.client.ProviderConfig)
+	if limits.IsEmpty() {
+		return nil, nil
+	}
+
+	limiter := getProviderLimiter(params.client, limits)
+
+	start := time.Now()
+	err := limiter.acquire(ctx, rateLimitPriorityForRole(params.role), params.inputTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if waited := time.Since(start); waited > time.Second {
+		log.Printf("waitForRateLimit - %s request waited %s for %s rate limit\n", params.role, waited.Round(time.Millisecond), params.client.ProviderConfig.ToComposite())
+	}
+
+	return limiter, nil
+}
+
+func getProviderLimiter(client ClientInfo, limits shared.ModelProviderRateLimits) *providerLimiter {
+	// the api key is hashed so that it isn't kept around in memory as a map key
+	keyHash := sha256.Sum256([]byte(client.ApiKey))
+	key := client.ProviderConfig.ToComposite() + "|" + hex.EncodeToString(keyHash[:8])
+
+	providerLimitersMu.Lock()
+	defer providerLimitersMu.Unlock()
+
+	limiter, ok := providerLimiters[key]
+	if !ok {
+		limiter = newProviderLimiter(limits)
+		providerLimiters[key] = limiter
+		return limiter
+	}
+
+	limiter.setLimits(limits)
+	return limiter
+}
+
+type rateLimitWaiter struct {
+	priority  rateLimitPriority
+	seq       uint64
+	numTokens int
+	ready     chan struct{}
+	granted   bool
+	index     int
+}
+
+// rateLimitQueue is a heap ordered by priority, then by arrival
+type rateLimitQueue []*rateLimitWaiter
+
+func (q rateLimitQueue) Len() int { return len(q) }
+
+func (q rateLimitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q rateLimitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *rateLimitQueue) Push(x any) {
+	w := x.(*rateLimitWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *rateLimitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// providerLimiter is a pair of token buckets (requests and input tokens per minute) plus a cap on requests in flight. Each bucket holds up to a minute's worth of capacity and refills continuously.
+type providerLimiter struct {
+	mu sync.Mutex
+
+	limits shared.ModelProviderRateLimits
+
+	requests   float64
+	tokens     float64
+	lastRefill time.Time
+	inFlight   int
+
+	// set when the provider responds with a rate limit error, so queued requests don't all run into it too
+	pausedUntil time.Time
+
+	queue rateLimitQueue
+	seq   uint64
+	timer *time.Timer
+
+	now func() time.Time
+}
+
+func newProviderLimiter(limits shared.ModelProviderRateLimits) *providerLimiter {
+	return &providerLimiter{
+		limits:     limits,
+		requests:   float64(limits.RequestsPerMinute),
+		tokens:     float64(limits.TokensPerMinute),
+		lastRefill: time.Now(),
+		now:        time.Now,
+	}
+}
+
+func (l *providerLimiter) setLimits(limits shared.ModelProviderRateLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limits == limits {
+		return
+	}
+
+	l.refillLocked(l.now())
+	l.limits = limits
+	l.requests = min(l.requests, float64(limits.RequestsPerMinute))
+	l.tokens = min(l.tokens, float64(limits.TokensPerMinute))
+	l.dispatchLocked()
+}
+
+func (l *providerLimiter) acquire(ctx context.Context, priority rateLimitPriority, numTokens int) error {
+	l.mu.Lock()
+	l.seq++
+	w := &rateLimitWaiter{
+		priority:  priority,
+		seq:       l.seq,
+		numTokens: numTokens,
+		ready:     make(chan struct{}),
+	}
+	heap.Push(&l.queue, w)
+	l.dispatchLocked()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// granted just as the context finished—nothing was sent, so give back the capacity
+			l.inFlight--
+			l.refundLocked(w.numTokens)
+		} else {
+			heap.Remove(&l.queue, w.index)
+		}
+		l.dispatchLocked()
+		return ctx.Err()
+	}
+}
+
+// release frees the request's concurrency slot once it's finished
+func (l *providerLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.dispatchLocked()
+}
+
+// pause holds back queued requests after the provider responds with a rate limit error, since its own accounting doesn't always match ours
+func (l *providerLimiter) pause(retryAfter time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(retryAfter)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.dispatchLocked()
+}
+
+func (l *providerLimiter) refillLocked(now time.Time) {
+	elapsed := now.Sub(l.lastRefill).Minutes()
+	if elapsed <= 0 {
+		return
+	}
+	l.lastRefill = now
+	l.requests = min(float64(l.limits.RequestsPerMinute), l.requests+elapsed*float64(l.limits.RequestsPerMinute))
+	l.tokens = min(float64(l.limits.TokensPerMinute), l.tokens+elapsed*float64(l.limits.TokensPerMinute))
+}
+
+func (l *providerLimiter) refundLocked(numTokens int) {
+	l.requests = min(float64(l.limits.RequestsPerMinute), l.requests+1)
+	l.tokens = min(float64(l.limits.TokensPerMinute), l.tokens+float64(l.tokenCost(numTokens)))
+}
+
+// a request larger than the per-minute token limit would never fit, so it only waits for a full bucket
+func (l *providerLimiter) tokenCost(numTokens int) int {
+	return min(numTokens, l.limits.TokensPerMinute)
+}
+
+// waitLocked returns how long until the given request fits in both buckets
+func (l *providerLimiter) waitLocked(now time.Time, numTokens int) time.Duration {
+	var wait time.Duration
+
+	if now.Before(l.pausedUntil) {
+		wait = l.pausedUntil.Sub(now)
+	}
+
+	if l.limits.RequestsPerMinute > 0 && l.requests < 1 {
+		wait = max(wait, minutesToDuration((1-l.requests)/float64(l.limits.RequestsPerMinute)))
+	}
+
+	if l.limits.TokensPerMinute > 0 {
+		cost := float64(l.tokenCost(numTokens))
+		if l.tokens < cost {
+			wait = max(wait, minutesToDuration((cost-l.tokens)/float64(l.limits.TokensPerMinute)))
+		}
+	}
+
+	return wait
+}
+
+// dispatchLocked grants queued requests in priority order for as long as they fit. Requests are never granted out of order, so a builder can't take the capacity a queued planner request is waiting for.
+func (l *providerLimiter) dispatchLocked() {
+	now := l.now()
+	l.refillLocked(now)
+
+	for l.queue.Len() > 0 {
+		if l.limits.MaxConcurrentRequests > 0 && l.inFlight >= l.limits.MaxConcurrentRequests {
+			// release dispatches again when a slot frees up
+			return
+		}
+
+		w := l.queue[0]
+
+		wait := l.waitLocked(now, w.numTokens)
+		if wait > 0 {
+			l.scheduleLocked(wait)
+			return
+		}
+
+		heap.Pop(&l.queue)
+		if l.limits.RequestsPerMinute > 0 {
+			l.requests--
+		}
+		if l.limits.TokensPerMinute > 0 {
+			l.tokens -= float64(l.tokenCost(w.numTokens))
+		}
+		l.inFlight++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+func (l *providerLimiter) scheduleLocked(wait time.Duration) {
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.timer = time.AfterFunc(wait, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.timer = nil
+		l.dispatchLocked()
+	})
+}
+
+const (
+	rateLimitDefaultPause = 5 * time.Second
+	rateLimitMaxPause     = time.Minute
+)
+
+func rateLimitPauseDuration(header http.Header, body string) time.Duration {
+	retryAfter := extractRetryAfter(header, body)
+	if retryAfter <= 0 {
+		return rateLimitDefaultPause
+	}
+	return min(time.Duration(retryAfter)*time.Second, rateLimitMaxPause)
+}
+
+func minutesToDuration(minutes float64) time.Duration {
+	// round up so the bucket has refilled by the time the timer fires
+	return time.Duration(minutes*float64(time.Minute)) + time.Millisecond
+}
+
+func getChatRequestTokensEstimate(req types.ExtendedChatCompletionRequest) int {
+	numTokens := 0
+	for _, msg := range req.Messages {
+		for _, part := range msg.Content {
+			numTokens += shared.GetNumTokensEstimate(part.Text)
+		}
+	}
+	return numTokens
+}
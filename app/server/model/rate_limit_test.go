@@ -0,0 +1,84 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestProviderLimiterPriority(t *testing.T) {
+	l := newProviderLimiter(shared.ModelProviderRateLimits{MaxConcurrentRequests: 1})
+	ctx := context.Background()
+
+	if err := l.acquire(ctx, rateLimitPriorityLow, 0); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	granted := make(chan rateLimitPriority, 2)
+	queue := func(priority rateLimitPriority, wantQueued int) {
+		go func() {
+			if err := l.acquire(ctx, priority, 0); err == nil {
+				granted <- priority
+			}
+		}()
+		// wait until the request is queued so arrival order is deterministic
+		for {
+			l.mu.Lock()
+			n := l.queue.Len()
+			l.mu.Unlock()
+			if n == wantQueued {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	queue(rateLimitPriorityLow, 1)
+	queue(rateLimitPriorityHigh, 2)
+
+	l.release()
+	if got := <-granted; got != rateLimitPriorityHigh {
+		t.Errorf("first granted priority = %d, want %d", got, rateLimitPriorityHigh)
+	}
+
+	l.release()
+	if got := <-granted; got != rateLimitPriorityLow {
+		t.Errorf("second granted priority = %d, want %d", got, rateLimitPriorityLow)
+	}
+}
+
+func TestProviderLimiterWait(t *testing.T) {
+	now := time.Now()
+	l := newProviderLimiter(shared.ModelProviderRateLimits{RequestsPerMinute: 60, TokensPerMinute: 6000})
+	l.now = func() time.Time { return now }
+	l.lastRefill = now
+
+	l.requests = 0
+	if got := l.waitLocked(now, 0); got < time.Second || got > time.Second+10*time.Millisecond {
+		t.Errorf("wait for request bucket = %s, want ~1s", got)
+	}
+
+	l.requests = 60
+	l.tokens = 0
+	if got := l.waitLocked(now, 1000); got < 10*time.Second || got > 10*time.Second+10*time.Millisecond {
+		t.Errorf("wait for token bucket = %s, want ~10s", got)
+	}
+
+	// a request larger than the per-minute limit only waits for a full bucket
+	if got := l.waitLocked(now, 100000); got < time.Minute || got > time.Minute+10*time.Millisecond {
+		t.Errorf("wait for oversized request = %s, want ~1m", got)
+	}
+
+	l.tokens = 6000
+	l.pausedUntil = now.Add(3 * time.Second)
+	if got := l.waitLocked(now, 1000); got != 3*time.Second {
+		t.Errorf("wait while paused = %s, want 3s", got)
+	}
+
+	l.refillLocked(now.Add(30 * time.Second))
+	if l.requests != 60 || l.tokens != 6000 {
+		t.Errorf("buckets should be capped at a minute's capacity, got %v requests and %v tokens", l.requests, l.tokens)
+	}
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"plandex-server/db"
+	"plandex-server/syntax/file_map"
+	"plandex-server/types"
+	"sort"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// limits concurrent reads of index files during a search
+const semanticIndexReadConcurrency = 16
+
+type SemanticIndexParams struct {
+	Clients       map[string]ClientInfo
+	AuthVars      map[string]string
+	Auth          *types.ServerAuth
+	Plan          *db.Plan
+	Settings      *shared.PlanSettings
+	OrgUserConfig *shared.OrgUserConfig
+	SessionId     string
+}
+
+func (p SemanticIndexParams) embeddingsParams(purpose string, inputs []string) EmbeddingsParams {
+	return EmbeddingsParams{
+		Clients:       p.Clients,
+		AuthVars:      p.AuthVars,
+		Auth:          p.Auth,
+		Plan:          p.Plan,
+		Settings:      p.Settings,
+		OrgUserConfig: p.OrgUserConfig,
+		SessionId:     p.SessionId,
+		Purpose:       purpose,
+		Inputs:        inputs,
+	}
+}
+
+func (p SemanticIndexParams) embeddingsModelId() shared.ModelId {
+	return p.Settings.GetModelPack().Embeddings.GetModelId()
+}
+
+// UpdateSemanticIndex chunks and embeds files for the plan's project. Files whose content and embedding model haven't changed since they were last indexed are skipped.
+func UpdateSemanticIndex(ctx context.Context, params SemanticIndexParams, inputs shared.FileMapInputs) error {
+	plan := params.Plan
+	modelId := params.embeddingsModelId()
+
+	var toIndex []*db.SemanticIndexFile
+	var texts []string
+
+	for path, input := range inputs {
+		shaBytes := sha256.Sum256([]byte(input))
+		sha := hex.EncodeToString(shaBytes[:])
+
+		existing, err := db.GetSemanticIndexFile(plan.OrgId, plan.ProjectId, path)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Sha == sha && existing.ModelId == modelId {
+			continue
+		}
+
+		chunks, err := file_map.ChunkFile(ctx, path, []byte(input))
+		if err != nil {
+			return fmt.Errorf("error chunking %s: %v", path, err)
+		}
+
+		file := &db.SemanticIndexFile{
+			Path:    path,
+			Sha:     sha,
+			ModelId: modelId,
+			Chunks:  []*db.SemanticIndexChunk{},
+		}
+		for _, chunk := range chunks {
+			file.Chunks = append(file.Chunks, &db.SemanticIndexChunk{
+				StartLine: chunk.StartLine,
+				EndLine:   chunk.EndLine,
+				Text:      chunk.Text,
+			})
+			// the path is included so that file and directory names count toward relevance
+			texts = append(texts, fmt.Sprintf("%s\n\n%s", path, chunk.Text))
+		}
+		toIndex = append(toIndex, file)
+	}
+
+	if len(toIndex) == 0 {
+		return nil
+	}
+
+	log.Printf("UpdateSemanticIndex - embedding %d chunks from %d files", len(texts), len(toIndex))
+
+	if len(texts) > 0 {
+		embeddings, err := CreateEmbeddings(ctx, params.embeddingsParams("Semantic index", texts))
+		if err != nil {
+			return err
+		}
+
+		i := 0
+		for _, file := range toIndex {
+			for _, chunk := range file.Chunks {
+				chunk.Embedding = embeddings[i]
+				i++
+			}
+		}
+	}
+
+	for _, file := range toIndex {
+		err := db.StoreSemanticIndexFile(plan.OrgId, plan.ProjectId, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SemanticSearchResult struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Text      string
+	Score     float64
+}
+
+type SemanticSearchParams struct {
+	SemanticIndexParams
+	Query string
+
+	// only chunks from these paths are searched, so results are limited to files that are in the plan's context maps
+	Paths []string
+
+	TopK      int
+	MaxTokens int
+}
+
+// SearchSemanticIndex returns the indexed chunks most similar to the query, most relevant first
+func SearchSemanticIndex(ctx context.Context, params SemanticSearchParams) ([]*SemanticSearchResult, error) {
+	plan := params.Plan
+	modelId := params.embeddingsModelId()
+
+	embeddings, err := CreateEmbeddings(ctx, params.embeddingsParams("Semantic search", []string{params.Query}))
+	if err != nil {
+		return nil, err
+	}
+	query := embeddings[0]
+
+	var results []*SemanticSearchResult
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, semanticIndexReadConcurrency)
+
+	for _, path := range params.Paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			file, err := db.GetSemanticIndexFile(plan.OrgId, plan.ProjectId, path)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			// files embedded with a different model aren't comparable until they're indexed again
+			if file == nil || file.ModelId != modelId {
+				return
+			}
+
+			var fileResults []*SemanticSearchResult
+			for _, chunk := range file.Chunks {
+				fileResults = append(fileResults, &SemanticSearchResult{
+					Path:      path,
+					StartLine: chunk.StartLine,
+					EndLine:   chunk.EndLine,
+					Text:      chunk.Text,
+					Score:     cosineSimilarity(query, chunk.Embedding),
+				})
+			}
+
+			mu.Lock()
+			results = append(results, fileResults...)
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	var res []*SemanticSearchResult
+	tokens := 0
+	for _, result := range results {
+		if params.TopK > 0 && len(res) >= params.TopK {
+			break
+		}
+		numTokens := shared.GetNumTokensEstimate(result.Text)
+		if params.MaxTokens > 0 && tokens+numTokens > params.MaxTokens {
+			continue
+		}
+		tokens += numTokens
+		res = append(res, result)
+	}
+
+	return res, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -23,6 +23,11 @@ const (
 )
 
 func GetMessagesTokenEstimate(messages ...types.ExtendedChatMessage) int {
+	return GetMessagesTokenCount(shared.GetDefaultTokenizer(), messages...)
+}
+
+// GetMessagesTokenCount counts with a specific model's tokenizer — see shared.GetTokenizer
+func GetMessagesTokenCount(tokenizer shared.Tokenizer, messages ...types.ExtendedChatMessage) int {
 	tokens := 0
 
 	for _, msg := range messages {
@@ -36,7 +41,7 @@ func GetMessagesTokenEstimate(messages ...types.ExtendedChatMessage) int {
 			for _, part := range msg.Content {
 				if part.Type == openai.ChatMessagePartTypeText {
 					tokens += TokensPerExtendedPart // Overhead for the part object structure
-					tokens += shared.GetNumTokensEstimate(part.Text)
+					tokens += tokenizer.CountTokens(part.Text)
 				}
 
 				// images are handled separately
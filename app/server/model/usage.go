@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	shared "plandex-shared"
+)
+
+// RecordModelUsageHook stores every model request in the self-hosted usage ledger that backs `plandex usage`
+// It's registered as the DidSendModelRequest hook by the self-hosted server—Plandex Cloud registers its own billing hook instead
+func RecordModelUsageHook(params hooks.HookParams) (hooks.HookResult, *shared.ApiError) {
+	err := RecordModelUsage(params)
+	if err != nil {
+		log.Printf("RecordModelUsageHook - error recording model usage: %v", err)
+		return hooks.HookResult{}, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: 500,
+			Msg:    "Error recording model usage",
+		}
+	}
+	return hooks.HookResult{}, nil
+}
+
+func RecordModelUsage(params hooks.HookParams) error {
+	usageParams := params.DidSendModelRequestParams
+	if usageParams == nil || params.Auth == nil {
+		return nil
+	}
+
+	orgId := params.Auth.OrgId
+
+	price, err := db.GetModelPrice(orgId, usageParams.ModelId)
+	if err != nil {
+		return fmt.Errorf("error getting model price: %v", err)
+	}
+
+	cost, cacheSavings := price.Cost(usageParams.InputTokens, usageParams.OutputTokens, usageParams.CachedTokens)
+
+	usage := &db.ModelUsage{
+		OrgId:         orgId,
+		ModelId:       usageParams.ModelId,
+		ModelName:     usageParams.ModelName,
+		ModelProvider: usageParams.ModelProvider,
+		ModelRole:     usageParams.ModelRole,
+		ModelPackName: usageParams.ModelPackName,
+		Purpose:       usageParams.Purpose,
+
+		InputTokens:  usageParams.InputTokens,
+		OutputTokens: usageParams.OutputTokens,
+		CachedTokens: usageParams.CachedTokens,
+
+		InputPricePerMillion:       price.InputPricePerMillion,
+		OutputPricePerMillion:      price.OutputPricePerMillion,
+		CachedInputPricePerMillion: price.CachedInputPricePerMillion,
+		Cost:                       cost,
+		CacheSavings:               cacheSavings,
+
+		StoppedEarly:    usageParams.StoppedEarly,
+		HadError:        usageParams.HadError,
+		NoReportedUsage: usageParams.NoReportedUsage,
+	}
+
+	if params.Auth.User != nil {
+		usage.UserId = &params.Auth.User.Id
+	}
+	if params.Plan != nil {
+		usage.PlanId = &params.Plan.Id
+		usage.PlanName = &params.Plan.Name
+	} else if usageParams.PlanId != "" {
+		usage.PlanId = &usageParams.PlanId
+	}
+	if usageParams.SessionId != "" {
+		usage.SessionId = &usageParams.SessionId
+	}
+	if usageParams.GenerationId != "" {
+		usage.GenerationId = &usageParams.GenerationId
+	}
+
+	return db.StoreModelUsage(usage)
+}
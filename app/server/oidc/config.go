@@ -0,0 +1,163 @@
+package oidc
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultGroupsClaim = "groups"
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+type RoleMapping struct {
+	Group string
+	Role  string
+}
+
+type Config struct {
+	IssuerUrl    string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+
+	// ID token claim that lists the user's groups
+	GroupsClaim string
+	// org that SSO users are added to—if empty and the server has a single org, that org is used
+	OrgId string
+	// checked in order—the first group the user belongs to sets their org role
+	RoleMappings []RoleMapping
+	// org role for users who don't match any mapping—if empty, they aren't added to the org
+	DefaultRole string
+
+	// disables email pin sign in and account creation
+	Required bool
+
+	// fixed port for the CLI's loopback redirect, for providers that need an exact redirect uri—0 picks a free port
+	RedirectPort int
+}
+
+var config *Config
+
+// MustLoadConfig loads OIDC settings from the environment. SSO stays disabled if OIDC_ISSUER_URL isn't set.
+func MustLoadConfig() {
+	c, err := LoadConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Invalid OIDC config: %v", err))
+	}
+	config = c
+
+	if c != nil {
+		log.Printf("OIDC single sign-on enabled with issuer %s\n", c.IssuerUrl)
+	}
+}
+
+// GetConfig returns nil if SSO isn't enabled
+func GetConfig() *Config {
+	return config
+}
+
+func Enabled() bool {
+	return config != nil
+}
+
+func Required() bool {
+	return config != nil && config.Required
+}
+
+func LoadConfig() (*Config, error) {
+	issuerUrl := strings.TrimSpace(os.Getenv("OIDC_ISSUER_URL"))
+	if issuerUrl == "" {
+		return nil, nil
+	}
+
+	c := &Config{
+		IssuerUrl:    strings.TrimSuffix(issuerUrl, "/"),
+		ClientId:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		Scopes:       defaultScopes,
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		OrgId:        os.Getenv("OIDC_ORG_ID"),
+		DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+		Required:     os.Getenv("OIDC_REQUIRED") != "",
+	}
+
+	if c.ClientId == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+	}
+
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		c.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+
+	hasOpenId := false
+	for _, scope := range c.Scopes {
+		if scope == "openid" {
+			hasOpenId = true
+			break
+		}
+	}
+	if !hasOpenId {
+		c.Scopes = append([]string{"openid"}, c.Scopes...)
+	}
+
+	if c.GroupsClaim == "" {
+		c.GroupsClaim = defaultGroupsClaim
+	}
+
+	var err error
+	c.RoleMappings, err = ParseRoleMappings(os.Getenv("OIDC_ROLE_MAPPING"))
+	if err != nil {
+		return nil, err
+	}
+
+	if port := os.Getenv("OIDC_CLI_REDIRECT_PORT"); port != "" {
+		c.RedirectPort, err = strconv.Atoi(port)
+		if err != nil || c.RedirectPort < 0 || c.RedirectPort > 65535 {
+			return nil, fmt.Errorf("invalid OIDC_CLI_REDIRECT_PORT: %s", port)
+		}
+	}
+
+	return c, nil
+}
+
+// ParseRoleMappings parses a comma-separated list of group=role pairs, like "plandex-admins=admin,engineering=member"
+func ParseRoleMappings(s string) ([]RoleMapping, error) {
+	var mappings []RoleMapping
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		group, role, ok := strings.Cut(pair, "=")
+		group = strings.TrimSpace(group)
+		role = strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING entry '%s'—use group=role", pair)
+		}
+
+		mappings = append(mappings, RoleMapping{Group: group, Role: role})
+	}
+
+	return mappings, nil
+}
+
+// ResolveRole returns the org role name for a user's groups, or an empty string if they shouldn't be added to the org
+func (c *Config) ResolveRole(groups []string) string {
+	inGroup := map[string]bool{}
+	for _, group := range groups {
+		inGroup[group] = true
+	}
+
+	for _, mapping := range c.RoleMappings {
+		if inGroup[mapping.Group] {
+			return mapping.Role
+		}
+	}
+
+	return c.DefaultRole
+}
@@ -0,0 +1,284 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const testClientId = "plandex-test"
+const testKid = "test-key"
+
+// mockIssuer is a minimal OIDC provider: discovery, keys, and a token endpoint that enforces PKCE
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// the challenge sent with the authorization request, and the claims for the code it returns
+	challenge string
+	claims    map[string]any
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	m := &mockIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != "test-code" || r.Form.Get("client_id") != testClientId {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if shared.PkceChallenge(r.Form.Get("code_verifier")) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant","error_description":"PKCE verification failed"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     m.sign(t, m.key, m.claims),
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockIssuer) sign(t *testing.T, key *rsa.PrivateKey, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": testKid, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("error marshalling claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (m *mockIssuer) validClaims(nonce string) map[string]any {
+	return map[string]any{
+		"iss":            m.server.URL,
+		"sub":            "user-123",
+		"aud":            testClientId,
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "Dev@Example.com",
+		"email_verified": true,
+		"name":           "Dev User",
+		"groups":         []string{"engineering", "plandex-admins"},
+	}
+}
+
+func (m *mockIssuer) provider() *Provider {
+	return NewProvider(&Config{
+		IssuerUrl:   m.server.URL,
+		ClientId:    testClientId,
+		GroupsClaim: defaultGroupsClaim,
+	})
+}
+
+func TestExchange(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	verifier, _ := shared.GenPkceVerifier()
+	m.challenge = shared.PkceChallenge(verifier)
+	m.claims = m.validClaims("nonce-1")
+
+	claims, err := p.Exchange(context.Background(), "test-code", verifier, "http://127.0.0.1:4000/callback", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error: %v", err)
+	}
+
+	if claims.Subject != "user-123" || claims.Email != "dev@example.com" || claims.Name != "Dev User" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if claims.EmailVerified == nil || !*claims.EmailVerified {
+		t.Errorf("expected email to be verified")
+	}
+	if len(claims.Groups) != 2 || claims.Groups[1] != "plandex-admins" {
+		t.Errorf("unexpected groups: %v", claims.Groups)
+	}
+
+	// a different verifier than the one the challenge was made from must fail
+	otherVerifier, _ := shared.GenPkceVerifier()
+	_, err = p.Exchange(context.Background(), "test-code", otherVerifier, "http://127.0.0.1:4000/callback", "nonce-1")
+	if err == nil {
+		t.Errorf("expected exchange with the wrong code verifier to fail")
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	with := func(name string, value any) map[string]any {
+		claims := m.validClaims("nonce-1")
+		claims[name] = value
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", m.sign(t, m.key, m.validClaims("nonce-1")), false},
+		{"audience list", m.sign(t, m.key, with("aud", []string{"other", testClientId})), false},
+		{"wrong audience", m.sign(t, m.key, with("aud", "other-client")), true},
+		{"wrong issuer", m.sign(t, m.key, with("iss", "https://evil.example.com")), true},
+		{"expired", m.sign(t, m.key, with("exp", time.Now().Add(-time.Hour).Unix())), true},
+		{"wrong nonce", m.sign(t, m.key, with("nonce", "nonce-2")), true},
+		{"wrong key", m.sign(t, otherKey, m.validClaims("nonce-1")), true},
+		{"alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"test-key"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"x"}`)) + ".", true},
+		{"malformed", "not-a-token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.VerifyIdToken(context.Background(), tt.token, "nonce-1")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				if !errors.Is(err, ErrInvalidIdToken) {
+					t.Errorf("expected ErrInvalidIdToken, got: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+
+	p := NewProvider(&Config{
+		IssuerUrl: m.server.URL + "/other",
+		ClientId:  testClientId,
+	})
+
+	_, err := p.Discover(context.Background())
+	if err == nil {
+		t.Errorf("expected an error when the discovery issuer doesn't match")
+	}
+}
+
+func TestResolveRole(t *testing.T) {
+	mappings, err := ParseRoleMappings("plandex-owners=owner, plandex-admins=admin,engineering=member")
+	if err != nil {
+		t.Fatalf("ParseRoleMappings() error: %v", err)
+	}
+
+	c := &Config{RoleMappings: mappings}
+
+	tests := []struct {
+		groups []string
+		want   string
+	}{
+		{[]string{"engineering", "plandex-admins"}, "admin"},
+		{[]string{"engineering"}, "member"},
+		{[]string{"plandex-owners", "plandex-admins"}, "owner"},
+		{[]string{"sales"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := c.ResolveRole(tt.groups); got != tt.want {
+			t.Errorf("ResolveRole(%v) = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+
+	c.DefaultRole = "member"
+	if got := c.ResolveRole([]string{"sales"}); got != "member" {
+		t.Errorf("ResolveRole with default = %q, want member", got)
+	}
+
+	for _, invalid := range []string{"admins", "=admin", "admins="} {
+		if _, err := ParseRoleMappings(invalid); err == nil {
+			t.Errorf("ParseRoleMappings(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestClaimsIsEmailVerified(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   bool
+	}{
+		{"verified", m.validClaims("nonce-1"), true},
+		{"unverified", m.validClaims("nonce-1"), false},
+		{"missing", m.validClaims("nonce-1"), false},
+		{"string", m.validClaims("nonce-1"), false},
+	}
+	tests[1].claims["email_verified"] = false
+	delete(tests[2].claims, "email_verified")
+	tests[3].claims["email_verified"] = "true"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := p.VerifyIdToken(context.Background(), m.sign(t, m.key, tt.claims), "nonce-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := claims.IsEmailVerified(); got != tt.want {
+				t.Errorf("IsEmailVerified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
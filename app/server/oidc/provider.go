@@ -0,0 +1,428 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// allowed difference between our clock and the provider's when checking token times
+const clockSkew = time.Minute
+
+// how long discovery documents are cached before being fetched again
+const discoveryTTL = time.Hour
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+var ErrInvalidIdToken = errors.New("invalid id token")
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	config *Config
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         map[string]crypto.PublicKey
+}
+
+func NewProvider(config *Config) *Provider {
+	return &Provider{config: config}
+}
+
+var provider *Provider
+var providerMu sync.Mutex
+
+// GetProvider returns the provider for the loaded config, or nil if SSO isn't enabled
+func GetProvider() *Provider {
+	if config == nil {
+		return nil
+	}
+
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	if provider == nil {
+		provider = NewProvider(config)
+	}
+	return provider
+}
+
+type Claims struct {
+	Subject       string
+	Issuer        string
+	Email         string
+	EmailVerified *bool
+	Name          string
+	Groups        []string
+}
+
+// IsEmailVerified is true only when the provider explicitly marks the email as verified
+func (c *Claims) IsEmailVerified() bool {
+	return c.EmailVerified != nil && *c.EmailVerified
+}
+
+// Discover fetches the provider's discovery document, checking that it's for the configured issuer
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var d Discovery
+	err := getJson(ctx, p.config.IssuerUrl+"/.well-known/openid-configuration", &d)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching discovery document: %v", err)
+	}
+
+	if strings.TrimSuffix(d.Issuer, "/") != p.config.IssuerUrl {
+		return nil, fmt.Errorf("discovery document issuer %s doesn't match %s", d.Issuer, p.config.IssuerUrl)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksUri == "" {
+		return nil, fmt.Errorf("discovery document is missing endpoints")
+	}
+
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+
+	return p.discovery, nil
+}
+
+// Exchange trades an authorization code and its PKCE verifier for an ID token, then verifies it
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, redirectUri, nonce string) (*Claims, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {codeVerifier},
+		"redirect_uri":  {redirectUri},
+		"client_id":     {p.config.ClientId},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending token request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading token response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange failed - status: %d, body: %s", resp.StatusCode, body)
+	}
+
+	var tokenRes struct {
+		IdToken string `json:"id_token"`
+	}
+	err = json.Unmarshal(body, &tokenRes)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding token response: %v", err)
+	}
+
+	if tokenRes.IdToken == "" {
+		return nil, fmt.Errorf("token response is missing id_token")
+	}
+
+	return p.VerifyIdToken(ctx, tokenRes.IdToken, nonce)
+}
+
+// VerifyIdToken checks an ID token's signature against the provider's keys along with its issuer, audience, expiry, and nonce
+func (p *Provider) VerifyIdToken(ctx context.Context, rawToken, nonce string) (*Claims, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIdToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %v", ErrInvalidIdToken, err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature: %v", ErrInvalidIdToken, err)
+	}
+
+	key, err := p.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIdToken, err)
+	}
+
+	var raw map[string]any
+	err = decodeSegment(parts[1], &raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidIdToken, err)
+	}
+
+	claims := &Claims{
+		Subject: stringClaim(raw, "sub"),
+		Issuer:  stringClaim(raw, "iss"),
+		Email:   strings.ToLower(stringClaim(raw, "email")),
+		Name:    stringClaim(raw, "name"),
+		Groups:  stringsClaim(raw, p.config.GroupsClaim),
+	}
+
+	if claims.Name == "" {
+		claims.Name = stringClaim(raw, "preferred_username")
+	}
+
+	if verified, ok := raw["email_verified"].(bool); ok {
+		claims.EmailVerified = &verified
+	}
+
+	if strings.TrimSuffix(claims.Issuer, "/") != p.config.IssuerUrl {
+		return nil, fmt.Errorf("%w: unexpected issuer %s", ErrInvalidIdToken, claims.Issuer)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIdToken)
+	}
+
+	audOk := false
+	for _, aud := range stringsClaim(raw, "aud") {
+		if aud == p.config.ClientId {
+			audOk = true
+			break
+		}
+	}
+	if !audOk {
+		return nil, fmt.Errorf("%w: token wasn't issued for this client", ErrInvalidIdToken)
+	}
+
+	exp, ok := raw["exp"].(float64)
+	if !ok || time.Unix(int64(exp), 0).Add(clockSkew).Before(time.Now()) {
+		return nil, fmt.Errorf("%w: token has expired", ErrInvalidIdToken)
+	}
+
+	if nonce != "" && stringClaim(raw, "nonce") != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIdToken)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	// unknown key id—the provider may have rotated its keys, so fetch them again
+	keys, err := fetchKeys(ctx, d.JwksUri)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	// tokens without a key id can be checked against a provider's only key
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unknown signing key %s", ErrInvalidIdToken, kid)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func fetchKeys(ctx context.Context, jwksUri string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err := getJson(ctx, jwksUri, &set)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching signing keys: %v", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				continue
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				continue
+			}
+			y, err := base64.RawURLEncoding.DecodeString(k.Y)
+			if err != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, sig []byte) error {
+	hash := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type doesn't match alg %s", alg)
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash[:], sig)
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type doesn't match alg %s", alg)
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("malformed ES256 signature")
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(ecKey, hash[:], r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %s", alg)
+	}
+}
+
+func getJson(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func decodeSegment(seg string, v any) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func stringClaim(raw map[string]any, name string) string {
+	s, _ := raw[name].(string)
+	return s
+}
+
+// stringsClaim handles claims like aud and groups that can be a single string or a list
+func stringsClaim(raw map[string]any, name string) []string {
+	switch v := raw[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
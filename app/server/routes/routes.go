@@ -86,6 +86,15 @@ func AddProxyableApiRoutesWithPrefix(r *mux.Router, prefix string) {
 	addProxyableApiRoutes(r, prefix)
 }
 
+// usage ledger routes for self-hosted servers—Plandex Cloud serves /billing from its own billing service
+func AddUsageRoutes(r *mux.Router) {
+	addUsageRoutes(r, "")
+}
+
+func AddUsageRoutesWithPrefix(r *mux.Router, prefix string) {
+	addUsageRoutes(r, prefix)
+}
+
 func addApiRoutes(r *mux.Router, prefix string) {
 	EnsureHandlePlandex()
 
@@ -95,6 +104,8 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/accounts/sign_in", false, handlers.SignInHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/accounts/sign_out", false, handlers.SignOutHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/accounts", false, handlers.CreateAccountHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/accounts/oidc", false, handlers.GetOidcConfigHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/accounts/oidc/sign_in", false, handlers.OidcSignInHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/orgs/session", false, handlers.GetOrgSessionHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/orgs", false, handlers.ListOrgsHandler).Methods("GET")
@@ -103,6 +114,11 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/users", false, handlers.ListUsersHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/orgs/users/{userId}", false, handlers.DeleteOrgUserHandler).Methods("DELETE")
 	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.ListOrgRolesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.CreateOrgRoleHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.UpdateOrgRoleHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.DeleteOrgRoleHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/orgs/users/{userId}/role", false, handlers.SetOrgUserRoleHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/permissions", false, handlers.ListPermissionsHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/invites", false, handlers.InviteUserHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/invites/pending", false, handlers.ListPendingInvitesHandler).Methods("GET")
@@ -110,6 +126,15 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/invites/all", false, handlers.ListAllInvitesHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/invites/{inviteId}", false, handlers.DeleteInviteHandler).Methods("DELETE")
 
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.ListApiTokensHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.CreateApiTokenHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/api_tokens/session", false, handlers.GetApiTokenSessionHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/api_tokens/{tokenId}", false, handlers.RevokeApiTokenHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/service_accounts", false, handlers.ListServiceAccountsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/service_accounts", false, handlers.CreateServiceAccountHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/service_accounts/{userId}", false, handlers.DeleteServiceAccountHandler).Methods("DELETE")
+
 	HandlePlandexFn(r, prefix+"/projects", false, handlers.CreateProjectHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/projects", false, handlers.ListProjectsHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/set_plan", false, handlers.ProjectSetPlanHandler).Methods("PUT")
@@ -117,11 +142,32 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/current_branches", false, handlers.GetCurrentBranchByPlanIdHandler).Methods("POST")
 
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/mcp_servers", false, handlers.ListMcpServersHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/mcp_servers", false, handlers.UpsertMcpServerHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/mcp_servers/{name}", false, handlers.DeleteMcpServerHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/mcp_servers/{name}/enabled", false, handlers.SetMcpServerEnabledHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/mcp_servers/{name}/tools", false, handlers.ListMcpServerToolsHandler).Methods("GET")
+
+	HandlePlandexFn(r, prefix+"/webhooks", false, handlers.ListWebhooksHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/webhooks", false, handlers.CreateWebhookHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/webhooks/deliveries", false, handlers.ListWebhookDeliveriesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/webhooks/{webhookId}", false, handlers.DeleteWebhookHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/webhooks/{webhookId}/enabled", false, handlers.SetWebhookEnabledHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/webhooks/{webhookId}/test", false, handlers.TestWebhookHandler).Methods("POST")
+
+	HandlePlandexFn(r, prefix+"/audit", false, handlers.ListAuditEventsHandler).Methods("GET")
+
+	HandlePlandexFn(r, prefix+"/plan_templates", false, handlers.ListPlanTemplatesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plan_templates", false, handlers.CreatePlanTemplateHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plan_templates/{name}", false, handlers.GetPlanTemplateHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plan_templates/{name}", false, handlers.DeletePlanTemplateHandler).Methods("DELETE")
+
 	HandlePlandexFn(r, prefix+"/plans", false, handlers.ListPlansHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/archive", false, handlers.ListArchivedPlansHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/ps", false, handlers.ListPlansRunningHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/import", false, handlers.ImportPlanHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.DeleteAllPlansHandler).Methods("DELETE")
 
@@ -135,9 +181,12 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/unarchive", false, handlers.UnarchivePlanHandler).Methods("PATCH")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/rename", false, handlers.RenamePlanHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/export", false, handlers.ExportPlanHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_all", false, handlers.RejectAllChangesHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_file", false, handlers.RejectFileHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_files", false, handlers.RejectFilesHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_replacements", false, handlers.RejectReplacementsHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/edit_replacements", false, handlers.EditReplacementsHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diffs", false, handlers.GetPlanDiffsHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.ListContextHandler).Methods("GET")
@@ -153,6 +202,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/branches", false, handlers.ListBranchesHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/branches/{branch}", false, handlers.DeleteBranchHandler).Methods("DELETE")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/branches", false, handlers.CreateBranchHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/merge", false, handlers.MergeBranchHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.GetSettingsHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.UpdateSettingsHandler).Methods("PUT")
@@ -162,6 +212,14 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/tell", true, handlers.TellPlanHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build", true, handlers.BuildPlanHandler).Methods("PATCH")
 
+	HandlePlandexFn(r, prefix+"/plans/{planId}/jobs", false, handlers.ListPlanJobsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/jobs", false, handlers.CreatePlanJobHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plan_jobs/{jobId}", false, handlers.DeletePlanJobHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.ListPlanSharesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.SharePlanHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares/{userId}", false, handlers.UnsharePlanHandler).Methods("DELETE")
+
 	HandlePlandexFn(r, prefix+"/custom_models", false, handlers.ListCustomModelsHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/custom_models", false, handlers.UpsertCustomModelsHandler).Methods("POST")
 
@@ -189,6 +247,17 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/org_user_config", false, handlers.UpdateOrgUserConfigHandler).Methods("PUT")
 }
 
+func addUsageRoutes(r *mux.Router, prefix string) {
+	EnsureHandlePlandex()
+
+	HandlePlandexFn(r, prefix+"/billing/credits_summary", false, handlers.CreditsSummaryHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/billing/credits_transactions", false, handlers.CreditsTransactionsHandler).Methods("POST")
+
+	HandlePlandexFn(r, prefix+"/model_prices", false, handlers.ListModelPricesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/model_prices", false, handlers.SetModelPriceHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/model_prices/{modelId:.+}", false, handlers.DeleteModelPriceHandler).Methods("DELETE")
+}
+
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
 	EnsureHandlePlandex()
 
@@ -199,5 +268,10 @@ func addProxyableApiRoutes(r *mux.Router, prefix string) {
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/auto_load_context", false, handlers.AutoLoadContextHandler).Methods("POST")
 
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/respond_code_search", false, handlers.RespondCodeSearchHandler).Methods("POST")
+
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/build_status", false, handlers.GetBuildStatusHandler).Methods("GET")
+
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/events", false, handlers.ReportPlanEventHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/executed_commands", false, handlers.ReportExecutedCommandsHandler).Methods("POST")
 }
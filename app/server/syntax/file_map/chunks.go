@@ -0,0 +1,138 @@
+package file_map
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// chunks are split on definition boundaries so that each one holds whole functions, classes, etc. where possible
+const (
+	maxChunkLines = 120
+	minChunkLines = 8
+
+	// keeps a chunk of very long lines (minified code, data) well under the input limit of embedding models
+	maxChunkBytes = 12000
+)
+
+type Chunk struct {
+	StartLine int // 1-based, inclusive
+	EndLine   int // 1-based, inclusive
+	Text      string
+}
+
+type lineRange struct {
+	start int
+	end   int
+}
+
+// ChunkFile splits a file into chunks for the semantic index, using the same definitions as MapFile to find boundaries. Files without map support are split into fixed-size chunks.
+func ChunkFile(ctx context.Context, filename string, content []byte) ([]Chunk, error) {
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return nil, nil
+	}
+
+	fileMap, err := MapFile(ctx, filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	var topLevelStarts []int
+	var innerStarts []int
+	for _, def := range fileMap.Definitions {
+		if def.Line > 0 {
+			topLevelStarts = append(topLevelStarts, def.Line)
+		}
+		innerStarts = appendChildStarts(innerStarts, def.Children)
+	}
+	sort.Ints(innerStarts)
+
+	return chunksForRanges(lines, rangesForStarts(topLevelStarts, innerStarts, len(lines))), nil
+}
+
+func appendChildStarts(starts []int, defs []Definition) []int {
+	for _, def := range defs {
+		if def.Line > 0 {
+			starts = append(starts, def.Line)
+		}
+		starts = appendChildStarts(starts, def.Children)
+	}
+	return starts
+}
+
+func rangesForStarts(topLevelStarts, innerStarts []int, numLines int) []lineRange {
+	// the first range always starts at the top of the file so that imports and package declarations are included
+	starts := []int{1}
+	for _, start := range topLevelStarts {
+		if start > starts[len(starts)-1] && start <= numLines {
+			starts = append(starts, start)
+		}
+	}
+
+	var ranges []lineRange
+	for i, start := range starts {
+		end := numLines
+		if i+1 < len(starts) {
+			end = starts[i+1] - 1
+		}
+		ranges = append(ranges, splitRange(lineRange{start, end}, innerStarts)...)
+	}
+
+	return mergeSmallRanges(ranges)
+}
+
+// splitRange splits a range that's too long at the last nested definition that fits, or at the line limit if there isn't one
+func splitRange(r lineRange, innerStarts []int) []lineRange {
+	var res []lineRange
+	for r.end-r.start+1 > maxChunkLines {
+		cut := r.start + maxChunkLines
+		for i := len(innerStarts) - 1; i >= 0; i-- {
+			start := innerStarts[i]
+			if start > r.start && start <= r.start+maxChunkLines {
+				cut = start
+				break
+			}
+		}
+		res = append(res, lineRange{r.start, cut - 1})
+		r.start = cut
+	}
+	return append(res, r)
+}
+
+func mergeSmallRanges(ranges []lineRange) []lineRange {
+	var res []lineRange
+	for _, r := range ranges {
+		if len(res) > 0 {
+			prev := &res[len(res)-1]
+			prevLen := prev.end - prev.start + 1
+			curLen := r.end - r.start + 1
+			if (curLen < minChunkLines || prevLen < minChunkLines) && r.end-prev.start+1 <= maxChunkLines {
+				prev.end = r.end
+				continue
+			}
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func chunksForRanges(lines []string, ranges []lineRange) []Chunk {
+	var chunks []Chunk
+	for _, r := range ranges {
+		text := strings.Join(lines[r.start-1:r.end], "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if len(text) > maxChunkBytes {
+			text = text[:maxChunkBytes]
+		}
+		chunks = append(chunks, Chunk{
+			StartLine: r.start,
+			EndLine:   r.end,
+			Text:      text,
+		})
+	}
+	return chunks
+}
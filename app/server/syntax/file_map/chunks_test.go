@@ -0,0 +1,58 @@
+package file_map
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkFile(t *testing.T) {
+	t.Run("splits on top-level definitions", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("package main\n\nimport \"fmt\"\n\n")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(&b, "func fn%d() {\n", i)
+			for j := 0; j < 20; j++ {
+				fmt.Fprintf(&b, "\tfmt.Println(%d)\n", j)
+			}
+			b.WriteString("}\n\n")
+		}
+
+		chunks, err := ChunkFile(context.Background(), "main.go", []byte(b.String()))
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 3)
+
+		// the package and import lines are merged into the first function's chunk since they're too short to stand alone
+		assert.Equal(t, 1, chunks[0].StartLine)
+		assert.True(t, strings.HasPrefix(chunks[0].Text, "package main"))
+		assert.Contains(t, chunks[0].Text, "func fn0()")
+		assert.True(t, strings.HasPrefix(chunks[1].Text, "func fn1()"))
+		assert.True(t, strings.HasPrefix(chunks[2].Text, "func fn2()"))
+
+		for i := 1; i < len(chunks); i++ {
+			assert.Equal(t, chunks[i-1].EndLine+1, chunks[i].StartLine)
+		}
+	})
+
+	t.Run("splits long files without definitions into fixed-size chunks", func(t *testing.T) {
+		var b strings.Builder
+		for i := 0; i < 300; i++ {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+
+		chunks, err := ChunkFile(context.Background(), "notes.txt", []byte(b.String()))
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 3)
+		assert.Equal(t, lineRange{1, maxChunkLines}, lineRange{chunks[0].StartLine, chunks[0].EndLine})
+		assert.Equal(t, maxChunkLines+1, chunks[1].StartLine)
+	})
+
+	t.Run("empty files have no chunks", func(t *testing.T) {
+		chunks, err := ChunkFile(context.Background(), "empty.go", []byte("\n\n"))
+		assert.NoError(t, err)
+		assert.Empty(t, chunks)
+	})
+}
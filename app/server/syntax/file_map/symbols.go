@@ -0,0 +1,88 @@
+package file_map
+
+import (
+	"context"
+	"plandex-server/syntax"
+	"strings"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+const maxSymbolSignatureLen = 200
+
+type SymbolDefinition struct {
+	Type      string // tree-sitter node type, e.g. "function_declaration"
+	Line      int    // 1-based
+	Signature string // the definition's first line
+}
+
+// FindSymbolDefinitions returns the definitions in a file that declare the given name. A node is a definition if its 'name' field matches, which covers functions, methods, types, classes, and variables in most grammars. Returns nil for files without a tree-sitter parser.
+func FindSymbolDefinitions(ctx context.Context, filename string, content []byte, name string) ([]SymbolDefinition, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	parser, _, fallbackParser, _ := syntax.GetParserForPath(filename)
+	if parser != nil {
+		defer parser.Close()
+	}
+	if fallbackParser != nil {
+		defer fallbackParser.Close()
+	}
+
+	var tree *tree_sitter.Tree
+	var err error
+
+	if parser != nil {
+		tree, err = parser.ParseCtx(ctx, nil, content)
+		if err != nil {
+			return nil, err
+		}
+		defer tree.Close()
+	}
+
+	if (tree == nil || tree.RootNode().Type() == "error") && fallbackParser != nil {
+		fallbackTree, err := fallbackParser.ParseCtx(ctx, nil, content)
+		if err != nil {
+			return nil, err
+		}
+		defer fallbackTree.Close()
+		tree = fallbackTree
+	}
+
+	if tree == nil {
+		return nil, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	var defs []SymbolDefinition
+	stack := []*tree_sitter.Node{tree.RootNode()}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil && nameNode.Content(content) == name {
+			row := int(node.StartPoint().Row)
+			var sig string
+			if row < len(lines) {
+				sig = strings.TrimSpace(lines[row])
+			}
+			if len(sig) > maxSymbolSignatureLen {
+				sig = sig[:maxSymbolSignatureLen] + "…"
+			}
+			defs = append(defs, SymbolDefinition{
+				Type:      node.Type(),
+				Line:      row + 1,
+				Signature: sig,
+			})
+		}
+
+		// push in reverse so definitions come out in file order
+		for i := int(node.NamedChildCount()) - 1; i >= 0; i-- {
+			stack = append(stack, node.NamedChild(i))
+		}
+	}
+
+	return defs, nil
+}
@@ -0,0 +1,67 @@
+package file_map
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSymbolDefinitions(t *testing.T) {
+	t.Run("finds go functions, methods, and types", func(t *testing.T) {
+		content := `package main
+
+type Server struct {
+	name string
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+func Start() {
+	s := &Server{}
+	s.Start()
+}
+`
+		defs, err := FindSymbolDefinitions(context.Background(), "main.go", []byte(content), "Start")
+		assert.NoError(t, err)
+		if assert.Len(t, defs, 2) {
+			assert.Equal(t, 7, defs[0].Line)
+			assert.Equal(t, "func (s *Server) Start() error {", defs[0].Signature)
+			assert.Equal(t, 11, defs[1].Line)
+			assert.Equal(t, "func Start() {", defs[1].Signature)
+		}
+
+		defs, err = FindSymbolDefinitions(context.Background(), "main.go", []byte(content), "Server")
+		assert.NoError(t, err)
+		if assert.Len(t, defs, 1) {
+			assert.Equal(t, 3, defs[0].Line)
+		}
+	})
+
+	t.Run("finds typescript classes and functions", func(t *testing.T) {
+		content := `export class Api {
+  fetchUser(id: string) {
+    return fetch(id);
+  }
+}
+
+export function fetchUser(id: string) {
+  return new Api().fetchUser(id);
+}
+`
+		defs, err := FindSymbolDefinitions(context.Background(), "api.ts", []byte(content), "fetchUser")
+		assert.NoError(t, err)
+		if assert.Len(t, defs, 2) {
+			assert.Equal(t, 2, defs[0].Line)
+			assert.Equal(t, 7, defs[1].Line)
+		}
+	})
+
+	t.Run("returns nothing for unsupported files", func(t *testing.T) {
+		defs, err := FindSymbolDefinitions(context.Background(), "notes.txt", []byte("Start here"), "Start")
+		assert.NoError(t, err)
+		assert.Empty(t, defs)
+	})
+}
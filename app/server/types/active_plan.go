@@ -77,6 +77,7 @@ type ActivePlan struct {
 	MissingFileResponseCh chan shared.RespondMissingFileChoice
 	AutoContext           bool
 	AutoLoadContextCh     chan struct{}
+	CodeSearchResponseCh  chan *shared.RespondCodeSearchRequest
 	AllowOverwritePaths   map[string]bool
 	SkippedPaths          map[string]bool
 	StoredReplyIds        []string
@@ -123,6 +124,7 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 		MissingFileResponseCh: make(chan shared.RespondMissingFileChoice),
 		AutoContext:           autoContext,
 		AutoLoadContextCh:     make(chan struct{}),
+		CodeSearchResponseCh:  make(chan *shared.RespondCodeSearchRequest, 1),
 		AllowOverwritePaths:   map[string]bool{},
 		SkippedPaths:          map[string]bool{},
 		SessionId:             sessionId,
@@ -192,7 +194,7 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	ap.streamMu.Lock()
 
 	skipBuffer := false
-	if msg.Type == shared.StreamMessagePromptMissingFile || msg.Type == shared.StreamMessageLoadContext || msg.Type == shared.StreamMessageFinished || msg.Type == shared.StreamMessageError {
+	if msg.Type == shared.StreamMessagePromptMissingFile || msg.Type == shared.StreamMessageLoadContext || msg.Type == shared.StreamMessageCodeSearch || msg.Type == shared.StreamMessageFinished || msg.Type == shared.StreamMessageError {
 		skipBuffer = true
 
 		log.Println("ActivePlan.Stream: skipping buffer for special message")
@@ -7,7 +7,9 @@ import (
 )
 
 type ServerAuth struct {
-	AuthToken   *db.AuthToken
+	AuthToken *db.AuthToken
+	// set instead of AuthToken when the request uses an api token
+	ApiToken    *db.ApiToken
 	User        *db.User
 	OrgId       string
 	Permissions shared.Permissions
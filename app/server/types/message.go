@@ -29,14 +29,29 @@ type ExtendedChatMessagePart struct {
 type ExtendedChatMessage struct {
 	Role    string                    `json:"role"`
 	Content []ExtendedChatMessagePart `json:"content"`
+
+	// set on assistant messages that called tools, and on the 'tool' role messages with their results
+	ToolCalls  []openai.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
 }
 
 func (msg *ExtendedChatMessage) ToOpenAI() *openai.ChatCompletionMessage {
 	// If there's only one part and it's text, use simple Content field
 	if len(msg.Content) == 1 && msg.Content[0].Type == "text" {
 		return &openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content[0].Text,
+			Role:       msg.Role,
+			Content:    msg.Content[0].Text,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+	}
+
+	// an assistant message that only called tools has no content
+	if len(msg.Content) == 0 {
+		return &openai.ChatCompletionMessage{
+			Role:       msg.Role,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
 		}
 	}
 
@@ -53,6 +68,8 @@ func (msg *ExtendedChatMessage) ToOpenAI() *openai.ChatCompletionMessage {
 	return &openai.ChatCompletionMessage{
 		Role:         msg.Role,
 		MultiContent: parts,
+		ToolCalls:    msg.ToolCalls,
+		ToolCallID:   msg.ToolCallID,
 	}
 }
 
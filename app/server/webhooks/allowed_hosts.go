@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhooks can be sent to any public address. Loopback, private, and link-local addresses (like a cloud metadata endpoint) are blocked so that webhooks can't be used to reach the server's own network.
+// Operators can allow some of them with WEBHOOK_ALLOWED_HOSTS, a comma-separated list of hostnames ('hooks.internal', '*.corp.example.com') and CIDRs ('10.1.0.0/16').
+
+var blockedNets = func() []*net.IPNet {
+	var res []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"100.64.0.0/10", // carrier-grade NAT, also used for some cloud metadata endpoints
+		"192.0.0.0/24",
+		"198.18.0.0/15",
+		"240.0.0.0/4",
+	} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		res = append(res, ipNet)
+	}
+	return res
+}()
+
+type allowedHosts struct {
+	names     map[string]bool
+	wildcards []string
+	nets      []*net.IPNet
+}
+
+func getAllowedHosts() *allowedHosts {
+	res := &allowedHosts{
+		names: map[string]bool{},
+	}
+
+	for _, entry := range strings.Split(os.Getenv("WEBHOOK_ALLOWED_HOSTS"), ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			res.nets = append(res.nets, ipNet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			res.nets = append(res.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else if strings.HasPrefix(entry, "*.") {
+			res.wildcards = append(res.wildcards, strings.TrimPrefix(entry, "*"))
+		} else {
+			res.names[entry] = true
+		}
+	}
+
+	return res
+}
+
+// allowsName is true if the operator allowed the hostname itself, in which case any address it resolves to is trusted
+func (a *allowedHosts) allowsName(host string) bool {
+	host = strings.ToLower(host)
+	if a.names[host] {
+		return true
+	}
+	for _, suffix := range a.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *allowedHosts) allowsIP(ip net.IP) bool {
+	for _, ipNet := range a.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return isPublicIP(ip)
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, ipNet := range blockedNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckUrl returns an error if webhooks can't be sent to the url's host. Hostnames are checked again against the addresses they resolve to when delivering.
+func CheckUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", rawUrl)
+	}
+
+	allowed := getAllowedHosts()
+	host := u.Hostname()
+	if allowed.allowsName(host) {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && !allowed.allowsIP(ip) {
+		return fmt.Errorf("webhook host %s isn't allowed—private and loopback addresses must be added to WEBHOOK_ALLOWED_HOSTS by the server's operator", host)
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("webhook host %s isn't allowed—private and loopback addresses must be added to WEBHOOK_ALLOWED_HOSTS by the server's operator", host)
+	}
+	return nil
+}
+
+// newRestrictedHttpClient re-checks every address it connects to, so a webhook's hostname can't resolve or redirect to a host that isn't allowed
+func newRestrictedHttpClient(host string) *http.Client {
+	allowed := getAllowedHosts()
+	trustName := allowed.allowsName(host)
+
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if trustName {
+				return nil
+			}
+			ipStr, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(ipStr)
+			if ip == nil || !allowed.allowsIP(ip) {
+				return fmt.Errorf("webhook address %s isn't allowed", address)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			// proxies from the environment would make the dialer check the proxy rather than the webhook's host
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			// a client is made for each delivery, so there's no connection to reuse
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			if !strings.EqualFold(req.URL.Hostname(), host) {
+				return fmt.Errorf("webhook redirected to another host: %s", req.URL.Host)
+			}
+			return nil
+		},
+	}
+}
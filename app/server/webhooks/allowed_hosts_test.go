@@ -0,0 +1,89 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/db"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestCheckUrl(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOWED_HOSTS", "hooks.internal, 10.1.0.0/16")
+
+	allowed := []string{
+		"https://example.com/hooks/plandex",
+		"https://93.184.216.34/hooks",
+		"https://hooks.internal/plandex",
+		"http://10.1.2.3/hooks",
+	}
+	for _, u := range allowed {
+		if err := CheckUrl(u); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", u, err)
+		}
+	}
+
+	denied := []string{
+		"http://localhost:8080/hooks",
+		"http://127.0.0.1/hooks",
+		"http://[::1]/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.2.0.1/hooks",
+		"http://192.168.1.1/hooks",
+		"http://100.100.100.200/latest/meta-data",
+	}
+	for _, u := range denied {
+		if err := CheckUrl(u); err == nil {
+			t.Errorf("expected %s to be denied", u)
+		}
+	}
+}
+
+func TestSendRestrictsAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal secret"))
+	}))
+	defer srv.Close()
+
+	webhook := &db.Webhook{Url: srv.URL, Secret: "whsec_test"}
+	delivery := &db.WebhookDelivery{Id: "delivery-1", Event: shared.WebhookEventPing, Payload: `{}`}
+
+	t.Setenv("WEBHOOK_ALLOWED_HOSTS", "")
+	status, err := send(context.Background(), webhook, delivery)
+	if err == nil || status != 0 {
+		t.Fatalf("expected a loopback delivery to be blocked, got status %d, err %v", status, err)
+	}
+
+	t.Setenv("WEBHOOK_ALLOWED_HOSTS", "127.0.0.1")
+	status, err = send(context.Background(), webhook, delivery)
+	if status != http.StatusInternalServerError {
+		t.Fatalf("expected status 500 from an allowed host, got %d, err %v", status, err)
+	}
+	if err == nil || strings.Contains(err.Error(), "internal secret") {
+		t.Errorf("expected an error without the response body, got %v", err)
+	}
+}
+
+func TestSendRefusesRedirectsToOtherHosts(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	t.Setenv("WEBHOOK_ALLOWED_HOSTS", "127.0.0.1, localhost")
+
+	webhook := &db.Webhook{Url: srv.URL, Secret: "whsec_test"}
+	delivery := &db.WebhookDelivery{Id: "delivery-1", Event: shared.WebhookEventPing, Payload: `{}`}
+
+	_, err := send(context.Background(), webhook, delivery)
+	if err == nil || !strings.Contains(err.Error(), "redirected to another host") {
+		t.Errorf("expected the redirect to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"plandex-server/shutdown"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const (
+	SignatureHeader = "X-Plandex-Signature"
+	TimestampHeader = "X-Plandex-Timestamp"
+	EventHeader     = "X-Plandex-Event"
+	DeliveryHeader  = "X-Plandex-Delivery"
+
+	deliveryTimeout = 10 * time.Second
+	pollInterval    = 15 * time.Second
+	claimBatchSize  = 20
+
+	// long enough to cover a delivery attempt, so a crashed instance's claimed deliveries get retried by another
+	claimLease = 2 * time.Minute
+
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// delays between attempts—a delivery fails for good after len(retryBackoff)+1 attempts
+var retryBackoff = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+}
+
+var (
+	wakeCh      = make(chan struct{}, 1)
+	workerStart sync.Once
+)
+
+// StartWorker starts the background delivery loop. Pending deliveries are stored in the db, so any left over from a restart are picked up on the first poll.
+func StartWorker() {
+	workerStart.Do(func() {
+		go runWorker(shutdown.ShutdownCtx)
+	})
+}
+
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func runWorker(ctx context.Context) {
+	log.Println("Starting webhook delivery worker")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+		case <-wakeCh:
+		}
+
+		processDue(ctx)
+
+		if time.Since(lastCleanup) > time.Hour {
+			lastCleanup = time.Now()
+			err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryRetention))
+			if err != nil {
+				log.Printf("Error cleaning up webhook deliveries: %v\n", err)
+			}
+		}
+	}
+}
+
+func processDue(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic in webhooks processDue: %v\n%s", r, debug.Stack())
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in webhooks processDue: %v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	for {
+		deliveries, err := db.ClaimDueWebhookDeliveries(claimBatchSize, claimLease)
+		if err != nil {
+			log.Printf("Error claiming webhook deliveries: %v\n", err)
+			return
+		}
+
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *db.WebhookDelivery) {
+				defer wg.Done()
+				attemptDelivery(ctx, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil || len(deliveries) < claimBatchSize {
+			return
+		}
+	}
+}
+
+func attemptDelivery(ctx context.Context, delivery *db.WebhookDelivery) {
+	webhook, err := db.GetWebhook(delivery.OrgId, delivery.WebhookId)
+	if err != nil {
+		log.Printf("Error getting webhook %s: %v\n", delivery.WebhookId, err)
+		return
+	}
+
+	attempt := db.WebhookDeliveryAttempt{}
+
+	if webhook == nil || !webhook.Enabled {
+		msg := "webhook was disabled before delivery"
+		attempt.Status = shared.WebhookDeliveryStatusFailed
+		attempt.Error = &msg
+	} else {
+		status, err := send(ctx, webhook, delivery)
+		if status != 0 {
+			attempt.ResponseStatus = &status
+		}
+
+		if err == nil {
+			attempt.Status = shared.WebhookDeliveryStatusSucceeded
+		} else {
+			msg := err.Error()
+			attempt.Error = &msg
+
+			// delivery.Attempts doesn't include this attempt yet
+			if delivery.Attempts < len(retryBackoff) {
+				attempt.Status = shared.WebhookDeliveryStatusPending
+				attempt.RetryIn = retryBackoff[delivery.Attempts]
+			} else {
+				attempt.Status = shared.WebhookDeliveryStatusFailed
+			}
+
+			log.Printf("Webhook delivery %s to %s failed (attempt %d): %v\n", delivery.Id, webhook.Url, delivery.Attempts+1, err)
+		}
+	}
+
+	err = db.RecordWebhookDeliveryAttempt(delivery.Id, attempt)
+	if err != nil {
+		log.Printf("Error recording webhook delivery attempt: %v\n", err)
+	}
+}
+
+// send posts the payload and returns the response status (0 if there was no response)
+func send(ctx context.Context, webhook *db.Webhook, delivery *db.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plandex-Webhooks")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, delivery.Id)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := newRestrictedHttpClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	// the response body isn't kept—it's shown in the delivery log, and could be from a host that returns internal data
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("received status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>". Including the timestamp lets receivers reject replayed deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,23 @@
+package webhooks
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+
+	// expected value computed independently with: printf '1700000000.{"event":"ping"}' | openssl dgst -sha256 -hmac whsec_test
+	want := "aa8efe37b751e71157c508c5ac4acb1e9fe5225db98355dfc00f4b680afbc447"
+
+	got := Sign("whsec_test", "1700000000", body)
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+
+	if Sign("whsec_test", "1700000001", body) == got {
+		t.Errorf("signature should change with the timestamp")
+	}
+
+	if Sign("whsec_other", "1700000000", body) == got {
+		t.Errorf("signature should change with the secret")
+	}
+}
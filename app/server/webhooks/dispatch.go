@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"runtime/debug"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	Type   shared.WebhookEvent
+	OrgId  string
+	PlanId string
+	Branch string
+	Data   map[string]interface{}
+
+	// set for events that aren't tied to a plan, or to skip the plan lookup when the caller already has it
+	Plan *db.Plan
+
+	// deliver only to this webhook, regardless of its project and events—used for test pings
+	WebhookId string
+}
+
+// Dispatch queues an event for delivery to every matching webhook. It returns immediately—lookups, storage, and delivery all happen in the background so lifecycle code is never slowed down by webhooks.
+func Dispatch(ev Event) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in webhooks.Dispatch: %v\n%s", r, debug.Stack())
+				go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in webhooks.Dispatch: %v\n%s", r, debug.Stack()))
+			}
+		}()
+
+		_, err := queue(ev)
+		if err != nil {
+			log.Printf("Error dispatching %s webhook event: %v\n", ev.Type, err)
+		}
+	}()
+}
+
+// Ping queues a test delivery to a single webhook, synchronously so that the caller knows it was stored
+func Ping(orgId, webhookId string) error {
+	n, err := queue(Event{
+		Type:      shared.WebhookEventPing,
+		OrgId:     orgId,
+		WebhookId: webhookId,
+		Data:      map[string]interface{}{"message": "Test delivery from Plandex"},
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// queue stores a delivery for each matching webhook and wakes the delivery worker. Returns the number of deliveries queued.
+func queue(ev Event) (int, error) {
+	plan := ev.Plan
+	if plan == nil && ev.PlanId != "" {
+		var err error
+		plan, err = db.GetPlan(ev.PlanId)
+		if err != nil {
+			return 0, fmt.Errorf("error getting plan: %v", err)
+		}
+	}
+
+	var projectId string
+	if plan != nil {
+		projectId = plan.ProjectId
+	}
+
+	var webhooks []*db.Webhook
+	if ev.WebhookId != "" {
+		webhook, err := db.GetWebhook(ev.OrgId, ev.WebhookId)
+		if err != nil {
+			return 0, err
+		}
+		if webhook != nil {
+			webhooks = append(webhooks, webhook)
+		}
+	} else {
+		var err error
+		webhooks, err = db.ListWebhooksForEvent(ev.OrgId, projectId, ev.Type)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(webhooks) == 0 {
+		return 0, nil
+	}
+
+	payload := shared.WebhookPayload{
+		Event:     ev.Type,
+		CreatedAt: time.Now().UTC(),
+		OrgId:     ev.OrgId,
+		ProjectId: projectId,
+		Branch:    ev.Branch,
+		Data:      ev.Data,
+	}
+	if plan != nil {
+		payload.PlanId = plan.Id
+		payload.PlanName = plan.Name
+	}
+
+	for _, webhook := range webhooks {
+		// each delivery gets its own id so receivers can dedupe retries
+		payload.Id = uuid.New().String()
+
+		bytes, err := json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("error marshalling webhook payload: %v", err)
+		}
+
+		err = db.CreateWebhookDelivery(&db.WebhookDelivery{
+			Id:        payload.Id,
+			OrgId:     ev.OrgId,
+			WebhookId: webhook.Id,
+			Event:     ev.Type,
+			Payload:   string(bytes),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	wake()
+
+	return len(webhooks), nil
+}
@@ -29,6 +29,8 @@ When checking for sufficient credits on Plandex Cloud, we use MaxOutputTokens-In
 
 'ModelCompatibility' is used to check for feature support (like image support).
 
+'InputPricePerMillion', 'OutputPricePerMillion', and 'CachedInputPricePerMillion' are list prices in USD per 1M tokens. They seed the self-hosted usage ledger and can be overridden per org. When 'CachedInputPricePerMillion' is 0, cached input is charged at the input price.
+
 'BaseUrl' is the base URL for the provider.
 
 'PreferredOutputFormat' is the preferred output format for the model—currently either 'ModelOutputFormatToolCallJson' or 'ModelOutputFormatXml' — OpenAI models like JSON (and benefit from strict JSON schemas), while most other providers are unreliable for JSON generation and do better with XML, even if they claim to support JSON.
@@ -44,6 +46,8 @@ When checking for sufficient credits on Plandex Cloud, we use MaxOutputTokens-In
 'PredictedOutputEnabled' is used to enable predicted output for the model (currently only supported by gpt-4o).
 
 'ApiKeyEnvVar' is the environment variable that contains the API key for the model.
+
+'IsEmbeddingModel' marks models that produce embeddings rather than completions. They can only be used for the 'embeddings' role, and they don't need the output and conversation limits that completion models do—'MaxTokens' is the input limit for a single chunk.
 */
 
 var BuiltInModels = []*BaseModelConfigSchema{
@@ -56,6 +60,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 40000, ModelCompatibility: FullCompatibility,
 			PreferredOutputFormat: ModelOutputFormatXml, SystemPromptDisabled: true,
 			RoleParamsDisabled: true, ReasoningEffortEnabled: true, StopDisabled: true,
+			InputPricePerMillion: 2, OutputPricePerMillion: 8, CachedInputPricePerMillion: 0.5,
 		},
 		RequiresVariantOverrides: []string{"ReasoningEffort"},
 		Variants: []BaseModelConfigVariant{
@@ -80,7 +85,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 40000, ModelCompatibility: FullCompatibility,
 			PreferredOutputFormat: ModelOutputFormatToolCallJson, SystemPromptDisabled: true,
 			RoleParamsDisabled: true, ReasoningEffortEnabled: true, ReasoningEffort: ReasoningEffortHigh,
-			StopDisabled: true,
+			StopDisabled: true, InputPricePerMillion: 1.1, OutputPricePerMillion: 4.4, CachedInputPricePerMillion: 0.275,
 		},
 		RequiresVariantOverrides: []string{"ReasoningEffort"},
 		Variants: []BaseModelConfigVariant{
@@ -104,6 +109,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputPricePerMillion: 2, OutputPricePerMillion: 8, CachedInputPricePerMillion: 0.5,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1"},
@@ -121,6 +127,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputPricePerMillion: 0.4, OutputPricePerMillion: 1.6, CachedInputPricePerMillion: 0.1,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1-mini"},
@@ -138,6 +145,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputPricePerMillion: 0.1, OutputPricePerMillion: 0.4, CachedInputPricePerMillion: 0.025,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1-nano"},
@@ -155,7 +163,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000, MaxOutputTokens: 128000,
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
-			TokenEstimatePaddingPct: 0.10,
+			TokenEstimatePaddingPct: 0.10, InputPricePerMillion: 15, OutputPricePerMillion: 75, CachedInputPricePerMillion: 1.5,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -184,7 +192,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 1000000, MaxOutputTokens: 128000,
 			ReservedOutputTokens: 40000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
-			TokenEstimatePaddingPct: 0.10,
+			TokenEstimatePaddingPct: 0.10, InputPricePerMillion: 3, OutputPricePerMillion: 15, CachedInputPricePerMillion: 0.3,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -214,7 +222,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000, MaxOutputTokens: 128000,
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
-			TokenEstimatePaddingPct: 0.10,
+			TokenEstimatePaddingPct: 0.10, InputPricePerMillion: 3, OutputPricePerMillion: 15, CachedInputPricePerMillion: 0.3,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -242,7 +250,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000, MaxOutputTokens: 128000,
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
-			TokenEstimatePaddingPct: 0.10,
+			TokenEstimatePaddingPct: 0.10, InputPricePerMillion: 3, OutputPricePerMillion: 15, CachedInputPricePerMillion: 0.3,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderAnthropic, ModelName: "anthropic/claude-3-5-sonnet-latest"},
@@ -261,7 +269,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000, MaxOutputTokens: 8192,
 			ReservedOutputTokens: 8192, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
-			TokenEstimatePaddingPct: 0.10,
+			TokenEstimatePaddingPct: 0.10, InputPricePerMillion: 0.8, OutputPricePerMillion: 4, CachedInputPricePerMillion: 0.08,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderAnthropic, ModelName: "anthropic/claude-3-5-haiku-latest"},
@@ -279,7 +287,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 2000000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 1.25, OutputPricePerMillion: 5, CachedInputPricePerMillion: 0.3125,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderGoogleAIStudio, ModelName: "gemini/gemini-1.5-pro"},
@@ -296,7 +304,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1048576,
 			MaxOutputTokens: 65535, ReservedOutputTokens: 65535,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 1.25, OutputPricePerMillion: 10, CachedInputPricePerMillion: 0.31,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderGoogleAIStudio, ModelName: "gemini/gemini-2.5-pro"},
@@ -313,7 +321,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1048576,
 			MaxOutputTokens: 65535, ReservedOutputTokens: 65535,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 0.3, OutputPricePerMillion: 2.5, CachedInputPricePerMillion: 0.075,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -342,7 +350,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 64000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 0.27, OutputPricePerMillion: 1.1, CachedInputPricePerMillion: 0.07,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderDeepSeek, ModelName: "deepseek/deepseek-chat"},
@@ -358,7 +366,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 164000,
 			MaxOutputTokens: 33000, ReservedOutputTokens: 20000,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 0.55, OutputPricePerMillion: 2.19, CachedInputPricePerMillion: 0.14,
 		},
 		Variants: []BaseModelConfigVariant{
 			{VariantTag: "visible", IsDefaultVariant: true, Description: "(reasoning visible)", Overrides: BaseModelShared{IncludeReasoning: true}},
@@ -431,7 +439,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
-			PreferredOutputFormat: ModelOutputFormatXml,
+			PreferredOutputFormat: ModelOutputFormatXml, InputPricePerMillion: 0.6, OutputPricePerMillion: 2.2, CachedInputPricePerMillion: 0.11,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderNanoGPT, ModelName: "z-ai/glm-4.6"},
@@ -445,8 +453,8 @@ var BuiltInModels = []*BaseModelConfigSchema{
 		BaseModelShared: BaseModelShared{
 			DefaultMaxConvoTokens: 15000, MaxTokens: 200000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
-			PreferredOutputFormat: ModelOutputFormatXml,
-			IncludeReasoning:      true,
+			PreferredOutputFormat: ModelOutputFormatXml, IncludeReasoning: true,
+			InputPricePerMillion: 0.6, OutputPricePerMillion: 2.2, CachedInputPricePerMillion: 0.11,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderNanoGPT, ModelName: "z-ai/glm-4.6:thinking"},
@@ -639,6 +647,31 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			{Provider: ModelProviderOpenRouter, ModelName: "perplexity/sonar-reasoning"},
 		},
 	},
+
+	{
+		ModelTag:    "openai/text-embedding-3-small",
+		Publisher:   ModelPublisherOpenAI,
+		Description: "OpenAI text-embedding-3-small",
+		BaseModelShared: BaseModelShared{
+			MaxTokens: 8191, IsEmbeddingModel: true,
+			InputPricePerMillion: 0.02,
+		},
+		Providers: []BaseModelUsesProvider{
+			{Provider: ModelProviderOpenAI, ModelName: "text-embedding-3-small"},
+		},
+	},
+	{
+		ModelTag:    "openai/text-embedding-3-large",
+		Publisher:   ModelPublisherOpenAI,
+		Description: "OpenAI text-embedding-3-large",
+		BaseModelShared: BaseModelShared{
+			MaxTokens: 8191, IsEmbeddingModel: true,
+			InputPricePerMillion: 0.13,
+		},
+		Providers: []BaseModelUsesProvider{
+			{Provider: ModelProviderOpenAI, ModelName: "text-embedding-3-large"},
+		},
+	},
 }
 
 var BuiltInBaseModelsById = map[ModelId]*BaseModelConfigSchema{}
@@ -732,24 +765,32 @@ func init() {
 			panic("model id is not set")
 		}
 
-		if model.DefaultMaxConvoTokens == 0 {
-			spew.Dump(model)
-			panic("default max convo tokens is not set")
-		}
-
 		if model.MaxTokens == 0 {
 			spew.Dump(model)
 			panic("max tokens is not set")
 		}
 
-		if model.MaxOutputTokens == 0 {
-			spew.Dump(model)
-			panic("max output tokens is not set")
-		}
+		// embedding models don't produce output, so output and conversation limits only apply to completion models
+		if !model.IsEmbeddingModel {
+			if model.DefaultMaxConvoTokens == 0 {
+				spew.Dump(model)
+				panic("default max convo tokens is not set")
+			}
 
-		if model.ReservedOutputTokens == 0 {
-			spew.Dump(model)
-			panic("reserved output tokens is not set")
+			if model.MaxOutputTokens == 0 {
+				spew.Dump(model)
+				panic("max output tokens is not set")
+			}
+
+			if model.ReservedOutputTokens == 0 {
+				spew.Dump(model)
+				panic("reserved output tokens is not set")
+			}
+
+			if model.PreferredOutputFormat == "" {
+				spew.Dump(model)
+				panic("preferred model output format is not set")
+			}
 		}
 
 		if model.ApiKeyEnvVar == "" && len(model.ExtraAuthVars) == 0 && !model.SkipAuth && !model.HasAWSAuth && !model.HasClaudeMaxAuth {
@@ -762,11 +803,6 @@ func init() {
 			panic("base url is not set")
 		}
 
-		if model.PreferredOutputFormat == "" {
-			spew.Dump(model)
-			panic("preferred model output format is not set")
-		}
-
 		compositeKey := string(model.Provider) + "/" + string(model.ModelId)
 		AvailableModelsByComposite[compositeKey] = model
 	}
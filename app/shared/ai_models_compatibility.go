@@ -14,6 +14,12 @@ var RequiredCompatibilityByRole = map[ModelRole]ModelCompatibility{
 	ModelRoleArchitect:        {},
 	ModelRoleCoder:            {},
 	ModelRoleWholeFileBuilder: {},
+	ModelRoleEmbeddings:       {},
+}
+
+// embedding models can only fill the embeddings role, and completion models can fill every other role
+func isCompatibleModelKind(isEmbeddingModel bool, role ModelRole) bool {
+	return isEmbeddingModel == (role == ModelRoleEmbeddings)
 }
 
 func FilterBuiltInCompatibleModels(models []*BaseModelConfigSchema, role ModelRole) []*BaseModelConfigSchema {
@@ -21,7 +27,10 @@ func FilterBuiltInCompatibleModels(models []*BaseModelConfigSchema, role ModelRo
 	var compatibleModels []*BaseModelConfigSchema
 
 	for _, model := range models {
-		// no compatibility checks are needed in v2, but keeping this here in case compatibility checks are needed in the future
+		// no other compatibility checks are needed in v2, but keeping this here in case compatibility checks are needed in the future
+		if !isCompatibleModelKind(model.IsEmbeddingModel, role) {
+			continue
+		}
 
 		compatibleModels = append(compatibleModels, model)
 	}
@@ -34,7 +43,10 @@ func FilterCustomCompatibleModels(models []*CustomModel, role ModelRole) []*Cust
 	var compatibleModels []*CustomModel
 
 	for _, model := range models {
-		// no compatibility checks are needed in v2, but keeping this here in case compatibility checks are needed in the future
+		// no other compatibility checks are needed in v2, but keeping this here in case compatibility checks are needed in the future
+		if !isCompatibleModelKind(model.IsEmbeddingModel, role) {
+			continue
+		}
 
 		compatibleModels = append(compatibleModels, model)
 	}
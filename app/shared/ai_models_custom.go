@@ -50,6 +50,8 @@ type CustomProvider struct {
 	ApiKeyEnvVar  string                       `json:"apiKeyEnvVar,omitempty"`
 	ExtraAuthVars []ModelProviderExtraAuthVars `json:"extraAuthVars,omitempty"`
 
+	ModelProviderRateLimits
+
 	CreatedAt *time.Time `json:"createdAt,omitempty"`
 	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
 }
@@ -269,6 +271,8 @@ func (cp *CustomProvider) ToModelProviderConfigSchema() ModelProviderConfigSchem
 		SkipAuth:       cp.SkipAuth,
 		ApiKeyEnvVar:   cp.ApiKeyEnvVar,
 		ExtraAuthVars:  cp.ExtraAuthVars,
+
+		ModelProviderRateLimits: cp.ModelProviderRateLimits,
 	}
 }
 
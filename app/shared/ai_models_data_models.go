@@ -33,7 +33,7 @@ type BaseModelShared struct {
 	MaxTokens                   int               `json:"maxTokens"`
 	MaxOutputTokens             int               `json:"maxOutputTokens"`
 	ReservedOutputTokens        int               `json:"reservedOutputTokens"`
-	PreferredOutputFormat       ModelOutputFormat `json:"preferredOutputFormat"`
+	PreferredOutputFormat       ModelOutputFormat `json:"preferredOutputFormat,omitempty"`
 	SystemPromptDisabled        bool              `json:"systemPromptDisabled,omitempty"`
 	RoleParamsDisabled          bool              `json:"roleParamsDisabled,omitempty"`
 	StopDisabled                bool              `json:"stopDisabled,omitempty"`
@@ -46,6 +46,10 @@ type BaseModelShared struct {
 	SupportsCacheControl        bool              `json:"supportsCacheControl,omitempty"`
 	SingleMessageNoSystemPrompt bool              `json:"singleMessageNoSystemPrompt,omitempty"`
 	TokenEstimatePaddingPct     float64           `json:"tokenEstimatePaddingPct,omitempty"`
+	InputPricePerMillion        float64           `json:"inputPricePerMillion,omitempty"`
+	OutputPricePerMillion       float64           `json:"outputPricePerMillion,omitempty"`
+	CachedInputPricePerMillion  float64           `json:"cachedInputPricePerMillion,omitempty"`
+	IsEmbeddingModel            bool              `json:"isEmbeddingModel,omitempty"`
 	ModelCompatibility
 }
 
@@ -722,6 +726,7 @@ type ClientModelPackSchemaRoles struct {
 	Namer            RoleJSON `json:"names"`
 	CommitMsg        RoleJSON `json:"commitMessages"`
 	ExecStatus       RoleJSON `json:"autoContinue"`
+	Embeddings       RoleJSON `json:"embeddings,omitempty"`
 }
 
 func (c *ClientModelPackSchemaRoles) ToModelPackSchemaRoles() ModelPackSchemaRoles {
@@ -790,6 +795,10 @@ func (c *ClientModelPackSchemaRoles) ToModelPackSchemaRoles() ModelPackSchemaRol
 		converted := convertField(c.Architect)
 		res.Architect = converted
 	}
+	if c.Embeddings != nil {
+		converted := convertField(c.Embeddings)
+		res.Embeddings = converted
+	}
 
 	return res
 }
@@ -805,6 +814,7 @@ type ModelPackSchemaRoles struct {
 	CommitMsg        ModelRoleConfigSchema  `json:"commitMsg"`
 	ExecStatus       ModelRoleConfigSchema  `json:"execStatus"`
 	Architect        *ModelRoleConfigSchema `json:"contextLoader,omitempty"`
+	Embeddings       *ModelRoleConfigSchema `json:"embeddings,omitempty"` // optional, semantic retrieval for auto context is off without it
 }
 
 func (m *ModelPackSchemaRoles) ToClientModelPackSchemaRoles() ClientModelPackSchemaRoles {
@@ -831,6 +841,10 @@ func (m *ModelPackSchemaRoles) ToClientModelPackSchemaRoles() ClientModelPackSch
 		val := m.Architect.ToClientVal()
 		res.Architect = &val
 	}
+	if m.Embeddings != nil {
+		val := m.Embeddings.ToClientVal()
+		res.Embeddings = &val
+	}
 
 	return res
 }
@@ -866,14 +880,53 @@ func (m *ModelPackSchema) AllModelIds() []ModelId {
 		ids = append(ids, m.Architect.AllModelIds()...)
 	}
 
+	if m.Embeddings != nil {
+		ids = append(ids, m.Embeddings.AllModelIds()...)
+	}
+
 	return ids
 }
 
+// CheckModelKinds returns an error if an embedding model is used for a completion role or a completion model is used for the embeddings role. Fallbacks are included.
+func (m *ModelPackSchema) CheckModelKinds(isEmbeddingModel func(ModelId) bool) error {
+	roles := map[ModelRole]*ModelRoleConfigSchema{
+		ModelRolePlanner:          &m.Planner,
+		ModelRoleCoder:            m.Coder,
+		ModelRolePlanSummary:      &m.PlanSummary,
+		ModelRoleBuilder:          &m.Builder,
+		ModelRoleWholeFileBuilder: m.WholeFileBuilder,
+		ModelRoleName:             &m.Namer,
+		ModelRoleCommitMsg:        &m.CommitMsg,
+		ModelRoleExecStatus:       &m.ExecStatus,
+		ModelRoleArchitect:        m.Architect,
+		ModelRoleEmbeddings:       m.Embeddings,
+	}
+
+	for _, role := range AllModelRoles {
+		schema := roles[role]
+		if schema == nil {
+			continue
+		}
+		for _, id := range schema.AllModelIds() {
+			if isCompatibleModelKind(isEmbeddingModel(id), role) {
+				continue
+			}
+			if role == ModelRoleEmbeddings {
+				return fmt.Errorf("'%s' isn't an embedding model, so it can't be used for the %s role", id, role)
+			}
+			return fmt.Errorf("'%s' is an embedding model, so it can only be used for the %s role", id, ModelRoleEmbeddings)
+		}
+	}
+
+	return nil
+}
+
 func (m *ModelPackSchema) ToModelPack() ModelPack {
 	var (
 		coder            *ModelRoleConfig
 		wholeFileBuilder *ModelRoleConfig
 		architect        *ModelRoleConfig
+		embeddings       *ModelRoleConfig
 	)
 
 	if m.Coder != nil {
@@ -891,6 +944,11 @@ func (m *ModelPackSchema) ToModelPack() ModelPack {
 		architect = &c
 	}
 
+	if m.Embeddings != nil {
+		c := m.Embeddings.ToModelRoleConfig(ModelRoleEmbeddings)
+		embeddings = &c
+	}
+
 	var maxConvoTokens int
 	if m.Planner.MaxConvoTokens != nil {
 		maxConvoTokens = *m.Planner.MaxConvoTokens
@@ -914,6 +972,7 @@ func (m *ModelPackSchema) ToModelPack() ModelPack {
 		CommitMsg:        m.CommitMsg.ToModelRoleConfig(ModelRoleCommitMsg),
 		ExecStatus:       m.ExecStatus.ToModelRoleConfig(ModelRoleExecStatus),
 		Architect:        architect,
+		Embeddings:       embeddings,
 	}
 }
 
@@ -931,6 +990,7 @@ type ModelPack struct {
 	CommitMsg        ModelRoleConfig   `json:"commitMsg"`
 	ExecStatus       ModelRoleConfig   `json:"execStatus"`
 	Architect        *ModelRoleConfig  `json:"contextLoader"`
+	Embeddings       *ModelRoleConfig  `json:"embeddings,omitempty"` // optional, semantic retrieval for auto context is off without it
 }
 
 func (m *ModelPack) GetCoder() ModelRoleConfig {
@@ -970,6 +1030,11 @@ func (m *ModelPack) ToModelPackSchema() *ModelPackSchema {
 		c := m.Architect.ToModelRoleConfigSchema()
 		architect = &c
 	}
+	var embeddings *ModelRoleConfigSchema
+	if m.Embeddings != nil {
+		c := m.Embeddings.ToModelRoleConfigSchema()
+		embeddings = &c
+	}
 
 	return &ModelPackSchema{
 		Name:        m.Name,
@@ -985,6 +1050,7 @@ func (m *ModelPack) ToModelPackSchema() *ModelPackSchema {
 			Namer:            m.Namer.ToModelRoleConfigSchema(),
 			CommitMsg:        m.CommitMsg.ToModelRoleConfigSchema(),
 			ExecStatus:       m.ExecStatus.ToModelRoleConfigSchema(),
+			Embeddings:       embeddings,
 		},
 	}
 }
@@ -75,6 +75,47 @@ func (m ModelRoleConfig) GetRoleForInputTokens(inputTokens int, settings *PlanSe
 	return currentConfig
 }
 
+// GetRoleForInputTokenCounter works like GetRoleForInputTokens, but counts the input with each candidate model's own tokenizer as it walks the fallback chain, since the same input can be well under one model's limit and over another's
+// counts are cached per tokenizer so the input is only tokenized once per distinct vocabulary
+
+func (m ModelRoleConfig) GetRoleForInputTokenCounter(countFn func(tokenizer Tokenizer) int, settings *PlanSettings) ModelRoleConfig {
+	countsByTokenizer := map[TokenizerName]int{}
+	count := func(config ModelRoleConfig) int {
+		tokenizer := config.GetTokenizer(settings)
+		n, ok := countsByTokenizer[tokenizer.Name()]
+		if !ok {
+			n = countFn(tokenizer)
+			countsByTokenizer[tokenizer.Name()] = n
+		}
+		return n
+	}
+
+	var currentConfig ModelRoleConfig = m
+	var n int = 0
+	for {
+		sharedBaseConfig := currentConfig.GetSharedBaseConfig(settings)
+		if sharedBaseConfig == nil {
+			return currentConfig
+		}
+
+		inputTokens := int(float64(count(currentConfig)) * (1 + sharedBaseConfig.TokenEstimatePaddingPct))
+		if sharedBaseConfig.MaxTokens >= inputTokens {
+			return currentConfig
+		}
+
+		if currentConfig.LargeContextFallback == nil {
+			return currentConfig
+		} else {
+			currentConfig = *currentConfig.LargeContextFallback
+		}
+		n++
+		if n > maxFallbackDepth {
+			break
+		}
+	}
+	return currentConfig
+}
+
 func (m ModelRoleConfig) GetRoleForOutputTokens(outputTokens int, settings *PlanSettings) ModelRoleConfig {
 	sharedBaseConfig := m.GetSharedBaseConfig(settings)
 
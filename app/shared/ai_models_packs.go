@@ -467,6 +467,10 @@ func cloneSchema(schema ModelPackSchema) ModelPackSchema {
 		tmp := *schema.WholeFileBuilder
 		res.WholeFileBuilder = &tmp
 	}
+	if schema.Embeddings != nil {
+		tmp := *schema.Embeddings
+		res.Embeddings = &tmp
+	}
 
 	return res
 }
@@ -108,6 +108,19 @@ type ModelProviderConfigSchema struct {
 
 	ApiKeyEnvVar  string                       `json:"apiKeyEnvVar,omitempty"`
 	ExtraAuthVars []ModelProviderExtraAuthVars `json:"extraAuthVars,omitempty"`
+
+	ModelProviderRateLimits
+}
+
+// ModelProviderRateLimits caps how fast the server sends requests to a provider for each api key. Zero values mean no limit.
+type ModelProviderRateLimits struct {
+	RequestsPerMinute     int `json:"requestsPerMinute,omitempty"`
+	TokensPerMinute       int `json:"tokensPerMinute,omitempty"`
+	MaxConcurrentRequests int `json:"maxConcurrentRequests,omitempty"`
+}
+
+func (l ModelProviderRateLimits) IsEmpty() bool {
+	return l.RequestsPerMinute <= 0 && l.TokensPerMinute <= 0 && l.MaxConcurrentRequests <= 0
 }
 
 func (m *ModelProviderConfigSchema) ToComposite() string {
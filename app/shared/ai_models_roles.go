@@ -12,9 +12,10 @@ const (
 	ModelRoleName             ModelRole = "names"
 	ModelRoleCommitMsg        ModelRole = "commit-messages"
 	ModelRoleExecStatus       ModelRole = "auto-continue"
+	ModelRoleEmbeddings       ModelRole = "embeddings"
 )
 
-var AllModelRoles = []ModelRole{ModelRolePlanner, ModelRoleCoder, ModelRoleArchitect, ModelRolePlanSummary, ModelRoleBuilder, ModelRoleWholeFileBuilder, ModelRoleName, ModelRoleCommitMsg, ModelRoleExecStatus}
+var AllModelRoles = []ModelRole{ModelRolePlanner, ModelRoleCoder, ModelRoleArchitect, ModelRolePlanSummary, ModelRoleBuilder, ModelRoleWholeFileBuilder, ModelRoleName, ModelRoleCommitMsg, ModelRoleExecStatus, ModelRoleEmbeddings}
 
 var ModelRoleDescriptions = map[ModelRole]string{
 	ModelRolePlanner:          "replies to prompts and makes plans",
@@ -26,4 +27,5 @@ var ModelRoleDescriptions = map[ModelRole]string{
 	ModelRoleCommitMsg:        "writes commit messages",
 	ModelRoleExecStatus:       "determines whether to auto-continue",
 	ModelRoleArchitect:        "makes high level plan and decides what context to load using codebase map",
+	ModelRoleEmbeddings:       "embeds code chunks so auto context can find relevant code in large projects",
 }
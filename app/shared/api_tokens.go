@@ -0,0 +1,115 @@
+package shared
+
+import (
+	"strings"
+	"time"
+)
+
+// API tokens are sent as-is in the Authorization header rather than as an encoded AuthHeader, so they're easy to use from scripts and CI
+const ApiTokenPrefix = "plx_"
+
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, ApiTokenPrefix)
+}
+
+type ApiTokenScope string
+
+const (
+	// read-only access to plans and org info
+	ApiTokenScopeRead ApiTokenScope = "read"
+	// also create and run plans
+	ApiTokenScopeWrite ApiTokenScope = "write"
+	// also the org administration permissions of the token user's role
+	ApiTokenScopeAdmin ApiTokenScope = "admin"
+)
+
+var ApiTokenScopes = []ApiTokenScope{
+	ApiTokenScopeRead,
+	ApiTokenScopeWrite,
+	ApiTokenScopeAdmin,
+}
+
+func (scope ApiTokenScope) IsValid() bool {
+	for _, s := range ApiTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// the permissions a token with the write scope keeps from its user's org role
+var apiTokenWritePermissions = map[Permission]bool{
+	PermissionCreateProject:       true,
+	PermissionCreatePlan:          true,
+	PermissionManageAnyPlanShares: true,
+	PermissionRenameAnyPlan:       true,
+	PermissionDeleteAnyPlan:       true,
+	PermissionUpdateAnyPlan:       true,
+	PermissionArchiveAnyPlan:      true,
+	PermissionExecCommands:        true,
+	PermissionChangeModelPack:     true,
+	PermissionForceLoadIgnored:    true,
+}
+
+// HasApiTokenScope reports whether the scopes include the given scope. Each scope includes the ones before it.
+func HasApiTokenScope(scopes []ApiTokenScope, scope ApiTokenScope) bool {
+	for _, s := range scopes {
+		if s == scope ||
+			s == ApiTokenScopeAdmin ||
+			(s == ApiTokenScopeWrite && scope == ApiTokenScopeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopePermissions limits a user's permissions to what an API token with the given scopes allows
+func ScopePermissions(perms Permissions, scopes []ApiTokenScope) Permissions {
+	if HasApiTokenScope(scopes, ApiTokenScopeAdmin) {
+		return perms
+	}
+
+	res := Permissions{}
+	if !HasApiTokenScope(scopes, ApiTokenScopeWrite) {
+		return res
+	}
+
+	for p := range perms {
+		perm := Permission(strings.Split(p, "|")[0])
+		if apiTokenWritePermissions[perm] {
+			res[p] = true
+		}
+	}
+	return res
+}
+
+type ApiToken struct {
+	Id     string          `json:"id"`
+	Name   string          `json:"name"`
+	Scopes []ApiTokenScope `json:"scopes"`
+
+	// the user the token authenticates as—a service account's user for service account tokens
+	UserId           string `json:"userId"`
+	UserEmail        string `json:"userEmail"`
+	UserName         string `json:"userName"`
+	IsServiceAccount bool   `json:"isServiceAccount"`
+	CreatorId        string `json:"creatorId"`
+
+	// the start of the token, to help identify it—the full token is only shown when it's created
+	TokenPrefix string `json:"tokenPrefix"`
+
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// A service account is an org member that only authenticates with API tokens. Its access is set by its org role like any other user.
+type ServiceAccount struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	OrgRoleId string    `json:"orgRoleId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,57 @@
+package shared
+
+import "testing"
+
+func TestScopePermissions(t *testing.T) {
+	perms := Permissions{
+		string(PermissionCreatePlan):                 true,
+		string(PermissionUpdateAnyPlan):              true,
+		string(PermissionInviteUser):                 true,
+		string(PermissionManageWebhooks):             true,
+		string(PermissionExecCommands):               true,
+		string(PermissionManageCustomProviders):      true,
+		string(PermissionSetUserRole) + "|role-id-1": true,
+	}
+
+	admin := ScopePermissions(perms, []ApiTokenScope{ApiTokenScopeAdmin})
+	if len(admin) != len(perms) {
+		t.Errorf("admin scope: got %d permissions, want %d", len(admin), len(perms))
+	}
+
+	write := ScopePermissions(perms, []ApiTokenScope{ApiTokenScopeWrite})
+	if !write.HasPermission(PermissionCreatePlan) || !write.HasPermission(PermissionUpdateAnyPlan) {
+		t.Errorf("write scope: missing plan permissions: %v", write)
+	}
+	if !write.HasPermission(PermissionExecCommands) {
+		t.Errorf("write scope: missing exec commands permission: %v", write)
+	}
+	if write.HasPermission(PermissionInviteUser) || write.HasPermission(PermissionSetUserRole) || write.HasPermission(PermissionManageCustomProviders) {
+		t.Errorf("write scope: kept org admin permissions: %v", write)
+	}
+
+	read := ScopePermissions(perms, []ApiTokenScope{ApiTokenScopeRead})
+	if len(read) != 0 {
+		t.Errorf("read scope: got %v, want no permissions", read)
+	}
+}
+
+func TestHasApiTokenScope(t *testing.T) {
+	tests := []struct {
+		scopes []ApiTokenScope
+		scope  ApiTokenScope
+		want   bool
+	}{
+		{[]ApiTokenScope{ApiTokenScopeRead}, ApiTokenScopeRead, true},
+		{[]ApiTokenScope{ApiTokenScopeRead}, ApiTokenScopeWrite, false},
+		{[]ApiTokenScope{ApiTokenScopeWrite}, ApiTokenScopeRead, true},
+		{[]ApiTokenScope{ApiTokenScopeWrite}, ApiTokenScopeAdmin, false},
+		{[]ApiTokenScope{ApiTokenScopeAdmin}, ApiTokenScopeWrite, true},
+		{nil, ApiTokenScopeRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasApiTokenScope(tt.scopes, tt.scope); got != tt.want {
+			t.Errorf("HasApiTokenScope(%v, %q) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
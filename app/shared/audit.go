@@ -0,0 +1,61 @@
+package shared
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionPlanCreate    AuditAction = "plan.create"
+	AuditActionPlanRename    AuditAction = "plan.rename"
+	AuditActionPlanDelete    AuditAction = "plan.delete"
+	AuditActionPlanDeleteAll AuditAction = "plan.delete_all"
+	AuditActionPlanArchive   AuditAction = "plan.archive"
+	AuditActionPlanUnarchive AuditAction = "plan.unarchive"
+	AuditActionPlanApply     AuditAction = "plan.apply"
+	AuditActionPlanRewind    AuditAction = "plan.rewind"
+
+	AuditActionContextLoadIgnored AuditAction = "context.load_ignored"
+
+	AuditActionCommandsExecute AuditAction = "commands.execute"
+
+	AuditActionInviteCreate   AuditAction = "invite.create"
+	AuditActionInviteDelete   AuditAction = "invite.delete"
+	AuditActionUserRemove     AuditAction = "user.remove"
+	AuditActionUserRoleChange AuditAction = "user.role_change"
+
+	AuditActionServiceAccountCreate AuditAction = "service_account.create"
+	AuditActionApiTokenCreate       AuditAction = "api_token.create"
+	AuditActionApiTokenRevoke       AuditAction = "api_token.revoke"
+
+	AuditActionRoleCreate AuditAction = "role.create"
+	AuditActionRoleUpdate AuditAction = "role.update"
+	AuditActionRoleDelete AuditAction = "role.delete"
+
+	AuditActionSettingsUpdate        AuditAction = "settings.update"
+	AuditActionDefaultSettingsUpdate AuditAction = "settings.update_default"
+	AuditActionConfigUpdate          AuditAction = "config.update"
+	AuditActionDefaultConfigUpdate   AuditAction = "config.update_default"
+	AuditActionCustomModelsUpdate    AuditAction = "models.update"
+)
+
+type AuditEvent struct {
+	Id     string      `json:"id"`
+	Action AuditAction `json:"action"`
+
+	UserId    string `json:"userId,omitempty"`
+	UserEmail string `json:"userEmail,omitempty"`
+	// set when the action was taken with an API token rather than a CLI session
+	ApiTokenId string `json:"apiTokenId,omitempty"`
+	Ip         string `json:"ip,omitempty"`
+
+	PlanId   string `json:"planId,omitempty"`
+	PlanName string `json:"planName,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+
+	// the invite, user, or other resource the action applies to, when it isn't a plan
+	TargetId string `json:"targetId,omitempty"`
+
+	Data map[string]interface{} `json:"data,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -23,6 +23,8 @@ const (
 	ApiErrorTypeTrialMessagesExceeded ApiErrorType = "trial_messages_exceeded"
 	ApiErrorTypeTrialActionNotAllowed ApiErrorType = "trial_action_not_allowed"
 
+	ApiErrorTypeSsoRequired ApiErrorType = "sso_required"
+
 	ApiErrorTypeContinueNoMessages ApiErrorType = "continue_no_messages"
 
 	ApiErrorTypeCloudInsufficientCredits ApiErrorType = "cloud_insufficient_credits"
@@ -73,6 +75,7 @@ type ClientAccount struct {
 	UserId      string `json:"userId"`
 	Token       string `json:"token"`
 	IsLocalMode bool   `json:"isLocalMode"`
+	IsSso       bool   `json:"isSso,omitempty"`
 
 	IsTrial bool `json:"isTrial"` // legacy field
 }
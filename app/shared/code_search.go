@@ -0,0 +1,68 @@
+package shared
+
+// The planner can search the project while it's responding. The server streams a CodeSearchRequest to the client, which runs the search over the project's files (respecting .gitignore and .plandexignore) and responds with RespondCodeSearchRequest. Results are only passed back to the model for the current response—they aren't loaded into context.
+
+type CodeSearchKind string
+
+const (
+	// lines matching a literal string or regex, like ripgrep
+	CodeSearchKindText CodeSearchKind = "text"
+
+	// definitions of a function, type, class, etc. by name, plus the lines that reference it
+	CodeSearchKindSymbol CodeSearchKind = "symbol"
+)
+
+const (
+	CodeSearchDefaultMaxResults = 50
+	CodeSearchMaxResults        = 200
+
+	// files larger than this are skipped
+	CodeSearchMaxFileSize = 1024 * 1024
+
+	// for symbol searches, the client sends back the files with matches so definitions can be found by parsing them on the server
+	CodeSearchMaxSymbolFiles    = 20
+	CodeSearchMaxSymbolFileSize = 200 * 1024
+)
+
+type CodeSearchRequest struct {
+	Id            string         `json:"id"`
+	Kind          CodeSearchKind `json:"kind"`
+	Query         string         `json:"query"`
+	IsRegex       bool           `json:"isRegex,omitempty"`
+	CaseSensitive bool           `json:"caseSensitive,omitempty"`
+
+	// files or directories to limit the search to—the whole project is searched if empty
+	Paths []string `json:"paths,omitempty"`
+
+	MaxResults int `json:"maxResults,omitempty"`
+}
+
+type CodeSearchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type CodeSearchFile struct {
+	Path string `json:"path"`
+	Body string `json:"body"`
+}
+
+type RespondCodeSearchRequest struct {
+	Id               string            `json:"id"`
+	Matches          []CodeSearchMatch `json:"matches"`
+	NumFilesSearched int               `json:"numFilesSearched"`
+	Truncated        bool              `json:"truncated,omitempty"`
+
+	// for symbol searches, the files with matches
+	Files []CodeSearchFile `json:"files,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func (r *CodeSearchRequest) GetMaxResults() int {
+	if r.MaxResults <= 0 {
+		return CodeSearchDefaultMaxResults
+	}
+	return min(r.MaxResults, CodeSearchMaxResults)
+}
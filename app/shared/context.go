@@ -31,6 +31,7 @@ type ContextUpdateResult struct {
 	NumImages       int
 	NumTrees        int
 	NumMaps         int
+	NumDiffs        int
 	MaxTokens       int
 }
 
@@ -59,6 +60,9 @@ func (c *Context) TypeAndIcon() (string, string) {
 	case ContextMapType:
 		icon = "🗺️ "
 		t = "map"
+	case ContextGitDiffType:
+		icon = "🔀"
+		t = "diff"
 	}
 
 	return t, icon
@@ -117,6 +121,7 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	var numTrees int
 	var numUrls int
 	var numMaps int
+	var numDiffs int
 
 	for _, context := range contexts {
 		switch context.ContextType {
@@ -132,6 +137,8 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 			hasPiped = true
 		case ContextMapType:
 			numMaps++
+		case ContextGitDiffType:
+			numDiffs++
 		}
 	}
 
@@ -143,6 +150,13 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	if hasPiped {
 		added = append(added, "piped data")
 	}
+	if numDiffs > 0 {
+		label := "a git diff"
+		if numDiffs > 1 {
+			label = fmt.Sprintf("%d git diffs", numDiffs)
+		}
+		added = append(added, label)
+	}
 	if numFiles > 0 {
 		label := "file"
 		if numFiles > 1 {
@@ -239,6 +253,7 @@ type SummaryForUpdateContextParams struct {
 	NumTrees    int
 	NumUrls     int
 	NumMaps     int
+	NumDiffs    int
 	TokensDiff  int
 	TotalTokens int
 }
@@ -248,6 +263,7 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 	numTrees := params.NumTrees
 	numUrls := params.NumUrls
 	numMaps := params.NumMaps
+	numDiffs := params.NumDiffs
 	tokensDiff := params.TokensDiff
 	totalTokens := params.TotalTokens
 
@@ -281,6 +297,13 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 		}
 		toAdd = append(toAdd, fmt.Sprintf("%d map%s", numMaps, postfix))
 	}
+	if numDiffs > 0 {
+		postfix := "s"
+		if numDiffs == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d git diff%s", numDiffs, postfix))
+	}
 
 	if len(toAdd) <= 2 {
 		msg += " " + strings.Join(toAdd, " and ")
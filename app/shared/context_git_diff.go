@@ -0,0 +1,27 @@
+package shared
+
+// GitDiffSource is the set of changes a git diff context was loaded from, so that the diff can be regenerated when context is checked for updates. Exactly one of the fields is set.
+type GitDiffSource struct {
+	// Base compares the working tree with the point where the current branch diverged from Base ('HEAD' for uncommitted changes)
+	Base string `json:"base,omitempty"`
+
+	// Since compares HEAD with an earlier commit
+	Since string `json:"since,omitempty"`
+
+	// Staged compares the index with HEAD
+	Staged bool `json:"staged,omitempty"`
+}
+
+// Label is used as the name of the git diff context
+func (s *GitDiffSource) Label() string {
+	switch {
+	case s.Staged:
+		return "staged changes"
+	case s.Since != "":
+		return "changes since " + s.Since
+	case s.Base == "" || s.Base == "HEAD":
+		return "uncommitted changes"
+	default:
+		return "changes vs " + s.Base
+	}
+}
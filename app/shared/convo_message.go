@@ -14,6 +14,9 @@ func (f *ConvoMessageFlags) GetReplyTags() []string {
 			replyTags = append(replyTags, "📋 Made Plan")
 		}
 	}
+	if f.DidCallTools {
+		replyTags = append(replyTags, "🔧 Called Tools")
+	}
 	if f.DidWriteCode {
 		replyTags = append(replyTags, "👨‍💻 Wrote Code")
 	}
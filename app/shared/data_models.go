@@ -24,6 +24,7 @@ type User struct {
 	Email            string `json:"email"`
 	IsTrial          bool   `json:"isTrial"`
 	NumNonDraftPlans int    `json:"numNonDraftPlans"`
+	IsServiceAccount bool   `json:"isServiceAccount,omitempty"`
 
 	DefaultPlanConfig *PlanConfig `json:"defaultPlanConfig,omitempty"`
 }
@@ -92,6 +93,7 @@ const (
 	ContextPipedDataType     ContextType = "piped data"
 	ContextImageType         ContextType = "image"
 	ContextMapType           ContextType = "map"
+	ContextGitDiffType       ContextType = "git diff"
 )
 
 type FileMapBodies map[string]string
@@ -113,6 +115,7 @@ type Context struct {
 	MapShas         map[string]string     `json:"mapShas,omitempty"`
 	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
 	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
+	GitDiff         *GitDiffSource        `json:"gitDiff,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	UpdatedAt       time.Time             `json:"updatedAt"`
@@ -151,6 +154,7 @@ type ConvoMessageFlags struct {
 	IsApplyDebug          bool `json:"isApplyDebug"`
 	IsUserDebug           bool `json:"isUserDebug"`
 	HasError              bool `json:"hasError"`
+	DidCallTools          bool `json:"didCallTools"`
 }
 
 type Subtask struct {
@@ -173,9 +177,124 @@ type ConvoMessage struct {
 	AddedSubtasks    []*Subtask        `json:"addedSubtasks,omitempty"`
 	RemovedSubtasks  []string          `json:"removedSubtasks,omitempty"`
 	ActiveContextIds []string          `json:"activeContextIds"`
+	ToolCalls        []*ConvoToolCall  `json:"toolCalls,omitempty"`
 	CreatedAt        time.Time         `json:"createdAt"`
 }
 
+// ConvoToolCall is a call the model made to a tool on one of the project's MCP servers, along with its result
+type ConvoToolCall struct {
+	Id         string    `json:"id"`
+	Server     string    `json:"server"`
+	Tool       string    `json:"tool"`
+	Arguments  string    `json:"arguments"`
+	Result     string    `json:"result,omitempty"`
+	IsError    bool      `json:"isError,omitempty"`
+	Finished   bool      `json:"finished"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+type McpTransport string
+
+const (
+	McpTransportStdio McpTransport = "stdio"
+	McpTransportHttp  McpTransport = "http"
+)
+
+// McpRedactedValue replaces env and header values in responses, since they often hold credentials
+const McpRedactedValue = "********"
+
+// McpServer is an MCP (Model Context Protocol) server configured for a project. Its tools are offered to the planner and coder roles during a tell.
+type McpServer struct {
+	Id        string       `json:"id"`
+	ProjectId string       `json:"projectId"`
+	Name      string       `json:"name"`
+	Transport McpTransport `json:"transport"`
+
+	// stdio transport
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+
+	// http transport
+	Url     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventPlanFinished       WebhookEvent = "plan.finished"
+	WebhookEventPlanStreamError    WebhookEvent = "plan.stream_error"
+	WebhookEventBuildFailed        WebhookEvent = "build.failed"
+	WebhookEventApplySucceeded     WebhookEvent = "apply.succeeded"
+	WebhookEventAutoDebugExhausted WebhookEvent = "apply.debug_exhausted"
+
+	// sent only by 'plandex webhooks test'
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEventPlanFinished,
+	WebhookEventPlanStreamError,
+	WebhookEventBuildFailed,
+	WebhookEventApplySucceeded,
+	WebhookEventAutoDebugExhausted,
+}
+
+// Webhook is an outbound endpoint that receives plan lifecycle events. With no ProjectId it receives events for every project in the org. With no Events it receives every event.
+type Webhook struct {
+	Id        string         `json:"id"`
+	ProjectId *string        `json:"projectId,omitempty"`
+	Url       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	Enabled   bool           `json:"enabled"`
+
+	// only included when the webhook is created
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	WebhookId      string                `json:"webhookId"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"responseStatus,omitempty"`
+	Error          string                `json:"error,omitempty"`
+	LastAttemptAt  *time.Time            `json:"lastAttemptAt,omitempty"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+// WebhookPayload is the JSON body posted to a webhook's url
+type WebhookPayload struct {
+	Id        string                 `json:"id"`
+	Event     WebhookEvent           `json:"event"`
+	CreatedAt time.Time              `json:"createdAt"`
+	OrgId     string                 `json:"orgId"`
+	ProjectId string                 `json:"projectId,omitempty"`
+	PlanId    string                 `json:"planId,omitempty"`
+	PlanName  string                 `json:"planName,omitempty"`
+	Branch    string                 `json:"branch,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
 type ConvoSummary struct {
 	Id                          string    `json:"id"`
 	LatestConvoMessageCreatedAt time.Time `json:"latestConvoMessageCreatedAt"`
@@ -301,10 +420,12 @@ type CurrentPlanState struct {
 }
 
 type OrgRole struct {
-	Id          string `json:"id"`
-	IsDefault   bool   `json:"isDefault"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
+	Id          string       `json:"id"`
+	Name        string       `json:"name"`
+	IsDefault   bool         `json:"isDefault"`
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty"`
 }
 
 type CloudBillingFields struct {
@@ -398,3 +519,46 @@ func (t *CreditsTransaction) ModelString() string {
 
 	return s
 }
+
+// ModelPrice is the effective price for a model in the self-hosted usage ledger—either an org override or the model's BaseModelShared list price
+type ModelPrice struct {
+	ModelId                    ModelId         `json:"modelId"`
+	InputPricePerMillion       decimal.Decimal `json:"inputPricePerMillion"`
+	OutputPricePerMillion      decimal.Decimal `json:"outputPricePerMillion"`
+	CachedInputPricePerMillion decimal.Decimal `json:"cachedInputPricePerMillion"`
+	IsOverride                 bool            `json:"isOverride"`
+	UpdatedAt                  *time.Time      `json:"updatedAt,omitempty"`
+}
+
+func ModelPriceFromBaseModelShared(modelId ModelId, b BaseModelShared) *ModelPrice {
+	return &ModelPrice{
+		ModelId:                    modelId,
+		InputPricePerMillion:       decimal.NewFromFloat(b.InputPricePerMillion),
+		OutputPricePerMillion:      decimal.NewFromFloat(b.OutputPricePerMillion),
+		CachedInputPricePerMillion: decimal.NewFromFloat(b.CachedInputPricePerMillion),
+	}
+}
+
+// Cost returns the total cost of a request along with the amount saved by cached input
+func (p *ModelPrice) Cost(inputTokens, outputTokens, cachedTokens int) (cost decimal.Decimal, cacheSavings decimal.Decimal) {
+	million := decimal.NewFromInt(1000000)
+
+	cachedPrice := p.CachedInputPricePerMillion
+	if cachedPrice.IsZero() {
+		cachedPrice = p.InputPricePerMillion
+	}
+
+	if cachedTokens > inputTokens {
+		cachedTokens = inputTokens
+	}
+	uncachedTokens := inputTokens - cachedTokens
+
+	cost = p.InputPricePerMillion.Mul(decimal.NewFromInt(int64(uncachedTokens))).
+		Add(cachedPrice.Mul(decimal.NewFromInt(int64(cachedTokens)))).
+		Add(p.OutputPricePerMillion.Mul(decimal.NewFromInt(int64(outputTokens)))).
+		Div(million)
+
+	cacheSavings = p.InputPricePerMillion.Sub(cachedPrice).Mul(decimal.NewFromInt(int64(cachedTokens))).Div(million)
+
+	return cost, cacheSavings
+}
@@ -0,0 +1,34 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestModelPriceCost(t *testing.T) {
+	price := &ModelPrice{
+		InputPricePerMillion:       decimal.NewFromInt(3),
+		OutputPricePerMillion:      decimal.NewFromInt(15),
+		CachedInputPricePerMillion: decimal.RequireFromString("0.3"),
+	}
+
+	cost, savings := price.Cost(1000000, 100000, 500000)
+
+	// 500k uncached * $3 + 500k cached * $0.30 + 100k output * $15
+	if !cost.Equal(decimal.RequireFromString("3.15")) {
+		t.Errorf("expected cost 3.15, got %s", cost)
+	}
+	if !savings.Equal(decimal.RequireFromString("1.35")) {
+		t.Errorf("expected cache savings 1.35, got %s", savings)
+	}
+
+	price.CachedInputPricePerMillion = decimal.Zero
+	cost, savings = price.Cost(1000000, 0, 500000)
+	if !cost.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected cached input to fall back to the input price, got %s", cost)
+	}
+	if !savings.IsZero() {
+		t.Errorf("expected no cache savings, got %s", savings)
+	}
+}
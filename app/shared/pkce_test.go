@@ -0,0 +1,13 @@
+package shared
+
+import "testing"
+
+func TestPkceChallenge(t *testing.T) {
+	// example from RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if got := PkceChallenge(verifier); got != want {
+		t.Errorf("PkceChallenge() = %s, want %s", got, want)
+	}
+}
@@ -47,6 +47,27 @@ var AutoModeOptions = [][3]string{
 
 var AutoModeLabels = map[AutoModeType]string{}
 
+type ExecSandboxMode string
+
+const (
+	ExecSandboxNone       ExecSandboxMode = "none"
+	ExecSandboxBubblewrap ExecSandboxMode = "bubblewrap"
+)
+
+var ExecSandboxOptions = [][3]string{
+	{string(ExecSandboxNone), "None", "Run commands directly on the host"},
+	{string(ExecSandboxBubblewrap), "Sandbox", "Run commands in a bubblewrap sandbox (Linux only): read-only system and toolchain dirs, writable project dir, no credentials from the environment, no network unless enabled, with CPU, memory, and time limits"},
+}
+
+// populated in init()
+var ExecSandboxChoices []string
+
+const (
+	DefaultExecSandboxMemoryMB    = 4096
+	DefaultExecSandboxCPUs        = 2
+	DefaultExecSandboxTimeoutSecs = 600
+)
+
 // populated in init()
 var AutoModeChoices []string
 
@@ -83,6 +104,13 @@ type PlanConfig struct {
 	AutoDebug      bool `json:"autoDebug"`
 	AutoDebugTries int  `json:"autoDebugTries"`
 
+	// zero values for the limits mean the defaults above
+	ExecSandbox            ExecSandboxMode `json:"execSandbox,omitempty"`
+	ExecSandboxNetwork     bool            `json:"execSandboxNetwork,omitempty"`
+	ExecSandboxMemoryMB    int             `json:"execSandboxMemoryMB,omitempty"`
+	ExecSandboxCPUs        int             `json:"execSandboxCPUs,omitempty"`
+	ExecSandboxTimeoutSecs int             `json:"execSandboxTimeoutSecs,omitempty"`
+
 	AutoRevertOnRewind bool `json:"autoRevertOnRewind"`
 
 	SkipChangesMenu bool `json:"skipChangesMenu"`
@@ -125,6 +153,31 @@ func (p PlanConfig) Value() (driver.Value, error) {
 	return json.Marshal(p)
 }
 
+func (p *PlanConfig) IsExecSandboxed() bool {
+	return p.ExecSandbox == ExecSandboxBubblewrap
+}
+
+func (p *PlanConfig) GetExecSandboxMemoryMB() int {
+	if p.ExecSandboxMemoryMB > 0 {
+		return p.ExecSandboxMemoryMB
+	}
+	return DefaultExecSandboxMemoryMB
+}
+
+func (p *PlanConfig) GetExecSandboxCPUs() int {
+	if p.ExecSandboxCPUs > 0 {
+		return p.ExecSandboxCPUs
+	}
+	return DefaultExecSandboxCPUs
+}
+
+func (p *PlanConfig) GetExecSandboxTimeoutSecs() int {
+	if p.ExecSandboxTimeoutSecs > 0 {
+		return p.ExecSandboxTimeoutSecs
+	}
+	return DefaultExecSandboxTimeoutSecs
+}
+
 func (p *PlanConfig) SetAutoMode(mode AutoModeType) {
 	p.AutoMode = mode
 
@@ -463,6 +516,91 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%d", p.AutoDebugTries)
 		},
 	},
+	"execsandbox": {
+		Name: "exec-sandbox",
+		Desc: "Where to execute commands",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecSandbox = ExecSandboxMode(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			if p.ExecSandbox == "" {
+				return string(ExecSandboxNone)
+			}
+			return string(p.ExecSandbox)
+		},
+		Choices: &ExecSandboxChoices,
+		ChoiceToKey: func(choice string) string {
+			for _, option := range ExecSandboxOptions {
+				if strings.HasPrefix(choice, option[1]) {
+					return option[0]
+				}
+			}
+			return ""
+		},
+		KeyToLabel: func(key string) string {
+			for _, option := range ExecSandboxOptions {
+				if option[0] == key {
+					return option[1]
+				}
+			}
+			return ""
+		},
+	},
+	"execsandboxnetwork": {
+		Name: "exec-sandbox-network",
+		Desc: "Allow network access in the exec sandbox",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.IsExecSandboxed()
+		},
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.ExecSandboxNetwork = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.ExecSandboxNetwork)
+		},
+	},
+	"execsandboxmemory": {
+		Name: "exec-sandbox-memory",
+		Desc: "Memory limit in MB for the exec sandbox",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.IsExecSandboxed()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandboxMemoryMB = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.GetExecSandboxMemoryMB())
+		},
+	},
+	"execsandboxcpus": {
+		Name: "exec-sandbox-cpus",
+		Desc: "CPU limit (number of cores) for the exec sandbox",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.IsExecSandboxed()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandboxCPUs = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.GetExecSandboxCPUs())
+		},
+	},
+	"execsandboxtimeout": {
+		Name: "exec-sandbox-timeout",
+		Desc: "Time limit in seconds for commands in the exec sandbox",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.IsExecSandboxed()
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ExecSandboxTimeoutSecs = value
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.GetExecSandboxTimeoutSecs())
+		},
+	},
 	"autorevert": {
 		Name: "auto-revert",
 		Desc: "Automatically update project files when rewinding plan",
@@ -495,4 +633,8 @@ func init() {
 		AutoModeChoices = append(AutoModeChoices, fmt.Sprintf("%s → %s", choice[1], choice[2]))
 		AutoModeLabels[AutoModeType(choice[0])] = choice[1]
 	}
+
+	for _, choice := range ExecSandboxOptions {
+		ExecSandboxChoices = append(ExecSandboxChoices, fmt.Sprintf("%s → %s", choice[1], choice[2]))
+	}
 }
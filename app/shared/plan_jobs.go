@@ -0,0 +1,216 @@
+package shared
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PlanJobStatus string
+
+const (
+	PlanJobStatusQueued    PlanJobStatus = "queued"
+	PlanJobStatusRunning   PlanJobStatus = "running"
+	PlanJobStatusFinished  PlanJobStatus = "finished"
+	PlanJobStatusFailed    PlanJobStatus = "failed"
+	PlanJobStatusCancelled PlanJobStatus = "cancelled"
+)
+
+// A plan job sends a prompt to a plan from a server-side worker, either once or on a cron schedule. Changes are left pending on the job's branch.
+type PlanJob struct {
+	Id       string       `json:"id"`
+	PlanId   string       `json:"planId"`
+	Branch   string       `json:"branch"`
+	Prompt   string       `json:"prompt"`
+	AutoMode AutoModeType `json:"autoMode,omitempty"`
+
+	// 5-field cron expression in UTC—empty for a job that runs once
+	Schedule string `json:"schedule,omitempty"`
+
+	// a recurring job goes back to queued after each run, with the result of the run in LastRunStatus
+	Status        PlanJobStatus `json:"status"`
+	LastRunStatus PlanJobStatus `json:"lastRunStatus,omitempty"`
+	LastError     string        `json:"lastError,omitempty"`
+	NumRuns       int           `json:"numRuns"`
+
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt  *time.Time `json:"lastRunAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// CronSchedule is a parsed 5-field cron expression: minute, hour, day of month, month, day of week
+type CronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+
+	// cron matches either day field when both are restricted
+	domRestricted, dowRestricted bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@nightly":  "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+func ParseCronSchedule(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron schedule '%s'—expected 5 fields (minute hour day-of-month month day-of-week) or a macro like @daily", expr)
+	}
+
+	s := &CronSchedule{}
+	var err error
+
+	if s.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %v", err)
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %v", err)
+	}
+	if s.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %v", err)
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid month field: %v", err)
+	}
+	if s.daysOfWeek, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %v", err)
+	}
+
+	// 7 is also sunday
+	if s.daysOfWeek&(1<<7) != 0 {
+		s.daysOfWeek |= 1
+	}
+
+	s.domRestricted = fields[2] != "*" && fields[2] != "?"
+	s.dowRestricted = fields[4] != "*" && fields[4] != "?"
+
+	return s, nil
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", stepPart)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		if rangePart == "*" || rangePart == "?" {
+			lo, hi = min, max
+		} else {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			lo, err = parseCronValue(loStr, names)
+			if err != nil {
+				return 0, err
+			}
+
+			if isRange {
+				hi, err = parseCronValue(hiStr, names)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max
+			} else {
+				hi = lo
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", part, min, max)
+		}
+
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if n, ok := names[s]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	return n, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's location. Returns the zero time if nothing matches in the next 5 years (e.g. Feb 30).
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// a wednesday
+	start := time.Date(2025, 7, 23, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 7, 23, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 7, 24, 3, 0, 0, 0, time.UTC)},
+		{"@nightly", time.Date(2025, 7, 24, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 7, 23, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2025, 7, 24, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 7, 27, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan,jul *", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+		// both day fields restricted: either one matches
+		{"0 0 1 * fri", time.Date(2025, 7, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronSchedule(%q) error = %v", tt.expr, err)
+		}
+
+		got := s.Next(start)
+		if !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		if _, err := ParseCronSchedule(expr); err == nil {
+			t.Errorf("ParseCronSchedule(%q) expected an error", expr)
+		}
+	}
+}
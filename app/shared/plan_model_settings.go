@@ -183,6 +183,11 @@ func (ps PlanSettings) GetArchitectEffectiveMaxTokens() int {
 	return maxArchitectTokens - maxReservedOutputTokens
 }
 
+// SemanticRetrievalEnabled is true when the model pack has an embeddings role, in which case auto context can search the project's semantic index rather than relying on the full project map alone
+func (ps PlanSettings) SemanticRetrievalEnabled() bool {
+	return ps.GetModelPack().Embeddings != nil
+}
+
 func (ps PlanSettings) GetCoderEffectiveMaxTokens() int {
 	maxCoderTokens := ps.GetCoderMaxTokens()
 	maxReservedOutputTokens := ps.GetCoderMaxReservedOutputTokens()
@@ -197,6 +202,23 @@ func (ps PlanSettings) GetWholeFileBuilderEffectiveMaxTokens() int {
 	return maxWholeFileBuilderTokens - maxReservedOutputTokens
 }
 
+// tokenizers below match the models whose limits are used by the corresponding Get*EffectiveMaxTokens methods
+
+func (ps PlanSettings) GetPlannerTokenizer() Tokenizer {
+	modelPack := ps.GetModelPack()
+	return modelPack.Planner.GetFinalLargeContextFallback().GetTokenizer(&ps)
+}
+
+func (ps PlanSettings) GetArchitectTokenizer() Tokenizer {
+	modelPack := ps.GetModelPack()
+	return modelPack.GetArchitect().GetFinalLargeContextFallback().GetTokenizer(&ps)
+}
+
+func (ps PlanSettings) GetCoderTokenizer() Tokenizer {
+	modelPack := ps.GetModelPack()
+	return modelPack.GetCoder().GetFinalLargeContextFallback().GetTokenizer(&ps)
+}
+
 func (ps PlanSettings) GetModelProviderOptions() ModelProviderOptions {
 	opts := ModelProviderOptions{}
 
@@ -216,6 +238,7 @@ func (ps PlanSettings) GetModelProviderOptions() ModelProviderOptions {
 		getOptionalModelProviderOptions(&ps, ms.WholeFileBuilder),
 		getOptionalModelProviderOptions(&ps, ms.Architect),
 		getOptionalModelProviderOptions(&ps, ms.Coder),
+		getOptionalModelProviderOptions(&ps, ms.Embeddings),
 	)
 
 	return opts
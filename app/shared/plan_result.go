@@ -12,6 +12,17 @@ func (rep *Replacement) SetRejected(t time.Time) {
 	rep.RejectedAt = &t
 }
 
+// ActiveReplacements skips replacements that were rejected individually during review—the rest still apply in order
+func (res *PlanFileResult) ActiveReplacements() []*Replacement {
+	active := make([]*Replacement, 0, len(res.Replacements))
+	for _, rep := range res.Replacements {
+		if rep.RejectedAt == nil {
+			active = append(active, rep)
+		}
+	}
+	return active
+}
+
 func (res *PlanFileResult) NumPendingReplacements() int {
 	numPending := 0
 	for _, rep := range res.Replacements {
@@ -87,7 +98,8 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 		for _, res := range planRes {
 
 			// log.Println("res:", res.Id)
-			if len(res.Replacements) == 0 {
+			replacements := res.ActiveReplacements()
+			if len(replacements) == 0 {
 				continue
 			}
 
@@ -97,7 +109,7 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 			}
 
 			var succeeded bool
-			updated, succeeded = ApplyReplacements(maybeWithLineNums, res.Replacements, false)
+			updated, succeeded = ApplyReplacements(maybeWithLineNums, replacements, false)
 
 			updated = RemoveLineNums(LineNumberedTextType(updated))
 
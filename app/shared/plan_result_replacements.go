@@ -222,6 +222,9 @@ func (planState *CurrentPlanState) GetFilesBeforeReplacement(
 					foundTarget = true
 					break
 				}
+				if replacement.RejectedAt != nil {
+					continue
+				}
 				replacements = append(replacements, replacement)
 			}
 
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetFilesSkipsRejectedReplacements(t *testing.T) {
+	rejectedAt := time.Now()
+
+	res := &PlanFileResult{
+		Id:   "res1",
+		Path: "main.go",
+		Replacements: []*Replacement{
+			{Id: "rep1", Old: "a := 1", New: "a := 2"},
+			{Id: "rep2", Old: "b := 1", New: "b := 2", RejectedAt: &rejectedAt},
+			{Id: "rep3", Old: "c := 1", New: "c := 2"},
+		},
+	}
+
+	planState := &CurrentPlanState{
+		PlanResult: &PlanResult{
+			FileResultsByPath: PlanFileResultsByPath{"main.go": {res}},
+		},
+		ContextsByPath: map[string]*Context{
+			"main.go": {FilePath: "main.go", Body: "a := 1\nb := 1\nc := 1"},
+		},
+	}
+
+	files, err := planState.GetFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "a := 2\nb := 1\nc := 2"
+	if files.Files["main.go"] != expected {
+		t.Errorf("expected %q, got %q", expected, files.Files["main.go"])
+	}
+
+	if res.NumPendingReplacements() != 2 {
+		t.Errorf("expected 2 pending replacements, got %d", res.NumPendingReplacements())
+	}
+
+	for _, rep := range res.Replacements {
+		if rep.Id != "rep2" {
+			rep.RejectedAt = &rejectedAt
+		}
+	}
+
+	if res.IsPending() {
+		t.Error("expected result with every replacement rejected to no longer be pending")
+	}
+}
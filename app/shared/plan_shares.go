@@ -0,0 +1,51 @@
+package shared
+
+import "time"
+
+type PlanShareRole string
+
+const (
+	// viewers can see a plan's conversation, context, diffs, and history
+	PlanShareRoleViewer PlanShareRole = "viewer"
+	// commenters can also chat with the plan, but can't make changes or load context
+	PlanShareRoleCommenter PlanShareRole = "commenter"
+	// editors can do anything the plan's owner can except rename, archive, or delete it, or manage its shares
+	PlanShareRoleEditor PlanShareRole = "editor"
+)
+
+var PlanShareRoles = []PlanShareRole{
+	PlanShareRoleViewer,
+	PlanShareRoleCommenter,
+	PlanShareRoleEditor,
+}
+
+func (role PlanShareRole) IsValid() bool {
+	return role.rank() > 0
+}
+
+// Includes reports whether the role grants everything the other role does
+func (role PlanShareRole) Includes(other PlanShareRole) bool {
+	return role.IsValid() && role.rank() >= other.rank()
+}
+
+func (role PlanShareRole) rank() int {
+	for i, r := range PlanShareRoles {
+		if r == role {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// A plan share gives a user in the plan's org access to the plan
+type PlanShare struct {
+	Id        string        `json:"id"`
+	PlanId    string        `json:"planId"`
+	UserId    string        `json:"userId"`
+	UserEmail string        `json:"userEmail"`
+	UserName  string        `json:"userName"`
+	Role      PlanShareRole `json:"role"`
+	CreatedBy string        `json:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
@@ -0,0 +1,25 @@
+package shared
+
+import "testing"
+
+func TestPlanShareRoleIncludes(t *testing.T) {
+	tests := []struct {
+		role  PlanShareRole
+		other PlanShareRole
+		want  bool
+	}{
+		{PlanShareRoleEditor, PlanShareRoleViewer, true},
+		{PlanShareRoleEditor, PlanShareRoleEditor, true},
+		{PlanShareRoleCommenter, PlanShareRoleViewer, true},
+		{PlanShareRoleCommenter, PlanShareRoleEditor, false},
+		{PlanShareRoleViewer, PlanShareRoleCommenter, false},
+		{"", PlanShareRoleViewer, false},
+		{"owner", PlanShareRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Includes(tt.other); got != tt.want {
+			t.Errorf("%q.Includes(%q) = %v, want %v", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package shared
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A plan template is a reusable starting point for plans that run the same kind of task: a prompt with {{variables}}, context to load, and settings to use for the new plan
+type PlanTemplate struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// may include {{variable}} placeholders, which are also allowed in context globs
+	Prompt        string       `json:"prompt"`
+	ContextGlobs  []string     `json:"contextGlobs"`
+	ModelPackName string       `json:"modelPackName,omitempty"`
+	AutoMode      AutoModeType `json:"autoMode,omitempty"`
+
+	// lines added to the project's .plandexignore if they aren't already there
+	PlandexIgnore []string `json:"plandexIgnore"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var planTemplateVarRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_-]*)\s*\}\}`)
+
+var planTemplateNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func ValidatePlanTemplateName(name string) error {
+	if !planTemplateNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid template name '%s'—use up to 64 lowercase letters, numbers, '-', and '_'", name)
+	}
+	return nil
+}
+
+// Variables returns the names of the template's variables in the order they first appear
+func (t *PlanTemplate) Variables() []string {
+	var vars []string
+	seen := map[string]bool{}
+
+	texts := append([]string{t.Prompt}, t.ContextGlobs...)
+	for _, text := range texts {
+		for _, match := range planTemplateVarRegex.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if !seen[name] {
+				seen[name] = true
+				vars = append(vars, name)
+			}
+		}
+	}
+
+	return vars
+}
+
+// Render returns the template's prompt and context globs with variables replaced. Every variable must have a value.
+func (t *PlanTemplate) Render(vars map[string]string) (string, []string, error) {
+	var missing []string
+	for _, name := range t.Variables() {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing values for template variables: %s", strings.Join(missing, ", "))
+	}
+
+	render := func(s string) string {
+		return planTemplateVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := planTemplateVarRegex.FindStringSubmatch(match)[1]
+			return vars[name]
+		})
+	}
+
+	globs := make([]string, len(t.ContextGlobs))
+	for i, glob := range t.ContextGlobs {
+		globs[i] = render(glob)
+	}
+
+	return render(t.Prompt), globs, nil
+}
@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanTemplateVariables(t *testing.T) {
+	tmpl := &PlanTemplate{
+		Prompt:       "Add a {{method}} endpoint for {{ resource }} to the {{service}} service. Use {{method}} semantics.",
+		ContextGlobs: []string{"services/{{service}}/**/*.go", "docs/api.md"},
+	}
+
+	got := tmpl.Variables()
+	want := []string{"method", "resource", "service"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+}
+
+func TestPlanTemplateRender(t *testing.T) {
+	tmpl := &PlanTemplate{
+		Prompt:       "Add a {{method}} endpoint for {{ resource }} to the {{service}} service.",
+		ContextGlobs: []string{"services/{{service}}/**/*.go"},
+	}
+
+	prompt, globs, err := tmpl.Render(map[string]string{
+		"method":   "GET",
+		"resource": "invoices",
+		"service":  "billing",
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if prompt != "Add a GET endpoint for invoices to the billing service." {
+		t.Errorf("unexpected prompt: %q", prompt)
+	}
+	if !reflect.DeepEqual(globs, []string{"services/billing/**/*.go"}) {
+		t.Errorf("unexpected globs: %v", globs)
+	}
+
+	_, _, err = tmpl.Render(map[string]string{"method": "GET"})
+	if err == nil {
+		t.Error("expected an error for missing variables")
+	}
+}
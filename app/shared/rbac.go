@@ -23,8 +23,50 @@ const (
 	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
 	PermissionUpdateAnyPlan         Permission = "update_any_plan"
 	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionManageMcpServers      Permission = "manage_mcp_servers"
+	PermissionManageWebhooks        Permission = "manage_webhooks"
+	PermissionManagePlanTemplates   Permission = "manage_plan_templates"
+	PermissionManageServiceAccounts Permission = "manage_service_accounts"
+	PermissionViewAuditLog          Permission = "view_audit_log"
+	PermissionExecCommands          Permission = "exec_commands"
+	PermissionChangeModelPack       Permission = "change_model_pack"
+	PermissionManageCustomProviders Permission = "manage_custom_providers"
+	PermissionForceLoadIgnored      Permission = "force_load_ignored"
+	PermissionManageOrgRoles        Permission = "manage_org_roles"
 )
 
+// the permissions a custom org role can be composed of—the invite, remove, and set role permissions for each role are granted by the server, and org-level admin permissions stay with the default roles
+var CustomOrgRolePermissions = []Permission{
+	PermissionListOrgRoles,
+	PermissionCreateProject,
+	PermissionRenameAnyProject,
+	PermissionDeleteAnyProject,
+	PermissionCreatePlan,
+	PermissionManageAnyPlanShares,
+	PermissionRenameAnyPlan,
+	PermissionDeleteAnyPlan,
+	PermissionUpdateAnyPlan,
+	PermissionArchiveAnyPlan,
+	PermissionManageMcpServers,
+	PermissionManageWebhooks,
+	PermissionManagePlanTemplates,
+	PermissionManageServiceAccounts,
+	PermissionViewAuditLog,
+	PermissionExecCommands,
+	PermissionChangeModelPack,
+	PermissionManageCustomProviders,
+	PermissionForceLoadIgnored,
+}
+
+func (permission Permission) IsCustomOrgRolePermission() bool {
+	for _, p := range CustomOrgRolePermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type Permissions map[string]bool
 
 func (perms Permissions) HasPermission(permission Permission) bool {
@@ -42,6 +84,9 @@ func (perms Permissions) HasPermission(permission Permission) bool {
 func (perms Permissions) HasPermissionForResource(permission Permission, resourceId string) bool {
 	for p := range perms {
 		split := strings.Split(p, "|")
+		if len(split) < 2 {
+			continue
+		}
 		perm := Permission(split[0])
 		resId := split[1]
 
@@ -0,0 +1,37 @@
+package shared
+
+import "testing"
+
+func TestPermissionsHasPermissionForResource(t *testing.T) {
+	perms := Permissions{
+		string(PermissionCreatePlan):                 true,
+		string(PermissionSetUserRole) + "|role-id-1": true,
+	}
+
+	if !perms.HasPermission(PermissionSetUserRole) {
+		t.Errorf("HasPermission: want set_user_role for any role")
+	}
+	if !perms.HasPermissionForResource(PermissionSetUserRole, "role-id-1") {
+		t.Errorf("HasPermissionForResource: want set_user_role for role-id-1")
+	}
+	if perms.HasPermissionForResource(PermissionSetUserRole, "role-id-2") {
+		t.Errorf("HasPermissionForResource: got set_user_role for role-id-2")
+	}
+	if perms.HasPermissionForResource(PermissionCreatePlan, "role-id-1") {
+		t.Errorf("HasPermissionForResource: got create_plan for a resource")
+	}
+}
+
+func TestIsCustomOrgRolePermission(t *testing.T) {
+	for _, p := range []Permission{PermissionExecCommands, PermissionChangeModelPack, PermissionManageCustomProviders, PermissionForceLoadIgnored, PermissionCreatePlan} {
+		if !p.IsCustomOrgRolePermission() {
+			t.Errorf("%s should be allowed in custom roles", p)
+		}
+	}
+
+	for _, p := range []Permission{PermissionDeleteOrg, PermissionManageBilling, PermissionInviteUser, PermissionSetUserRole, PermissionManageOrgRoles} {
+		if p.IsCustomOrgRolePermission() {
+			t.Errorf("%s shouldn't be allowed in custom roles", p)
+		}
+	}
+}
@@ -48,6 +48,25 @@ type SessionResponse struct {
 	UserName    string `json:"userName"`
 	Orgs        []*Org `json:"orgs"`
 	IsLocalMode bool   `json:"isLocalMode"`
+	IsSso       bool   `json:"isSso,omitempty"`
+}
+
+type OidcConfigResponse struct {
+	Enabled bool `json:"enabled"`
+	// email pin sign in is disabled when single sign-on is required
+	Required              bool     `json:"required"`
+	AuthorizationEndpoint string   `json:"authorizationEndpoint"`
+	ClientId              string   `json:"clientId"`
+	Scopes                []string `json:"scopes"`
+	// 0 means the client can listen on any free port
+	RedirectPort int `json:"redirectPort"`
+}
+
+type OidcSignInRequest struct {
+	Code         string `json:"code"`
+	CodeVerifier string `json:"codeVerifier"`
+	RedirectUri  string `json:"redirectUri"`
+	Nonce        string `json:"nonce"`
 }
 
 type CreateOrgRequest struct {
@@ -73,6 +92,23 @@ type InviteRequest struct {
 	OrgRoleId string `json:"orgRoleId"`
 }
 
+type CreateOrgRoleRequest struct {
+	Name        string       `json:"name"`
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type UpdateOrgRoleRequest struct {
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type SetOrgUserRoleRequest struct {
+	OrgRoleId string `json:"orgRoleId"`
+}
+
 type CreateProjectRequest struct {
 	Name string `json:"name"`
 }
@@ -108,6 +144,9 @@ type ListPlansRunningResponse struct {
 	StreamFinishedAtByBranchId map[string]time.Time `json:"streamFinishedAtByBranchId"`
 	StreamIdByBranchId         map[string]string    `json:"streamIdByBranchId"`
 	PlansById                  map[string]*Plan     `json:"plansById"`
+
+	// queued and running server-side jobs
+	Jobs []*PlanJob `json:"jobs"`
 }
 
 type BuildMode string
@@ -140,6 +179,12 @@ type TellPlanRequest struct {
 	IsImplementationOfChat bool            `json:"isImplementationOfChat"`
 	IsGitRepo              bool            `json:"isGitRepo"`
 	SessionId              string          `json:"sessionId"`
+
+	// additional repo roots in a multi-repo project, described as '@<alias> ($PLANDEX_ROOT_<ALIAS>)'
+	RepoRoots []string `json:"repoRoots,omitempty"`
+
+	// the client can run code searches for the planner—see StreamMessageCodeSearch
+	CodeSearchEnabled bool `json:"codeSearchEnabled,omitempty"`
 }
 
 type BuildPlanRequest struct {
@@ -192,6 +237,7 @@ type LoadContextParams struct {
 	Body            string                `json:"body"`
 	ForceSkipIgnore bool                  `json:"forceSkipIgnore"`
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
+	GitDiff         *GitDiffSource        `json:"gitDiff,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 
 	InputShas   map[string]string `json:"inputShas"`
@@ -230,10 +276,18 @@ type UpdateContextParams struct {
 
 type GetFileMapRequest struct {
 	MapInputs FileMapInputs `json:"mapInputs"`
+
+	// when set and the plan's model pack has an embeddings role, the inputs are also added to the project's semantic index
+	PlanId   string            `json:"planId,omitempty"`
+	Branch   string            `json:"branch,omitempty"`
+	AuthVars map[string]string `json:"authVars,omitempty"`
 }
 
 type GetFileMapResponse struct {
 	MapBodies FileMapBodies `json:"mapBodies"`
+
+	// maps are still returned if indexing fails
+	SemanticIndexError string `json:"semanticIndexError,omitempty"`
 }
 
 type LoadCachedFileMapRequest struct {
@@ -275,6 +329,19 @@ type RejectFilesRequest struct {
 	Paths []string `json:"paths"`
 }
 
+type RejectReplacementsRequest struct {
+	ReplacementIds []string `json:"replacementIds"`
+}
+
+type ReplacementEdit struct {
+	ReplacementId string `json:"replacementId"`
+	New           string `json:"new"`
+}
+
+type EditReplacementsRequest struct {
+	Edits []*ReplacementEdit `json:"edits"`
+}
+
 type RewindPlanRequest struct {
 	Sha string `json:"sha"`
 }
@@ -293,6 +360,62 @@ type CreateBranchRequest struct {
 	Name string `json:"name"`
 }
 
+type MergeConflictResolution string
+
+const (
+	// keep the target branch's pending changes to the file and reject the incoming ones
+	MergeConflictResolutionOurs MergeConflictResolution = "ours"
+	// take the incoming pending changes to the file and reject the target branch's
+	MergeConflictResolutionTheirs MergeConflictResolution = "theirs"
+)
+
+type MergeBranchRequest struct {
+	// Source is the branch to merge into the branch in the url
+	Source string `json:"source"`
+
+	// Sha, if set, cherry-picks a single commit instead of merging all of Source
+	Sha string `json:"sha,omitempty"`
+
+	// DryRun reports what would be merged and any conflicts without changing anything
+	DryRun bool `json:"dryRun"`
+
+	// Resolutions by path for conflicting pending changes, with DefaultResolution for any path that isn't included
+	Resolutions       map[string]MergeConflictResolution `json:"resolutions,omitempty"`
+	DefaultResolution MergeConflictResolution            `json:"defaultResolution,omitempty"`
+}
+
+// MergeConflict is a file with pending changes on both branches that can't be applied one after the other
+type MergeConflict struct {
+	Path string `json:"path"`
+
+	OursNumChanges   int    `json:"oursNumChanges"`
+	TheirsNumChanges int    `json:"theirsNumChanges"`
+	OursDiff         string `json:"oursDiff"`
+	TheirsDiff       string `json:"theirsDiff"`
+}
+
+type MergeBranchResponse struct {
+	// UpToDate is true if there was nothing to merge
+	UpToDate bool `json:"upToDate"`
+
+	// FastForward is true if the target branch had no changes of its own, so it was simply moved up to the source
+	FastForward bool `json:"fastForward"`
+
+	NumConvoMessages int      `json:"numConvoMessages"`
+	NumContexts      int      `json:"numContexts"`
+	PendingPaths     []string `json:"pendingPaths"`
+
+	// AutoResolvedPaths had pending changes on both branches that apply cleanly one after the other, so both were kept
+	AutoResolvedPaths []string `json:"autoResolvedPaths"`
+
+	// Conflicts that weren't resolved. If there are any, nothing was merged.
+	Conflicts []*MergeConflict `json:"conflicts"`
+
+	Merged       bool   `json:"merged"`
+	LatestSha    string `json:"latestSha,omitempty"`
+	LatestCommit string `json:"latestCommit,omitempty"`
+}
+
 type UpdateSettingsRequest struct {
 	ModelPackName string     `json:"modelPackName"`
 	ModelPack     *ModelPack `json:"modelPack"`
@@ -373,8 +496,172 @@ type CreditsSummaryResponse struct {
 	ByPurpose   map[string]decimal.Decimal `json:"byPurpose"`
 
 	CacheSavings decimal.Decimal `json:"cacheSavings"`
+
+	// self-hosted usage ledger only
+	ByDay         map[string]decimal.Decimal `json:"byDay,omitempty"`
+	ByModelRole   map[string]decimal.Decimal `json:"byModelRole,omitempty"`
+	ByUserId      map[string]decimal.Decimal `json:"byUserId,omitempty"`
+	UserNamesById map[string]string          `json:"userNamesById,omitempty"`
+
+	TotalInputTokens  int `json:"totalInputTokens,omitempty"`
+	TotalOutputTokens int `json:"totalOutputTokens,omitempty"`
+	TotalCachedTokens int `json:"totalCachedTokens,omitempty"`
+}
+
+type ListModelPricesResponse struct {
+	Prices []*ModelPrice `json:"prices"`
+}
+
+type SetModelPriceRequest struct {
+	ModelId                    ModelId         `json:"modelId"`
+	InputPricePerMillion       decimal.Decimal `json:"inputPricePerMillion"`
+	OutputPricePerMillion      decimal.Decimal `json:"outputPricePerMillion"`
+	CachedInputPricePerMillion decimal.Decimal `json:"cachedInputPricePerMillion"`
 }
 
 type GetBalanceResponse struct {
 	Balance decimal.Decimal `json:"balance"`
 }
+
+type ListMcpServersResponse struct {
+	Servers []*McpServer `json:"servers"`
+}
+
+// When updating a server, env and headers are merged into the stored values rather than replacing them: omitted keys and keys set to McpRedactedValue keep their stored values, and keys set to an empty string are removed
+type UpsertMcpServerRequest struct {
+	Name      string            `json:"name"`
+	Transport McpTransport      `json:"transport"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Url       string            `json:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Enabled   bool              `json:"enabled"`
+}
+
+type McpServerToolsResponse struct {
+	Tools []*McpToolInfo `json:"tools"`
+}
+
+type McpToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreateWebhookRequest struct {
+	Url       string         `json:"url"`
+	ProjectId string         `json:"projectId,omitempty"`
+	Events    []WebhookEvent `json:"events"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
+
+// ReportPlanEventRequest lets the client report lifecycle events that only it can see, like auto-debug running out of tries
+type ReportPlanEventRequest struct {
+	Event WebhookEvent           `json:"event"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+type CreatePlanTemplateRequest struct {
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Prompt        string       `json:"prompt"`
+	ContextGlobs  []string     `json:"contextGlobs"`
+	ModelPackName string       `json:"modelPackName,omitempty"`
+	AutoMode      AutoModeType `json:"autoMode,omitempty"`
+	PlandexIgnore []string     `json:"plandexIgnore"`
+
+	// replace an existing template with the same name
+	Replace bool `json:"replace"`
+}
+
+type ListPlanTemplatesResponse struct {
+	Templates []*PlanTemplate `json:"templates"`
+}
+
+type CreatePlanJobRequest struct {
+	Prompt   string       `json:"prompt"`
+	AutoMode AutoModeType `json:"autoMode,omitempty"`
+
+	// cron expression for a recurring job—the job runs once, as soon as a worker is free, if it's empty
+	Schedule string `json:"schedule,omitempty"`
+}
+
+type ListPlanJobsResponse struct {
+	Jobs []*PlanJob `json:"jobs"`
+}
+
+// SharePlanRequest shares a plan with a user in the org, or changes their role if it's already shared with them
+type SharePlanRequest struct {
+	Email string        `json:"email"`
+	Role  PlanShareRole `json:"role"`
+}
+
+type ListPlanSharesResponse struct {
+	Shares []*PlanShare `json:"shares"`
+}
+
+type CreateApiTokenRequest struct {
+	Name   string          `json:"name"`
+	Scopes []ApiTokenScope `json:"scopes"`
+
+	// 0 for a token that doesn't expire
+	ExpiresInDays int `json:"expiresInDays,omitempty"`
+
+	// creates the token for a service account rather than the current user
+	ServiceAccountId string `json:"serviceAccountId,omitempty"`
+}
+
+type CreateApiTokenResponse struct {
+	// only returned when the token is created
+	Token    string    `json:"token"`
+	ApiToken *ApiToken `json:"apiToken"`
+}
+
+type ListApiTokensResponse struct {
+	Tokens []*ApiToken `json:"tokens"`
+}
+
+type ApiTokenSessionResponse struct {
+	ApiToken *ApiToken `json:"apiToken"`
+	Org      *Org      `json:"org"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name      string `json:"name"`
+	OrgRoleId string `json:"orgRoleId"`
+}
+
+type ListServiceAccountsResponse struct {
+	ServiceAccounts []*ServiceAccount `json:"serviceAccounts"`
+}
+
+// ListAuditEventsRequest is sent as query params
+type ListAuditEventsRequest struct {
+	Action string
+	User   string
+	PlanId string
+	Since  *time.Time
+	Until  *time.Time
+	Before string
+	Limit  int
+}
+
+// ListAuditEventsResponse returns events newest first. NextCursor is set when there are more events—pass it as 'before' to get the next page.
+type ListAuditEventsResponse struct {
+	Events     []*AuditEvent `json:"events"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// ReportExecutedCommandsRequest records commands the client ran from a plan's _apply.sh in the audit log
+type ReportExecutedCommandsRequest struct {
+	Commands   string `json:"commands"`
+	ExitStatus int    `json:"exitStatus"`
+	Attempt    int    `json:"attempt"`
+}
@@ -21,6 +21,8 @@ const (
 	StreamMessageBuildInfo         StreamMessageType = "buildInfo"
 	StreamMessagePromptMissingFile StreamMessageType = "promptMissingFile"
 	StreamMessageLoadContext       StreamMessageType = "loadContext"
+	StreamMessageToolCall          StreamMessageType = "toolCall"
+	StreamMessageCodeSearch        StreamMessageType = "codeSearch"
 	StreamMessageAborted           StreamMessageType = "aborted"
 	StreamMessageFinished          StreamMessageType = "finished"
 	StreamMessageError             StreamMessageType = "error"
@@ -43,6 +45,8 @@ type StreamMessage struct {
 	InitPrompt             string                   `json:"initPrompt,omitempty"`
 	InitReplies            []string                 `json:"initReplies,omitempty"`
 	InitBuildOnly          bool                     `json:"initBuildOnly,omitempty"`
+	ToolCall               *ConvoToolCall           `json:"toolCall,omitempty"`
+	CodeSearch             *CodeSearchRequest       `json:"codeSearch,omitempty"`
 
 	StreamMessages []StreamMessage `json:"streamMessages,omitempty"`
 }
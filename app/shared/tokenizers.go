@@ -0,0 +1,221 @@
+package shared
+
+import (
+	"embed"
+	"encoding/base64"
+	"io/fs"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Tokenizers are resolved per model so that context sizing and large context fallback selection use a count that matches the model's actual vocabulary.
+// Resolution order is: model id override -> publisher default -> DefaultTokenizerName.
+// Any BPE tokenizer that can't load its table (no embedded table, no cache, no network) falls back to the byte estimate rather than failing.
+
+type TokenizerName string
+
+const (
+	TokenizerO200kBase    TokenizerName = "o200k_base"
+	TokenizerCl100kBase   TokenizerName = "cl100k_base"
+	TokenizerByteEstimate TokenizerName = "byte_estimate"
+)
+
+const DefaultTokenizerName = TokenizerO200kBase
+
+type Tokenizer interface {
+	Name() TokenizerName
+	CountTokens(text string) int
+}
+
+// BPE tables (.tiktoken files) placed in tokenizer_data are embedded at build time — see tokenizer_data/README.md
+//
+//go:embed tokenizer_data
+var embeddedTokenizerData embed.FS
+
+var (
+	tokenizersMu         sync.RWMutex
+	tokenizersByName     = map[TokenizerName]Tokenizer{}
+	tokenizerByModelId   = map[ModelId]TokenizerName{}
+	tokenizerByPublisher = map[ModelPublisher]TokenizerName{}
+)
+
+func init() {
+	tiktoken.SetBpeLoader(&embeddedBpeLoader{fallback: tiktoken.NewDefaultBpeLoader()})
+
+	RegisterTokenizer(ByteEstimateTokenizer{})
+	RegisterTokenizer(newBpeTokenizer(TokenizerO200kBase))
+	RegisterTokenizer(newBpeTokenizer(TokenizerCl100kBase))
+
+	SetPublisherTokenizer(ModelPublisherOpenAI, TokenizerO200kBase)
+
+	// Anthropic doesn't publish its vocabulary; cl100k is the closest public BPE and TokenEstimatePaddingPct covers the remaining gap
+	SetPublisherTokenizer(ModelPublisherAnthropic, TokenizerCl100kBase)
+
+	// OpenAI-compatible vocabularies
+	SetPublisherTokenizer(ModelPublisherDeepSeek, TokenizerCl100kBase)
+	SetPublisherTokenizer(ModelPublisherQwen, TokenizerCl100kBase)
+	SetPublisherTokenizer(ModelPublisherZhipu, TokenizerCl100kBase)
+	SetPublisherTokenizer(ModelPublisherMistral, TokenizerCl100kBase)
+	SetPublisherTokenizer(ModelPublisherPerplexity, TokenizerCl100kBase)
+}
+
+func RegisterTokenizer(tokenizer Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizersByName[tokenizer.Name()] = tokenizer
+}
+
+func SetModelTokenizer(modelId ModelId, name TokenizerName) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizerByModelId[modelId] = name
+}
+
+func SetPublisherTokenizer(publisher ModelPublisher, name TokenizerName) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizerByPublisher[publisher] = name
+}
+
+func GetTokenizerByName(name TokenizerName) Tokenizer {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+
+	if tokenizer, ok := tokenizersByName[name]; ok {
+		return tokenizer
+	}
+	if tokenizer, ok := tokenizersByName[DefaultTokenizerName]; ok {
+		return tokenizer
+	}
+	return ByteEstimateTokenizer{}
+}
+
+func GetTokenizer(modelId ModelId, publisher ModelPublisher) Tokenizer {
+	tokenizersMu.RLock()
+	name, ok := tokenizerByModelId[modelId]
+	if !ok {
+		name, ok = tokenizerByPublisher[publisher]
+	}
+	tokenizersMu.RUnlock()
+
+	if !ok {
+		name = DefaultTokenizerName
+	}
+
+	return GetTokenizerByName(name)
+}
+
+func GetDefaultTokenizer() Tokenizer {
+	return GetTokenizerByName(DefaultTokenizerName)
+}
+
+func GetNumTokensForModel(modelId ModelId, publisher ModelPublisher, text string) int {
+	return GetTokenizer(modelId, publisher).CountTokens(text)
+}
+
+type ByteEstimateTokenizer struct{}
+
+func (ByteEstimateTokenizer) Name() TokenizerName {
+	return TokenizerByteEstimate
+}
+
+func (ByteEstimateTokenizer) CountTokens(text string) int {
+	return GetBytesToTokensEstimate(int64(len(text)))
+}
+
+type bpeTokenizer struct {
+	name    TokenizerName
+	once    sync.Once
+	tkm     *tiktoken.Tiktoken
+	loadErr error
+}
+
+func newBpeTokenizer(name TokenizerName) *bpeTokenizer {
+	return &bpeTokenizer{name: name}
+}
+
+func (t *bpeTokenizer) Name() TokenizerName {
+	return t.name
+}
+
+func (t *bpeTokenizer) CountTokens(text string) int {
+	t.once.Do(func() {
+		t.tkm, t.loadErr = tiktoken.GetEncoding(string(t.name))
+		if t.loadErr != nil {
+			log.Printf("error loading %s tokenizer, falling back to byte estimate: %v", t.name, t.loadErr)
+		}
+	})
+
+	if t.tkm == nil {
+		return ByteEstimateTokenizer{}.CountTokens(text)
+	}
+
+	return len(t.tkm.Encode(text, nil, nil))
+}
+
+// embeddedBpeLoader serves BPE tables from tokenizer_data when present, otherwise defers to tiktoken's default loader (TIKTOKEN_CACHE_DIR cache, then download)
+type embeddedBpeLoader struct {
+	fallback tiktoken.BpeLoader
+}
+
+func (l *embeddedBpeLoader) LoadTiktokenBpe(tiktokenBpeFile string) (map[string]int, error) {
+	bytes, err := fs.ReadFile(embeddedTokenizerData, path.Join("tokenizer_data", path.Base(tiktokenBpeFile)))
+	if err != nil {
+		return l.fallback.LoadTiktokenBpe(tiktokenBpeFile)
+	}
+
+	bpeRanks := make(map[string]int)
+	for _, line := range strings.Split(string(bytes), "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, " ")
+		if len(parts) != 2 {
+			return l.fallback.LoadTiktokenBpe(tiktokenBpeFile)
+		}
+		token, err := base64.StdEncoding.DecodeString(parts[0])
+		if err != nil {
+			return l.fallback.LoadTiktokenBpe(tiktokenBpeFile)
+		}
+		rank, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return l.fallback.LoadTiktokenBpe(tiktokenBpeFile)
+		}
+		bpeRanks[string(token)] = rank
+	}
+
+	return bpeRanks, nil
+}
+
+func (m ModelRoleConfig) GetPublisher(customModels map[ModelId]*CustomModel) ModelPublisher {
+	if m.BaseModelConfig != nil && m.BaseModelConfig.Publisher != "" {
+		return m.BaseModelConfig.Publisher
+	}
+
+	modelId := m.GetModelId()
+
+	builtInModel := BuiltInBaseModelsById[modelId]
+	if builtInModel != nil {
+		return builtInModel.Publisher
+	}
+
+	customModel := customModels[modelId]
+	if customModel != nil {
+		return customModel.Publisher
+	}
+
+	return ""
+}
+
+func (m ModelRoleConfig) GetTokenizer(settings *PlanSettings) Tokenizer {
+	var customModels map[ModelId]*CustomModel
+	if settings != nil {
+		customModels = settings.CustomModelsById
+	}
+	return GetTokenizer(m.GetModelId(), m.GetPublisher(customModels))
+}
@@ -0,0 +1,52 @@
+package shared
+
+import "testing"
+
+func TestGetTokenizerResolution(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelId   ModelId
+		publisher ModelPublisher
+		want      TokenizerName
+	}{
+		{"openai publisher", "openai/o3-high", ModelPublisherOpenAI, TokenizerO200kBase},
+		{"anthropic publisher", "anthropic/claude-sonnet-4", ModelPublisherAnthropic, TokenizerCl100kBase},
+		{"unknown publisher uses default", "some/custom-model", "", DefaultTokenizerName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetTokenizer(tt.modelId, tt.publisher).Name()
+			if got != tt.want {
+				t.Errorf("GetTokenizer(%q, %q) = %q, want %q", tt.modelId, tt.publisher, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelTokenizerOverridesPublisher(t *testing.T) {
+	modelId := ModelId("test/override-model")
+	SetModelTokenizer(modelId, TokenizerByteEstimate)
+	defer func() {
+		tokenizersMu.Lock()
+		delete(tokenizerByModelId, modelId)
+		tokenizersMu.Unlock()
+	}()
+
+	got := GetTokenizer(modelId, ModelPublisherOpenAI)
+	if got.Name() != TokenizerByteEstimate {
+		t.Fatalf("expected model override %q, got %q", TokenizerByteEstimate, got.Name())
+	}
+
+	text := "0123456789abcdef"
+	if n := got.CountTokens(text); n != GetBytesToTokensEstimate(int64(len(text))) {
+		t.Errorf("byte estimate tokenizer returned %d tokens", n)
+	}
+}
+
+func TestUnknownTokenizerNameFallsBackToDefault(t *testing.T) {
+	got := GetTokenizerByName("not-a-tokenizer")
+	if got.Name() != DefaultTokenizerName {
+		t.Errorf("expected %q, got %q", DefaultTokenizerName, got.Name())
+	}
+}
@@ -35,6 +35,9 @@ declare -a platforms=(
     "darwin/arm64"
 )
 
+log "Fetching embedded tokenizer tables..."
+app/scripts/fetch_tokenizer_data.sh
+
 log "Building Plandex CLI v${VERSION} for multiple platforms..."
 
 cd app/cli